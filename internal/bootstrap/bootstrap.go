@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"smctf/internal/config"
 	"smctf/internal/logging"
@@ -21,31 +23,51 @@ func BootstrapStackService(ctx context.Context, cfg config.Config, logger *loggi
 		return nil, fmt.Errorf("init repository: %w", err)
 	}
 
-	k8s, err := stack.NewKubernetesClientFromConfig(cfg.Stack)
+	clusters, err := stack.NewClustersFromConfig(cfg.Stack)
 	if err != nil {
 		return nil, fmt.Errorf("init kubernetes client: %w", err)
 	}
 
-	if cfg.Stack.RequireIngressNP {
-		ok, err := k8s.HasIngressNetworkPolicy(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("check ingress networkpolicy: %w", err)
+	for _, cluster := range clusters {
+		if cfg.Stack.RequireIngressNP {
+			ok, err := cluster.Client.HasIngressNetworkPolicy(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("check ingress networkpolicy: %w", err)
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("missing ingress networkpolicy")
+			}
 		}
 
-		if !ok {
-			return nil, fmt.Errorf("missing ingress networkpolicy")
+		for _, name := range stack.RuntimeClassNames(cfg.Stack) {
+			ok, err := cluster.Client.RuntimeClassExists(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("check runtimeclass %q: %w", name, err)
+			}
+
+			if !ok {
+				return nil, fmt.Errorf("missing runtimeclass %q in cluster %s", name, cluster.ID)
+			}
 		}
-	}
 
-	if count, err := k8s.CountSchedulableNodes(ctx); err != nil {
-		if log != nil {
-			log.Warn("count schedulable nodes failed", slog.Any("error", err))
+		if counts, err := cluster.Client.CountSchedulableNodes(ctx); err != nil {
+			if log != nil {
+				log.Warn("count schedulable nodes failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			}
+		} else if log != nil {
+			for _, pool := range slices.Sorted(maps.Keys(counts)) {
+				if pool == "" {
+					log.Info("schedulable nodes detected", slog.String("cluster_id", cluster.ID), slog.Int("count", counts[pool]), slog.String("role", clusterNodeRole(cfg.Stack, cluster.ID)))
+					continue
+				}
+
+				log.Info("schedulable nodes detected", slog.String("cluster_id", cluster.ID), slog.String("node_pool", pool), slog.Int("count", counts[pool]))
+			}
 		}
-	} else if log != nil {
-		log.Info("schedulable nodes detected", slog.Int("count", count), slog.String("role", cfg.Stack.StackNodeRole))
 	}
 
-	service := stack.NewService(cfg.Stack, repo, k8s)
+	service := stack.NewServiceWithClusters(cfg.Stack, repo, clusters)
 	scheduler := stack.NewScheduler(cfg.Stack.SchedulerInterval, service)
 	if cfg.Stack.LeaderElection.Enabled {
 		if cfg.Stack.UseMockKubernetes {
@@ -77,3 +99,13 @@ func BootstrapStackService(ctx context.Context, cfg config.Config, logger *loggi
 
 	return service, nil
 }
+
+func clusterNodeRole(cfg config.StackConfig, clusterID string) string {
+	for _, cluster := range cfg.Clusters {
+		if cluster.ID == clusterID && cluster.StackNodeRole != "" {
+			return cluster.StackNodeRole
+		}
+	}
+
+	return cfg.StackNodeRole
+}
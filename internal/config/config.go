@@ -3,7 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -42,8 +44,12 @@ type StackConfig struct {
 	NodePortMin       int
 	NodePortMax       int
 	PortLockTTL       time.Duration
+	NodePortCooldown  time.Duration
 	LeaderElection    LeaderElectionConfig
 
+	NodePortPools       []NodePortPool
+	DefaultNodePortPool string
+
 	DynamoTableName      string
 	AWSRegion            string
 	AWSEndpoint          string
@@ -58,6 +64,226 @@ type StackConfig struct {
 	UseMockKubernetes bool
 	RequireIngressNP  bool
 	StackNodeRole     string
+
+	NodeAddressTypes       []string
+	NodeAddressFamily      string
+	NodeAddressOverrideKey string
+	NodeAddressCacheTTL    time.Duration
+
+	CapacitySource      string
+	CapacityCPUMilli    int64
+	CapacityMemoryBytes int64
+	CapacityCacheTTL    time.Duration
+
+	QueueMaxLength   int
+	QueueMaxPerOwner int
+	QueueWaitTimeout time.Duration
+
+	DemandWindow         time.Duration
+	BalloonEnabled       bool
+	BalloonPriorityClass string
+	BalloonImage         string
+	BalloonMax           int
+
+	PlacementStrategy string
+
+	PriorityTiers       []PriorityTier
+	DefaultPriorityTier string
+
+	NodePools       []NodePool
+	DefaultNodePool string
+	// RuntimeClass is used by stacks whose node pool sets none, "" for the cluster default.
+	RuntimeClass string
+
+	Clusters       []ClusterConfig
+	ClusterRouting string
+
+	NamespaceMode                  string
+	NamespacePrefix                string
+	NamespaceNetworkPolicyTemplate string
+	NamespaceQuotaTemplate         string
+	NamespaceLimitRangeTemplate    string
+	NamespaceIngressExceptCIDRs    []string
+	NamespaceIPv6                  bool
+
+	ImageAllowlist    []string
+	ImageDigestPolicy string
+
+	// Resource ceilings and container defaults; zero means no limit or no default.
+	MaxContainerCPUMilli        int64
+	MaxContainerMemoryBytes     int64
+	MaxStackCPUMilli            int64
+	MaxStackMemoryBytes         int64
+	MaxContainers               int
+	DefaultContainerCPUMilli    int64
+	DefaultContainerMemoryBytes int64
+
+	// VolumeAllowlist holds the pod spec volume types stack pods may use, e.g. emptyDir.
+	VolumeAllowlist []string
+	// MaxEmptyDirBytes caps the sizeLimit every emptyDir volume must set.
+	MaxEmptyDirBytes int64
+	// WritablePathSizeBytes is the sizeLimit of the emptyDirs added for
+	// smctf.io/writable-paths.
+	WritablePathSizeBytes int64
+	// MaxConfigMapBytes caps the total keys and values of the ConfigMaps sent with a
+	// stack.
+	MaxConfigMapBytes int64
+
+	HardeningProfiles       []HardeningProfile
+	DefaultHardeningProfile string
+	PIDLimitAnnotation      string
+}
+
+// ClusterConfig is one Kubernetes cluster stacks are provisioned in. Empty connection
+// settings and node role fall back to the top-level K8S_* and STACK_NODE_ROLE values.
+type ClusterConfig struct {
+	ID             string
+	KubeConfigPath string
+	KubeContext    string
+	StackNodeRole  string
+	Region         string
+	PortPool       string
+}
+
+// PriorityTier maps a priority name accepted on create requests to a Kubernetes
+// PriorityClass. Tiers are ordered from lowest to highest priority.
+type PriorityTier struct {
+	Name      string
+	ClassName string
+}
+
+// NodePool is a set of nodes create requests can pick for their stack, such as arm64 or
+// high-memory nodes. Pods of the pool select its labels and get its tolerations, and run
+// with its RuntimeClass, e.g. gVisor for untrusted binaries.
+type NodePool struct {
+	Name         string
+	Labels       map[string]string
+	Tolerations  []NodePoolToleration
+	RuntimeClass string
+}
+
+// NodePoolToleration tolerates a taint of the pool's nodes. An empty Value tolerates the
+// key with any value, an empty Effect any effect.
+type NodePoolToleration struct {
+	Key    string
+	Value  string
+	Effect string
+}
+
+// HardeningProfile is a container security baseline a pod spec selects with the
+// smctf.io/hardening-profile annotation, e.g. a locked down one for web challenges and one
+// allowing a few capabilities for pwn.
+type HardeningProfile struct {
+	Name                   string
+	DropAllCapabilities    bool
+	AllowedCapabilities    []string
+	RunAsNonRoot           bool
+	RunAsUser              *int64
+	RunAsGroup             *int64
+	ReadOnlyRootFilesystem bool
+	PIDLimit               int64
+	// SeccompProfile is RuntimeDefault, Unconfined or localhost/<path>.
+	SeccompProfile string
+	// AppArmorProfile is empty, RuntimeDefault, Unconfined or localhost/<name>.
+	AppArmorProfile string
+}
+
+type NodePortPool struct {
+	Name string
+	Min  int
+	Max  int
+}
+
+const DefaultNodePortPoolName = "default"
+
+const (
+	CapacitySourceNone   = "none"
+	CapacitySourceStatic = "static"
+	CapacitySourceQuota  = "quota"
+	CapacitySourceNodes  = "nodes"
+)
+
+const (
+	NamespaceModeShared = "shared"
+	NamespaceModeOwner  = "owner"
+	NamespaceModeStack  = "stack"
+)
+
+// maxNamespacePrefixLength leaves room for the longest generated suffix, "owner-" or a
+// stack id, within the 63 character namespace name limit.
+const maxNamespacePrefixLength = 41
+
+const (
+	ImageDigestPolicyNone    = "none"
+	ImageDigestPolicyRequire = "require"
+)
+
+const (
+	ClusterRoutingLeastLoaded = "least_loaded"
+	ClusterRoutingRegion      = "region"
+)
+
+const (
+	PlacementNone              = "none"
+	PlacementSpread            = "spread"
+	PlacementBinpack           = "binpack"
+	PlacementOwnerAntiAffinity = "owner_anti_affinity"
+)
+
+// IsPlacementStrategy reports whether s names a known placement strategy.
+func IsPlacementStrategy(s string) bool {
+	switch s {
+	case PlacementNone, PlacementSpread, PlacementBinpack, PlacementOwnerAntiAffinity:
+		return true
+	default:
+		return false
+	}
+}
+
+// PortPools returns the configured NodePort pools, falling back to a single
+// default pool spanning NodePortMin-NodePortMax.
+func (c StackConfig) PortPools() []NodePortPool {
+	if len(c.NodePortPools) > 0 {
+		return c.NodePortPools
+	}
+
+	return []NodePortPool{{Name: DefaultNodePortPoolName, Min: c.NodePortMin, Max: c.NodePortMax}}
+}
+
+func (c StackConfig) DefaultPortPool() string {
+	if c.DefaultNodePortPool != "" {
+		return c.DefaultNodePortPool
+	}
+
+	return c.PortPools()[0].Name
+}
+
+// DefaultPriority returns the tier used when a create request does not set one, or ""
+// when no tiers are configured.
+func (c StackConfig) DefaultPriority() string {
+	if c.DefaultPriorityTier != "" {
+		return c.DefaultPriorityTier
+	}
+
+	if len(c.PriorityTiers) > 0 {
+		return c.PriorityTiers[0].Name
+	}
+
+	return ""
+}
+
+// DefaultNodePoolName returns the pool used when neither the request nor the pod spec
+// picks one, or "" when no node pools are configured.
+func (c StackConfig) DefaultNodePoolName() string {
+	if c.DefaultNodePool != "" {
+		return c.DefaultNodePool
+	}
+
+	if len(c.NodePools) > 0 {
+		return c.NodePools[0].Name
+	}
+
+	return ""
 }
 
 type LeaderElectionConfig struct {
@@ -133,6 +359,30 @@ func Load() (Config, error) {
 		errs = append(errs, err)
 	}
 
+	nodePortCooldown, err := getDuration("STACK_NODEPORT_COOLDOWN", 60*time.Second)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	nodePortPools, err := getNodePortPools("STACK_NODEPORT_POOLS")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	priorityTiers := getPriorityTiers("STACK_PRIORITY_TIERS")
+
+	nodePools, err := getNodePools("STACK_NODE_POOLS")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	hardeningProfiles, err := getHardeningProfiles("STACK_HARDENING_PROFILES")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	clusters := getClusters("STACK_CLUSTERS")
+
 	leaderEnabled, err := getEnvBool("LEADER_ELECTION_ENABLED", false)
 	if err != nil {
 		errs = append(errs, err)
@@ -188,6 +438,111 @@ func Load() (Config, error) {
 	}
 	stackNodeRole := getEnv("STACK_NODE_ROLE", "stack")
 
+	namespaceIPv6, err := getEnvBool("STACK_NAMESPACE_IPV6", false)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	nodeAddressCacheTTL, err := getDuration("STACK_NODE_ADDRESS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	capacityCPUMilli, err := getEnvOptionalCPUMilli("STACK_CAPACITY_CPU")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	capacityMemoryBytes, err := getEnvOptionalBytes("STACK_CAPACITY_MEMORY")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxContainerCPUMilli, err := getEnvOptionalCPUMilli("STACK_MAX_CONTAINER_CPU")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxContainerMemoryBytes, err := getEnvOptionalBytes("STACK_MAX_CONTAINER_MEMORY")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxStackCPUMilli, err := getEnvOptionalCPUMilli("STACK_MAX_STACK_CPU")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxStackMemoryBytes, err := getEnvOptionalBytes("STACK_MAX_STACK_MEMORY")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxContainers, err := getEnvInt("STACK_MAX_CONTAINERS", 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	defaultContainerCPUMilli, err := getEnvOptionalCPUMilli("STACK_DEFAULT_CONTAINER_CPU")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	defaultContainerMemoryBytes, err := getEnvOptionalBytes("STACK_DEFAULT_CONTAINER_MEMORY")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxEmptyDirBytes, err := getEnvBytes("STACK_MAX_EMPTYDIR_SIZE", "1Gi")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	writablePathSizeBytes, err := getEnvBytes("STACK_WRITABLE_PATH_SIZE", "64Mi")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxConfigMapBytes, err := getEnvBytes("STACK_MAX_CONFIGMAP_SIZE", "256Ki")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	capacityCacheTTL, err := getDuration("STACK_CAPACITY_CACHE_TTL", 15*time.Second)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	queueMaxLength, err := getEnvInt("STACK_QUEUE_MAX_LENGTH", 500)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	queueMaxPerOwner, err := getEnvInt("STACK_QUEUE_MAX_PER_OWNER", 1)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	queueWaitTimeout, err := getDuration("STACK_QUEUE_WAIT_TIMEOUT", 30*time.Minute)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	demandWindow, err := getDuration("STACK_DEMAND_WINDOW", 5*time.Minute)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	balloonEnabled, err := getEnvBool("STACK_BALLOON_ENABLED", false)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	balloonMax, err := getEnvInt("STACK_BALLOON_MAX", 10)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	cfg := Config{
 		AppEnv:                appEnv,
 		HTTPAddr:              httpAddr,
@@ -205,12 +560,15 @@ func Load() (Config, error) {
 			Value:   apiKeyValue,
 		},
 		Stack: StackConfig{
-			Namespace:         getEnv("STACK_NAMESPACE", "stacks"),
-			StackTTL:          stackTTL,
-			SchedulerInterval: schedulerInterval,
-			NodePortMin:       nodePortMin,
-			NodePortMax:       nodePortMax,
-			PortLockTTL:       portLockTTL,
+			Namespace:           getEnv("STACK_NAMESPACE", "stacks"),
+			StackTTL:            stackTTL,
+			SchedulerInterval:   schedulerInterval,
+			NodePortMin:         nodePortMin,
+			NodePortMax:         nodePortMax,
+			PortLockTTL:         portLockTTL,
+			NodePortCooldown:    nodePortCooldown,
+			NodePortPools:       nodePortPools,
+			DefaultNodePortPool: getEnv("STACK_NODEPORT_DEFAULT_POOL", ""),
 			LeaderElection: LeaderElectionConfig{
 				Enabled:       leaderEnabled,
 				Namespace:     getEnv("LEADER_ELECTION_NAMESPACE", "backend"),
@@ -232,6 +590,66 @@ func Load() (Config, error) {
 			UseMockKubernetes:    useMockK8s,
 			RequireIngressNP:     requireIngressNP,
 			StackNodeRole:        stackNodeRole,
+
+			NodeAddressTypes:       getEnvList("STACK_NODE_ADDRESS_TYPES", []string{"ExternalIP"}),
+			NodeAddressFamily:      strings.ToLower(getEnv("STACK_NODE_ADDRESS_FAMILY", "any")),
+			NodeAddressOverrideKey: getEnv("STACK_NODE_ADDRESS_OVERRIDE_KEY", ""),
+			NodeAddressCacheTTL:    nodeAddressCacheTTL,
+
+			CapacitySource:      strings.ToLower(getEnv("STACK_CAPACITY_SOURCE", CapacitySourceNone)),
+			CapacityCPUMilli:    capacityCPUMilli,
+			CapacityMemoryBytes: capacityMemoryBytes,
+			CapacityCacheTTL:    capacityCacheTTL,
+
+			QueueMaxLength:   queueMaxLength,
+			QueueMaxPerOwner: queueMaxPerOwner,
+			QueueWaitTimeout: queueWaitTimeout,
+
+			DemandWindow:         demandWindow,
+			BalloonEnabled:       balloonEnabled,
+			BalloonPriorityClass: getEnv("STACK_BALLOON_PRIORITY_CLASS", ""),
+			BalloonImage:         getEnv("STACK_BALLOON_IMAGE", "registry.k8s.io/pause:3.10"),
+			BalloonMax:           balloonMax,
+
+			PlacementStrategy: strings.ToLower(getEnv("STACK_PLACEMENT_STRATEGY", PlacementNone)),
+
+			PriorityTiers:       priorityTiers,
+			DefaultPriorityTier: getEnv("STACK_PRIORITY_DEFAULT_TIER", ""),
+
+			NodePools:       nodePools,
+			DefaultNodePool: getEnv("STACK_NODE_POOL_DEFAULT", ""),
+			RuntimeClass:    getEnv("STACK_RUNTIME_CLASS", ""),
+
+			Clusters:       clusters,
+			ClusterRouting: strings.ToLower(getEnv("STACK_CLUSTER_ROUTING", ClusterRoutingLeastLoaded)),
+
+			NamespaceMode:                  strings.ToLower(getEnv("STACK_NAMESPACE_MODE", NamespaceModeShared)),
+			NamespacePrefix:                getEnv("STACK_NAMESPACE_PREFIX", "smctf-"),
+			NamespaceNetworkPolicyTemplate: getEnv("STACK_NAMESPACE_NETWORK_POLICY_TEMPLATE", ""),
+			NamespaceQuotaTemplate:         getEnv("STACK_NAMESPACE_QUOTA_TEMPLATE", ""),
+			NamespaceLimitRangeTemplate:    getEnv("STACK_NAMESPACE_LIMIT_RANGE_TEMPLATE", ""),
+			NamespaceIngressExceptCIDRs:    getEnvList("STACK_NAMESPACE_INGRESS_EXCEPT_CIDRS", nil),
+			NamespaceIPv6:                  namespaceIPv6,
+
+			ImageAllowlist:    getEnvList("STACK_IMAGE_ALLOWLIST", nil),
+			ImageDigestPolicy: strings.ToLower(getEnv("STACK_IMAGE_DIGEST_POLICY", ImageDigestPolicyNone)),
+
+			MaxContainerCPUMilli:        maxContainerCPUMilli,
+			MaxContainerMemoryBytes:     maxContainerMemoryBytes,
+			MaxStackCPUMilli:            maxStackCPUMilli,
+			MaxStackMemoryBytes:         maxStackMemoryBytes,
+			MaxContainers:               maxContainers,
+			DefaultContainerCPUMilli:    defaultContainerCPUMilli,
+			DefaultContainerMemoryBytes: defaultContainerMemoryBytes,
+
+			VolumeAllowlist:       getEnvList("STACK_VOLUME_ALLOWLIST", []string{"emptyDir", "configMap", "downwardAPI"}),
+			MaxEmptyDirBytes:      maxEmptyDirBytes,
+			WritablePathSizeBytes: writablePathSizeBytes,
+			MaxConfigMapBytes:     maxConfigMapBytes,
+
+			HardeningProfiles:       hardeningProfiles,
+			DefaultHardeningProfile: getEnv("STACK_HARDENING_PROFILE_DEFAULT", ""),
+			PIDLimitAnnotation:      getEnv("STACK_PID_LIMIT_ANNOTATION", ""),
 		},
 	}
 
@@ -255,6 +673,204 @@ func getEnv(key, def string) string {
 	return v
 }
 
+func getEnvList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+
+	out := make([]string, 0)
+	for item := range strings.SplitSeq(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		out = append(out, item)
+	}
+
+	return out
+}
+
+func getNodePortPools(key string) ([]NodePortPool, error) {
+	names := getEnvList(key, nil)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	pools := make([]NodePortPool, 0, len(names))
+	for _, name := range names {
+		prefix := "STACK_NODEPORT_POOL_" + envKeySuffix(name)
+		minPort, err := getEnvInt(prefix+"_MIN", 0)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		maxPort, err := getEnvInt(prefix+"_MAX", 0)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		pools = append(pools, NodePortPool{Name: name, Min: minPort, Max: maxPort})
+	}
+
+	return pools, errors.Join(errs...)
+}
+
+func getPriorityTiers(key string) []PriorityTier {
+	names := getEnvList(key, nil)
+	if len(names) == 0 {
+		return nil
+	}
+
+	tiers := make([]PriorityTier, 0, len(names))
+	for _, name := range names {
+		tiers = append(tiers, PriorityTier{
+			Name:      name,
+			ClassName: getEnv("STACK_PRIORITY_TIER_"+envKeySuffix(name)+"_CLASS", ""),
+		})
+	}
+
+	return tiers
+}
+
+func getNodePools(key string) ([]NodePool, error) {
+	names := getEnvList(key, nil)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	pools := make([]NodePool, 0, len(names))
+	for _, name := range names {
+		prefix := "STACK_NODE_POOL_" + envKeySuffix(name)
+		pool := NodePool{
+			Name:         name,
+			Labels:       make(map[string]string),
+			RuntimeClass: getEnv(prefix+"_RUNTIME_CLASS", ""),
+		}
+
+		for _, item := range getEnvList(prefix+"_LABELS", nil) {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				errs = append(errs, fmt.Errorf("%s_LABELS entry %q must be key=value", prefix, item))
+				continue
+			}
+			pool.Labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+
+		// Tolerations use the taint syntax of kubectl: key[=value][:effect].
+		for _, item := range getEnvList(prefix+"_TOLERATIONS", nil) {
+			spec, effect, _ := strings.Cut(item, ":")
+			k, v, _ := strings.Cut(spec, "=")
+			if strings.TrimSpace(k) == "" {
+				errs = append(errs, fmt.Errorf("%s_TOLERATIONS entry %q must be key[=value][:effect]", prefix, item))
+				continue
+			}
+			pool.Tolerations = append(pool.Tolerations, NodePoolToleration{
+				Key:    strings.TrimSpace(k),
+				Value:  strings.TrimSpace(v),
+				Effect: strings.TrimSpace(effect),
+			})
+		}
+
+		pools = append(pools, pool)
+	}
+
+	return pools, errors.Join(errs...)
+}
+
+func getHardeningProfiles(key string) ([]HardeningProfile, error) {
+	names := getEnvList(key, nil)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	profiles := make([]HardeningProfile, 0, len(names))
+	for _, name := range names {
+		prefix := "STACK_HARDENING_PROFILE_" + envKeySuffix(name)
+		profile := HardeningProfile{
+			Name:                name,
+			AllowedCapabilities: getEnvList(prefix+"_ALLOWED_CAPABILITIES", nil),
+			SeccompProfile:      getEnv(prefix+"_SECCOMP_PROFILE", "RuntimeDefault"),
+			AppArmorProfile:     getEnv(prefix+"_APPARMOR_PROFILE", ""),
+		}
+
+		var err error
+		if profile.DropAllCapabilities, err = getEnvBool(prefix+"_DROP_ALL_CAPABILITIES", false); err != nil {
+			errs = append(errs, err)
+		}
+
+		if profile.RunAsNonRoot, err = getEnvBool(prefix+"_RUN_AS_NON_ROOT", false); err != nil {
+			errs = append(errs, err)
+		}
+
+		if profile.ReadOnlyRootFilesystem, err = getEnvBool(prefix+"_READ_ONLY_ROOT_FILESYSTEM", false); err != nil {
+			errs = append(errs, err)
+		}
+
+		if profile.RunAsUser, err = getEnvOptionalInt64(prefix + "_RUN_AS_USER"); err != nil {
+			errs = append(errs, err)
+		}
+
+		if profile.RunAsGroup, err = getEnvOptionalInt64(prefix + "_RUN_AS_GROUP"); err != nil {
+			errs = append(errs, err)
+		}
+
+		pidLimit, err := getEnvInt(prefix+"_PID_LIMIT", 0)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		profile.PIDLimit = int64(pidLimit)
+
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, errors.Join(errs...)
+}
+
+func getEnvOptionalInt64(key string) (*int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+
+	return &n, nil
+}
+
+func getClusters(key string) []ClusterConfig {
+	ids := getEnvList(key, nil)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	clusters := make([]ClusterConfig, 0, len(ids))
+	for _, id := range ids {
+		prefix := "STACK_CLUSTER_" + envKeySuffix(id)
+		clusters = append(clusters, ClusterConfig{
+			ID:             id,
+			KubeConfigPath: getEnv(prefix+"_KUBECONFIG", ""),
+			KubeContext:    getEnv(prefix+"_CONTEXT", ""),
+			StackNodeRole:  getEnv(prefix+"_NODE_ROLE", ""),
+			Region:         getEnv(prefix+"_REGION", ""),
+			PortPool:       getEnv(prefix+"_PORT_POOL", ""),
+		})
+	}
+
+	return clusters
+}
+
+func envKeySuffix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
 func getEnvInt(key string, def int) (int, error) {
 	v := os.Getenv(key)
 	if v == "" {
@@ -339,6 +955,26 @@ func getEnvBytes(key, def string) (int64, error) {
 	return b, nil
 }
 
+// getEnvOptionalCPUMilli is getEnvCPUMilli for settings where unset means 0.
+func getEnvOptionalCPUMilli(key string) (int64, error) {
+	if os.Getenv(key) == "" {
+		return 0, nil
+	}
+
+	return getEnvCPUMilli(key, "")
+}
+
+// getEnvOptionalBytes is getEnvBytes for settings where unset means 0.
+func getEnvOptionalBytes(key string) (int64, error) {
+	if os.Getenv(key) == "" {
+		return 0, nil
+	}
+
+	return getEnvBytes(key, "")
+}
+
+var validNodeAddressTypes = []string{"ExternalIP", "InternalIP", "Hostname", "ExternalDNS", "InternalDNS"}
+
 func validateConfig(cfg Config) error {
 	var errs []error
 
@@ -381,10 +1017,40 @@ func validateConfig(cfg Config) error {
 		errs = append(errs, errors.New("STACK_NODEPORT range is invalid"))
 	}
 
+	errs = append(errs, validateNodePortPools(cfg.Stack)...)
+	errs = append(errs, validatePriorityTiers(cfg.Stack)...)
+	errs = append(errs, validateNodePools(cfg.Stack)...)
+	errs = append(errs, validateClusters(cfg.Stack)...)
+	errs = append(errs, validateImagePolicy(cfg.Stack)...)
+	errs = append(errs, validateResourceLimits(cfg.Stack)...)
+	errs = append(errs, validateVolumePolicy(cfg.Stack)...)
+	errs = append(errs, validateHardeningProfiles(cfg.Stack)...)
+
+	switch cfg.Stack.NamespaceMode {
+	case NamespaceModeShared:
+	case NamespaceModeOwner, NamespaceModeStack:
+		if !isValidPoolName(cfg.Stack.NamespacePrefix) || len(cfg.Stack.NamespacePrefix) > maxNamespacePrefixLength {
+			errs = append(errs, fmt.Errorf("STACK_NAMESPACE_PREFIX must be at most %d lowercase alphanumeric characters or '-'", maxNamespacePrefixLength))
+		}
+
+		// The quota source reads STACK_NAMESPACE, which holds no stacks in these modes.
+		if cfg.Stack.CapacitySource == CapacitySourceQuota {
+			errs = append(errs, fmt.Errorf("STACK_CAPACITY_SOURCE=quota requires STACK_NAMESPACE_MODE=%s", NamespaceModeShared))
+		}
+
+		errs = append(errs, validateNamespaceIngressExcept(cfg.Stack)...)
+	default:
+		errs = append(errs, fmt.Errorf("STACK_NAMESPACE_MODE must be one of %s, %s, %s", NamespaceModeShared, NamespaceModeOwner, NamespaceModeStack))
+	}
+
 	if cfg.Stack.PortLockTTL <= 0 {
 		errs = append(errs, errors.New("STACK_PORT_LOCK_TTL must be positive"))
 	}
 
+	if cfg.Stack.NodePortCooldown < 0 {
+		errs = append(errs, errors.New("STACK_NODEPORT_COOLDOWN must not be negative"))
+	}
+
 	if cfg.Stack.LeaderElection.Enabled {
 		if cfg.Stack.LeaderElection.Namespace == "" {
 			errs = append(errs, errors.New("LEADER_ELECTION_NAMESPACE must not be empty when LEADER_ELECTION_ENABLED=true"))
@@ -435,6 +1101,74 @@ func validateConfig(cfg Config) error {
 		errs = append(errs, errors.New("STACK_NODE_ROLE must not be empty"))
 	}
 
+	if len(cfg.Stack.NodeAddressTypes) == 0 {
+		errs = append(errs, errors.New("STACK_NODE_ADDRESS_TYPES must not be empty"))
+	}
+
+	for _, t := range cfg.Stack.NodeAddressTypes {
+		if !slices.Contains(validNodeAddressTypes, t) {
+			errs = append(errs, fmt.Errorf("STACK_NODE_ADDRESS_TYPES contains unsupported type %q", t))
+		}
+	}
+
+	switch cfg.Stack.NodeAddressFamily {
+	case "any", "ipv4", "ipv6":
+	default:
+		errs = append(errs, errors.New("STACK_NODE_ADDRESS_FAMILY must be one of any, ipv4, ipv6"))
+	}
+
+	if cfg.Stack.NodeAddressCacheTTL < 0 {
+		errs = append(errs, errors.New("STACK_NODE_ADDRESS_CACHE_TTL must not be negative"))
+	}
+
+	switch cfg.Stack.CapacitySource {
+	case CapacitySourceNone, CapacitySourceQuota, CapacitySourceNodes:
+	case CapacitySourceStatic:
+		if cfg.Stack.CapacityCPUMilli <= 0 || cfg.Stack.CapacityMemoryBytes <= 0 {
+			errs = append(errs, errors.New("STACK_CAPACITY_CPU and STACK_CAPACITY_MEMORY must be positive when STACK_CAPACITY_SOURCE=static"))
+		}
+	default:
+		errs = append(errs, errors.New("STACK_CAPACITY_SOURCE must be one of none, static, quota, nodes"))
+	}
+
+	if cfg.Stack.CapacityCacheTTL < 0 {
+		errs = append(errs, errors.New("STACK_CAPACITY_CACHE_TTL must not be negative"))
+	}
+
+	if cfg.Stack.QueueMaxLength <= 0 {
+		errs = append(errs, errors.New("STACK_QUEUE_MAX_LENGTH must be positive"))
+	}
+
+	if cfg.Stack.QueueMaxPerOwner <= 0 {
+		errs = append(errs, errors.New("STACK_QUEUE_MAX_PER_OWNER must be positive"))
+	}
+
+	if cfg.Stack.QueueWaitTimeout <= 0 {
+		errs = append(errs, errors.New("STACK_QUEUE_WAIT_TIMEOUT must be positive"))
+	}
+
+	if cfg.Stack.DemandWindow <= 0 {
+		errs = append(errs, errors.New("STACK_DEMAND_WINDOW must be positive"))
+	}
+
+	if cfg.Stack.BalloonEnabled {
+		if cfg.Stack.BalloonPriorityClass == "" {
+			errs = append(errs, errors.New("STACK_BALLOON_PRIORITY_CLASS must not be empty when STACK_BALLOON_ENABLED=true"))
+		}
+
+		if cfg.Stack.BalloonImage == "" {
+			errs = append(errs, errors.New("STACK_BALLOON_IMAGE must not be empty when STACK_BALLOON_ENABLED=true"))
+		}
+
+		if cfg.Stack.BalloonMax <= 0 {
+			errs = append(errs, errors.New("STACK_BALLOON_MAX must be positive when STACK_BALLOON_ENABLED=true"))
+		}
+	}
+
+	if !IsPlacementStrategy(cfg.Stack.PlacementStrategy) {
+		errs = append(errs, errors.New("STACK_PLACEMENT_STRATEGY must be one of none, spread, binpack, owner_anti_affinity"))
+	}
+
 	if !cfg.Stack.UseMockRepository && cfg.Stack.DynamoTableName == "" {
 		errs = append(errs, errors.New("DDB_STACK_TABLE must not be empty when DDB_USE_MOCK=false"))
 	}
@@ -450,6 +1184,374 @@ func validateConfig(cfg Config) error {
 	return errors.Join(errs...)
 }
 
+func validateNodePortPools(cfg StackConfig) []error {
+	var errs []error
+	pools := cfg.NodePortPools
+	seen := make(map[string]struct{}, len(pools))
+	if len(pools) == 0 {
+		seen[DefaultNodePortPoolName] = struct{}{}
+	}
+
+	for i, pool := range pools {
+		if !isValidPoolName(pool.Name) {
+			errs = append(errs, fmt.Errorf("STACK_NODEPORT_POOLS contains invalid pool name %q", pool.Name))
+		}
+
+		if _, exists := seen[pool.Name]; exists {
+			errs = append(errs, fmt.Errorf("STACK_NODEPORT_POOLS contains duplicate pool %q", pool.Name))
+		}
+		seen[pool.Name] = struct{}{}
+
+		if pool.Min < 1 || pool.Max > 65535 || pool.Min > pool.Max {
+			errs = append(errs, fmt.Errorf("STACK_NODEPORT_POOL_%s range is invalid", envKeySuffix(pool.Name)))
+			continue
+		}
+
+		for _, other := range pools[:i] {
+			if pool.Min <= other.Max && other.Min <= pool.Max {
+				errs = append(errs, fmt.Errorf("STACK_NODEPORT_POOL_%s overlaps pool %q", envKeySuffix(pool.Name), other.Name))
+			}
+		}
+	}
+
+	if cfg.DefaultNodePortPool != "" {
+		if _, ok := seen[cfg.DefaultNodePortPool]; !ok {
+			errs = append(errs, fmt.Errorf("STACK_NODEPORT_DEFAULT_POOL %q is not a configured pool", cfg.DefaultNodePortPool))
+		}
+	}
+
+	return errs
+}
+
+func validatePriorityTiers(cfg StackConfig) []error {
+	var errs []error
+	seen := make(map[string]struct{}, len(cfg.PriorityTiers))
+	for _, tier := range cfg.PriorityTiers {
+		if !isValidPoolName(tier.Name) {
+			errs = append(errs, fmt.Errorf("STACK_PRIORITY_TIERS contains invalid tier name %q", tier.Name))
+		}
+
+		if _, exists := seen[tier.Name]; exists {
+			errs = append(errs, fmt.Errorf("STACK_PRIORITY_TIERS contains duplicate tier %q", tier.Name))
+		}
+		seen[tier.Name] = struct{}{}
+
+		if tier.ClassName == "" {
+			errs = append(errs, fmt.Errorf("STACK_PRIORITY_TIER_%s_CLASS must not be empty", envKeySuffix(tier.Name)))
+		}
+	}
+
+	if cfg.DefaultPriorityTier != "" {
+		if _, ok := seen[cfg.DefaultPriorityTier]; !ok {
+			errs = append(errs, fmt.Errorf("STACK_PRIORITY_DEFAULT_TIER %q is not a configured tier", cfg.DefaultPriorityTier))
+		}
+	}
+
+	return errs
+}
+
+func validateNodePools(cfg StackConfig) []error {
+	var errs []error
+	seen := make(map[string]struct{}, len(cfg.NodePools))
+	for _, pool := range cfg.NodePools {
+		if !isValidPoolName(pool.Name) {
+			errs = append(errs, fmt.Errorf("STACK_NODE_POOLS contains invalid pool name %q", pool.Name))
+		}
+
+		if _, exists := seen[pool.Name]; exists {
+			errs = append(errs, fmt.Errorf("STACK_NODE_POOLS contains duplicate pool %q", pool.Name))
+		}
+		seen[pool.Name] = struct{}{}
+
+		if len(pool.Labels) == 0 {
+			errs = append(errs, fmt.Errorf("STACK_NODE_POOL_%s_LABELS must not be empty", envKeySuffix(pool.Name)))
+		}
+
+		for _, toleration := range pool.Tolerations {
+			switch toleration.Effect {
+			case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+			default:
+				errs = append(errs, fmt.Errorf("STACK_NODE_POOL_%s_TOLERATIONS has unknown effect %q", envKeySuffix(pool.Name), toleration.Effect))
+			}
+		}
+
+		if pool.RuntimeClass != "" && !isValidRuntimeClassName(pool.RuntimeClass) {
+			errs = append(errs, fmt.Errorf("STACK_NODE_POOL_%s_RUNTIME_CLASS %q is not a valid RuntimeClass name", envKeySuffix(pool.Name), pool.RuntimeClass))
+		}
+	}
+
+	if cfg.RuntimeClass != "" && !isValidRuntimeClassName(cfg.RuntimeClass) {
+		errs = append(errs, fmt.Errorf("STACK_RUNTIME_CLASS %q is not a valid RuntimeClass name", cfg.RuntimeClass))
+	}
+
+	if cfg.DefaultNodePool != "" {
+		if _, ok := seen[cfg.DefaultNodePool]; !ok {
+			errs = append(errs, fmt.Errorf("STACK_NODE_POOL_DEFAULT %q is not a configured pool", cfg.DefaultNodePool))
+		}
+	}
+
+	if len(cfg.NodePools) > 0 {
+		for _, cluster := range cfg.Clusters {
+			if cluster.StackNodeRole != "" {
+				errs = append(errs, fmt.Errorf("STACK_CLUSTER_%s_NODE_ROLE cannot be combined with STACK_NODE_POOLS", envKeySuffix(cluster.ID)))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateImagePolicy(cfg StackConfig) []error {
+	var errs []error
+	for _, entry := range cfg.ImageAllowlist {
+		if strings.Contains(entry, "://") || strings.ContainsAny(entry, " @") || strings.Trim(entry, "/") == "" {
+			errs = append(errs, fmt.Errorf("STACK_IMAGE_ALLOWLIST entry %q must be a registry or repository such as ghcr.io/acme", entry))
+		}
+	}
+
+	switch cfg.ImageDigestPolicy {
+	case ImageDigestPolicyNone, ImageDigestPolicyRequire:
+	default:
+		errs = append(errs, fmt.Errorf("STACK_IMAGE_DIGEST_POLICY must be one of %s, %s", ImageDigestPolicyNone, ImageDigestPolicyRequire))
+	}
+
+	return errs
+}
+
+func validateResourceLimits(cfg StackConfig) []error {
+	var errs []error
+	if cfg.MaxContainers < 0 {
+		errs = append(errs, errors.New("STACK_MAX_CONTAINERS must not be negative"))
+	}
+
+	if exceedsLimit(cfg.MaxContainerCPUMilli, cfg.MaxStackCPUMilli) {
+		errs = append(errs, errors.New("STACK_MAX_CONTAINER_CPU must not exceed STACK_MAX_STACK_CPU"))
+	}
+
+	if exceedsLimit(cfg.MaxContainerMemoryBytes, cfg.MaxStackMemoryBytes) {
+		errs = append(errs, errors.New("STACK_MAX_CONTAINER_MEMORY must not exceed STACK_MAX_STACK_MEMORY"))
+	}
+
+	if exceedsLimit(cfg.DefaultContainerCPUMilli, cfg.MaxContainerCPUMilli) || exceedsLimit(cfg.DefaultContainerCPUMilli, cfg.MaxStackCPUMilli) {
+		errs = append(errs, errors.New("STACK_DEFAULT_CONTAINER_CPU must not exceed the configured CPU maximums"))
+	}
+
+	if exceedsLimit(cfg.DefaultContainerMemoryBytes, cfg.MaxContainerMemoryBytes) || exceedsLimit(cfg.DefaultContainerMemoryBytes, cfg.MaxStackMemoryBytes) {
+		errs = append(errs, errors.New("STACK_DEFAULT_CONTAINER_MEMORY must not exceed the configured memory maximums"))
+	}
+
+	return errs
+}
+
+// maxConfigMapBytes is the size Kubernetes allows a single ConfigMap.
+const maxConfigMapBytes = 1 << 20
+
+// volumeTypes are the pod spec volume sources STACK_VOLUME_ALLOWLIST may name. hostPath
+// is never allowed.
+var volumeTypes = []string{
+	"emptyDir", "configMap", "downwardAPI", "secret", "projected", "persistentVolumeClaim",
+	"ephemeral", "csi", "nfs", "iscsi", "image",
+}
+
+func validateVolumePolicy(cfg StackConfig) []error {
+	var errs []error
+	for _, entry := range cfg.VolumeAllowlist {
+		if !slices.Contains(volumeTypes, entry) {
+			errs = append(errs, fmt.Errorf("STACK_VOLUME_ALLOWLIST entry %q must be one of %s", entry, strings.Join(volumeTypes, ", ")))
+		}
+	}
+
+	if cfg.MaxEmptyDirBytes <= 0 {
+		errs = append(errs, errors.New("STACK_MAX_EMPTYDIR_SIZE must be positive"))
+	}
+
+	if cfg.WritablePathSizeBytes <= 0 {
+		errs = append(errs, errors.New("STACK_WRITABLE_PATH_SIZE must be positive"))
+	} else if exceedsLimit(cfg.WritablePathSizeBytes, cfg.MaxEmptyDirBytes) {
+		errs = append(errs, errors.New("STACK_WRITABLE_PATH_SIZE must not exceed STACK_MAX_EMPTYDIR_SIZE"))
+	}
+
+	if cfg.MaxConfigMapBytes <= 0 || cfg.MaxConfigMapBytes > maxConfigMapBytes {
+		errs = append(errs, errors.New("STACK_MAX_CONFIGMAP_SIZE must be positive and at most 1Mi"))
+	}
+
+	return errs
+}
+
+// validateNamespaceIngressExcept checks the CIDRs cut out of the built-in NetworkPolicy's
+// 0.0.0.0/0 and ::/0 ingress blocks.
+func validateNamespaceIngressExcept(cfg StackConfig) []error {
+	var errs []error
+	for _, entry := range cfg.NamespaceIngressExceptCIDRs {
+		ip, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("STACK_NAMESPACE_INGRESS_EXCEPT_CIDRS entry %q must be a CIDR", entry))
+			continue
+		}
+
+		if ones, _ := ipNet.Mask.Size(); ones == 0 {
+			errs = append(errs, fmt.Errorf("STACK_NAMESPACE_INGRESS_EXCEPT_CIDRS entry %q must not cover every address", entry))
+		}
+
+		if ip.To4() == nil && !cfg.NamespaceIPv6 {
+			errs = append(errs, fmt.Errorf("STACK_NAMESPACE_INGRESS_EXCEPT_CIDRS entry %q requires STACK_NAMESPACE_IPV6", entry))
+		}
+	}
+
+	return errs
+}
+
+// exceedsLimit reports whether v is above limit, where a zero limit means none.
+func exceedsLimit(v, limit int64) bool {
+	return limit > 0 && v > limit
+}
+
+func validateHardeningProfiles(cfg StackConfig) []error {
+	var errs []error
+	seen := make(map[string]struct{}, len(cfg.HardeningProfiles))
+	pidLimits := false
+	for _, profile := range cfg.HardeningProfiles {
+		prefix := "STACK_HARDENING_PROFILE_" + envKeySuffix(profile.Name)
+		if !isValidPoolName(profile.Name) {
+			errs = append(errs, fmt.Errorf("STACK_HARDENING_PROFILES contains invalid profile name %q", profile.Name))
+		}
+
+		if _, exists := seen[profile.Name]; exists {
+			errs = append(errs, fmt.Errorf("STACK_HARDENING_PROFILES contains duplicate profile %q", profile.Name))
+		}
+		seen[profile.Name] = struct{}{}
+
+		for _, capability := range profile.AllowedCapabilities {
+			if !isValidCapability(capability) {
+				errs = append(errs, fmt.Errorf("%s_ALLOWED_CAPABILITIES entry %q must be a capability name such as NET_BIND_SERVICE", prefix, capability))
+			}
+		}
+
+		if (profile.RunAsUser != nil && *profile.RunAsUser < 0) || (profile.RunAsGroup != nil && *profile.RunAsGroup < 0) {
+			errs = append(errs, fmt.Errorf("%s_RUN_AS_USER and _RUN_AS_GROUP must not be negative", prefix))
+		}
+
+		if profile.RunAsNonRoot && profile.RunAsUser != nil && *profile.RunAsUser == 0 {
+			errs = append(errs, fmt.Errorf("%s_RUN_AS_USER must not be 0 with _RUN_AS_NON_ROOT=true", prefix))
+		}
+
+		if profile.PIDLimit < 0 {
+			errs = append(errs, fmt.Errorf("%s_PID_LIMIT must not be negative", prefix))
+		}
+		pidLimits = pidLimits || profile.PIDLimit > 0
+
+		switch {
+		case profile.SeccompProfile == "RuntimeDefault", profile.SeccompProfile == "Unconfined":
+		case strings.HasPrefix(profile.SeccompProfile, "localhost/") && len(profile.SeccompProfile) > len("localhost/"):
+		default:
+			errs = append(errs, fmt.Errorf("%s_SECCOMP_PROFILE must be RuntimeDefault, Unconfined or localhost/<path>", prefix))
+		}
+
+		switch {
+		case profile.AppArmorProfile == "", profile.AppArmorProfile == "RuntimeDefault", profile.AppArmorProfile == "Unconfined":
+		case strings.HasPrefix(profile.AppArmorProfile, "localhost/") && len(profile.AppArmorProfile) > len("localhost/"):
+		default:
+			errs = append(errs, fmt.Errorf("%s_APPARMOR_PROFILE must be RuntimeDefault, Unconfined or localhost/<name>", prefix))
+		}
+	}
+
+	if cfg.DefaultHardeningProfile != "" {
+		if _, ok := seen[cfg.DefaultHardeningProfile]; !ok {
+			errs = append(errs, fmt.Errorf("STACK_HARDENING_PROFILE_DEFAULT %q is not a configured profile", cfg.DefaultHardeningProfile))
+		}
+	}
+
+	if pidLimits && cfg.PIDLimitAnnotation == "" {
+		errs = append(errs, errors.New("STACK_PID_LIMIT_ANNOTATION must not be empty when a hardening profile sets _PID_LIMIT"))
+	}
+
+	return errs
+}
+
+// isValidCapability reports whether name is a Linux capability name without the CAP_
+// prefix, the way Kubernetes expects it.
+func isValidCapability(name string) bool {
+	if name == "" || strings.HasPrefix(name, "CAP_") {
+		return false
+	}
+
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateClusters(cfg StackConfig) []error {
+	var errs []error
+	switch cfg.ClusterRouting {
+	case ClusterRoutingLeastLoaded, ClusterRoutingRegion:
+	default:
+		errs = append(errs, fmt.Errorf("STACK_CLUSTER_ROUTING must be one of %s, %s", ClusterRoutingLeastLoaded, ClusterRoutingRegion))
+	}
+
+	pools := make(map[string]struct{})
+	for _, pool := range cfg.PortPools() {
+		pools[pool.Name] = struct{}{}
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Clusters))
+	for _, cluster := range cfg.Clusters {
+		if !isValidPoolName(cluster.ID) {
+			errs = append(errs, fmt.Errorf("STACK_CLUSTERS contains invalid cluster id %q", cluster.ID))
+		}
+
+		if _, exists := seen[cluster.ID]; exists {
+			errs = append(errs, fmt.Errorf("STACK_CLUSTERS contains duplicate cluster %q", cluster.ID))
+		}
+		seen[cluster.ID] = struct{}{}
+
+		if cluster.PortPool != "" {
+			if _, ok := pools[cluster.PortPool]; !ok {
+				errs = append(errs, fmt.Errorf("STACK_CLUSTER_%s_PORT_POOL %q is not a configured pool", envKeySuffix(cluster.ID), cluster.PortPool))
+			}
+		}
+
+		if cfg.ClusterRouting == ClusterRoutingRegion && cluster.Region == "" {
+			errs = append(errs, fmt.Errorf("STACK_CLUSTER_%s_REGION is required with STACK_CLUSTER_ROUTING=%s", envKeySuffix(cluster.ID), ClusterRoutingRegion))
+		}
+	}
+
+	return errs
+}
+
+func isValidPoolName(name string) bool {
+	if name == "" || len(name) > 63 {
+		return false
+	}
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isValidRuntimeClassName reports whether name is a DNS subdomain, as RuntimeClass names
+// must be.
+func isValidRuntimeClassName(name string) bool {
+	if len(name) > 253 {
+		return false
+	}
+
+	for label := range strings.SplitSeq(name, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' || !isValidPoolName(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func Redact(cfg Config) Config {
 	return cfg
 }
@@ -492,6 +1594,9 @@ func FormatForLog(cfg Config) map[string]any {
 			"node_port_min":                  cfg.Stack.NodePortMin,
 			"node_port_max":                  cfg.Stack.NodePortMax,
 			"port_lock_ttl":                  seconds(cfg.Stack.PortLockTTL),
+			"node_port_cooldown":             seconds(cfg.Stack.NodePortCooldown),
+			"node_port_pools":                formatNodePortPools(cfg.Stack.PortPools()),
+			"default_node_port_pool":         cfg.Stack.DefaultPortPool(),
 			"dynamo_table_name":              cfg.Stack.DynamoTableName,
 			"aws_region":                     cfg.Stack.AWSRegion,
 			"aws_endpoint":                   cfg.Stack.AWSEndpoint,
@@ -505,6 +1610,53 @@ func FormatForLog(cfg Config) map[string]any {
 			"use_mock_kubernetes":            cfg.Stack.UseMockKubernetes,
 			"require_ingress_network_policy": cfg.Stack.RequireIngressNP,
 			"stack_node_role":                cfg.Stack.StackNodeRole,
+			"node_address_types":             cfg.Stack.NodeAddressTypes,
+			"node_address_family":            cfg.Stack.NodeAddressFamily,
+			"node_address_override_key":      cfg.Stack.NodeAddressOverrideKey,
+			"node_address_cache_ttl":         seconds(cfg.Stack.NodeAddressCacheTTL),
+			"capacity_source":                cfg.Stack.CapacitySource,
+			"capacity_cpu_milli":             cfg.Stack.CapacityCPUMilli,
+			"capacity_memory_bytes":          cfg.Stack.CapacityMemoryBytes,
+			"capacity_cache_ttl":             seconds(cfg.Stack.CapacityCacheTTL),
+			"queue_max_length":               cfg.Stack.QueueMaxLength,
+			"queue_max_per_owner":            cfg.Stack.QueueMaxPerOwner,
+			"queue_wait_timeout":             seconds(cfg.Stack.QueueWaitTimeout),
+			"demand_window":                  seconds(cfg.Stack.DemandWindow),
+			"balloon_enabled":                cfg.Stack.BalloonEnabled,
+			"balloon_priority_class":         cfg.Stack.BalloonPriorityClass,
+			"balloon_image":                  cfg.Stack.BalloonImage,
+			"balloon_max":                    cfg.Stack.BalloonMax,
+			"placement_strategy":             cfg.Stack.PlacementStrategy,
+			"priority_tiers":                 formatPriorityTiers(cfg.Stack.PriorityTiers),
+			"priority_default_tier":          cfg.Stack.DefaultPriority(),
+			"node_pools":                     formatNodePools(cfg.Stack.NodePools),
+			"node_pool_default":              cfg.Stack.DefaultNodePoolName(),
+			"runtime_class":                  cfg.Stack.RuntimeClass,
+			"clusters":                       formatClusters(cfg.Stack.Clusters),
+			"cluster_routing":                cfg.Stack.ClusterRouting,
+			"namespace_mode":                 cfg.Stack.NamespaceMode,
+			"namespace_prefix":               cfg.Stack.NamespacePrefix,
+			"namespace_network_policy":       cfg.Stack.NamespaceNetworkPolicyTemplate,
+			"namespace_quota":                cfg.Stack.NamespaceQuotaTemplate,
+			"namespace_limit_range":          cfg.Stack.NamespaceLimitRangeTemplate,
+			"namespace_ingress_except_cidrs": cfg.Stack.NamespaceIngressExceptCIDRs,
+			"namespace_ipv6":                 cfg.Stack.NamespaceIPv6,
+			"image_allowlist":                cfg.Stack.ImageAllowlist,
+			"image_digest_policy":            cfg.Stack.ImageDigestPolicy,
+			"max_container_cpu_milli":        cfg.Stack.MaxContainerCPUMilli,
+			"max_container_memory_bytes":     cfg.Stack.MaxContainerMemoryBytes,
+			"max_stack_cpu_milli":            cfg.Stack.MaxStackCPUMilli,
+			"max_stack_memory_bytes":         cfg.Stack.MaxStackMemoryBytes,
+			"max_containers":                 cfg.Stack.MaxContainers,
+			"default_container_cpu_milli":    cfg.Stack.DefaultContainerCPUMilli,
+			"default_container_memory_bytes": cfg.Stack.DefaultContainerMemoryBytes,
+			"volume_allowlist":               cfg.Stack.VolumeAllowlist,
+			"max_emptydir_bytes":             cfg.Stack.MaxEmptyDirBytes,
+			"writable_path_size_bytes":       cfg.Stack.WritablePathSizeBytes,
+			"max_configmap_bytes":            cfg.Stack.MaxConfigMapBytes,
+			"hardening_profiles":             formatHardeningProfiles(cfg.Stack.HardeningProfiles),
+			"hardening_profile_default":      cfg.Stack.DefaultHardeningProfile,
+			"pid_limit_annotation":           cfg.Stack.PIDLimitAnnotation,
 		},
 		"api_key": map[string]any{
 			"enabled": cfg.APIKey.Enabled,
@@ -512,6 +1664,83 @@ func FormatForLog(cfg Config) map[string]any {
 	}
 }
 
+func formatNodePortPools(pools []NodePortPool) map[string]string {
+	out := make(map[string]string, len(pools))
+	for _, pool := range pools {
+		out[pool.Name] = fmt.Sprintf("%d-%d", pool.Min, pool.Max)
+	}
+
+	return out
+}
+
+func formatPriorityTiers(tiers []PriorityTier) []string {
+	out := make([]string, 0, len(tiers))
+	for _, tier := range tiers {
+		out = append(out, tier.Name+"="+tier.ClassName)
+	}
+
+	return out
+}
+
+func formatHardeningProfiles(profiles []HardeningProfile) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(profiles))
+	for _, profile := range profiles {
+		out[profile.Name] = map[string]any{
+			"drop_all_capabilities":     profile.DropAllCapabilities,
+			"allowed_capabilities":      profile.AllowedCapabilities,
+			"run_as_non_root":           profile.RunAsNonRoot,
+			"run_as_user":               profile.RunAsUser,
+			"run_as_group":              profile.RunAsGroup,
+			"read_only_root_filesystem": profile.ReadOnlyRootFilesystem,
+			"pid_limit":                 profile.PIDLimit,
+			"seccomp_profile":           profile.SeccompProfile,
+			"apparmor_profile":          profile.AppArmorProfile,
+		}
+	}
+
+	return out
+}
+
+func formatNodePools(pools []NodePool) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(pools))
+	for _, pool := range pools {
+		tolerations := make([]string, 0, len(pool.Tolerations))
+		for _, toleration := range pool.Tolerations {
+			item := toleration.Key
+			if toleration.Value != "" {
+				item += "=" + toleration.Value
+			}
+			if toleration.Effect != "" {
+				item += ":" + toleration.Effect
+			}
+			tolerations = append(tolerations, item)
+		}
+
+		out[pool.Name] = map[string]any{
+			"labels":        pool.Labels,
+			"tolerations":   tolerations,
+			"runtime_class": pool.RuntimeClass,
+		}
+	}
+
+	return out
+}
+
+func formatClusters(clusters []ClusterConfig) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(clusters))
+	for _, cluster := range clusters {
+		out[cluster.ID] = map[string]string{
+			"kube_config_path": cluster.KubeConfigPath,
+			"kube_context":     cluster.KubeContext,
+			"stack_node_role":  cluster.StackNodeRole,
+			"region":           cluster.Region,
+			"port_pool":        cluster.PortPool,
+		}
+	}
+
+	return out
+}
+
 func seconds(d time.Duration) int64 {
 	return int64(d.Seconds())
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -34,15 +35,31 @@ func baseConfig() Config {
 				RenewDeadline: 10 * time.Second,
 				RetryPeriod:   2 * time.Second,
 			},
-			DynamoTableName:      "smctf-stacks",
-			AWSRegion:            "us-east-1",
-			DynamoConsistentRead: true,
-			UseMockRepository:    false,
-			K8sQPS:               1,
-			K8sBurst:             1,
-			SchedulingTimeout:    time.Second,
-			RequireIngressNP:     false,
-			StackNodeRole:        "stack",
+			DynamoTableName:       "smctf-stacks",
+			AWSRegion:             "us-east-1",
+			DynamoConsistentRead:  true,
+			UseMockRepository:     false,
+			K8sQPS:                1,
+			K8sBurst:              1,
+			SchedulingTimeout:     time.Second,
+			RequireIngressNP:      false,
+			StackNodeRole:         "stack",
+			NodeAddressTypes:      []string{"ExternalIP"},
+			NodeAddressFamily:     "any",
+			CapacitySource:        CapacitySourceNone,
+			PlacementStrategy:     PlacementNone,
+			ClusterRouting:        ClusterRoutingLeastLoaded,
+			NamespaceMode:         NamespaceModeShared,
+			NamespacePrefix:       "smctf-",
+			ImageDigestPolicy:     ImageDigestPolicyNone,
+			VolumeAllowlist:       []string{"emptyDir", "configMap", "downwardAPI"},
+			MaxEmptyDirBytes:      1 << 30,
+			WritablePathSizeBytes: 64 << 20,
+			MaxConfigMapBytes:     256 << 10,
+			QueueMaxLength:        10,
+			QueueMaxPerOwner:      1,
+			QueueWaitTimeout:      time.Minute,
+			DemandWindow:          time.Minute,
 		},
 	}
 }
@@ -67,3 +84,436 @@ func TestValidateConfigLeaderElectionDurations(t *testing.T) {
 		t.Fatalf("expected error when retry period >= renew deadline")
 	}
 }
+
+func TestValidateConfigNodeAddress(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.NodeAddressTypes = []string{"ExternalIP", "PublicIP"}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected error for unsupported node address type")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.NodeAddressFamily = "ipv5"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected error for unsupported node address family")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.NodeAddressTypes = []string{"InternalIP", "ExternalIP"}
+	cfg.Stack.NodeAddressFamily = "ipv6"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected node address config to be valid, got: %v", err)
+	}
+}
+
+func TestValidateConfigNodePortPools(t *testing.T) {
+	cfg := baseConfig()
+	if got := cfg.Stack.PortPools(); len(got) != 1 || got[0].Name != DefaultNodePortPoolName || got[0].Min != 1 || got[0].Max != 2 {
+		t.Fatalf("unexpected fallback pools: %+v", got)
+	}
+
+	cfg.Stack.NodePortPools = []NodePortPool{
+		{Name: "web", Min: 31000, Max: 31499},
+		{Name: "pwn", Min: 31500, Max: 31999},
+	}
+	cfg.Stack.DefaultNodePortPool = "pwn"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected pools to be valid, got: %v", err)
+	}
+
+	cfg.Stack.NodePortPools[1].Min = 31400
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected overlapping pools to be rejected")
+	}
+
+	cfg.Stack.NodePortPools[1].Min = 31500
+	cfg.Stack.DefaultNodePortPool = "misc"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown default pool to be rejected")
+	}
+
+	cfg.Stack.DefaultNodePortPool = ""
+	cfg.Stack.NodePortPools[0].Name = "Web"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected invalid pool name to be rejected")
+	}
+}
+
+func TestValidateConfigNodePortCooldown(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.NodePortCooldown = -time.Second
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected negative cooldown to be rejected")
+	}
+}
+
+func TestValidateConfigCapacitySource(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.CapacitySource = "cluster"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown capacity source to be rejected")
+	}
+
+	cfg.Stack.CapacitySource = CapacitySourceStatic
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected static capacity without limits to be rejected")
+	}
+
+	cfg.Stack.CapacityCPUMilli = 16000
+	cfg.Stack.CapacityMemoryBytes = 32 << 30
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected static capacity to be valid: %v", err)
+	}
+
+	cfg.Stack.CapacitySource = CapacitySourceQuota
+	cfg.Stack.CapacityCacheTTL = -time.Second
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected negative capacity cache ttl to be rejected")
+	}
+}
+
+func TestValidateConfigPlacementStrategy(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.PlacementStrategy = "random"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown placement strategy to be rejected")
+	}
+
+	cfg.Stack.PlacementStrategy = PlacementOwnerAntiAffinity
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected owner_anti_affinity to be valid: %v", err)
+	}
+}
+
+func TestValidateConfigPriorityTiers(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.PriorityTiers = []PriorityTier{
+		{Name: "player", ClassName: "smctf-player"},
+		{Name: "admin", ClassName: ""},
+	}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected tier without class to be rejected")
+	}
+
+	cfg.Stack.PriorityTiers[1].ClassName = "smctf-admin"
+	cfg.Stack.DefaultPriorityTier = "verification"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown default tier to be rejected")
+	}
+
+	cfg.Stack.DefaultPriorityTier = ""
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected priority tiers to be valid: %v", err)
+	}
+
+	if got := cfg.Stack.DefaultPriority(); got != "player" {
+		t.Fatalf("expected lowest tier as default, got %q", got)
+	}
+}
+
+func TestValidateConfigClusters(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.Clusters = []ClusterConfig{
+		{ID: "eks-a", Region: "ap-northeast-2"},
+		{ID: "eks-a", Region: "us-east-1"},
+	}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected duplicate cluster to be rejected")
+	}
+
+	cfg.Stack.Clusters[1].ID = "eks-b"
+	cfg.Stack.Clusters[1].PortPool = "players"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown cluster port pool to be rejected")
+	}
+
+	cfg.Stack.Clusters[1].PortPool = DefaultNodePortPoolName
+	cfg.Stack.Clusters[1].Region = ""
+	cfg.Stack.ClusterRouting = ClusterRoutingRegion
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected region routing to require a region per cluster")
+	}
+
+	cfg.Stack.Clusters[1].Region = "us-east-1"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected clusters to be valid: %v", err)
+	}
+
+	cfg.Stack.ClusterRouting = "round_robin"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown cluster routing to be rejected")
+	}
+}
+
+func TestValidateConfigNodePools(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.NodePools = []NodePool{
+		{Name: "amd64", Labels: map[string]string{"kubernetes.io/arch": "amd64"}},
+		{Name: "arm64"},
+	}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected node pool without labels to be rejected")
+	}
+
+	cfg.Stack.NodePools[1].Labels = map[string]string{"kubernetes.io/arch": "arm64"}
+	cfg.Stack.NodePools[1].Tolerations = []NodePoolToleration{{Key: "arch", Value: "arm64", Effect: "NoBind"}}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown toleration effect to be rejected")
+	}
+
+	cfg.Stack.NodePools[1].Tolerations[0].Effect = "NoSchedule"
+	cfg.Stack.DefaultNodePool = "gvisor"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown default node pool to be rejected")
+	}
+
+	cfg.Stack.DefaultNodePool = "arm64"
+	cfg.Stack.NodePools[1].RuntimeClass = "gvisor_runsc"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected invalid runtime class name to be rejected")
+	}
+
+	cfg.Stack.NodePools[1].RuntimeClass = "gvisor"
+	cfg.Stack.RuntimeClass = "-kata"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected invalid STACK_RUNTIME_CLASS to be rejected")
+	}
+
+	cfg.Stack.RuntimeClass = "kata.qemu"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected node pools to be valid: %v", err)
+	}
+
+	cfg.Stack.Clusters = []ClusterConfig{{ID: "eks-a", StackNodeRole: "ctf"}}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected cluster node role to be rejected with node pools")
+	}
+}
+
+func TestGetNodePools(t *testing.T) {
+	t.Setenv("STACK_NODE_POOLS", "arm64")
+	t.Setenv("STACK_NODE_POOL_ARM64_LABELS", "kubernetes.io/arch=arm64, role=stack")
+	t.Setenv("STACK_NODE_POOL_ARM64_TOLERATIONS", "arch=arm64:NoSchedule,dedicated")
+
+	pools, err := getNodePools("STACK_NODE_POOLS")
+	if err != nil {
+		t.Fatalf("get node pools: %v", err)
+	}
+
+	if len(pools) != 1 || pools[0].Labels["kubernetes.io/arch"] != "arm64" || pools[0].Labels["role"] != "stack" {
+		t.Fatalf("unexpected pools: %+v", pools)
+	}
+
+	want := []NodePoolToleration{{Key: "arch", Value: "arm64", Effect: "NoSchedule"}, {Key: "dedicated"}}
+	if !reflect.DeepEqual(pools[0].Tolerations, want) {
+		t.Fatalf("unexpected tolerations: %+v", pools[0].Tolerations)
+	}
+
+	t.Setenv("STACK_NODE_POOL_ARM64_LABELS", "arm64")
+	if _, err := getNodePools("STACK_NODE_POOLS"); err == nil {
+		t.Fatalf("expected label without value to be rejected")
+	}
+}
+
+func TestValidateConfigNamespaceMode(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.NamespaceMode = "team"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown namespace mode to be rejected")
+	}
+
+	cfg.Stack.NamespaceMode = NamespaceModeOwner
+	cfg.Stack.NamespacePrefix = "CTF_"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected invalid namespace prefix to be rejected")
+	}
+
+	cfg.Stack.NamespacePrefix = "ctf-"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected owner namespace mode to be valid: %v", err)
+	}
+
+	cfg.Stack.CapacitySource = CapacitySourceQuota
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected quota capacity source to be rejected with owner namespaces")
+	}
+
+	cfg.Stack.CapacitySource = CapacitySourceNone
+	cfg.Stack.NamespaceIngressExceptCIDRs = []string{"10.244.0.0/16", "fd00:10:244::/56"}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected IPv6 except CIDR to require STACK_NAMESPACE_IPV6")
+	}
+
+	cfg.Stack.NamespaceIPv6 = true
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected except CIDRs to be valid: %v", err)
+	}
+
+	for _, entry := range []string{"10.244.0.0", "0.0.0.0/0"} {
+		cfg.Stack.NamespaceIngressExceptCIDRs = []string{entry}
+		if err := validateConfig(cfg); err == nil {
+			t.Fatalf("expected except CIDR %q to be rejected", entry)
+		}
+	}
+}
+
+func TestValidateConfigQueue(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.QueueMaxLength = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected zero queue length to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.QueueMaxPerOwner = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected zero per-owner queue limit to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.QueueWaitTimeout = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected zero queue wait timeout to be rejected")
+	}
+}
+
+func TestValidateConfigBalloons(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.DemandWindow = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected zero demand window to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.BalloonEnabled = true
+	cfg.Stack.BalloonImage = "registry.k8s.io/pause:3.10"
+	cfg.Stack.BalloonMax = 5
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected balloons without a priority class to be rejected")
+	}
+
+	cfg.Stack.BalloonPriorityClass = "smctf-balloon"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected balloon config to be valid: %v", err)
+	}
+
+	cfg.Stack.BalloonMax = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected zero balloon max to be rejected")
+	}
+}
+
+func TestValidateConfigImagePolicy(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.ImageAllowlist = []string{"ghcr.io/acme", "registry.example.com/"}
+	cfg.Stack.ImageDigestPolicy = ImageDigestPolicyRequire
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected image policy to be valid: %v", err)
+	}
+
+	cfg.Stack.ImageAllowlist = []string{"https://ghcr.io/acme"}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected allowlist entry with a scheme to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.ImageDigestPolicy = "resolve"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown digest policy to be rejected")
+	}
+}
+
+func TestValidateConfigResourceLimits(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.MaxContainerCPUMilli = 1000
+	cfg.Stack.MaxStackCPUMilli = 2000
+	cfg.Stack.DefaultContainerCPUMilli = 100
+	cfg.Stack.DefaultContainerMemoryBytes = 64 << 20
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected resource limits to be valid: %v", err)
+	}
+
+	cfg.Stack.MaxContainerCPUMilli = 4000
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected container maximum above the stack maximum to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.MaxContainerMemoryBytes = 128 << 20
+	cfg.Stack.DefaultContainerMemoryBytes = 256 << 20
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected default above the container maximum to be rejected")
+	}
+
+	cfg = baseConfig()
+	cfg.Stack.MaxContainers = -1
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected negative container limit to be rejected")
+	}
+}
+
+func TestValidateConfigHardeningProfiles(t *testing.T) {
+	root := int64(0)
+	cfg := baseConfig()
+	cfg.Stack.HardeningProfiles = []HardeningProfile{
+		{Name: "web", DropAllCapabilities: true, RunAsNonRoot: true, SeccompProfile: "RuntimeDefault"},
+		{Name: "pwn", AllowedCapabilities: []string{"SYS_PTRACE"}, SeccompProfile: "localhost/pwn.json", AppArmorProfile: "Unconfined"},
+	}
+	cfg.Stack.DefaultHardeningProfile = "web"
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected hardening profiles to be valid: %v", err)
+	}
+
+	cfg.Stack.HardeningProfiles[1].AllowedCapabilities = []string{"CAP_SYS_PTRACE"}
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected CAP_ prefixed capability to be rejected")
+	}
+
+	cfg.Stack.HardeningProfiles[1].AllowedCapabilities = nil
+	cfg.Stack.HardeningProfiles[0].RunAsUser = &root
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected run as root with runAsNonRoot to be rejected")
+	}
+
+	cfg.Stack.HardeningProfiles[0].RunAsUser = nil
+	cfg.Stack.HardeningProfiles[0].PIDLimit = 256
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected pid limit without STACK_PID_LIMIT_ANNOTATION to be rejected")
+	}
+
+	cfg.Stack.PIDLimitAnnotation = "example.com/pids-limit"
+	cfg.Stack.HardeningProfiles[0].SeccompProfile = "unconfined"
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected unknown seccomp profile to be rejected")
+	}
+}
+
+func TestValidateConfigVolumePolicy(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Stack.VolumeAllowlist = append(cfg.Stack.VolumeAllowlist, "hostPath")
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected hostPath in STACK_VOLUME_ALLOWLIST to be rejected")
+	}
+
+	cfg.Stack.VolumeAllowlist = []string{"emptyDir", "secret"}
+	cfg.Stack.MaxEmptyDirBytes = 0
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected missing emptyDir maximum to be rejected")
+	}
+
+	cfg.Stack.MaxEmptyDirBytes = 32 << 20
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected writable path size above the emptyDir maximum to be rejected")
+	}
+
+	cfg.Stack.WritablePathSizeBytes = 16 << 20
+	cfg.Stack.MaxConfigMapBytes = 2 << 20
+	if err := validateConfig(cfg); err == nil {
+		t.Fatalf("expected ConfigMap size above 1Mi to be rejected")
+	}
+
+	cfg.Stack.MaxConfigMapBytes = 1 << 20
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("expected volume policy to be valid: %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -32,6 +33,7 @@ const (
 	Status_STATUS_STOPPED      Status = 3
 	Status_STATUS_FAILED       Status = 4
 	Status_STATUS_NODE_DELETED Status = 5
+	Status_STATUS_PREEMPTED    Status = 6
 )
 
 // Enum value maps for Status.
@@ -43,6 +45,7 @@ var (
 		3: "STATUS_STOPPED",
 		4: "STATUS_FAILED",
 		5: "STATUS_NODE_DELETED",
+		6: "STATUS_PREEMPTED",
 	}
 	Status_value = map[string]int32{
 		"STATUS_UNSPECIFIED":  0,
@@ -51,6 +54,7 @@ var (
 		"STATUS_STOPPED":      3,
 		"STATUS_FAILED":       4,
 		"STATUS_NODE_DELETED": 5,
+		"STATUS_PREEMPTED":    6,
 	}
 )
 
@@ -136,6 +140,67 @@ func (JobStatus) EnumDescriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{1}
 }
 
+type QueueTicketStatus int32
+
+const (
+	QueueTicketStatus_QUEUE_TICKET_STATUS_UNSPECIFIED  QueueTicketStatus = 0
+	QueueTicketStatus_QUEUE_TICKET_STATUS_QUEUED       QueueTicketStatus = 1
+	QueueTicketStatus_QUEUE_TICKET_STATUS_PROVISIONING QueueTicketStatus = 2
+	QueueTicketStatus_QUEUE_TICKET_STATUS_PROVISIONED  QueueTicketStatus = 3
+	QueueTicketStatus_QUEUE_TICKET_STATUS_FAILED       QueueTicketStatus = 4
+	QueueTicketStatus_QUEUE_TICKET_STATUS_CANCELLED    QueueTicketStatus = 5
+	QueueTicketStatus_QUEUE_TICKET_STATUS_EXPIRED      QueueTicketStatus = 6
+)
+
+// Enum value maps for QueueTicketStatus.
+var (
+	QueueTicketStatus_name = map[int32]string{
+		0: "QUEUE_TICKET_STATUS_UNSPECIFIED",
+		1: "QUEUE_TICKET_STATUS_QUEUED",
+		2: "QUEUE_TICKET_STATUS_PROVISIONING",
+		3: "QUEUE_TICKET_STATUS_PROVISIONED",
+		4: "QUEUE_TICKET_STATUS_FAILED",
+		5: "QUEUE_TICKET_STATUS_CANCELLED",
+		6: "QUEUE_TICKET_STATUS_EXPIRED",
+	}
+	QueueTicketStatus_value = map[string]int32{
+		"QUEUE_TICKET_STATUS_UNSPECIFIED":  0,
+		"QUEUE_TICKET_STATUS_QUEUED":       1,
+		"QUEUE_TICKET_STATUS_PROVISIONING": 2,
+		"QUEUE_TICKET_STATUS_PROVISIONED":  3,
+		"QUEUE_TICKET_STATUS_FAILED":       4,
+		"QUEUE_TICKET_STATUS_CANCELLED":    5,
+		"QUEUE_TICKET_STATUS_EXPIRED":      6,
+	}
+)
+
+func (x QueueTicketStatus) Enum() *QueueTicketStatus {
+	p := new(QueueTicketStatus)
+	*p = x
+	return p
+}
+
+func (x QueueTicketStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueueTicketStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_stack_v1_stack_proto_enumTypes[2].Descriptor()
+}
+
+func (QueueTicketStatus) Type() protoreflect.EnumType {
+	return &file_stack_v1_stack_proto_enumTypes[2]
+}
+
+func (x QueueTicketStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueueTicketStatus.Descriptor instead.
+func (QueueTicketStatus) EnumDescriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{2}
+}
+
 type HealthzRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -220,6 +285,14 @@ type CreateStackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PodSpec       string                 `protobuf:"bytes,1,opt,name=pod_spec,json=podSpec,proto3" json:"pod_spec,omitempty"`
 	TargetPorts   []*PortSpec            `protobuf:"bytes,2,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,3,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	ChallengeId   string                 `protobuf:"bytes,4,opt,name=challenge_id,json=challengeId,proto3" json:"challenge_id,omitempty"`
+	PortPool      string                 `protobuf:"bytes,5,opt,name=port_pool,json=portPool,proto3" json:"port_pool,omitempty"`
+	Queue         bool                   `protobuf:"varint,6,opt,name=queue,proto3" json:"queue,omitempty"`
+	Priority      string                 `protobuf:"bytes,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	Region        string                 `protobuf:"bytes,8,opt,name=region,proto3" json:"region,omitempty"`
+	NodePool      string                 `protobuf:"bytes,9,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	PodSpecObject *structpb.Struct       `protobuf:"bytes,10,opt,name=pod_spec_object,json=podSpecObject,proto3" json:"pod_spec_object,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -268,9 +341,66 @@ func (x *CreateStackRequest) GetTargetPorts() []*PortSpec {
 	return nil
 }
 
+func (x *CreateStackRequest) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetChallengeId() string {
+	if x != nil {
+		return x.ChallengeId
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetPortPool() string {
+	if x != nil {
+		return x.PortPool
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetQueue() bool {
+	if x != nil {
+		return x.Queue
+	}
+	return false
+}
+
+func (x *CreateStackRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
+}
+
+func (x *CreateStackRequest) GetPodSpecObject() *structpb.Struct {
+	if x != nil {
+		return x.PodSpecObject
+	}
+	return nil
+}
+
 type CreateStackResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Stack         *Stack                 `protobuf:"bytes,1,opt,name=stack,proto3" json:"stack,omitempty"`
+	Ticket        *QueueTicket           `protobuf:"bytes,2,opt,name=ticket,proto3" json:"ticket,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -312,27 +442,42 @@ func (x *CreateStackResponse) GetStack() *Stack {
 	return nil
 }
 
-type GetStackRequest struct {
+func (x *CreateStackResponse) GetTicket() *QueueTicket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type ValidateStackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	PodSpec       string                 `protobuf:"bytes,1,opt,name=pod_spec,json=podSpec,proto3" json:"pod_spec,omitempty"`
+	TargetPorts   []*PortSpec            `protobuf:"bytes,2,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,3,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	ChallengeId   string                 `protobuf:"bytes,4,opt,name=challenge_id,json=challengeId,proto3" json:"challenge_id,omitempty"`
+	PortPool      string                 `protobuf:"bytes,5,opt,name=port_pool,json=portPool,proto3" json:"port_pool,omitempty"`
+	Priority      string                 `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	Region        string                 `protobuf:"bytes,7,opt,name=region,proto3" json:"region,omitempty"`
+	NodePool      string                 `protobuf:"bytes,8,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	PodSpecObject *structpb.Struct       `protobuf:"bytes,9,opt,name=pod_spec_object,json=podSpecObject,proto3" json:"pod_spec_object,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStackRequest) Reset() {
-	*x = GetStackRequest{}
+func (x *ValidateStackRequest) Reset() {
+	*x = ValidateStackRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStackRequest) String() string {
+func (x *ValidateStackRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStackRequest) ProtoMessage() {}
+func (*ValidateStackRequest) ProtoMessage() {}
 
-func (x *GetStackRequest) ProtoReflect() protoreflect.Message {
+func (x *ValidateStackRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -344,39 +489,95 @@ func (x *GetStackRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStackRequest.ProtoReflect.Descriptor instead.
-func (*GetStackRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ValidateStackRequest.ProtoReflect.Descriptor instead.
+func (*ValidateStackRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GetStackRequest) GetStackId() string {
+func (x *ValidateStackRequest) GetPodSpec() string {
 	if x != nil {
-		return x.StackId
+		return x.PodSpec
 	}
 	return ""
 }
 
-type GetStackResponse struct {
+func (x *ValidateStackRequest) GetTargetPorts() []*PortSpec {
+	if x != nil {
+		return x.TargetPorts
+	}
+	return nil
+}
+
+func (x *ValidateStackRequest) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetChallengeId() string {
+	if x != nil {
+		return x.ChallengeId
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetPortPool() string {
+	if x != nil {
+		return x.PortPool
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
+}
+
+func (x *ValidateStackRequest) GetPodSpecObject() *structpb.Struct {
+	if x != nil {
+		return x.PodSpecObject
+	}
+	return nil
+}
+
+type ValidateStackResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Stack         *Stack                 `protobuf:"bytes,1,opt,name=stack,proto3" json:"stack,omitempty"`
+	Validation    *StackValidation       `protobuf:"bytes,1,opt,name=validation,proto3" json:"validation,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStackResponse) Reset() {
-	*x = GetStackResponse{}
+func (x *ValidateStackResponse) Reset() {
+	*x = ValidateStackResponse{}
 	mi := &file_stack_v1_stack_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStackResponse) String() string {
+func (x *ValidateStackResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStackResponse) ProtoMessage() {}
+func (*ValidateStackResponse) ProtoMessage() {}
 
-func (x *GetStackResponse) ProtoReflect() protoreflect.Message {
+func (x *ValidateStackResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -388,39 +589,47 @@ func (x *GetStackResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStackResponse.ProtoReflect.Descriptor instead.
-func (*GetStackResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ValidateStackResponse.ProtoReflect.Descriptor instead.
+func (*ValidateStackResponse) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetStackResponse) GetStack() *Stack {
+func (x *ValidateStackResponse) GetValidation() *StackValidation {
 	if x != nil {
-		return x.Stack
+		return x.Validation
 	}
 	return nil
 }
 
-type GetStackStatusSummaryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type StackValidation struct {
+	state                          protoimpl.MessageState `protogen:"open.v1"`
+	Valid                          bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	SanitizedPodSpec               string                 `protobuf:"bytes,2,opt,name=sanitized_pod_spec,json=sanitizedPodSpec,proto3" json:"sanitized_pod_spec,omitempty"`
+	RequestedCpuMilli              int64                  `protobuf:"varint,3,opt,name=requested_cpu_milli,json=requestedCpuMilli,proto3" json:"requested_cpu_milli,omitempty"`
+	RequestedMemoryBytes           int64                  `protobuf:"varint,4,opt,name=requested_memory_bytes,json=requestedMemoryBytes,proto3" json:"requested_memory_bytes,omitempty"`
+	RequestedEphemeralStorageBytes int64                  `protobuf:"varint,5,opt,name=requested_ephemeral_storage_bytes,json=requestedEphemeralStorageBytes,proto3" json:"requested_ephemeral_storage_bytes,omitempty"`
+	TargetPorts                    []*PortSpec            `protobuf:"bytes,6,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
+	NodePool                       string                 `protobuf:"bytes,7,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	Changes                        []*FieldChange         `protobuf:"bytes,8,rep,name=changes,proto3" json:"changes,omitempty"`
+	Violations                     []*Violation           `protobuf:"bytes,9,rep,name=violations,proto3" json:"violations,omitempty"`
+	unknownFields                  protoimpl.UnknownFields
+	sizeCache                      protoimpl.SizeCache
 }
 
-func (x *GetStackStatusSummaryRequest) Reset() {
-	*x = GetStackStatusSummaryRequest{}
+func (x *StackValidation) Reset() {
+	*x = StackValidation{}
 	mi := &file_stack_v1_stack_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStackStatusSummaryRequest) String() string {
+func (x *StackValidation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStackStatusSummaryRequest) ProtoMessage() {}
+func (*StackValidation) ProtoMessage() {}
 
-func (x *GetStackStatusSummaryRequest) ProtoReflect() protoreflect.Message {
+func (x *StackValidation) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -432,39 +641,97 @@ func (x *GetStackStatusSummaryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStackStatusSummaryRequest.ProtoReflect.Descriptor instead.
-func (*GetStackStatusSummaryRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StackValidation.ProtoReflect.Descriptor instead.
+func (*StackValidation) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetStackStatusSummaryRequest) GetStackId() string {
+func (x *StackValidation) GetValid() bool {
 	if x != nil {
-		return x.StackId
+		return x.Valid
+	}
+	return false
+}
+
+func (x *StackValidation) GetSanitizedPodSpec() string {
+	if x != nil {
+		return x.SanitizedPodSpec
 	}
 	return ""
 }
 
-type GetStackStatusSummaryResponse struct {
+func (x *StackValidation) GetRequestedCpuMilli() int64 {
+	if x != nil {
+		return x.RequestedCpuMilli
+	}
+	return 0
+}
+
+func (x *StackValidation) GetRequestedMemoryBytes() int64 {
+	if x != nil {
+		return x.RequestedMemoryBytes
+	}
+	return 0
+}
+
+func (x *StackValidation) GetRequestedEphemeralStorageBytes() int64 {
+	if x != nil {
+		return x.RequestedEphemeralStorageBytes
+	}
+	return 0
+}
+
+func (x *StackValidation) GetTargetPorts() []*PortSpec {
+	if x != nil {
+		return x.TargetPorts
+	}
+	return nil
+}
+
+func (x *StackValidation) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
+}
+
+func (x *StackValidation) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *StackValidation) GetViolations() []*Violation {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+type FieldChange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Summary       *StackStatusSummary    `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Before        string                 `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After         string                 `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStackStatusSummaryResponse) Reset() {
-	*x = GetStackStatusSummaryResponse{}
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
 	mi := &file_stack_v1_stack_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStackStatusSummaryResponse) String() string {
+func (x *FieldChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStackStatusSummaryResponse) ProtoMessage() {}
+func (*FieldChange) ProtoMessage() {}
 
-func (x *GetStackStatusSummaryResponse) ProtoReflect() protoreflect.Message {
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -476,39 +743,55 @@ func (x *GetStackStatusSummaryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStackStatusSummaryResponse.ProtoReflect.Descriptor instead.
-func (*GetStackStatusSummaryResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetStackStatusSummaryResponse) GetSummary() *StackStatusSummary {
+func (x *FieldChange) GetPath() string {
 	if x != nil {
-		return x.Summary
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-type DeleteStackRequest struct {
+func (x *FieldChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *FieldChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type Violation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Code          string                 `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteStackRequest) Reset() {
-	*x = DeleteStackRequest{}
+func (x *Violation) Reset() {
+	*x = Violation{}
 	mi := &file_stack_v1_stack_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteStackRequest) String() string {
+func (x *Violation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteStackRequest) ProtoMessage() {}
+func (*Violation) ProtoMessage() {}
 
-func (x *DeleteStackRequest) ProtoReflect() protoreflect.Message {
+func (x *Violation) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -520,40 +803,53 @@ func (x *DeleteStackRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteStackRequest.ProtoReflect.Descriptor instead.
-func (*DeleteStackRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Violation.ProtoReflect.Descriptor instead.
+func (*Violation) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *DeleteStackRequest) GetStackId() string {
+func (x *Violation) GetField() string {
 	if x != nil {
-		return x.StackId
+		return x.Field
 	}
 	return ""
 }
 
-type DeleteStackResponse struct {
+func (x *Violation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Violation) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type GetStackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Deleted       bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
-	StackId       string                 `protobuf:"bytes,2,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteStackResponse) Reset() {
-	*x = DeleteStackResponse{}
+func (x *GetStackRequest) Reset() {
+	*x = GetStackRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteStackResponse) String() string {
+func (x *GetStackRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteStackResponse) ProtoMessage() {}
+func (*GetStackRequest) ProtoMessage() {}
 
-func (x *DeleteStackResponse) ProtoReflect() protoreflect.Message {
+func (x *GetStackRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -565,45 +861,39 @@ func (x *DeleteStackResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteStackResponse.ProtoReflect.Descriptor instead.
-func (*DeleteStackResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetStackRequest.ProtoReflect.Descriptor instead.
+func (*GetStackRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *DeleteStackResponse) GetDeleted() bool {
-	if x != nil {
-		return x.Deleted
-	}
-	return false
-}
-
-func (x *DeleteStackResponse) GetStackId() string {
+func (x *GetStackRequest) GetStackId() string {
 	if x != nil {
 		return x.StackId
 	}
 	return ""
 }
 
-type ListStacksRequest struct {
+type GetStackResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stack         *Stack                 `protobuf:"bytes,1,opt,name=stack,proto3" json:"stack,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListStacksRequest) Reset() {
-	*x = ListStacksRequest{}
+func (x *GetStackResponse) Reset() {
+	*x = GetStackResponse{}
 	mi := &file_stack_v1_stack_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListStacksRequest) String() string {
+func (x *GetStackResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListStacksRequest) ProtoMessage() {}
+func (*GetStackResponse) ProtoMessage() {}
 
-func (x *ListStacksRequest) ProtoReflect() protoreflect.Message {
+func (x *GetStackResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -615,32 +905,39 @@ func (x *ListStacksRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListStacksRequest.ProtoReflect.Descriptor instead.
-func (*ListStacksRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetStackResponse.ProtoReflect.Descriptor instead.
+func (*GetStackResponse) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{10}
 }
 
-type ListStacksResponse struct {
+func (x *GetStackResponse) GetStack() *Stack {
+	if x != nil {
+		return x.Stack
+	}
+	return nil
+}
+
+type GetStackStatusSummaryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Stacks        []*Stack               `protobuf:"bytes,1,rep,name=stacks,proto3" json:"stacks,omitempty"`
+	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListStacksResponse) Reset() {
-	*x = ListStacksResponse{}
+func (x *GetStackStatusSummaryRequest) Reset() {
+	*x = GetStackStatusSummaryRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListStacksResponse) String() string {
+func (x *GetStackStatusSummaryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListStacksResponse) ProtoMessage() {}
+func (*GetStackStatusSummaryRequest) ProtoMessage() {}
 
-func (x *ListStacksResponse) ProtoReflect() protoreflect.Message {
+func (x *GetStackStatusSummaryRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -652,39 +949,39 @@ func (x *ListStacksResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListStacksResponse.ProtoReflect.Descriptor instead.
-func (*ListStacksResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetStackStatusSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetStackStatusSummaryRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ListStacksResponse) GetStacks() []*Stack {
+func (x *GetStackStatusSummaryRequest) GetStackId() string {
 	if x != nil {
-		return x.Stacks
+		return x.StackId
 	}
-	return nil
+	return ""
 }
 
-type CreateBatchDeleteJobRequest struct {
+type GetStackStatusSummaryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StackIds      []string               `protobuf:"bytes,1,rep,name=stack_ids,json=stackIds,proto3" json:"stack_ids,omitempty"`
+	Summary       *StackStatusSummary    `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateBatchDeleteJobRequest) Reset() {
-	*x = CreateBatchDeleteJobRequest{}
+func (x *GetStackStatusSummaryResponse) Reset() {
+	*x = GetStackStatusSummaryResponse{}
 	mi := &file_stack_v1_stack_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateBatchDeleteJobRequest) String() string {
+func (x *GetStackStatusSummaryResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateBatchDeleteJobRequest) ProtoMessage() {}
+func (*GetStackStatusSummaryResponse) ProtoMessage() {}
 
-func (x *CreateBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
+func (x *GetStackStatusSummaryResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -696,39 +993,39 @@ func (x *CreateBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateBatchDeleteJobRequest.ProtoReflect.Descriptor instead.
-func (*CreateBatchDeleteJobRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetStackStatusSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetStackStatusSummaryResponse) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *CreateBatchDeleteJobRequest) GetStackIds() []string {
+func (x *GetStackStatusSummaryResponse) GetSummary() *StackStatusSummary {
 	if x != nil {
-		return x.StackIds
+		return x.Summary
 	}
 	return nil
 }
 
-type CreateBatchDeleteJobResponse struct {
+type DeleteStackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateBatchDeleteJobResponse) Reset() {
-	*x = CreateBatchDeleteJobResponse{}
+func (x *DeleteStackRequest) Reset() {
+	*x = DeleteStackRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateBatchDeleteJobResponse) String() string {
+func (x *DeleteStackRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateBatchDeleteJobResponse) ProtoMessage() {}
+func (*DeleteStackRequest) ProtoMessage() {}
 
-func (x *CreateBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteStackRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -740,39 +1037,40 @@ func (x *CreateBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateBatchDeleteJobResponse.ProtoReflect.Descriptor instead.
-func (*CreateBatchDeleteJobResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteStackRequest.ProtoReflect.Descriptor instead.
+func (*DeleteStackRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *CreateBatchDeleteJobResponse) GetJobId() string {
+func (x *DeleteStackRequest) GetStackId() string {
 	if x != nil {
-		return x.JobId
+		return x.StackId
 	}
 	return ""
 }
 
-type GetBatchDeleteJobRequest struct {
+type DeleteStackResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Deleted       bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	StackId       string                 `protobuf:"bytes,2,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBatchDeleteJobRequest) Reset() {
-	*x = GetBatchDeleteJobRequest{}
+func (x *DeleteStackResponse) Reset() {
+	*x = DeleteStackResponse{}
 	mi := &file_stack_v1_stack_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBatchDeleteJobRequest) String() string {
+func (x *DeleteStackResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBatchDeleteJobRequest) ProtoMessage() {}
+func (*DeleteStackResponse) ProtoMessage() {}
 
-func (x *GetBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
+func (x *DeleteStackResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -784,39 +1082,45 @@ func (x *GetBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBatchDeleteJobRequest.ProtoReflect.Descriptor instead.
-func (*GetBatchDeleteJobRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteStackResponse.ProtoReflect.Descriptor instead.
+func (*DeleteStackResponse) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *GetBatchDeleteJobRequest) GetJobId() string {
+func (x *DeleteStackResponse) GetDeleted() bool {
 	if x != nil {
-		return x.JobId
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *DeleteStackResponse) GetStackId() string {
+	if x != nil {
+		return x.StackId
 	}
 	return ""
 }
 
-type GetBatchDeleteJobResponse struct {
+type ListStacksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Job           *BatchDeleteJob        `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBatchDeleteJobResponse) Reset() {
-	*x = GetBatchDeleteJobResponse{}
+func (x *ListStacksRequest) Reset() {
+	*x = ListStacksRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBatchDeleteJobResponse) String() string {
+func (x *ListStacksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBatchDeleteJobResponse) ProtoMessage() {}
+func (*ListStacksRequest) ProtoMessage() {}
 
-func (x *GetBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
+func (x *ListStacksRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -828,38 +1132,32 @@ func (x *GetBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBatchDeleteJobResponse.ProtoReflect.Descriptor instead.
-func (*GetBatchDeleteJobResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListStacksRequest.ProtoReflect.Descriptor instead.
+func (*ListStacksRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *GetBatchDeleteJobResponse) GetJob() *BatchDeleteJob {
-	if x != nil {
-		return x.Job
-	}
-	return nil
-}
-
-type GetStatsRequest struct {
+type ListStacksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stacks        []*Stack               `protobuf:"bytes,1,rep,name=stacks,proto3" json:"stacks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatsRequest) Reset() {
-	*x = GetStatsRequest{}
+func (x *ListStacksResponse) Reset() {
+	*x = ListStacksResponse{}
 	mi := &file_stack_v1_stack_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatsRequest) String() string {
+func (x *ListStacksResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatsRequest) ProtoMessage() {}
+func (*ListStacksResponse) ProtoMessage() {}
 
-func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+func (x *ListStacksResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -871,32 +1169,39 @@ func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
-func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListStacksResponse.ProtoReflect.Descriptor instead.
+func (*ListStacksResponse) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{16}
 }
 
-type GetStatsResponse struct {
+func (x *ListStacksResponse) GetStacks() []*Stack {
+	if x != nil {
+		return x.Stacks
+	}
+	return nil
+}
+
+type CreateBatchDeleteJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Stats         *Stats                 `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	StackIds      []string               `protobuf:"bytes,1,rep,name=stack_ids,json=stackIds,proto3" json:"stack_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatsResponse) Reset() {
-	*x = GetStatsResponse{}
+func (x *CreateBatchDeleteJobRequest) Reset() {
+	*x = CreateBatchDeleteJobRequest{}
 	mi := &file_stack_v1_stack_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatsResponse) String() string {
+func (x *CreateBatchDeleteJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatsResponse) ProtoMessage() {}
+func (*CreateBatchDeleteJobRequest) ProtoMessage() {}
 
-func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+func (x *CreateBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -908,45 +1213,1469 @@ func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
-func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateBatchDeleteJobRequest.ProtoReflect.Descriptor instead.
+func (*CreateBatchDeleteJobRequest) Descriptor() ([]byte, []int) {
 	return file_stack_v1_stack_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *GetStatsResponse) GetStats() *Stats {
+func (x *CreateBatchDeleteJobRequest) GetStackIds() []string {
 	if x != nil {
-		return x.Stats
+		return x.StackIds
 	}
 	return nil
 }
 
-type Stats struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	TotalStacks         int32                  `protobuf:"varint,1,opt,name=total_stacks,json=totalStacks,proto3" json:"total_stacks,omitempty"`
-	ActiveStacks        int32                  `protobuf:"varint,2,opt,name=active_stacks,json=activeStacks,proto3" json:"active_stacks,omitempty"`
-	NodeDistribution    map[string]int32       `protobuf:"bytes,3,rep,name=node_distribution,json=nodeDistribution,proto3" json:"node_distribution,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
-	UsedNodePorts       int32                  `protobuf:"varint,4,opt,name=used_node_ports,json=usedNodePorts,proto3" json:"used_node_ports,omitempty"`
-	ReservedCpuMilli    int64                  `protobuf:"varint,5,opt,name=reserved_cpu_milli,json=reservedCpuMilli,proto3" json:"reserved_cpu_milli,omitempty"`
-	ReservedMemoryBytes int64                  `protobuf:"varint,6,opt,name=reserved_memory_bytes,json=reservedMemoryBytes,proto3" json:"reserved_memory_bytes,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+type CreateBatchDeleteJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Stats) Reset() {
-	*x = Stats{}
+func (x *CreateBatchDeleteJobResponse) Reset() {
+	*x = CreateBatchDeleteJobResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBatchDeleteJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBatchDeleteJobResponse) ProtoMessage() {}
+
+func (x *CreateBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_stack_v1_stack_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBatchDeleteJobResponse.ProtoReflect.Descriptor instead.
+func (*CreateBatchDeleteJobResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreateBatchDeleteJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetBatchDeleteJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBatchDeleteJobRequest) Reset() {
+	*x = GetBatchDeleteJobRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchDeleteJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchDeleteJobRequest) ProtoMessage() {}
+
+func (x *GetBatchDeleteJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchDeleteJobRequest.ProtoReflect.Descriptor instead.
+func (*GetBatchDeleteJobRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetBatchDeleteJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetBatchDeleteJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *BatchDeleteJob        `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBatchDeleteJobResponse) Reset() {
+	*x = GetBatchDeleteJobResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchDeleteJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchDeleteJobResponse) ProtoMessage() {}
+
+func (x *GetBatchDeleteJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchDeleteJobResponse.ProtoReflect.Descriptor instead.
+func (*GetBatchDeleteJobResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetBatchDeleteJobResponse) GetJob() *BatchDeleteJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{21}
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stats         *Stats                 `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetStatsResponse) GetStats() *Stats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type Stats struct {
+	state                         protoimpl.MessageState        `protogen:"open.v1"`
+	TotalStacks                   int32                         `protobuf:"varint,1,opt,name=total_stacks,json=totalStacks,proto3" json:"total_stacks,omitempty"`
+	ActiveStacks                  int32                         `protobuf:"varint,2,opt,name=active_stacks,json=activeStacks,proto3" json:"active_stacks,omitempty"`
+	NodeDistribution              map[string]int32              `protobuf:"bytes,3,rep,name=node_distribution,json=nodeDistribution,proto3" json:"node_distribution,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	UsedNodePorts                 int32                         `protobuf:"varint,4,opt,name=used_node_ports,json=usedNodePorts,proto3" json:"used_node_ports,omitempty"`
+	ReservedCpuMilli              int64                         `protobuf:"varint,5,opt,name=reserved_cpu_milli,json=reservedCpuMilli,proto3" json:"reserved_cpu_milli,omitempty"`
+	ReservedMemoryBytes           int64                         `protobuf:"varint,6,opt,name=reserved_memory_bytes,json=reservedMemoryBytes,proto3" json:"reserved_memory_bytes,omitempty"`
+	NodePortPools                 map[string]*NodePortPoolUsage `protobuf:"bytes,7,rep,name=node_port_pools,json=nodePortPools,proto3" json:"node_port_pools,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PlacementStrategy             string                        `protobuf:"bytes,8,opt,name=placement_strategy,json=placementStrategy,proto3" json:"placement_strategy,omitempty"`
+	PlacementDistribution         map[string]*NodeDistribution  `protobuf:"bytes,9,rep,name=placement_distribution,json=placementDistribution,proto3" json:"placement_distribution,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Clusters                      map[string]*ClusterUsage      `protobuf:"bytes,10,rep,name=clusters,proto3" json:"clusters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	NodePools                     map[string]*NodePoolUsage     `protobuf:"bytes,11,rep,name=node_pools,json=nodePools,proto3" json:"node_pools,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ReservedEphemeralStorageBytes int64                         `protobuf:"varint,12,opt,name=reserved_ephemeral_storage_bytes,json=reservedEphemeralStorageBytes,proto3" json:"reserved_ephemeral_storage_bytes,omitempty"`
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_stack_v1_stack_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Stats) GetTotalStacks() int32 {
+	if x != nil {
+		return x.TotalStacks
+	}
+	return 0
+}
+
+func (x *Stats) GetActiveStacks() int32 {
+	if x != nil {
+		return x.ActiveStacks
+	}
+	return 0
+}
+
+func (x *Stats) GetNodeDistribution() map[string]int32 {
+	if x != nil {
+		return x.NodeDistribution
+	}
+	return nil
+}
+
+func (x *Stats) GetUsedNodePorts() int32 {
+	if x != nil {
+		return x.UsedNodePorts
+	}
+	return 0
+}
+
+func (x *Stats) GetReservedCpuMilli() int64 {
+	if x != nil {
+		return x.ReservedCpuMilli
+	}
+	return 0
+}
+
+func (x *Stats) GetReservedMemoryBytes() int64 {
+	if x != nil {
+		return x.ReservedMemoryBytes
+	}
+	return 0
+}
+
+func (x *Stats) GetNodePortPools() map[string]*NodePortPoolUsage {
+	if x != nil {
+		return x.NodePortPools
+	}
+	return nil
+}
+
+func (x *Stats) GetPlacementStrategy() string {
+	if x != nil {
+		return x.PlacementStrategy
+	}
+	return ""
+}
+
+func (x *Stats) GetPlacementDistribution() map[string]*NodeDistribution {
+	if x != nil {
+		return x.PlacementDistribution
+	}
+	return nil
+}
+
+func (x *Stats) GetClusters() map[string]*ClusterUsage {
+	if x != nil {
+		return x.Clusters
+	}
+	return nil
+}
+
+func (x *Stats) GetNodePools() map[string]*NodePoolUsage {
+	if x != nil {
+		return x.NodePools
+	}
+	return nil
+}
+
+func (x *Stats) GetReservedEphemeralStorageBytes() int64 {
+	if x != nil {
+		return x.ReservedEphemeralStorageBytes
+	}
+	return 0
+}
+
+type ClusterUsage struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Region              string                 `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	TotalStacks         int32                  `protobuf:"varint,2,opt,name=total_stacks,json=totalStacks,proto3" json:"total_stacks,omitempty"`
+	ActiveStacks        int32                  `protobuf:"varint,3,opt,name=active_stacks,json=activeStacks,proto3" json:"active_stacks,omitempty"`
+	ReservedCpuMilli    int64                  `protobuf:"varint,4,opt,name=reserved_cpu_milli,json=reservedCpuMilli,proto3" json:"reserved_cpu_milli,omitempty"`
+	ReservedMemoryBytes int64                  `protobuf:"varint,5,opt,name=reserved_memory_bytes,json=reservedMemoryBytes,proto3" json:"reserved_memory_bytes,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ClusterUsage) Reset() {
+	*x = ClusterUsage{}
+	mi := &file_stack_v1_stack_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClusterUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClusterUsage) ProtoMessage() {}
+
+func (x *ClusterUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClusterUsage.ProtoReflect.Descriptor instead.
+func (*ClusterUsage) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ClusterUsage) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ClusterUsage) GetTotalStacks() int32 {
+	if x != nil {
+		return x.TotalStacks
+	}
+	return 0
+}
+
+func (x *ClusterUsage) GetActiveStacks() int32 {
+	if x != nil {
+		return x.ActiveStacks
+	}
+	return 0
+}
+
+func (x *ClusterUsage) GetReservedCpuMilli() int64 {
+	if x != nil {
+		return x.ReservedCpuMilli
+	}
+	return 0
+}
+
+func (x *ClusterUsage) GetReservedMemoryBytes() int64 {
+	if x != nil {
+		return x.ReservedMemoryBytes
+	}
+	return 0
+}
+
+type NodePoolUsage struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Nodes                  int32                  `protobuf:"varint,1,opt,name=nodes,proto3" json:"nodes,omitempty"`
+	AllocatableCpuMilli    int64                  `protobuf:"varint,2,opt,name=allocatable_cpu_milli,json=allocatableCpuMilli,proto3" json:"allocatable_cpu_milli,omitempty"`
+	AllocatableMemoryBytes int64                  `protobuf:"varint,3,opt,name=allocatable_memory_bytes,json=allocatableMemoryBytes,proto3" json:"allocatable_memory_bytes,omitempty"`
+	TotalStacks            int32                  `protobuf:"varint,4,opt,name=total_stacks,json=totalStacks,proto3" json:"total_stacks,omitempty"`
+	ActiveStacks           int32                  `protobuf:"varint,5,opt,name=active_stacks,json=activeStacks,proto3" json:"active_stacks,omitempty"`
+	ReservedCpuMilli       int64                  `protobuf:"varint,6,opt,name=reserved_cpu_milli,json=reservedCpuMilli,proto3" json:"reserved_cpu_milli,omitempty"`
+	ReservedMemoryBytes    int64                  `protobuf:"varint,7,opt,name=reserved_memory_bytes,json=reservedMemoryBytes,proto3" json:"reserved_memory_bytes,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *NodePoolUsage) Reset() {
+	*x = NodePoolUsage{}
+	mi := &file_stack_v1_stack_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodePoolUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodePoolUsage) ProtoMessage() {}
+
+func (x *NodePoolUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodePoolUsage.ProtoReflect.Descriptor instead.
+func (*NodePoolUsage) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *NodePoolUsage) GetNodes() int32 {
+	if x != nil {
+		return x.Nodes
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetAllocatableCpuMilli() int64 {
+	if x != nil {
+		return x.AllocatableCpuMilli
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetAllocatableMemoryBytes() int64 {
+	if x != nil {
+		return x.AllocatableMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetTotalStacks() int32 {
+	if x != nil {
+		return x.TotalStacks
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetActiveStacks() int32 {
+	if x != nil {
+		return x.ActiveStacks
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetReservedCpuMilli() int64 {
+	if x != nil {
+		return x.ReservedCpuMilli
+	}
+	return 0
+}
+
+func (x *NodePoolUsage) GetReservedMemoryBytes() int64 {
+	if x != nil {
+		return x.ReservedMemoryBytes
+	}
+	return 0
+}
+
+type NodeDistribution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         map[string]int32       `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeDistribution) Reset() {
+	*x = NodeDistribution{}
+	mi := &file_stack_v1_stack_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeDistribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeDistribution) ProtoMessage() {}
+
+func (x *NodeDistribution) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeDistribution.ProtoReflect.Descriptor instead.
+func (*NodeDistribution) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *NodeDistribution) GetNodes() map[string]int32 {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type NodePortPoolUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           int32                  `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           int32                  `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
+	Used          int32                  `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
+	Capacity      int32                  `protobuf:"varint,4,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodePortPoolUsage) Reset() {
+	*x = NodePortPoolUsage{}
+	mi := &file_stack_v1_stack_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodePortPoolUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodePortPoolUsage) ProtoMessage() {}
+
+func (x *NodePortPoolUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodePortPoolUsage.ProtoReflect.Descriptor instead.
+func (*NodePortPoolUsage) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *NodePortPoolUsage) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *NodePortPoolUsage) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *NodePortPoolUsage) GetUsed() int32 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *NodePortPoolUsage) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+type GetPortReconcileReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPortReconcileReportRequest) Reset() {
+	*x = GetPortReconcileReportRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPortReconcileReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPortReconcileReportRequest) ProtoMessage() {}
+
+func (x *GetPortReconcileReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPortReconcileReportRequest.ProtoReflect.Descriptor instead.
+func (*GetPortReconcileReportRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{28}
+}
+
+type GetPortReconcileReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *PortReconcileReport   `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPortReconcileReportResponse) Reset() {
+	*x = GetPortReconcileReportResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPortReconcileReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPortReconcileReportResponse) ProtoMessage() {}
+
+func (x *GetPortReconcileReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPortReconcileReportResponse.ProtoReflect.Descriptor instead.
+func (*GetPortReconcileReportResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetPortReconcileReportResponse) GetReport() *PortReconcileReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+type PortReconcileReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CheckedAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Locks         int32                  `protobuf:"varint,3,opt,name=locks,proto3" json:"locks,omitempty"`
+	ServicePorts  int32                  `protobuf:"varint,4,opt,name=service_ports,json=servicePorts,proto3" json:"service_ports,omitempty"`
+	StackPorts    int32                  `protobuf:"varint,5,opt,name=stack_ports,json=stackPorts,proto3" json:"stack_ports,omitempty"`
+	Findings      []*PortFinding         `protobuf:"bytes,6,rep,name=findings,proto3" json:"findings,omitempty"`
+	Repaired      int32                  `protobuf:"varint,7,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	Failed        int32                  `protobuf:"varint,8,opt,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortReconcileReport) Reset() {
+	*x = PortReconcileReport{}
+	mi := &file_stack_v1_stack_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortReconcileReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortReconcileReport) ProtoMessage() {}
+
+func (x *PortReconcileReport) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortReconcileReport.ProtoReflect.Descriptor instead.
+func (*PortReconcileReport) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PortReconcileReport) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+func (x *PortReconcileReport) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PortReconcileReport) GetLocks() int32 {
+	if x != nil {
+		return x.Locks
+	}
+	return 0
+}
+
+func (x *PortReconcileReport) GetServicePorts() int32 {
+	if x != nil {
+		return x.ServicePorts
+	}
+	return 0
+}
+
+func (x *PortReconcileReport) GetStackPorts() int32 {
+	if x != nil {
+		return x.StackPorts
+	}
+	return 0
+}
+
+func (x *PortReconcileReport) GetFindings() []*PortFinding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *PortReconcileReport) GetRepaired() int32 {
+	if x != nil {
+		return x.Repaired
+	}
+	return 0
+}
+
+func (x *PortReconcileReport) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+type PortFinding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	NodePort      int32                  `protobuf:"varint,2,opt,name=node_port,json=nodePort,proto3" json:"node_port,omitempty"`
+	StackId       string                 `protobuf:"bytes,3,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	ServiceName   string                 `protobuf:"bytes,4,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Repaired      bool                   `protobuf:"varint,5,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortFinding) Reset() {
+	*x = PortFinding{}
+	mi := &file_stack_v1_stack_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortFinding) ProtoMessage() {}
+
+func (x *PortFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortFinding.ProtoReflect.Descriptor instead.
+func (*PortFinding) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PortFinding) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *PortFinding) GetNodePort() int32 {
+	if x != nil {
+		return x.NodePort
+	}
+	return 0
+}
+
+func (x *PortFinding) GetStackId() string {
+	if x != nil {
+		return x.StackId
+	}
+	return ""
+}
+
+func (x *PortFinding) GetServiceName() string {
+	if x != nil {
+		return x.ServiceName
+	}
+	return ""
+}
+
+func (x *PortFinding) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+func (x *PortFinding) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetCapacityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cpu           string                 `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory        string                 `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCapacityRequest) Reset() {
+	*x = GetCapacityRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCapacityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCapacityRequest) ProtoMessage() {}
+
+func (x *GetCapacityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCapacityRequest.ProtoReflect.Descriptor instead.
+func (*GetCapacityRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetCapacityRequest) GetCpu() string {
+	if x != nil {
+		return x.Cpu
+	}
+	return ""
+}
+
+func (x *GetCapacityRequest) GetMemory() string {
+	if x != nil {
+		return x.Memory
+	}
+	return ""
+}
+
+type GetCapacityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *CapacityReport        `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCapacityResponse) Reset() {
+	*x = GetCapacityResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCapacityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCapacityResponse) ProtoMessage() {}
+
+func (x *GetCapacityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCapacityResponse.ProtoReflect.Descriptor instead.
+func (*GetCapacityResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetCapacityResponse) GetReport() *CapacityReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+type CapacityReport struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	CheckedAt              *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	Nodes                  []*NodeCapacity        `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	AllocatableCpuMilli    int64                  `protobuf:"varint,3,opt,name=allocatable_cpu_milli,json=allocatableCpuMilli,proto3" json:"allocatable_cpu_milli,omitempty"`
+	AllocatableMemoryBytes int64                  `protobuf:"varint,4,opt,name=allocatable_memory_bytes,json=allocatableMemoryBytes,proto3" json:"allocatable_memory_bytes,omitempty"`
+	RequestedCpuMilli      int64                  `protobuf:"varint,5,opt,name=requested_cpu_milli,json=requestedCpuMilli,proto3" json:"requested_cpu_milli,omitempty"`
+	RequestedMemoryBytes   int64                  `protobuf:"varint,6,opt,name=requested_memory_bytes,json=requestedMemoryBytes,proto3" json:"requested_memory_bytes,omitempty"`
+	HeadroomCpuMilli       int64                  `protobuf:"varint,7,opt,name=headroom_cpu_milli,json=headroomCpuMilli,proto3" json:"headroom_cpu_milli,omitempty"`
+	HeadroomMemoryBytes    int64                  `protobuf:"varint,8,opt,name=headroom_memory_bytes,json=headroomMemoryBytes,proto3" json:"headroom_memory_bytes,omitempty"`
+	Fits                   *int32                 `protobuf:"varint,9,opt,name=fits,proto3,oneof" json:"fits,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CapacityReport) Reset() {
+	*x = CapacityReport{}
+	mi := &file_stack_v1_stack_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapacityReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapacityReport) ProtoMessage() {}
+
+func (x *CapacityReport) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapacityReport.ProtoReflect.Descriptor instead.
+func (*CapacityReport) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CapacityReport) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+func (x *CapacityReport) GetNodes() []*NodeCapacity {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *CapacityReport) GetAllocatableCpuMilli() int64 {
+	if x != nil {
+		return x.AllocatableCpuMilli
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetAllocatableMemoryBytes() int64 {
+	if x != nil {
+		return x.AllocatableMemoryBytes
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetRequestedCpuMilli() int64 {
+	if x != nil {
+		return x.RequestedCpuMilli
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetRequestedMemoryBytes() int64 {
+	if x != nil {
+		return x.RequestedMemoryBytes
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetHeadroomCpuMilli() int64 {
+	if x != nil {
+		return x.HeadroomCpuMilli
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetHeadroomMemoryBytes() int64 {
+	if x != nil {
+		return x.HeadroomMemoryBytes
+	}
+	return 0
+}
+
+func (x *CapacityReport) GetFits() int32 {
+	if x != nil && x.Fits != nil {
+		return *x.Fits
+	}
+	return 0
+}
+
+type NodeCapacity struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	NodeId                 string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	ClusterId              string                 `protobuf:"bytes,2,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
+	NodePools              []string               `protobuf:"bytes,3,rep,name=node_pools,json=nodePools,proto3" json:"node_pools,omitempty"`
+	PublicIp               *string                `protobuf:"bytes,4,opt,name=public_ip,json=publicIp,proto3,oneof" json:"public_ip,omitempty"`
+	Ready                  bool                   `protobuf:"varint,5,opt,name=ready,proto3" json:"ready,omitempty"`
+	Schedulable            bool                   `protobuf:"varint,6,opt,name=schedulable,proto3" json:"schedulable,omitempty"`
+	AllocatableCpuMilli    int64                  `protobuf:"varint,7,opt,name=allocatable_cpu_milli,json=allocatableCpuMilli,proto3" json:"allocatable_cpu_milli,omitempty"`
+	AllocatableMemoryBytes int64                  `protobuf:"varint,8,opt,name=allocatable_memory_bytes,json=allocatableMemoryBytes,proto3" json:"allocatable_memory_bytes,omitempty"`
+	RequestedCpuMilli      int64                  `protobuf:"varint,9,opt,name=requested_cpu_milli,json=requestedCpuMilli,proto3" json:"requested_cpu_milli,omitempty"`
+	RequestedMemoryBytes   int64                  `protobuf:"varint,10,opt,name=requested_memory_bytes,json=requestedMemoryBytes,proto3" json:"requested_memory_bytes,omitempty"`
+	HeadroomCpuMilli       int64                  `protobuf:"varint,11,opt,name=headroom_cpu_milli,json=headroomCpuMilli,proto3" json:"headroom_cpu_milli,omitempty"`
+	HeadroomMemoryBytes    int64                  `protobuf:"varint,12,opt,name=headroom_memory_bytes,json=headroomMemoryBytes,proto3" json:"headroom_memory_bytes,omitempty"`
+	Pods                   int32                  `protobuf:"varint,13,opt,name=pods,proto3" json:"pods,omitempty"`
+	Fits                   *int32                 `protobuf:"varint,14,opt,name=fits,proto3,oneof" json:"fits,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *NodeCapacity) Reset() {
+	*x = NodeCapacity{}
+	mi := &file_stack_v1_stack_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeCapacity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeCapacity) ProtoMessage() {}
+
+func (x *NodeCapacity) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeCapacity.ProtoReflect.Descriptor instead.
+func (*NodeCapacity) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *NodeCapacity) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeCapacity) GetClusterId() string {
+	if x != nil {
+		return x.ClusterId
+	}
+	return ""
+}
+
+func (x *NodeCapacity) GetNodePools() []string {
+	if x != nil {
+		return x.NodePools
+	}
+	return nil
+}
+
+func (x *NodeCapacity) GetPublicIp() string {
+	if x != nil && x.PublicIp != nil {
+		return *x.PublicIp
+	}
+	return ""
+}
+
+func (x *NodeCapacity) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *NodeCapacity) GetSchedulable() bool {
+	if x != nil {
+		return x.Schedulable
+	}
+	return false
+}
+
+func (x *NodeCapacity) GetAllocatableCpuMilli() int64 {
+	if x != nil {
+		return x.AllocatableCpuMilli
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetAllocatableMemoryBytes() int64 {
+	if x != nil {
+		return x.AllocatableMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetRequestedCpuMilli() int64 {
+	if x != nil {
+		return x.RequestedCpuMilli
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetRequestedMemoryBytes() int64 {
+	if x != nil {
+		return x.RequestedMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetHeadroomCpuMilli() int64 {
+	if x != nil {
+		return x.HeadroomCpuMilli
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetHeadroomMemoryBytes() int64 {
+	if x != nil {
+		return x.HeadroomMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetPods() int32 {
+	if x != nil {
+		return x.Pods
+	}
+	return 0
+}
+
+func (x *NodeCapacity) GetFits() int32 {
+	if x != nil && x.Fits != nil {
+		return *x.Fits
+	}
+	return 0
+}
+
+type GetQueueTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQueueTicketRequest) Reset() {
+	*x = GetQueueTicketRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQueueTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueTicketRequest) ProtoMessage() {}
+
+func (x *GetQueueTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueTicketRequest.ProtoReflect.Descriptor instead.
+func (*GetQueueTicketRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetQueueTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+type GetQueueTicketResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        *QueueTicket           `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQueueTicketResponse) Reset() {
+	*x = GetQueueTicketResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQueueTicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueTicketResponse) ProtoMessage() {}
+
+func (x *GetQueueTicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueTicketResponse.ProtoReflect.Descriptor instead.
+func (*GetQueueTicketResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetQueueTicketResponse) GetTicket() *QueueTicket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type CancelQueueTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelQueueTicketRequest) Reset() {
+	*x = CancelQueueTicketRequest{}
+	mi := &file_stack_v1_stack_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelQueueTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelQueueTicketRequest) ProtoMessage() {}
+
+func (x *CancelQueueTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelQueueTicketRequest.ProtoReflect.Descriptor instead.
+func (*CancelQueueTicketRequest) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *CancelQueueTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+type CancelQueueTicketResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        *QueueTicket           `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelQueueTicketResponse) Reset() {
+	*x = CancelQueueTicketResponse{}
+	mi := &file_stack_v1_stack_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelQueueTicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelQueueTicketResponse) ProtoMessage() {}
+
+func (x *CancelQueueTicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelQueueTicketResponse.ProtoReflect.Descriptor instead.
+func (*CancelQueueTicketResponse) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CancelQueueTicketResponse) GetTicket() *QueueTicket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type QueueTicket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	Status        QueueTicketStatus      `protobuf:"varint,2,opt,name=status,proto3,enum=stack.v1.QueueTicketStatus" json:"status,omitempty"`
+	Position      int32                  `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	Eta           *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=eta,proto3" json:"eta,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,5,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	ChallengeId   string                 `protobuf:"bytes,6,opt,name=challenge_id,json=challengeId,proto3" json:"challenge_id,omitempty"`
+	PortPool      string                 `protobuf:"bytes,7,opt,name=port_pool,json=portPool,proto3" json:"port_pool,omitempty"`
+	StackId       string                 `protobuf:"bytes,8,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Priority      string                 `protobuf:"bytes,12,opt,name=priority,proto3" json:"priority,omitempty"`
+	Region        string                 `protobuf:"bytes,13,opt,name=region,proto3" json:"region,omitempty"`
+	NodePool      string                 `protobuf:"bytes,14,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueTicket) Reset() {
+	*x = QueueTicket{}
+	mi := &file_stack_v1_stack_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Stats) String() string {
+func (x *QueueTicket) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Stats) ProtoMessage() {}
+func (*QueueTicket) ProtoMessage() {}
 
-func (x *Stats) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[18]
+func (x *QueueTicket) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -957,77 +2686,143 @@ func (x *Stats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
-func (*Stats) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use QueueTicket.ProtoReflect.Descriptor instead.
+func (*QueueTicket) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *Stats) GetTotalStacks() int32 {
+func (x *QueueTicket) GetTicketId() string {
 	if x != nil {
-		return x.TotalStacks
+		return x.TicketId
 	}
-	return 0
+	return ""
 }
 
-func (x *Stats) GetActiveStacks() int32 {
+func (x *QueueTicket) GetStatus() QueueTicketStatus {
 	if x != nil {
-		return x.ActiveStacks
+		return x.Status
+	}
+	return QueueTicketStatus_QUEUE_TICKET_STATUS_UNSPECIFIED
+}
+
+func (x *QueueTicket) GetPosition() int32 {
+	if x != nil {
+		return x.Position
 	}
 	return 0
 }
 
-func (x *Stats) GetNodeDistribution() map[string]int32 {
+func (x *QueueTicket) GetEta() *timestamppb.Timestamp {
 	if x != nil {
-		return x.NodeDistribution
+		return x.Eta
 	}
 	return nil
 }
 
-func (x *Stats) GetUsedNodePorts() int32 {
+func (x *QueueTicket) GetOwnerId() string {
 	if x != nil {
-		return x.UsedNodePorts
+		return x.OwnerId
 	}
-	return 0
+	return ""
 }
 
-func (x *Stats) GetReservedCpuMilli() int64 {
+func (x *QueueTicket) GetChallengeId() string {
 	if x != nil {
-		return x.ReservedCpuMilli
+		return x.ChallengeId
 	}
-	return 0
+	return ""
 }
 
-func (x *Stats) GetReservedMemoryBytes() int64 {
+func (x *QueueTicket) GetPortPool() string {
 	if x != nil {
-		return x.ReservedMemoryBytes
+		return x.PortPool
 	}
-	return 0
+	return ""
+}
+
+func (x *QueueTicket) GetStackId() string {
+	if x != nil {
+		return x.StackId
+	}
+	return ""
+}
+
+func (x *QueueTicket) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *QueueTicket) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *QueueTicket) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *QueueTicket) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *QueueTicket) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *QueueTicket) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
 }
 
 type Stack struct {
-	state                protoimpl.MessageState `protogen:"open.v1"`
-	StackId              string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
-	PodId                string                 `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
-	Namespace            string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	NodeId               string                 `protobuf:"bytes,4,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	NodePublicIp         *string                `protobuf:"bytes,5,opt,name=node_public_ip,json=nodePublicIp,proto3,oneof" json:"node_public_ip,omitempty"`
-	PodSpec              string                 `protobuf:"bytes,6,opt,name=pod_spec,json=podSpec,proto3" json:"pod_spec,omitempty"`
-	Ports                []*PortMapping         `protobuf:"bytes,7,rep,name=ports,proto3" json:"ports,omitempty"`
-	ServiceName          string                 `protobuf:"bytes,8,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
-	Status               Status                 `protobuf:"varint,9,opt,name=status,proto3,enum=stack.v1.Status" json:"status,omitempty"`
-	TtlExpiresAt         *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=ttl_expires_at,json=ttlExpiresAt,proto3" json:"ttl_expires_at,omitempty"`
-	CreatedAt            *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt            *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	RequestedCpuMilli    int64                  `protobuf:"varint,13,opt,name=requested_cpu_milli,json=requestedCpuMilli,proto3" json:"requested_cpu_milli,omitempty"`
-	RequestedMemoryBytes int64                  `protobuf:"varint,14,opt,name=requested_memory_bytes,json=requestedMemoryBytes,proto3" json:"requested_memory_bytes,omitempty"`
-	TargetPorts          []*PortSpec            `protobuf:"bytes,15,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	state                          protoimpl.MessageState `protogen:"open.v1"`
+	StackId                        string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
+	PodId                          string                 `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	Namespace                      string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	NodeId                         string                 `protobuf:"bytes,4,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	NodePublicIp                   *string                `protobuf:"bytes,5,opt,name=node_public_ip,json=nodePublicIp,proto3,oneof" json:"node_public_ip,omitempty"`
+	PodSpec                        string                 `protobuf:"bytes,6,opt,name=pod_spec,json=podSpec,proto3" json:"pod_spec,omitempty"`
+	Ports                          []*PortMapping         `protobuf:"bytes,7,rep,name=ports,proto3" json:"ports,omitempty"`
+	ServiceName                    string                 `protobuf:"bytes,8,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Status                         Status                 `protobuf:"varint,9,opt,name=status,proto3,enum=stack.v1.Status" json:"status,omitempty"`
+	TtlExpiresAt                   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=ttl_expires_at,json=ttlExpiresAt,proto3" json:"ttl_expires_at,omitempty"`
+	CreatedAt                      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt                      *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	RequestedCpuMilli              int64                  `protobuf:"varint,13,opt,name=requested_cpu_milli,json=requestedCpuMilli,proto3" json:"requested_cpu_milli,omitempty"`
+	RequestedMemoryBytes           int64                  `protobuf:"varint,14,opt,name=requested_memory_bytes,json=requestedMemoryBytes,proto3" json:"requested_memory_bytes,omitempty"`
+	TargetPorts                    []*PortSpec            `protobuf:"bytes,15,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
+	Connection                     []*ConnectionInfo      `protobuf:"bytes,16,rep,name=connection,proto3" json:"connection,omitempty"`
+	OwnerId                        string                 `protobuf:"bytes,17,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	ChallengeId                    string                 `protobuf:"bytes,18,opt,name=challenge_id,json=challengeId,proto3" json:"challenge_id,omitempty"`
+	PortPool                       string                 `protobuf:"bytes,19,opt,name=port_pool,json=portPool,proto3" json:"port_pool,omitempty"`
+	Placement                      string                 `protobuf:"bytes,20,opt,name=placement,proto3" json:"placement,omitempty"`
+	Priority                       string                 `protobuf:"bytes,21,opt,name=priority,proto3" json:"priority,omitempty"`
+	PreemptedBy                    string                 `protobuf:"bytes,22,opt,name=preempted_by,json=preemptedBy,proto3" json:"preempted_by,omitempty"`
+	ClusterId                      string                 `protobuf:"bytes,23,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
+	NodePool                       string                 `protobuf:"bytes,24,opt,name=node_pool,json=nodePool,proto3" json:"node_pool,omitempty"`
+	RequestedEphemeralStorageBytes int64                  `protobuf:"varint,25,opt,name=requested_ephemeral_storage_bytes,json=requestedEphemeralStorageBytes,proto3" json:"requested_ephemeral_storage_bytes,omitempty"`
+	unknownFields                  protoimpl.UnknownFields
+	sizeCache                      protoimpl.SizeCache
 }
 
 func (x *Stack) Reset() {
 	*x = Stack{}
-	mi := &file_stack_v1_stack_proto_msgTypes[19]
+	mi := &file_stack_v1_stack_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1039,7 +2834,7 @@ func (x *Stack) String() string {
 func (*Stack) ProtoMessage() {}
 
 func (x *Stack) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[19]
+	mi := &file_stack_v1_stack_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1052,7 +2847,7 @@ func (x *Stack) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Stack.ProtoReflect.Descriptor instead.
 func (*Stack) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{19}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *Stack) GetStackId() string {
@@ -1160,6 +2955,76 @@ func (x *Stack) GetTargetPorts() []*PortSpec {
 	return nil
 }
 
+func (x *Stack) GetConnection() []*ConnectionInfo {
+	if x != nil {
+		return x.Connection
+	}
+	return nil
+}
+
+func (x *Stack) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *Stack) GetChallengeId() string {
+	if x != nil {
+		return x.ChallengeId
+	}
+	return ""
+}
+
+func (x *Stack) GetPortPool() string {
+	if x != nil {
+		return x.PortPool
+	}
+	return ""
+}
+
+func (x *Stack) GetPlacement() string {
+	if x != nil {
+		return x.Placement
+	}
+	return ""
+}
+
+func (x *Stack) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Stack) GetPreemptedBy() string {
+	if x != nil {
+		return x.PreemptedBy
+	}
+	return ""
+}
+
+func (x *Stack) GetClusterId() string {
+	if x != nil {
+		return x.ClusterId
+	}
+	return ""
+}
+
+func (x *Stack) GetNodePool() string {
+	if x != nil {
+		return x.NodePool
+	}
+	return ""
+}
+
+func (x *Stack) GetRequestedEphemeralStorageBytes() int64 {
+	if x != nil {
+		return x.RequestedEphemeralStorageBytes
+	}
+	return 0
+}
+
 type StackStatusSummary struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	StackId       string                 `protobuf:"bytes,1,opt,name=stack_id,json=stackId,proto3" json:"stack_id,omitempty"`
@@ -1168,13 +3033,14 @@ type StackStatusSummary struct {
 	Ports         []*PortMapping         `protobuf:"bytes,4,rep,name=ports,proto3" json:"ports,omitempty"`
 	NodePublicIp  *string                `protobuf:"bytes,5,opt,name=node_public_ip,json=nodePublicIp,proto3,oneof" json:"node_public_ip,omitempty"`
 	TargetPorts   []*PortSpec            `protobuf:"bytes,6,rep,name=target_ports,json=targetPorts,proto3" json:"target_ports,omitempty"`
+	Connection    []*ConnectionInfo      `protobuf:"bytes,7,rep,name=connection,proto3" json:"connection,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StackStatusSummary) Reset() {
 	*x = StackStatusSummary{}
-	mi := &file_stack_v1_stack_proto_msgTypes[20]
+	mi := &file_stack_v1_stack_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1186,7 +3052,7 @@ func (x *StackStatusSummary) String() string {
 func (*StackStatusSummary) ProtoMessage() {}
 
 func (x *StackStatusSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[20]
+	mi := &file_stack_v1_stack_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1199,7 +3065,7 @@ func (x *StackStatusSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StackStatusSummary.ProtoReflect.Descriptor instead.
 func (*StackStatusSummary) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{20}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *StackStatusSummary) GetStackId() string {
@@ -1244,17 +3110,28 @@ func (x *StackStatusSummary) GetTargetPorts() []*PortSpec {
 	return nil
 }
 
+func (x *StackStatusSummary) GetConnection() []*ConnectionInfo {
+	if x != nil {
+		return x.Connection
+	}
+	return nil
+}
+
 type PortSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ContainerPort int32                  `protobuf:"varint,1,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
 	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Scheme        string                 `protobuf:"bytes,3,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	NodePort      int32                  `protobuf:"varint,5,opt,name=node_port,json=nodePort,proto3" json:"node_port,omitempty"`
+	Sticky        bool                   `protobuf:"varint,6,opt,name=sticky,proto3" json:"sticky,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PortSpec) Reset() {
 	*x = PortSpec{}
-	mi := &file_stack_v1_stack_proto_msgTypes[21]
+	mi := &file_stack_v1_stack_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1266,7 +3143,7 @@ func (x *PortSpec) String() string {
 func (*PortSpec) ProtoMessage() {}
 
 func (x *PortSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[21]
+	mi := &file_stack_v1_stack_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1279,7 +3156,7 @@ func (x *PortSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PortSpec.ProtoReflect.Descriptor instead.
 func (*PortSpec) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{21}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *PortSpec) GetContainerPort() int32 {
@@ -1296,18 +3173,48 @@ func (x *PortSpec) GetProtocol() string {
 	return ""
 }
 
+func (x *PortSpec) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *PortSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PortSpec) GetNodePort() int32 {
+	if x != nil {
+		return x.NodePort
+	}
+	return 0
+}
+
+func (x *PortSpec) GetSticky() bool {
+	if x != nil {
+		return x.Sticky
+	}
+	return false
+}
+
 type PortMapping struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ContainerPort int32                  `protobuf:"varint,1,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
 	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
 	NodePort      int32                  `protobuf:"varint,3,opt,name=node_port,json=nodePort,proto3" json:"node_port,omitempty"`
+	Scheme        string                 `protobuf:"bytes,4,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PortMapping) Reset() {
 	*x = PortMapping{}
-	mi := &file_stack_v1_stack_proto_msgTypes[22]
+	mi := &file_stack_v1_stack_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1319,7 +3226,7 @@ func (x *PortMapping) String() string {
 func (*PortMapping) ProtoMessage() {}
 
 func (x *PortMapping) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[22]
+	mi := &file_stack_v1_stack_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1332,7 +3239,7 @@ func (x *PortMapping) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PortMapping.ProtoReflect.Descriptor instead.
 func (*PortMapping) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{22}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *PortMapping) GetContainerPort() int32 {
@@ -1356,6 +3263,112 @@ func (x *PortMapping) GetNodePort() int32 {
 	return 0
 }
 
+func (x *PortMapping) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *PortMapping) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ConnectionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ContainerPort int32                  `protobuf:"varint,2,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	NodePort      int32                  `protobuf:"varint,4,opt,name=node_port,json=nodePort,proto3" json:"node_port,omitempty"`
+	Scheme        string                 `protobuf:"bytes,5,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	Host          string                 `protobuf:"bytes,6,opt,name=host,proto3" json:"host,omitempty"`
+	Rendered      string                 `protobuf:"bytes,7,opt,name=rendered,proto3" json:"rendered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectionInfo) Reset() {
+	*x = ConnectionInfo{}
+	mi := &file_stack_v1_stack_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionInfo) ProtoMessage() {}
+
+func (x *ConnectionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_v1_stack_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionInfo.ProtoReflect.Descriptor instead.
+func (*ConnectionInfo) Descriptor() ([]byte, []int) {
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ConnectionInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetContainerPort() int32 {
+	if x != nil {
+		return x.ContainerPort
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetNodePort() int32 {
+	if x != nil {
+		return x.NodePort
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetRendered() string {
+	if x != nil {
+		return x.Rendered
+	}
+	return ""
+}
+
 type BatchDeleteJob struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -1373,7 +3386,7 @@ type BatchDeleteJob struct {
 
 func (x *BatchDeleteJob) Reset() {
 	*x = BatchDeleteJob{}
-	mi := &file_stack_v1_stack_proto_msgTypes[23]
+	mi := &file_stack_v1_stack_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1385,7 +3398,7 @@ func (x *BatchDeleteJob) String() string {
 func (*BatchDeleteJob) ProtoMessage() {}
 
 func (x *BatchDeleteJob) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[23]
+	mi := &file_stack_v1_stack_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1398,7 +3411,7 @@ func (x *BatchDeleteJob) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchDeleteJob.ProtoReflect.Descriptor instead.
 func (*BatchDeleteJob) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{23}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *BatchDeleteJob) GetJobId() string {
@@ -1474,7 +3487,7 @@ type JobError struct {
 
 func (x *JobError) Reset() {
 	*x = JobError{}
-	mi := &file_stack_v1_stack_proto_msgTypes[24]
+	mi := &file_stack_v1_stack_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1486,7 +3499,7 @@ func (x *JobError) String() string {
 func (*JobError) ProtoMessage() {}
 
 func (x *JobError) ProtoReflect() protoreflect.Message {
-	mi := &file_stack_v1_stack_proto_msgTypes[24]
+	mi := &file_stack_v1_stack_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1499,7 +3512,7 @@ func (x *JobError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobError.ProtoReflect.Descriptor instead.
 func (*JobError) Descriptor() ([]byte, []int) {
-	return file_stack_v1_stack_proto_rawDescGZIP(), []int{24}
+	return file_stack_v1_stack_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *JobError) GetStackId() string {
@@ -1520,15 +3533,59 @@ var File_stack_v1_stack_proto protoreflect.FileDescriptor
 
 const file_stack_v1_stack_proto_rawDesc = "" +
 	"\n" +
-	"\x14stack/v1/stack.proto\x12\bstack.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x10\n" +
+	"\x14stack/v1/stack.proto\x12\bstack.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x10\n" +
 	"\x0eHealthzRequest\")\n" +
 	"\x0fHealthzResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"f\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\xe9\x02\n" +
 	"\x12CreateStackRequest\x12\x19\n" +
 	"\bpod_spec\x18\x01 \x01(\tR\apodSpec\x125\n" +
-	"\ftarget_ports\x18\x02 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\"<\n" +
+	"\ftarget_ports\x18\x02 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\x12\x19\n" +
+	"\bowner_id\x18\x03 \x01(\tR\aownerId\x12!\n" +
+	"\fchallenge_id\x18\x04 \x01(\tR\vchallengeId\x12\x1b\n" +
+	"\tport_pool\x18\x05 \x01(\tR\bportPool\x12\x14\n" +
+	"\x05queue\x18\x06 \x01(\bR\x05queue\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\tR\bpriority\x12\x16\n" +
+	"\x06region\x18\b \x01(\tR\x06region\x12\x1b\n" +
+	"\tnode_pool\x18\t \x01(\tR\bnodePool\x12?\n" +
+	"\x0fpod_spec_object\x18\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\rpodSpecObject\"k\n" +
 	"\x13CreateStackResponse\x12%\n" +
-	"\x05stack\x18\x01 \x01(\v2\x0f.stack.v1.StackR\x05stack\",\n" +
+	"\x05stack\x18\x01 \x01(\v2\x0f.stack.v1.StackR\x05stack\x12-\n" +
+	"\x06ticket\x18\x02 \x01(\v2\x15.stack.v1.QueueTicketR\x06ticket\"\xd5\x02\n" +
+	"\x14ValidateStackRequest\x12\x19\n" +
+	"\bpod_spec\x18\x01 \x01(\tR\apodSpec\x125\n" +
+	"\ftarget_ports\x18\x02 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\x12\x19\n" +
+	"\bowner_id\x18\x03 \x01(\tR\aownerId\x12!\n" +
+	"\fchallenge_id\x18\x04 \x01(\tR\vchallengeId\x12\x1b\n" +
+	"\tport_pool\x18\x05 \x01(\tR\bportPool\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\tR\bpriority\x12\x16\n" +
+	"\x06region\x18\a \x01(\tR\x06region\x12\x1b\n" +
+	"\tnode_pool\x18\b \x01(\tR\bnodePool\x12?\n" +
+	"\x0fpod_spec_object\x18\t \x01(\v2\x17.google.protobuf.StructR\rpodSpecObject\"R\n" +
+	"\x15ValidateStackResponse\x129\n" +
+	"\n" +
+	"validation\x18\x01 \x01(\v2\x19.stack.v1.StackValidationR\n" +
+	"validation\"\xc0\x03\n" +
+	"\x0fStackValidation\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12,\n" +
+	"\x12sanitized_pod_spec\x18\x02 \x01(\tR\x10sanitizedPodSpec\x12.\n" +
+	"\x13requested_cpu_milli\x18\x03 \x01(\x03R\x11requestedCpuMilli\x124\n" +
+	"\x16requested_memory_bytes\x18\x04 \x01(\x03R\x14requestedMemoryBytes\x12I\n" +
+	"!requested_ephemeral_storage_bytes\x18\x05 \x01(\x03R\x1erequestedEphemeralStorageBytes\x125\n" +
+	"\ftarget_ports\x18\x06 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\x12\x1b\n" +
+	"\tnode_pool\x18\a \x01(\tR\bnodePool\x12/\n" +
+	"\achanges\x18\b \x03(\v2\x15.stack.v1.FieldChangeR\achanges\x123\n" +
+	"\n" +
+	"violations\x18\t \x03(\v2\x13.stack.v1.ViolationR\n" +
+	"violations\"O\n" +
+	"\vFieldChange\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"O\n" +
+	"\tViolation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\",\n" +
 	"\x0fGetStackRequest\x12\x19\n" +
 	"\bstack_id\x18\x01 \x01(\tR\astackId\"9\n" +
 	"\x10GetStackResponse\x12%\n" +
@@ -1555,17 +3612,147 @@ const file_stack_v1_stack_proto_rawDesc = "" +
 	"\x03job\x18\x01 \x01(\v2\x18.stack.v1.BatchDeleteJobR\x03job\"\x11\n" +
 	"\x0fGetStatsRequest\"9\n" +
 	"\x10GetStatsResponse\x12%\n" +
-	"\x05stats\x18\x01 \x01(\v2\x0f.stack.v1.StatsR\x05stats\"\xf2\x02\n" +
+	"\x05stats\x18\x01 \x01(\v2\x0f.stack.v1.StatsR\x05stats\"\x84\t\n" +
 	"\x05Stats\x12!\n" +
 	"\ftotal_stacks\x18\x01 \x01(\x05R\vtotalStacks\x12#\n" +
 	"\ractive_stacks\x18\x02 \x01(\x05R\factiveStacks\x12R\n" +
 	"\x11node_distribution\x18\x03 \x03(\v2%.stack.v1.Stats.NodeDistributionEntryR\x10nodeDistribution\x12&\n" +
 	"\x0fused_node_ports\x18\x04 \x01(\x05R\rusedNodePorts\x12,\n" +
 	"\x12reserved_cpu_milli\x18\x05 \x01(\x03R\x10reservedCpuMilli\x122\n" +
-	"\x15reserved_memory_bytes\x18\x06 \x01(\x03R\x13reservedMemoryBytes\x1aC\n" +
+	"\x15reserved_memory_bytes\x18\x06 \x01(\x03R\x13reservedMemoryBytes\x12J\n" +
+	"\x0fnode_port_pools\x18\a \x03(\v2\".stack.v1.Stats.NodePortPoolsEntryR\rnodePortPools\x12-\n" +
+	"\x12placement_strategy\x18\b \x01(\tR\x11placementStrategy\x12a\n" +
+	"\x16placement_distribution\x18\t \x03(\v2*.stack.v1.Stats.PlacementDistributionEntryR\x15placementDistribution\x129\n" +
+	"\bclusters\x18\n" +
+	" \x03(\v2\x1d.stack.v1.Stats.ClustersEntryR\bclusters\x12=\n" +
+	"\n" +
+	"node_pools\x18\v \x03(\v2\x1e.stack.v1.Stats.NodePoolsEntryR\tnodePools\x12G\n" +
+	" reserved_ephemeral_storage_bytes\x18\f \x01(\x03R\x1dreservedEphemeralStorageBytes\x1aC\n" +
 	"\x15NodeDistributionEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x98\x05\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a]\n" +
+	"\x12NodePortPoolsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\x05value\x18\x02 \x01(\v2\x1b.stack.v1.NodePortPoolUsageR\x05value:\x028\x01\x1ad\n" +
+	"\x1aPlacementDistributionEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.stack.v1.NodeDistributionR\x05value:\x028\x01\x1aS\n" +
+	"\rClustersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.stack.v1.ClusterUsageR\x05value:\x028\x01\x1aU\n" +
+	"\x0eNodePoolsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.stack.v1.NodePoolUsageR\x05value:\x028\x01\"\xd0\x01\n" +
+	"\fClusterUsage\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12!\n" +
+	"\ftotal_stacks\x18\x02 \x01(\x05R\vtotalStacks\x12#\n" +
+	"\ractive_stacks\x18\x03 \x01(\x05R\factiveStacks\x12,\n" +
+	"\x12reserved_cpu_milli\x18\x04 \x01(\x03R\x10reservedCpuMilli\x122\n" +
+	"\x15reserved_memory_bytes\x18\x05 \x01(\x03R\x13reservedMemoryBytes\"\xbd\x02\n" +
+	"\rNodePoolUsage\x12\x14\n" +
+	"\x05nodes\x18\x01 \x01(\x05R\x05nodes\x122\n" +
+	"\x15allocatable_cpu_milli\x18\x02 \x01(\x03R\x13allocatableCpuMilli\x128\n" +
+	"\x18allocatable_memory_bytes\x18\x03 \x01(\x03R\x16allocatableMemoryBytes\x12!\n" +
+	"\ftotal_stacks\x18\x04 \x01(\x05R\vtotalStacks\x12#\n" +
+	"\ractive_stacks\x18\x05 \x01(\x05R\factiveStacks\x12,\n" +
+	"\x12reserved_cpu_milli\x18\x06 \x01(\x03R\x10reservedCpuMilli\x122\n" +
+	"\x15reserved_memory_bytes\x18\a \x01(\x03R\x13reservedMemoryBytes\"\x89\x01\n" +
+	"\x10NodeDistribution\x12;\n" +
+	"\x05nodes\x18\x01 \x03(\v2%.stack.v1.NodeDistribution.NodesEntryR\x05nodes\x1a8\n" +
+	"\n" +
+	"NodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"g\n" +
+	"\x11NodePortPoolUsage\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x05R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x05R\x03max\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x05R\x04used\x12\x1a\n" +
+	"\bcapacity\x18\x04 \x01(\x05R\bcapacity\"\x1f\n" +
+	"\x1dGetPortReconcileReportRequest\"W\n" +
+	"\x1eGetPortReconcileReportResponse\x125\n" +
+	"\x06report\x18\x01 \x01(\v2\x1d.stack.v1.PortReconcileReportR\x06report\"\xac\x02\n" +
+	"\x13PortReconcileReport\x129\n" +
+	"\n" +
+	"checked_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05locks\x18\x03 \x01(\x05R\x05locks\x12#\n" +
+	"\rservice_ports\x18\x04 \x01(\x05R\fservicePorts\x12\x1f\n" +
+	"\vstack_ports\x18\x05 \x01(\x05R\n" +
+	"stackPorts\x121\n" +
+	"\bfindings\x18\x06 \x03(\v2\x15.stack.v1.PortFindingR\bfindings\x12\x1a\n" +
+	"\brepaired\x18\a \x01(\x05R\brepaired\x12\x16\n" +
+	"\x06failed\x18\b \x01(\x05R\x06failed\"\xae\x01\n" +
+	"\vPortFinding\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1b\n" +
+	"\tnode_port\x18\x02 \x01(\x05R\bnodePort\x12\x19\n" +
+	"\bstack_id\x18\x03 \x01(\tR\astackId\x12!\n" +
+	"\fservice_name\x18\x04 \x01(\tR\vserviceName\x12\x1a\n" +
+	"\brepaired\x18\x05 \x01(\bR\brepaired\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\">\n" +
+	"\x12GetCapacityRequest\x12\x10\n" +
+	"\x03cpu\x18\x01 \x01(\tR\x03cpu\x12\x16\n" +
+	"\x06memory\x18\x02 \x01(\tR\x06memory\"G\n" +
+	"\x13GetCapacityResponse\x120\n" +
+	"\x06report\x18\x01 \x01(\v2\x18.stack.v1.CapacityReportR\x06report\"\xd1\x03\n" +
+	"\x0eCapacityReport\x129\n" +
+	"\n" +
+	"checked_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\x12,\n" +
+	"\x05nodes\x18\x02 \x03(\v2\x16.stack.v1.NodeCapacityR\x05nodes\x122\n" +
+	"\x15allocatable_cpu_milli\x18\x03 \x01(\x03R\x13allocatableCpuMilli\x128\n" +
+	"\x18allocatable_memory_bytes\x18\x04 \x01(\x03R\x16allocatableMemoryBytes\x12.\n" +
+	"\x13requested_cpu_milli\x18\x05 \x01(\x03R\x11requestedCpuMilli\x124\n" +
+	"\x16requested_memory_bytes\x18\x06 \x01(\x03R\x14requestedMemoryBytes\x12,\n" +
+	"\x12headroom_cpu_milli\x18\a \x01(\x03R\x10headroomCpuMilli\x122\n" +
+	"\x15headroom_memory_bytes\x18\b \x01(\x03R\x13headroomMemoryBytes\x12\x17\n" +
+	"\x04fits\x18\t \x01(\x05H\x00R\x04fits\x88\x01\x01B\a\n" +
+	"\x05_fits\"\xb9\x04\n" +
+	"\fNodeCapacity\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1d\n" +
+	"\n" +
+	"cluster_id\x18\x02 \x01(\tR\tclusterId\x12\x1d\n" +
+	"\n" +
+	"node_pools\x18\x03 \x03(\tR\tnodePools\x12 \n" +
+	"\tpublic_ip\x18\x04 \x01(\tH\x00R\bpublicIp\x88\x01\x01\x12\x14\n" +
+	"\x05ready\x18\x05 \x01(\bR\x05ready\x12 \n" +
+	"\vschedulable\x18\x06 \x01(\bR\vschedulable\x122\n" +
+	"\x15allocatable_cpu_milli\x18\a \x01(\x03R\x13allocatableCpuMilli\x128\n" +
+	"\x18allocatable_memory_bytes\x18\b \x01(\x03R\x16allocatableMemoryBytes\x12.\n" +
+	"\x13requested_cpu_milli\x18\t \x01(\x03R\x11requestedCpuMilli\x124\n" +
+	"\x16requested_memory_bytes\x18\n" +
+	" \x01(\x03R\x14requestedMemoryBytes\x12,\n" +
+	"\x12headroom_cpu_milli\x18\v \x01(\x03R\x10headroomCpuMilli\x122\n" +
+	"\x15headroom_memory_bytes\x18\f \x01(\x03R\x13headroomMemoryBytes\x12\x12\n" +
+	"\x04pods\x18\r \x01(\x05R\x04pods\x12\x17\n" +
+	"\x04fits\x18\x0e \x01(\x05H\x01R\x04fits\x88\x01\x01B\f\n" +
+	"\n" +
+	"_public_ipB\a\n" +
+	"\x05_fits\"4\n" +
+	"\x15GetQueueTicketRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\"G\n" +
+	"\x16GetQueueTicketResponse\x12-\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x15.stack.v1.QueueTicketR\x06ticket\"7\n" +
+	"\x18CancelQueueTicketRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\"J\n" +
+	"\x19CancelQueueTicketResponse\x12-\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x15.stack.v1.QueueTicketR\x06ticket\"\xfc\x03\n" +
+	"\vQueueTicket\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\x123\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1b.stack.v1.QueueTicketStatusR\x06status\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\x05R\bposition\x12,\n" +
+	"\x03eta\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x03eta\x12\x19\n" +
+	"\bowner_id\x18\x05 \x01(\tR\aownerId\x12!\n" +
+	"\fchallenge_id\x18\x06 \x01(\tR\vchallengeId\x12\x1b\n" +
+	"\tport_pool\x18\a \x01(\tR\bportPool\x12\x19\n" +
+	"\bstack_id\x18\b \x01(\tR\astackId\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1a\n" +
+	"\bpriority\x18\f \x01(\tR\bpriority\x12\x16\n" +
+	"\x06region\x18\r \x01(\tR\x06region\x12\x1b\n" +
+	"\tnode_pool\x18\x0e \x01(\tR\bnodePool\"\x91\b\n" +
 	"\x05Stack\x12\x19\n" +
 	"\bstack_id\x18\x01 \x01(\tR\astackId\x12\x15\n" +
 	"\x06pod_id\x18\x02 \x01(\tR\x05podId\x12\x1c\n" +
@@ -1584,23 +3771,53 @@ const file_stack_v1_stack_proto_rawDesc = "" +
 	"updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12.\n" +
 	"\x13requested_cpu_milli\x18\r \x01(\x03R\x11requestedCpuMilli\x124\n" +
 	"\x16requested_memory_bytes\x18\x0e \x01(\x03R\x14requestedMemoryBytes\x125\n" +
-	"\ftarget_ports\x18\x0f \x03(\v2\x12.stack.v1.PortSpecR\vtargetPortsB\x11\n" +
-	"\x0f_node_public_ip\"\xa9\x02\n" +
+	"\ftarget_ports\x18\x0f \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\x128\n" +
+	"\n" +
+	"connection\x18\x10 \x03(\v2\x18.stack.v1.ConnectionInfoR\n" +
+	"connection\x12\x19\n" +
+	"\bowner_id\x18\x11 \x01(\tR\aownerId\x12!\n" +
+	"\fchallenge_id\x18\x12 \x01(\tR\vchallengeId\x12\x1b\n" +
+	"\tport_pool\x18\x13 \x01(\tR\bportPool\x12\x1c\n" +
+	"\tplacement\x18\x14 \x01(\tR\tplacement\x12\x1a\n" +
+	"\bpriority\x18\x15 \x01(\tR\bpriority\x12!\n" +
+	"\fpreempted_by\x18\x16 \x01(\tR\vpreemptedBy\x12\x1d\n" +
+	"\n" +
+	"cluster_id\x18\x17 \x01(\tR\tclusterId\x12\x1b\n" +
+	"\tnode_pool\x18\x18 \x01(\tR\bnodePool\x12I\n" +
+	"!requested_ephemeral_storage_bytes\x18\x19 \x01(\x03R\x1erequestedEphemeralStorageBytesB\x11\n" +
+	"\x0f_node_public_ip\"\xe3\x02\n" +
 	"\x12StackStatusSummary\x12\x19\n" +
 	"\bstack_id\x18\x01 \x01(\tR\astackId\x12(\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x10.stack.v1.StatusR\x06status\x12,\n" +
 	"\x03ttl\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03ttl\x12+\n" +
 	"\x05ports\x18\x04 \x03(\v2\x15.stack.v1.PortMappingR\x05ports\x12)\n" +
 	"\x0enode_public_ip\x18\x05 \x01(\tH\x00R\fnodePublicIp\x88\x01\x01\x125\n" +
-	"\ftarget_ports\x18\x06 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPortsB\x11\n" +
-	"\x0f_node_public_ip\"M\n" +
+	"\ftarget_ports\x18\x06 \x03(\v2\x12.stack.v1.PortSpecR\vtargetPorts\x128\n" +
+	"\n" +
+	"connection\x18\a \x03(\v2\x18.stack.v1.ConnectionInfoR\n" +
+	"connectionB\x11\n" +
+	"\x0f_node_public_ip\"\xae\x01\n" +
 	"\bPortSpec\x12%\n" +
 	"\x0econtainer_port\x18\x01 \x01(\x05R\rcontainerPort\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\"m\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x16\n" +
+	"\x06scheme\x18\x03 \x01(\tR\x06scheme\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1b\n" +
+	"\tnode_port\x18\x05 \x01(\x05R\bnodePort\x12\x16\n" +
+	"\x06sticky\x18\x06 \x01(\bR\x06sticky\"\x99\x01\n" +
 	"\vPortMapping\x12%\n" +
 	"\x0econtainer_port\x18\x01 \x01(\x05R\rcontainerPort\x12\x1a\n" +
 	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1b\n" +
-	"\tnode_port\x18\x03 \x01(\x05R\bnodePort\"\xdb\x02\n" +
+	"\tnode_port\x18\x03 \x01(\x05R\bnodePort\x12\x16\n" +
+	"\x06scheme\x18\x04 \x01(\tR\x06scheme\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\"\xcc\x01\n" +
+	"\x0eConnectionInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
+	"\x0econtainer_port\x18\x02 \x01(\x05R\rcontainerPort\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x1b\n" +
+	"\tnode_port\x18\x04 \x01(\x05R\bnodePort\x12\x16\n" +
+	"\x06scheme\x18\x05 \x01(\tR\x06scheme\x12\x12\n" +
+	"\x04host\x18\x06 \x01(\tR\x04host\x12\x1a\n" +
+	"\brendered\x18\a \x01(\tR\brendered\"\xdb\x02\n" +
 	"\x0eBatchDeleteJob\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12+\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x13.stack.v1.JobStatusR\x06status\x12\x14\n" +
@@ -1615,23 +3832,33 @@ const file_stack_v1_stack_proto_rawDesc = "" +
 	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\";\n" +
 	"\bJobError\x12\x19\n" +
 	"\bstack_id\x18\x01 \x01(\tR\astackId\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error*\x89\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error*\x9f\x01\n" +
 	"\x06Status\x12\x16\n" +
 	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x13\n" +
 	"\x0fSTATUS_CREATING\x10\x01\x12\x12\n" +
 	"\x0eSTATUS_RUNNING\x10\x02\x12\x12\n" +
 	"\x0eSTATUS_STOPPED\x10\x03\x12\x11\n" +
 	"\rSTATUS_FAILED\x10\x04\x12\x17\n" +
-	"\x13STATUS_NODE_DELETED\x10\x05*\x87\x01\n" +
+	"\x13STATUS_NODE_DELETED\x10\x05\x12\x14\n" +
+	"\x10STATUS_PREEMPTED\x10\x06*\x87\x01\n" +
 	"\tJobStatus\x12\x1a\n" +
 	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11JOB_STATUS_QUEUED\x10\x01\x12\x16\n" +
 	"\x12JOB_STATUS_RUNNING\x10\x02\x12\x18\n" +
 	"\x14JOB_STATUS_COMPLETED\x10\x03\x12\x15\n" +
-	"\x11JOB_STATUS_FAILED\x10\x042\xe4\x05\n" +
+	"\x11JOB_STATUS_FAILED\x10\x04*\x87\x02\n" +
+	"\x11QueueTicketStatus\x12#\n" +
+	"\x1fQUEUE_TICKET_STATUS_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aQUEUE_TICKET_STATUS_QUEUED\x10\x01\x12$\n" +
+	" QUEUE_TICKET_STATUS_PROVISIONING\x10\x02\x12#\n" +
+	"\x1fQUEUE_TICKET_STATUS_PROVISIONED\x10\x03\x12\x1e\n" +
+	"\x1aQUEUE_TICKET_STATUS_FAILED\x10\x04\x12!\n" +
+	"\x1dQUEUE_TICKET_STATUS_CANCELLED\x10\x05\x12\x1f\n" +
+	"\x1bQUEUE_TICKET_STATUS_EXPIRED\x10\x062\xa2\t\n" +
 	"\fStackService\x12>\n" +
 	"\aHealthz\x12\x18.stack.v1.HealthzRequest\x1a\x19.stack.v1.HealthzResponse\x12J\n" +
-	"\vCreateStack\x12\x1c.stack.v1.CreateStackRequest\x1a\x1d.stack.v1.CreateStackResponse\x12A\n" +
+	"\vCreateStack\x12\x1c.stack.v1.CreateStackRequest\x1a\x1d.stack.v1.CreateStackResponse\x12P\n" +
+	"\rValidateStack\x12\x1e.stack.v1.ValidateStackRequest\x1a\x1f.stack.v1.ValidateStackResponse\x12A\n" +
 	"\bGetStack\x12\x19.stack.v1.GetStackRequest\x1a\x1a.stack.v1.GetStackResponse\x12h\n" +
 	"\x15GetStackStatusSummary\x12&.stack.v1.GetStackStatusSummaryRequest\x1a'.stack.v1.GetStackStatusSummaryResponse\x12J\n" +
 	"\vDeleteStack\x12\x1c.stack.v1.DeleteStackRequest\x1a\x1d.stack.v1.DeleteStackResponse\x12G\n" +
@@ -1639,7 +3866,11 @@ const file_stack_v1_stack_proto_rawDesc = "" +
 	"ListStacks\x12\x1b.stack.v1.ListStacksRequest\x1a\x1c.stack.v1.ListStacksResponse\x12e\n" +
 	"\x14CreateBatchDeleteJob\x12%.stack.v1.CreateBatchDeleteJobRequest\x1a&.stack.v1.CreateBatchDeleteJobResponse\x12\\\n" +
 	"\x11GetBatchDeleteJob\x12\".stack.v1.GetBatchDeleteJobRequest\x1a#.stack.v1.GetBatchDeleteJobResponse\x12A\n" +
-	"\bGetStats\x12\x19.stack.v1.GetStatsRequest\x1a\x1a.stack.v1.GetStatsResponseB%Z#smctf/internal/gen/stack/v1;stackv1b\x06proto3"
+	"\bGetStats\x12\x19.stack.v1.GetStatsRequest\x1a\x1a.stack.v1.GetStatsResponse\x12k\n" +
+	"\x16GetPortReconcileReport\x12'.stack.v1.GetPortReconcileReportRequest\x1a(.stack.v1.GetPortReconcileReportResponse\x12J\n" +
+	"\vGetCapacity\x12\x1c.stack.v1.GetCapacityRequest\x1a\x1d.stack.v1.GetCapacityResponse\x12S\n" +
+	"\x0eGetQueueTicket\x12\x1f.stack.v1.GetQueueTicketRequest\x1a .stack.v1.GetQueueTicketResponse\x12\\\n" +
+	"\x11CancelQueueTicket\x12\".stack.v1.CancelQueueTicketRequest\x1a#.stack.v1.CancelQueueTicketResponseB%Z#smctf/internal/gen/stack/v1;stackv1b\x06proto3"
 
 var (
 	file_stack_v1_stack_proto_rawDescOnce sync.Once
@@ -1653,85 +3884,156 @@ func file_stack_v1_stack_proto_rawDescGZIP() []byte {
 	return file_stack_v1_stack_proto_rawDescData
 }
 
-var file_stack_v1_stack_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_stack_v1_stack_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_stack_v1_stack_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_stack_v1_stack_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_stack_v1_stack_proto_goTypes = []any{
-	(Status)(0),                           // 0: stack.v1.Status
-	(JobStatus)(0),                        // 1: stack.v1.JobStatus
-	(*HealthzRequest)(nil),                // 2: stack.v1.HealthzRequest
-	(*HealthzResponse)(nil),               // 3: stack.v1.HealthzResponse
-	(*CreateStackRequest)(nil),            // 4: stack.v1.CreateStackRequest
-	(*CreateStackResponse)(nil),           // 5: stack.v1.CreateStackResponse
-	(*GetStackRequest)(nil),               // 6: stack.v1.GetStackRequest
-	(*GetStackResponse)(nil),              // 7: stack.v1.GetStackResponse
-	(*GetStackStatusSummaryRequest)(nil),  // 8: stack.v1.GetStackStatusSummaryRequest
-	(*GetStackStatusSummaryResponse)(nil), // 9: stack.v1.GetStackStatusSummaryResponse
-	(*DeleteStackRequest)(nil),            // 10: stack.v1.DeleteStackRequest
-	(*DeleteStackResponse)(nil),           // 11: stack.v1.DeleteStackResponse
-	(*ListStacksRequest)(nil),             // 12: stack.v1.ListStacksRequest
-	(*ListStacksResponse)(nil),            // 13: stack.v1.ListStacksResponse
-	(*CreateBatchDeleteJobRequest)(nil),   // 14: stack.v1.CreateBatchDeleteJobRequest
-	(*CreateBatchDeleteJobResponse)(nil),  // 15: stack.v1.CreateBatchDeleteJobResponse
-	(*GetBatchDeleteJobRequest)(nil),      // 16: stack.v1.GetBatchDeleteJobRequest
-	(*GetBatchDeleteJobResponse)(nil),     // 17: stack.v1.GetBatchDeleteJobResponse
-	(*GetStatsRequest)(nil),               // 18: stack.v1.GetStatsRequest
-	(*GetStatsResponse)(nil),              // 19: stack.v1.GetStatsResponse
-	(*Stats)(nil),                         // 20: stack.v1.Stats
-	(*Stack)(nil),                         // 21: stack.v1.Stack
-	(*StackStatusSummary)(nil),            // 22: stack.v1.StackStatusSummary
-	(*PortSpec)(nil),                      // 23: stack.v1.PortSpec
-	(*PortMapping)(nil),                   // 24: stack.v1.PortMapping
-	(*BatchDeleteJob)(nil),                // 25: stack.v1.BatchDeleteJob
-	(*JobError)(nil),                      // 26: stack.v1.JobError
-	nil,                                   // 27: stack.v1.Stats.NodeDistributionEntry
-	(*timestamppb.Timestamp)(nil),         // 28: google.protobuf.Timestamp
+	(Status)(0),                            // 0: stack.v1.Status
+	(JobStatus)(0),                         // 1: stack.v1.JobStatus
+	(QueueTicketStatus)(0),                 // 2: stack.v1.QueueTicketStatus
+	(*HealthzRequest)(nil),                 // 3: stack.v1.HealthzRequest
+	(*HealthzResponse)(nil),                // 4: stack.v1.HealthzResponse
+	(*CreateStackRequest)(nil),             // 5: stack.v1.CreateStackRequest
+	(*CreateStackResponse)(nil),            // 6: stack.v1.CreateStackResponse
+	(*ValidateStackRequest)(nil),           // 7: stack.v1.ValidateStackRequest
+	(*ValidateStackResponse)(nil),          // 8: stack.v1.ValidateStackResponse
+	(*StackValidation)(nil),                // 9: stack.v1.StackValidation
+	(*FieldChange)(nil),                    // 10: stack.v1.FieldChange
+	(*Violation)(nil),                      // 11: stack.v1.Violation
+	(*GetStackRequest)(nil),                // 12: stack.v1.GetStackRequest
+	(*GetStackResponse)(nil),               // 13: stack.v1.GetStackResponse
+	(*GetStackStatusSummaryRequest)(nil),   // 14: stack.v1.GetStackStatusSummaryRequest
+	(*GetStackStatusSummaryResponse)(nil),  // 15: stack.v1.GetStackStatusSummaryResponse
+	(*DeleteStackRequest)(nil),             // 16: stack.v1.DeleteStackRequest
+	(*DeleteStackResponse)(nil),            // 17: stack.v1.DeleteStackResponse
+	(*ListStacksRequest)(nil),              // 18: stack.v1.ListStacksRequest
+	(*ListStacksResponse)(nil),             // 19: stack.v1.ListStacksResponse
+	(*CreateBatchDeleteJobRequest)(nil),    // 20: stack.v1.CreateBatchDeleteJobRequest
+	(*CreateBatchDeleteJobResponse)(nil),   // 21: stack.v1.CreateBatchDeleteJobResponse
+	(*GetBatchDeleteJobRequest)(nil),       // 22: stack.v1.GetBatchDeleteJobRequest
+	(*GetBatchDeleteJobResponse)(nil),      // 23: stack.v1.GetBatchDeleteJobResponse
+	(*GetStatsRequest)(nil),                // 24: stack.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),               // 25: stack.v1.GetStatsResponse
+	(*Stats)(nil),                          // 26: stack.v1.Stats
+	(*ClusterUsage)(nil),                   // 27: stack.v1.ClusterUsage
+	(*NodePoolUsage)(nil),                  // 28: stack.v1.NodePoolUsage
+	(*NodeDistribution)(nil),               // 29: stack.v1.NodeDistribution
+	(*NodePortPoolUsage)(nil),              // 30: stack.v1.NodePortPoolUsage
+	(*GetPortReconcileReportRequest)(nil),  // 31: stack.v1.GetPortReconcileReportRequest
+	(*GetPortReconcileReportResponse)(nil), // 32: stack.v1.GetPortReconcileReportResponse
+	(*PortReconcileReport)(nil),            // 33: stack.v1.PortReconcileReport
+	(*PortFinding)(nil),                    // 34: stack.v1.PortFinding
+	(*GetCapacityRequest)(nil),             // 35: stack.v1.GetCapacityRequest
+	(*GetCapacityResponse)(nil),            // 36: stack.v1.GetCapacityResponse
+	(*CapacityReport)(nil),                 // 37: stack.v1.CapacityReport
+	(*NodeCapacity)(nil),                   // 38: stack.v1.NodeCapacity
+	(*GetQueueTicketRequest)(nil),          // 39: stack.v1.GetQueueTicketRequest
+	(*GetQueueTicketResponse)(nil),         // 40: stack.v1.GetQueueTicketResponse
+	(*CancelQueueTicketRequest)(nil),       // 41: stack.v1.CancelQueueTicketRequest
+	(*CancelQueueTicketResponse)(nil),      // 42: stack.v1.CancelQueueTicketResponse
+	(*QueueTicket)(nil),                    // 43: stack.v1.QueueTicket
+	(*Stack)(nil),                          // 44: stack.v1.Stack
+	(*StackStatusSummary)(nil),             // 45: stack.v1.StackStatusSummary
+	(*PortSpec)(nil),                       // 46: stack.v1.PortSpec
+	(*PortMapping)(nil),                    // 47: stack.v1.PortMapping
+	(*ConnectionInfo)(nil),                 // 48: stack.v1.ConnectionInfo
+	(*BatchDeleteJob)(nil),                 // 49: stack.v1.BatchDeleteJob
+	(*JobError)(nil),                       // 50: stack.v1.JobError
+	nil,                                    // 51: stack.v1.Stats.NodeDistributionEntry
+	nil,                                    // 52: stack.v1.Stats.NodePortPoolsEntry
+	nil,                                    // 53: stack.v1.Stats.PlacementDistributionEntry
+	nil,                                    // 54: stack.v1.Stats.ClustersEntry
+	nil,                                    // 55: stack.v1.Stats.NodePoolsEntry
+	nil,                                    // 56: stack.v1.NodeDistribution.NodesEntry
+	(*structpb.Struct)(nil),                // 57: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),          // 58: google.protobuf.Timestamp
 }
 var file_stack_v1_stack_proto_depIdxs = []int32{
-	23, // 0: stack.v1.CreateStackRequest.target_ports:type_name -> stack.v1.PortSpec
-	21, // 1: stack.v1.CreateStackResponse.stack:type_name -> stack.v1.Stack
-	21, // 2: stack.v1.GetStackResponse.stack:type_name -> stack.v1.Stack
-	22, // 3: stack.v1.GetStackStatusSummaryResponse.summary:type_name -> stack.v1.StackStatusSummary
-	21, // 4: stack.v1.ListStacksResponse.stacks:type_name -> stack.v1.Stack
-	25, // 5: stack.v1.GetBatchDeleteJobResponse.job:type_name -> stack.v1.BatchDeleteJob
-	20, // 6: stack.v1.GetStatsResponse.stats:type_name -> stack.v1.Stats
-	27, // 7: stack.v1.Stats.node_distribution:type_name -> stack.v1.Stats.NodeDistributionEntry
-	24, // 8: stack.v1.Stack.ports:type_name -> stack.v1.PortMapping
-	0,  // 9: stack.v1.Stack.status:type_name -> stack.v1.Status
-	28, // 10: stack.v1.Stack.ttl_expires_at:type_name -> google.protobuf.Timestamp
-	28, // 11: stack.v1.Stack.created_at:type_name -> google.protobuf.Timestamp
-	28, // 12: stack.v1.Stack.updated_at:type_name -> google.protobuf.Timestamp
-	23, // 13: stack.v1.Stack.target_ports:type_name -> stack.v1.PortSpec
-	0,  // 14: stack.v1.StackStatusSummary.status:type_name -> stack.v1.Status
-	28, // 15: stack.v1.StackStatusSummary.ttl:type_name -> google.protobuf.Timestamp
-	24, // 16: stack.v1.StackStatusSummary.ports:type_name -> stack.v1.PortMapping
-	23, // 17: stack.v1.StackStatusSummary.target_ports:type_name -> stack.v1.PortSpec
-	1,  // 18: stack.v1.BatchDeleteJob.status:type_name -> stack.v1.JobStatus
-	26, // 19: stack.v1.BatchDeleteJob.errors:type_name -> stack.v1.JobError
-	28, // 20: stack.v1.BatchDeleteJob.created_at:type_name -> google.protobuf.Timestamp
-	28, // 21: stack.v1.BatchDeleteJob.updated_at:type_name -> google.protobuf.Timestamp
-	2,  // 22: stack.v1.StackService.Healthz:input_type -> stack.v1.HealthzRequest
-	4,  // 23: stack.v1.StackService.CreateStack:input_type -> stack.v1.CreateStackRequest
-	6,  // 24: stack.v1.StackService.GetStack:input_type -> stack.v1.GetStackRequest
-	8,  // 25: stack.v1.StackService.GetStackStatusSummary:input_type -> stack.v1.GetStackStatusSummaryRequest
-	10, // 26: stack.v1.StackService.DeleteStack:input_type -> stack.v1.DeleteStackRequest
-	12, // 27: stack.v1.StackService.ListStacks:input_type -> stack.v1.ListStacksRequest
-	14, // 28: stack.v1.StackService.CreateBatchDeleteJob:input_type -> stack.v1.CreateBatchDeleteJobRequest
-	16, // 29: stack.v1.StackService.GetBatchDeleteJob:input_type -> stack.v1.GetBatchDeleteJobRequest
-	18, // 30: stack.v1.StackService.GetStats:input_type -> stack.v1.GetStatsRequest
-	3,  // 31: stack.v1.StackService.Healthz:output_type -> stack.v1.HealthzResponse
-	5,  // 32: stack.v1.StackService.CreateStack:output_type -> stack.v1.CreateStackResponse
-	7,  // 33: stack.v1.StackService.GetStack:output_type -> stack.v1.GetStackResponse
-	9,  // 34: stack.v1.StackService.GetStackStatusSummary:output_type -> stack.v1.GetStackStatusSummaryResponse
-	11, // 35: stack.v1.StackService.DeleteStack:output_type -> stack.v1.DeleteStackResponse
-	13, // 36: stack.v1.StackService.ListStacks:output_type -> stack.v1.ListStacksResponse
-	15, // 37: stack.v1.StackService.CreateBatchDeleteJob:output_type -> stack.v1.CreateBatchDeleteJobResponse
-	17, // 38: stack.v1.StackService.GetBatchDeleteJob:output_type -> stack.v1.GetBatchDeleteJobResponse
-	19, // 39: stack.v1.StackService.GetStats:output_type -> stack.v1.GetStatsResponse
-	31, // [31:40] is the sub-list for method output_type
-	22, // [22:31] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	46, // 0: stack.v1.CreateStackRequest.target_ports:type_name -> stack.v1.PortSpec
+	57, // 1: stack.v1.CreateStackRequest.pod_spec_object:type_name -> google.protobuf.Struct
+	44, // 2: stack.v1.CreateStackResponse.stack:type_name -> stack.v1.Stack
+	43, // 3: stack.v1.CreateStackResponse.ticket:type_name -> stack.v1.QueueTicket
+	46, // 4: stack.v1.ValidateStackRequest.target_ports:type_name -> stack.v1.PortSpec
+	57, // 5: stack.v1.ValidateStackRequest.pod_spec_object:type_name -> google.protobuf.Struct
+	9,  // 6: stack.v1.ValidateStackResponse.validation:type_name -> stack.v1.StackValidation
+	46, // 7: stack.v1.StackValidation.target_ports:type_name -> stack.v1.PortSpec
+	10, // 8: stack.v1.StackValidation.changes:type_name -> stack.v1.FieldChange
+	11, // 9: stack.v1.StackValidation.violations:type_name -> stack.v1.Violation
+	44, // 10: stack.v1.GetStackResponse.stack:type_name -> stack.v1.Stack
+	45, // 11: stack.v1.GetStackStatusSummaryResponse.summary:type_name -> stack.v1.StackStatusSummary
+	44, // 12: stack.v1.ListStacksResponse.stacks:type_name -> stack.v1.Stack
+	49, // 13: stack.v1.GetBatchDeleteJobResponse.job:type_name -> stack.v1.BatchDeleteJob
+	26, // 14: stack.v1.GetStatsResponse.stats:type_name -> stack.v1.Stats
+	51, // 15: stack.v1.Stats.node_distribution:type_name -> stack.v1.Stats.NodeDistributionEntry
+	52, // 16: stack.v1.Stats.node_port_pools:type_name -> stack.v1.Stats.NodePortPoolsEntry
+	53, // 17: stack.v1.Stats.placement_distribution:type_name -> stack.v1.Stats.PlacementDistributionEntry
+	54, // 18: stack.v1.Stats.clusters:type_name -> stack.v1.Stats.ClustersEntry
+	55, // 19: stack.v1.Stats.node_pools:type_name -> stack.v1.Stats.NodePoolsEntry
+	56, // 20: stack.v1.NodeDistribution.nodes:type_name -> stack.v1.NodeDistribution.NodesEntry
+	33, // 21: stack.v1.GetPortReconcileReportResponse.report:type_name -> stack.v1.PortReconcileReport
+	58, // 22: stack.v1.PortReconcileReport.checked_at:type_name -> google.protobuf.Timestamp
+	34, // 23: stack.v1.PortReconcileReport.findings:type_name -> stack.v1.PortFinding
+	37, // 24: stack.v1.GetCapacityResponse.report:type_name -> stack.v1.CapacityReport
+	58, // 25: stack.v1.CapacityReport.checked_at:type_name -> google.protobuf.Timestamp
+	38, // 26: stack.v1.CapacityReport.nodes:type_name -> stack.v1.NodeCapacity
+	43, // 27: stack.v1.GetQueueTicketResponse.ticket:type_name -> stack.v1.QueueTicket
+	43, // 28: stack.v1.CancelQueueTicketResponse.ticket:type_name -> stack.v1.QueueTicket
+	2,  // 29: stack.v1.QueueTicket.status:type_name -> stack.v1.QueueTicketStatus
+	58, // 30: stack.v1.QueueTicket.eta:type_name -> google.protobuf.Timestamp
+	58, // 31: stack.v1.QueueTicket.created_at:type_name -> google.protobuf.Timestamp
+	58, // 32: stack.v1.QueueTicket.updated_at:type_name -> google.protobuf.Timestamp
+	47, // 33: stack.v1.Stack.ports:type_name -> stack.v1.PortMapping
+	0,  // 34: stack.v1.Stack.status:type_name -> stack.v1.Status
+	58, // 35: stack.v1.Stack.ttl_expires_at:type_name -> google.protobuf.Timestamp
+	58, // 36: stack.v1.Stack.created_at:type_name -> google.protobuf.Timestamp
+	58, // 37: stack.v1.Stack.updated_at:type_name -> google.protobuf.Timestamp
+	46, // 38: stack.v1.Stack.target_ports:type_name -> stack.v1.PortSpec
+	48, // 39: stack.v1.Stack.connection:type_name -> stack.v1.ConnectionInfo
+	0,  // 40: stack.v1.StackStatusSummary.status:type_name -> stack.v1.Status
+	58, // 41: stack.v1.StackStatusSummary.ttl:type_name -> google.protobuf.Timestamp
+	47, // 42: stack.v1.StackStatusSummary.ports:type_name -> stack.v1.PortMapping
+	46, // 43: stack.v1.StackStatusSummary.target_ports:type_name -> stack.v1.PortSpec
+	48, // 44: stack.v1.StackStatusSummary.connection:type_name -> stack.v1.ConnectionInfo
+	1,  // 45: stack.v1.BatchDeleteJob.status:type_name -> stack.v1.JobStatus
+	50, // 46: stack.v1.BatchDeleteJob.errors:type_name -> stack.v1.JobError
+	58, // 47: stack.v1.BatchDeleteJob.created_at:type_name -> google.protobuf.Timestamp
+	58, // 48: stack.v1.BatchDeleteJob.updated_at:type_name -> google.protobuf.Timestamp
+	30, // 49: stack.v1.Stats.NodePortPoolsEntry.value:type_name -> stack.v1.NodePortPoolUsage
+	29, // 50: stack.v1.Stats.PlacementDistributionEntry.value:type_name -> stack.v1.NodeDistribution
+	27, // 51: stack.v1.Stats.ClustersEntry.value:type_name -> stack.v1.ClusterUsage
+	28, // 52: stack.v1.Stats.NodePoolsEntry.value:type_name -> stack.v1.NodePoolUsage
+	3,  // 53: stack.v1.StackService.Healthz:input_type -> stack.v1.HealthzRequest
+	5,  // 54: stack.v1.StackService.CreateStack:input_type -> stack.v1.CreateStackRequest
+	7,  // 55: stack.v1.StackService.ValidateStack:input_type -> stack.v1.ValidateStackRequest
+	12, // 56: stack.v1.StackService.GetStack:input_type -> stack.v1.GetStackRequest
+	14, // 57: stack.v1.StackService.GetStackStatusSummary:input_type -> stack.v1.GetStackStatusSummaryRequest
+	16, // 58: stack.v1.StackService.DeleteStack:input_type -> stack.v1.DeleteStackRequest
+	18, // 59: stack.v1.StackService.ListStacks:input_type -> stack.v1.ListStacksRequest
+	20, // 60: stack.v1.StackService.CreateBatchDeleteJob:input_type -> stack.v1.CreateBatchDeleteJobRequest
+	22, // 61: stack.v1.StackService.GetBatchDeleteJob:input_type -> stack.v1.GetBatchDeleteJobRequest
+	24, // 62: stack.v1.StackService.GetStats:input_type -> stack.v1.GetStatsRequest
+	31, // 63: stack.v1.StackService.GetPortReconcileReport:input_type -> stack.v1.GetPortReconcileReportRequest
+	35, // 64: stack.v1.StackService.GetCapacity:input_type -> stack.v1.GetCapacityRequest
+	39, // 65: stack.v1.StackService.GetQueueTicket:input_type -> stack.v1.GetQueueTicketRequest
+	41, // 66: stack.v1.StackService.CancelQueueTicket:input_type -> stack.v1.CancelQueueTicketRequest
+	4,  // 67: stack.v1.StackService.Healthz:output_type -> stack.v1.HealthzResponse
+	6,  // 68: stack.v1.StackService.CreateStack:output_type -> stack.v1.CreateStackResponse
+	8,  // 69: stack.v1.StackService.ValidateStack:output_type -> stack.v1.ValidateStackResponse
+	13, // 70: stack.v1.StackService.GetStack:output_type -> stack.v1.GetStackResponse
+	15, // 71: stack.v1.StackService.GetStackStatusSummary:output_type -> stack.v1.GetStackStatusSummaryResponse
+	17, // 72: stack.v1.StackService.DeleteStack:output_type -> stack.v1.DeleteStackResponse
+	19, // 73: stack.v1.StackService.ListStacks:output_type -> stack.v1.ListStacksResponse
+	21, // 74: stack.v1.StackService.CreateBatchDeleteJob:output_type -> stack.v1.CreateBatchDeleteJobResponse
+	23, // 75: stack.v1.StackService.GetBatchDeleteJob:output_type -> stack.v1.GetBatchDeleteJobResponse
+	25, // 76: stack.v1.StackService.GetStats:output_type -> stack.v1.GetStatsResponse
+	32, // 77: stack.v1.StackService.GetPortReconcileReport:output_type -> stack.v1.GetPortReconcileReportResponse
+	36, // 78: stack.v1.StackService.GetCapacity:output_type -> stack.v1.GetCapacityResponse
+	40, // 79: stack.v1.StackService.GetQueueTicket:output_type -> stack.v1.GetQueueTicketResponse
+	42, // 80: stack.v1.StackService.CancelQueueTicket:output_type -> stack.v1.CancelQueueTicketResponse
+	67, // [67:81] is the sub-list for method output_type
+	53, // [53:67] is the sub-list for method input_type
+	53, // [53:53] is the sub-list for extension type_name
+	53, // [53:53] is the sub-list for extension extendee
+	0,  // [0:53] is the sub-list for field type_name
 }
 
 func init() { file_stack_v1_stack_proto_init() }
@@ -1739,15 +4041,17 @@ func file_stack_v1_stack_proto_init() {
 	if File_stack_v1_stack_proto != nil {
 		return
 	}
-	file_stack_v1_stack_proto_msgTypes[19].OneofWrappers = []any{}
-	file_stack_v1_stack_proto_msgTypes[20].OneofWrappers = []any{}
+	file_stack_v1_stack_proto_msgTypes[34].OneofWrappers = []any{}
+	file_stack_v1_stack_proto_msgTypes[35].OneofWrappers = []any{}
+	file_stack_v1_stack_proto_msgTypes[41].OneofWrappers = []any{}
+	file_stack_v1_stack_proto_msgTypes[42].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stack_v1_stack_proto_rawDesc), len(file_stack_v1_stack_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   26,
+			NumEnums:      3,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
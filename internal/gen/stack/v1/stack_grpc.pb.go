@@ -19,15 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	StackService_Healthz_FullMethodName               = "/stack.v1.StackService/Healthz"
-	StackService_CreateStack_FullMethodName           = "/stack.v1.StackService/CreateStack"
-	StackService_GetStack_FullMethodName              = "/stack.v1.StackService/GetStack"
-	StackService_GetStackStatusSummary_FullMethodName = "/stack.v1.StackService/GetStackStatusSummary"
-	StackService_DeleteStack_FullMethodName           = "/stack.v1.StackService/DeleteStack"
-	StackService_ListStacks_FullMethodName            = "/stack.v1.StackService/ListStacks"
-	StackService_CreateBatchDeleteJob_FullMethodName  = "/stack.v1.StackService/CreateBatchDeleteJob"
-	StackService_GetBatchDeleteJob_FullMethodName     = "/stack.v1.StackService/GetBatchDeleteJob"
-	StackService_GetStats_FullMethodName              = "/stack.v1.StackService/GetStats"
+	StackService_Healthz_FullMethodName                = "/stack.v1.StackService/Healthz"
+	StackService_CreateStack_FullMethodName            = "/stack.v1.StackService/CreateStack"
+	StackService_ValidateStack_FullMethodName          = "/stack.v1.StackService/ValidateStack"
+	StackService_GetStack_FullMethodName               = "/stack.v1.StackService/GetStack"
+	StackService_GetStackStatusSummary_FullMethodName  = "/stack.v1.StackService/GetStackStatusSummary"
+	StackService_DeleteStack_FullMethodName            = "/stack.v1.StackService/DeleteStack"
+	StackService_ListStacks_FullMethodName             = "/stack.v1.StackService/ListStacks"
+	StackService_CreateBatchDeleteJob_FullMethodName   = "/stack.v1.StackService/CreateBatchDeleteJob"
+	StackService_GetBatchDeleteJob_FullMethodName      = "/stack.v1.StackService/GetBatchDeleteJob"
+	StackService_GetStats_FullMethodName               = "/stack.v1.StackService/GetStats"
+	StackService_GetPortReconcileReport_FullMethodName = "/stack.v1.StackService/GetPortReconcileReport"
+	StackService_GetCapacity_FullMethodName            = "/stack.v1.StackService/GetCapacity"
+	StackService_GetQueueTicket_FullMethodName         = "/stack.v1.StackService/GetQueueTicket"
+	StackService_CancelQueueTicket_FullMethodName      = "/stack.v1.StackService/CancelQueueTicket"
 )
 
 // StackServiceClient is the client API for StackService service.
@@ -36,6 +41,7 @@ const (
 type StackServiceClient interface {
 	Healthz(ctx context.Context, in *HealthzRequest, opts ...grpc.CallOption) (*HealthzResponse, error)
 	CreateStack(ctx context.Context, in *CreateStackRequest, opts ...grpc.CallOption) (*CreateStackResponse, error)
+	ValidateStack(ctx context.Context, in *ValidateStackRequest, opts ...grpc.CallOption) (*ValidateStackResponse, error)
 	GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error)
 	GetStackStatusSummary(ctx context.Context, in *GetStackStatusSummaryRequest, opts ...grpc.CallOption) (*GetStackStatusSummaryResponse, error)
 	DeleteStack(ctx context.Context, in *DeleteStackRequest, opts ...grpc.CallOption) (*DeleteStackResponse, error)
@@ -43,6 +49,10 @@ type StackServiceClient interface {
 	CreateBatchDeleteJob(ctx context.Context, in *CreateBatchDeleteJobRequest, opts ...grpc.CallOption) (*CreateBatchDeleteJobResponse, error)
 	GetBatchDeleteJob(ctx context.Context, in *GetBatchDeleteJobRequest, opts ...grpc.CallOption) (*GetBatchDeleteJobResponse, error)
 	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	GetPortReconcileReport(ctx context.Context, in *GetPortReconcileReportRequest, opts ...grpc.CallOption) (*GetPortReconcileReportResponse, error)
+	GetCapacity(ctx context.Context, in *GetCapacityRequest, opts ...grpc.CallOption) (*GetCapacityResponse, error)
+	GetQueueTicket(ctx context.Context, in *GetQueueTicketRequest, opts ...grpc.CallOption) (*GetQueueTicketResponse, error)
+	CancelQueueTicket(ctx context.Context, in *CancelQueueTicketRequest, opts ...grpc.CallOption) (*CancelQueueTicketResponse, error)
 }
 
 type stackServiceClient struct {
@@ -73,6 +83,16 @@ func (c *stackServiceClient) CreateStack(ctx context.Context, in *CreateStackReq
 	return out, nil
 }
 
+func (c *stackServiceClient) ValidateStack(ctx context.Context, in *ValidateStackRequest, opts ...grpc.CallOption) (*ValidateStackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateStackResponse)
+	err := c.cc.Invoke(ctx, StackService_ValidateStack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *stackServiceClient) GetStack(ctx context.Context, in *GetStackRequest, opts ...grpc.CallOption) (*GetStackResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStackResponse)
@@ -143,12 +163,53 @@ func (c *stackServiceClient) GetStats(ctx context.Context, in *GetStatsRequest,
 	return out, nil
 }
 
+func (c *stackServiceClient) GetPortReconcileReport(ctx context.Context, in *GetPortReconcileReportRequest, opts ...grpc.CallOption) (*GetPortReconcileReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPortReconcileReportResponse)
+	err := c.cc.Invoke(ctx, StackService_GetPortReconcileReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) GetCapacity(ctx context.Context, in *GetCapacityRequest, opts ...grpc.CallOption) (*GetCapacityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCapacityResponse)
+	err := c.cc.Invoke(ctx, StackService_GetCapacity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) GetQueueTicket(ctx context.Context, in *GetQueueTicketRequest, opts ...grpc.CallOption) (*GetQueueTicketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQueueTicketResponse)
+	err := c.cc.Invoke(ctx, StackService_GetQueueTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) CancelQueueTicket(ctx context.Context, in *CancelQueueTicketRequest, opts ...grpc.CallOption) (*CancelQueueTicketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelQueueTicketResponse)
+	err := c.cc.Invoke(ctx, StackService_CancelQueueTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StackServiceServer is the server API for StackService service.
 // All implementations must embed UnimplementedStackServiceServer
 // for forward compatibility.
 type StackServiceServer interface {
 	Healthz(context.Context, *HealthzRequest) (*HealthzResponse, error)
 	CreateStack(context.Context, *CreateStackRequest) (*CreateStackResponse, error)
+	ValidateStack(context.Context, *ValidateStackRequest) (*ValidateStackResponse, error)
 	GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error)
 	GetStackStatusSummary(context.Context, *GetStackStatusSummaryRequest) (*GetStackStatusSummaryResponse, error)
 	DeleteStack(context.Context, *DeleteStackRequest) (*DeleteStackResponse, error)
@@ -156,6 +217,10 @@ type StackServiceServer interface {
 	CreateBatchDeleteJob(context.Context, *CreateBatchDeleteJobRequest) (*CreateBatchDeleteJobResponse, error)
 	GetBatchDeleteJob(context.Context, *GetBatchDeleteJobRequest) (*GetBatchDeleteJobResponse, error)
 	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	GetPortReconcileReport(context.Context, *GetPortReconcileReportRequest) (*GetPortReconcileReportResponse, error)
+	GetCapacity(context.Context, *GetCapacityRequest) (*GetCapacityResponse, error)
+	GetQueueTicket(context.Context, *GetQueueTicketRequest) (*GetQueueTicketResponse, error)
+	CancelQueueTicket(context.Context, *CancelQueueTicketRequest) (*CancelQueueTicketResponse, error)
 	mustEmbedUnimplementedStackServiceServer()
 }
 
@@ -172,6 +237,9 @@ func (UnimplementedStackServiceServer) Healthz(context.Context, *HealthzRequest)
 func (UnimplementedStackServiceServer) CreateStack(context.Context, *CreateStackRequest) (*CreateStackResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateStack not implemented")
 }
+func (UnimplementedStackServiceServer) ValidateStack(context.Context, *ValidateStackRequest) (*ValidateStackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ValidateStack not implemented")
+}
 func (UnimplementedStackServiceServer) GetStack(context.Context, *GetStackRequest) (*GetStackResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStack not implemented")
 }
@@ -193,6 +261,18 @@ func (UnimplementedStackServiceServer) GetBatchDeleteJob(context.Context, *GetBa
 func (UnimplementedStackServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStats not implemented")
 }
+func (UnimplementedStackServiceServer) GetPortReconcileReport(context.Context, *GetPortReconcileReportRequest) (*GetPortReconcileReportResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPortReconcileReport not implemented")
+}
+func (UnimplementedStackServiceServer) GetCapacity(context.Context, *GetCapacityRequest) (*GetCapacityResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCapacity not implemented")
+}
+func (UnimplementedStackServiceServer) GetQueueTicket(context.Context, *GetQueueTicketRequest) (*GetQueueTicketResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetQueueTicket not implemented")
+}
+func (UnimplementedStackServiceServer) CancelQueueTicket(context.Context, *CancelQueueTicketRequest) (*CancelQueueTicketResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelQueueTicket not implemented")
+}
 func (UnimplementedStackServiceServer) mustEmbedUnimplementedStackServiceServer() {}
 func (UnimplementedStackServiceServer) testEmbeddedByValue()                      {}
 
@@ -250,6 +330,24 @@ func _StackService_CreateStack_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StackService_ValidateStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateStackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).ValidateStack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_ValidateStack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).ValidateStack(ctx, req.(*ValidateStackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StackService_GetStack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStackRequest)
 	if err := dec(in); err != nil {
@@ -376,6 +474,78 @@ func _StackService_GetStats_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StackService_GetPortReconcileReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPortReconcileReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).GetPortReconcileReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_GetPortReconcileReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).GetPortReconcileReport(ctx, req.(*GetPortReconcileReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_GetCapacity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCapacityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).GetCapacity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_GetCapacity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).GetCapacity(ctx, req.(*GetCapacityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_GetQueueTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQueueTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).GetQueueTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_GetQueueTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).GetQueueTicket(ctx, req.(*GetQueueTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_CancelQueueTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelQueueTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).CancelQueueTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_CancelQueueTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).CancelQueueTicket(ctx, req.(*CancelQueueTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StackService_ServiceDesc is the grpc.ServiceDesc for StackService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -391,6 +561,10 @@ var StackService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateStack",
 			Handler:    _StackService_CreateStack_Handler,
 		},
+		{
+			MethodName: "ValidateStack",
+			Handler:    _StackService_ValidateStack_Handler,
+		},
 		{
 			MethodName: "GetStack",
 			Handler:    _StackService_GetStack_Handler,
@@ -419,6 +593,22 @@ var StackService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStats",
 			Handler:    _StackService_GetStats_Handler,
 		},
+		{
+			MethodName: "GetPortReconcileReport",
+			Handler:    _StackService_GetPortReconcileReport_Handler,
+		},
+		{
+			MethodName: "GetCapacity",
+			Handler:    _StackService_GetCapacity_Handler,
+		},
+		{
+			MethodName: "GetQueueTicket",
+			Handler:    _StackService_GetQueueTicket_Handler,
+		},
+		{
+			MethodName: "CancelQueueTicket",
+			Handler:    _StackService_CancelQueueTicket_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "stack/v1/stack.proto",
@@ -2,6 +2,7 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"strings"
@@ -10,13 +11,17 @@ import (
 	stackv1 "smctf/internal/gen/stack/v1"
 	"smctf/internal/stack"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type StackService interface {
 	Create(ctx context.Context, in stack.CreateInput) (stack.Stack, error)
+	CreateOrQueue(ctx context.Context, in stack.CreateInput) (stack.Stack, *stack.QueueTicket, error)
+	ValidateStack(ctx context.Context, in stack.CreateInput) (stack.DryRunResult, error)
 	GetDetails(ctx context.Context, stackID string) (stack.Stack, error)
 	GetStatusSummary(ctx context.Context, stackID string) (stack.StackStatusSummary, error)
 	Delete(ctx context.Context, stackID string) error
@@ -24,6 +29,10 @@ type StackService interface {
 	StartBatchDelete(ctx context.Context, stackIDs []string) (string, error)
 	GetBatchDeleteJob(ctx context.Context, jobID string) (stack.BatchDeleteJob, error)
 	Stats(ctx context.Context) (stack.Stats, error)
+	PortReconcileReport(ctx context.Context) (stack.PortReconcileReport, error)
+	Capacity(ctx context.Context, cpu, memory string) (stack.CapacityReport, error)
+	GetQueueTicket(ctx context.Context, ticketID string) (stack.QueueTicket, error)
+	CancelQueueTicket(ctx context.Context, ticketID string) (stack.QueueTicket, error)
 }
 
 type Server struct {
@@ -45,19 +54,62 @@ func (s *Server) CreateStack(ctx context.Context, req *stackv1.CreateStackReques
 		return nil, status.Error(codes.InvalidArgument, "request is required")
 	}
 
+	spec, err := podSpecInput(req.PodSpec, req.PodSpecObject)
+	if err != nil {
+		return nil, err
+	}
+
 	input := stack.CreateInput{
-		PodSpecYML:  strings.TrimSpace(req.PodSpec),
+		PodSpecYML:  spec,
 		TargetPorts: fromProtoPortSpecs(req.TargetPorts),
+		OwnerID:     req.OwnerId,
+		ChallengeID: req.ChallengeId,
+		PortPool:    req.PortPool,
+		Priority:    req.Priority,
+		Region:      req.Region,
+		NodePool:    req.NodePool,
+		Queue:       req.Queue,
 	}
 
-	st, err := s.service.Create(ctx, input)
+	st, ticket, err := s.service.CreateOrQueue(ctx, input)
 	if err != nil {
 		return nil, s.grpcError(err)
 	}
 
+	if ticket != nil {
+		return &stackv1.CreateStackResponse{Ticket: toProtoQueueTicket(*ticket)}, nil
+	}
+
 	return &stackv1.CreateStackResponse{Stack: toProtoStack(st)}, nil
 }
 
+func (s *Server) ValidateStack(ctx context.Context, req *stackv1.ValidateStackRequest) (*stackv1.ValidateStackResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	spec, err := podSpecInput(req.PodSpec, req.PodSpecObject)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.service.ValidateStack(ctx, stack.CreateInput{
+		PodSpecYML:  spec,
+		TargetPorts: fromProtoPortSpecs(req.TargetPorts),
+		OwnerID:     req.OwnerId,
+		ChallengeID: req.ChallengeId,
+		PortPool:    req.PortPool,
+		Priority:    req.Priority,
+		Region:      req.Region,
+		NodePool:    req.NodePool,
+	})
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	return &stackv1.ValidateStackResponse{Validation: toProtoStackValidation(result)}, nil
+}
+
 func (s *Server) GetStack(ctx context.Context, req *stackv1.GetStackRequest) (*stackv1.GetStackResponse, error) {
 	if req == nil {
 		return nil, status.Error(codes.InvalidArgument, "request is required")
@@ -181,6 +233,83 @@ func (s *Server) GetStats(ctx context.Context, _ *stackv1.GetStatsRequest) (*sta
 	return &stackv1.GetStatsResponse{Stats: toProtoStats(stats)}, nil
 }
 
+func (s *Server) GetPortReconcileReport(ctx context.Context, _ *stackv1.GetPortReconcileReportRequest) (*stackv1.GetPortReconcileReportResponse, error) {
+	report, err := s.service.PortReconcileReport(ctx)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	return &stackv1.GetPortReconcileReportResponse{Report: toProtoPortReconcileReport(report)}, nil
+}
+
+func (s *Server) GetCapacity(ctx context.Context, req *stackv1.GetCapacityRequest) (*stackv1.GetCapacityResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	report, err := s.service.Capacity(ctx, req.Cpu, req.Memory)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	return &stackv1.GetCapacityResponse{Report: toProtoCapacityReport(report)}, nil
+}
+
+func (s *Server) GetQueueTicket(ctx context.Context, req *stackv1.GetQueueTicketRequest) (*stackv1.GetQueueTicketResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	ticketID := strings.TrimSpace(req.GetTicketId())
+	if ticketID == "" {
+		return nil, status.Error(codes.InvalidArgument, "ticket_id is required")
+	}
+
+	ticket, err := s.service.GetQueueTicket(ctx, ticketID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	return &stackv1.GetQueueTicketResponse{Ticket: toProtoQueueTicket(ticket)}, nil
+}
+
+func (s *Server) CancelQueueTicket(ctx context.Context, req *stackv1.CancelQueueTicketRequest) (*stackv1.CancelQueueTicketResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	ticketID := strings.TrimSpace(req.GetTicketId())
+	if ticketID == "" {
+		return nil, status.Error(codes.InvalidArgument, "ticket_id is required")
+	}
+
+	ticket, err := s.service.CancelQueueTicket(ctx, ticketID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	return &stackv1.CancelQueueTicketResponse{Ticket: toProtoQueueTicket(ticket)}, nil
+}
+
+// podSpecInput returns the pod spec of a request, given either as a YAML or JSON string
+// or as an object.
+func podSpecInput(spec string, object *structpb.Struct) (string, error) {
+	if object == nil {
+		return strings.TrimSpace(spec), nil
+	}
+
+	if strings.TrimSpace(spec) != "" {
+		return "", status.Error(codes.InvalidArgument, "set only one of pod_spec and pod_spec_object")
+	}
+
+	raw, err := json.Marshal(object.AsMap())
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, "pod_spec_object is invalid")
+	}
+
+	return string(raw), nil
+}
+
 func (s *Server) grpcError(err error) error {
 	switch {
 	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
@@ -188,9 +317,11 @@ func (s *Server) grpcError(err error) error {
 	case errors.Is(err, stack.ErrNotFound):
 		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, stack.ErrInvalidInput), errors.Is(err, stack.ErrPodSpecInvalid):
-		return status.Error(codes.InvalidArgument, err.Error())
+		return invalidArgument(err)
 	case errors.Is(err, stack.ErrNoAvailableNodePort), errors.Is(err, stack.ErrClusterSaturated):
 		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, stack.ErrTicketClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	default:
 		if s.logger != nil {
 			s.logger.Error("grpc internal error", slog.Any("error", err))
@@ -199,22 +330,52 @@ func (s *Server) grpcError(err error) error {
 	}
 }
 
+// invalidArgument attaches every violation as a google.rpc.BadRequest field violation.
+func invalidArgument(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range stack.Violations(err) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Message,
+			Reason:      v.Code,
+		})
+	}
+
+	detailed, detailErr := st.WithDetails(badRequest)
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
 func toProtoStack(st stack.Stack) *stackv1.Stack {
 	pb := &stackv1.Stack{
-		StackId:              st.StackID,
-		PodId:                st.PodID,
-		Namespace:            st.Namespace,
-		NodeId:               st.NodeID,
-		PodSpec:              st.PodSpecYAML,
-		Ports:                toProtoPortMappings(st.Ports),
-		ServiceName:          st.ServiceName,
-		Status:               toProtoStatus(st.Status),
-		TtlExpiresAt:         tsOrNil(st.TTLExpiresAt),
-		CreatedAt:            tsOrNil(st.CreatedAt),
-		UpdatedAt:            tsOrNil(st.UpdatedAt),
-		RequestedCpuMilli:    st.RequestedMilli,
-		RequestedMemoryBytes: st.RequestedBytes,
-		TargetPorts:          toProtoPortSpecs(st.TargetPorts),
+		StackId:                        st.StackID,
+		PodId:                          st.PodID,
+		Namespace:                      st.Namespace,
+		NodeId:                         st.NodeID,
+		PodSpec:                        st.PodSpecYAML,
+		Ports:                          toProtoPortMappings(st.Ports),
+		ServiceName:                    st.ServiceName,
+		Status:                         toProtoStatus(st.Status),
+		TtlExpiresAt:                   tsOrNil(st.TTLExpiresAt),
+		CreatedAt:                      tsOrNil(st.CreatedAt),
+		UpdatedAt:                      tsOrNil(st.UpdatedAt),
+		RequestedCpuMilli:              st.RequestedMilli,
+		RequestedMemoryBytes:           st.RequestedBytes,
+		RequestedEphemeralStorageBytes: st.RequestedEphemeralBytes,
+		TargetPorts:                    toProtoPortSpecs(st.TargetPorts),
+		Connection:                     toProtoConnections(st.Connection),
+		OwnerId:                        st.OwnerID,
+		ChallengeId:                    st.ChallengeID,
+		PortPool:                       st.PortPool,
+		Placement:                      st.Placement,
+		Priority:                       st.Priority,
+		PreemptedBy:                    st.PreemptedBy,
+		ClusterId:                      st.ClusterID,
+		NodePool:                       st.NodePool,
 	}
 	if st.NodePublicIP != nil {
 		pb.NodePublicIp = st.NodePublicIP
@@ -230,6 +391,7 @@ func toProtoStackStatusSummary(summary stack.StackStatusSummary) *stackv1.StackS
 		Ttl:         tsOrNil(summary.TTL),
 		Ports:       toProtoPortMappings(summary.Ports),
 		TargetPorts: toProtoPortSpecs(summary.TargetPorts),
+		Connection:  toProtoConnections(summary.Connection),
 	}
 	if summary.NodePublicIP != nil {
 		pb.NodePublicIp = summary.NodePublicIP
@@ -244,16 +406,180 @@ func toProtoStats(stats stack.Stats) *stackv1.Stats {
 		nodes[key] = int32(value)
 	}
 
+	pools := make(map[string]*stackv1.NodePortPoolUsage, len(stats.NodePortPools))
+	for name, usage := range stats.NodePortPools {
+		pools[name] = &stackv1.NodePortPoolUsage{
+			Min:      int32(usage.Min),
+			Max:      int32(usage.Max),
+			Used:     int32(usage.Used),
+			Capacity: int32(usage.Capacity),
+		}
+	}
+
+	placements := make(map[string]*stackv1.NodeDistribution, len(stats.PlacementDistribution))
+	for strategy, distribution := range stats.PlacementDistribution {
+		counts := make(map[string]int32, len(distribution))
+		for node, count := range distribution {
+			counts[node] = int32(count)
+		}
+		placements[strategy] = &stackv1.NodeDistribution{Nodes: counts}
+	}
+
+	clusters := make(map[string]*stackv1.ClusterUsage, len(stats.Clusters))
+	for id, usage := range stats.Clusters {
+		clusters[id] = &stackv1.ClusterUsage{
+			Region:              usage.Region,
+			TotalStacks:         int32(usage.TotalStacks),
+			ActiveStacks:        int32(usage.ActiveStacks),
+			ReservedCpuMilli:    usage.ReservedCPUMilli,
+			ReservedMemoryBytes: usage.ReservedMemoryBytes,
+		}
+	}
+
+	nodePools := make(map[string]*stackv1.NodePoolUsage, len(stats.NodePools))
+	for name, usage := range stats.NodePools {
+		nodePools[name] = &stackv1.NodePoolUsage{
+			Nodes:                  int32(usage.Nodes),
+			AllocatableCpuMilli:    usage.AllocatableCPUMilli,
+			AllocatableMemoryBytes: usage.AllocatableMemoryBytes,
+			TotalStacks:            int32(usage.TotalStacks),
+			ActiveStacks:           int32(usage.ActiveStacks),
+			ReservedCpuMilli:       usage.ReservedCPUMilli,
+			ReservedMemoryBytes:    usage.ReservedMemoryBytes,
+		}
+	}
+
 	return &stackv1.Stats{
-		TotalStacks:         int32(stats.TotalStacks),
-		ActiveStacks:        int32(stats.ActiveStacks),
-		NodeDistribution:    nodes,
-		UsedNodePorts:       int32(stats.UsedNodePorts),
-		ReservedCpuMilli:    stats.ReservedCPUMilli,
-		ReservedMemoryBytes: stats.ReservedMemoryBytes,
+		TotalStacks:                   int32(stats.TotalStacks),
+		ActiveStacks:                  int32(stats.ActiveStacks),
+		NodeDistribution:              nodes,
+		UsedNodePorts:                 int32(stats.UsedNodePorts),
+		ReservedCpuMilli:              stats.ReservedCPUMilli,
+		ReservedMemoryBytes:           stats.ReservedMemoryBytes,
+		ReservedEphemeralStorageBytes: stats.ReservedEphemeralBytes,
+		NodePortPools:                 pools,
+		PlacementStrategy:             stats.PlacementStrategy,
+		PlacementDistribution:         placements,
+		Clusters:                      clusters,
+		NodePools:                     nodePools,
+	}
+}
+
+func toProtoPortReconcileReport(report stack.PortReconcileReport) *stackv1.PortReconcileReport {
+	findings := make([]*stackv1.PortFinding, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		findings = append(findings, &stackv1.PortFinding{
+			Kind:        string(finding.Kind),
+			NodePort:    int32(finding.NodePort),
+			StackId:     finding.StackID,
+			ServiceName: finding.ServiceName,
+			Repaired:    finding.Repaired,
+			Error:       finding.Error,
+		})
+	}
+
+	return &stackv1.PortReconcileReport{
+		CheckedAt:    tsOrNil(report.CheckedAt),
+		DryRun:       report.DryRun,
+		Locks:        int32(report.Locks),
+		ServicePorts: int32(report.ServicePorts),
+		StackPorts:   int32(report.StackPorts),
+		Findings:     findings,
+		Repaired:     int32(report.Repaired),
+		Failed:       int32(report.Failed),
 	}
 }
 
+func toProtoStackValidation(result stack.DryRunResult) *stackv1.StackValidation {
+	changes := make([]*stackv1.FieldChange, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		changes = append(changes, &stackv1.FieldChange{
+			Path:   change.Path,
+			Before: change.Before,
+			After:  change.After,
+		})
+	}
+
+	violations := make([]*stackv1.Violation, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		violations = append(violations, &stackv1.Violation{
+			Field:   violation.Field,
+			Message: violation.Message,
+			Code:    violation.Code,
+		})
+	}
+
+	return &stackv1.StackValidation{
+		Valid:                          result.Valid,
+		SanitizedPodSpec:               result.SanitizedYAML,
+		RequestedCpuMilli:              result.RequestedMilli,
+		RequestedMemoryBytes:           result.RequestedBytes,
+		RequestedEphemeralStorageBytes: result.RequestedEphemeralBytes,
+		TargetPorts:                    toProtoPortSpecs(result.TargetPorts),
+		NodePool:                       result.NodePool,
+		Changes:                        changes,
+		Violations:                     violations,
+	}
+}
+
+func toProtoCapacityReport(report stack.CapacityReport) *stackv1.CapacityReport {
+	nodes := make([]*stackv1.NodeCapacity, 0, len(report.Nodes))
+	for _, node := range report.Nodes {
+		nodes = append(nodes, &stackv1.NodeCapacity{
+			NodeId:                 node.NodeID,
+			ClusterId:              node.ClusterID,
+			NodePools:              node.NodePools,
+			PublicIp:               node.PublicIP,
+			Ready:                  node.Ready,
+			Schedulable:            node.Schedulable,
+			AllocatableCpuMilli:    node.AllocatableCPUMilli,
+			AllocatableMemoryBytes: node.AllocatableMemoryBytes,
+			RequestedCpuMilli:      node.RequestedCPUMilli,
+			RequestedMemoryBytes:   node.RequestedMemoryBytes,
+			HeadroomCpuMilli:       node.HeadroomCPUMilli,
+			HeadroomMemoryBytes:    node.HeadroomMemoryBytes,
+			Pods:                   int32(node.Pods),
+			Fits:                   int32PtrOrNil(node.Fits),
+		})
+	}
+
+	return &stackv1.CapacityReport{
+		CheckedAt:              tsOrNil(report.CheckedAt),
+		Nodes:                  nodes,
+		AllocatableCpuMilli:    report.AllocatableCPUMilli,
+		AllocatableMemoryBytes: report.AllocatableMemoryBytes,
+		RequestedCpuMilli:      report.RequestedCPUMilli,
+		RequestedMemoryBytes:   report.RequestedMemoryBytes,
+		HeadroomCpuMilli:       report.HeadroomCPUMilli,
+		HeadroomMemoryBytes:    report.HeadroomMemoryBytes,
+		Fits:                   int32PtrOrNil(report.Fits),
+	}
+}
+
+func toProtoQueueTicket(ticket stack.QueueTicket) *stackv1.QueueTicket {
+	out := &stackv1.QueueTicket{
+		TicketId:    ticket.TicketID,
+		Status:      toProtoQueueTicketStatus(ticket.Status),
+		Position:    int32(ticket.Position),
+		OwnerId:     ticket.OwnerID,
+		ChallengeId: ticket.ChallengeID,
+		PortPool:    ticket.PortPool,
+		Priority:    ticket.Priority,
+		Region:      ticket.Region,
+		NodePool:    ticket.NodePool,
+		StackId:     ticket.StackID,
+		Error:       ticket.Error,
+		CreatedAt:   tsOrNil(ticket.CreatedAt),
+		UpdatedAt:   tsOrNil(ticket.UpdatedAt),
+	}
+
+	if ticket.ETA != nil {
+		out.Eta = tsOrNil(*ticket.ETA)
+	}
+
+	return out
+}
+
 func toProtoBatchDeleteJob(job stack.BatchDeleteJob) *stackv1.BatchDeleteJob {
 	errorsOut := make([]*stackv1.JobError, 0, len(job.Errors))
 	for _, errItem := range job.Errors {
@@ -282,6 +608,10 @@ func toProtoPortSpecs(specs []stack.PortSpec) []*stackv1.PortSpec {
 		out = append(out, &stackv1.PortSpec{
 			ContainerPort: int32(spec.ContainerPort),
 			Protocol:      spec.Protocol,
+			Scheme:        spec.Scheme,
+			Name:          spec.Name,
+			NodePort:      int32(spec.NodePort),
+			Sticky:        spec.Sticky,
 		})
 	}
 
@@ -297,6 +627,10 @@ func fromProtoPortSpecs(specs []*stackv1.PortSpec) []stack.PortSpec {
 		out = append(out, stack.PortSpec{
 			ContainerPort: int(spec.ContainerPort),
 			Protocol:      spec.Protocol,
+			Scheme:        spec.Scheme,
+			Name:          spec.Name,
+			NodePort:      int(spec.NodePort),
+			Sticky:        spec.Sticky,
 		})
 	}
 
@@ -310,6 +644,25 @@ func toProtoPortMappings(mappings []stack.PortMapping) []*stackv1.PortMapping {
 			ContainerPort: int32(mapping.ContainerPort),
 			Protocol:      mapping.Protocol,
 			NodePort:      int32(mapping.NodePort),
+			Scheme:        mapping.Scheme,
+			Name:          mapping.Name,
+		})
+	}
+
+	return out
+}
+
+func toProtoConnections(conns []stack.Connection) []*stackv1.ConnectionInfo {
+	out := make([]*stackv1.ConnectionInfo, 0, len(conns))
+	for _, conn := range conns {
+		out = append(out, &stackv1.ConnectionInfo{
+			Name:          conn.Name,
+			ContainerPort: int32(conn.ContainerPort),
+			Protocol:      conn.Protocol,
+			NodePort:      int32(conn.NodePort),
+			Scheme:        conn.Scheme,
+			Host:          conn.Host,
+			Rendered:      conn.Rendered,
 		})
 	}
 
@@ -328,6 +681,8 @@ func toProtoStatus(statusVal stack.Status) stackv1.Status {
 		return stackv1.Status_STATUS_FAILED
 	case stack.StatusNodeDeleted:
 		return stackv1.Status_STATUS_NODE_DELETED
+	case stack.StatusPreempted:
+		return stackv1.Status_STATUS_PREEMPTED
 	default:
 		return stackv1.Status_STATUS_UNSPECIFIED
 	}
@@ -348,6 +703,25 @@ func toProtoJobStatus(statusVal stack.JobStatus) stackv1.JobStatus {
 	}
 }
 
+func toProtoQueueTicketStatus(statusVal stack.TicketStatus) stackv1.QueueTicketStatus {
+	switch statusVal {
+	case stack.TicketStatusQueued:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_QUEUED
+	case stack.TicketStatusProvisioning:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_PROVISIONING
+	case stack.TicketStatusProvisioned:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_PROVISIONED
+	case stack.TicketStatusFailed:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_FAILED
+	case stack.TicketStatusCancelled:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_CANCELLED
+	case stack.TicketStatusExpired:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_EXPIRED
+	default:
+		return stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_UNSPECIFIED
+	}
+}
+
 func tsOrNil(t time.Time) *timestamppb.Timestamp {
 	if t.IsZero() {
 		return nil
@@ -355,3 +729,12 @@ func tsOrNil(t time.Time) *timestamppb.Timestamp {
 
 	return timestamppb.New(t)
 }
+
+func int32PtrOrNil(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+
+	out := int32(*v)
+	return &out
+}
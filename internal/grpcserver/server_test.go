@@ -2,6 +2,7 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/json"
 	"net"
 	"testing"
 	"time"
@@ -10,12 +11,15 @@ import (
 	stackv1 "smctf/internal/gen/stack/v1"
 	"smctf/internal/stack"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const bufSize = 1024 * 1024
@@ -49,6 +53,8 @@ func dialTestServer(t *testing.T, svc StackService, apiKey config.APIKeyConfig)
 
 type stubStackService struct {
 	createFn            func(context.Context, stack.CreateInput) (stack.Stack, error)
+	createOrQueueFn     func(context.Context, stack.CreateInput) (stack.Stack, *stack.QueueTicket, error)
+	validateStackFn     func(context.Context, stack.CreateInput) (stack.DryRunResult, error)
 	getDetailsFn        func(context.Context, string) (stack.Stack, error)
 	getStatusSummaryFn  func(context.Context, string) (stack.StackStatusSummary, error)
 	deleteFn            func(context.Context, string) error
@@ -56,6 +62,10 @@ type stubStackService struct {
 	startBatchDeleteFn  func(context.Context, []string) (string, error)
 	getBatchDeleteJobFn func(context.Context, string) (stack.BatchDeleteJob, error)
 	statsFn             func(context.Context) (stack.Stats, error)
+	portReconcileFn     func(context.Context) (stack.PortReconcileReport, error)
+	capacityFn          func(context.Context, string, string) (stack.CapacityReport, error)
+	getQueueTicketFn    func(context.Context, string) (stack.QueueTicket, error)
+	cancelQueueTicketFn func(context.Context, string) (stack.QueueTicket, error)
 }
 
 func (s stubStackService) Create(ctx context.Context, in stack.CreateInput) (stack.Stack, error) {
@@ -66,6 +76,23 @@ func (s stubStackService) Create(ctx context.Context, in stack.CreateInput) (sta
 	return stack.Stack{}, nil
 }
 
+func (s stubStackService) CreateOrQueue(ctx context.Context, in stack.CreateInput) (stack.Stack, *stack.QueueTicket, error) {
+	if s.createOrQueueFn != nil {
+		return s.createOrQueueFn(ctx, in)
+	}
+
+	st, err := s.Create(ctx, in)
+	return st, nil, err
+}
+
+func (s stubStackService) ValidateStack(ctx context.Context, in stack.CreateInput) (stack.DryRunResult, error) {
+	if s.validateStackFn != nil {
+		return s.validateStackFn(ctx, in)
+	}
+
+	return stack.DryRunResult{}, nil
+}
+
 func (s stubStackService) GetDetails(ctx context.Context, stackID string) (stack.Stack, error) {
 	if s.getDetailsFn != nil {
 		return s.getDetailsFn(ctx, stackID)
@@ -122,6 +149,38 @@ func (s stubStackService) Stats(ctx context.Context) (stack.Stats, error) {
 	return stack.Stats{}, nil
 }
 
+func (s stubStackService) PortReconcileReport(ctx context.Context) (stack.PortReconcileReport, error) {
+	if s.portReconcileFn != nil {
+		return s.portReconcileFn(ctx)
+	}
+
+	return stack.PortReconcileReport{}, nil
+}
+
+func (s stubStackService) Capacity(ctx context.Context, cpu, memory string) (stack.CapacityReport, error) {
+	if s.capacityFn != nil {
+		return s.capacityFn(ctx, cpu, memory)
+	}
+
+	return stack.CapacityReport{}, nil
+}
+
+func (s stubStackService) GetQueueTicket(ctx context.Context, ticketID string) (stack.QueueTicket, error) {
+	if s.getQueueTicketFn != nil {
+		return s.getQueueTicketFn(ctx, ticketID)
+	}
+
+	return stack.QueueTicket{}, nil
+}
+
+func (s stubStackService) CancelQueueTicket(ctx context.Context, ticketID string) (stack.QueueTicket, error) {
+	if s.cancelQueueTicketFn != nil {
+		return s.cancelQueueTicketFn(ctx, ticketID)
+	}
+
+	return stack.QueueTicket{}, nil
+}
+
 func TestHealthz(t *testing.T) {
 	conn, cleanup := dialTestServer(t, stubStackService{}, config.APIKeyConfig{Enabled: false})
 	defer cleanup()
@@ -173,6 +232,16 @@ func TestGetStats(t *testing.T) {
 				UsedNodePorts:       3,
 				ReservedCPUMilli:    500,
 				ReservedMemoryBytes: 256,
+				PlacementStrategy:   "spread",
+				PlacementDistribution: map[string]map[string]int{
+					"spread": {"node-a": 1, "node-b": 1},
+				},
+				Clusters: map[string]stack.ClusterUsage{
+					"eks-a": {Region: "ap-northeast-2", TotalStacks: 3, ActiveStacks: 2, ReservedCPUMilli: 500},
+				},
+				NodePools: map[string]stack.NodePoolUsage{
+					"arm64": {Nodes: 2, AllocatableCPUMilli: 8000, ActiveStacks: 1},
+				},
 			}, nil
 		},
 	}
@@ -192,6 +261,99 @@ func TestGetStats(t *testing.T) {
 	if resp.GetStats().GetTotalStacks() != 3 || resp.GetStats().GetActiveStacks() != 2 {
 		t.Fatalf("unexpected stats response: %+v", resp.GetStats())
 	}
+
+	if spread := resp.GetStats().GetPlacementDistribution()["spread"]; spread.GetNodes()["node-b"] != 1 || resp.GetStats().GetPlacementStrategy() != "spread" {
+		t.Fatalf("unexpected placement stats: %+v", resp.GetStats())
+	}
+
+	if cluster := resp.GetStats().GetClusters()["eks-a"]; cluster.GetRegion() != "ap-northeast-2" || cluster.GetActiveStacks() != 2 {
+		t.Fatalf("unexpected cluster stats: %+v", resp.GetStats().GetClusters())
+	}
+
+	if pool := resp.GetStats().GetNodePools()["arm64"]; pool.GetNodes() != 2 || pool.GetAllocatableCpuMilli() != 8000 || pool.GetActiveStacks() != 1 {
+		t.Fatalf("unexpected node pool stats: %+v", resp.GetStats().GetNodePools())
+	}
+}
+
+func TestGetPortReconcileReport(t *testing.T) {
+	service := stubStackService{
+		portReconcileFn: func(context.Context) (stack.PortReconcileReport, error) {
+			return stack.PortReconcileReport{
+				CheckedAt: time.Now().UTC(),
+				Locks:     2,
+				Findings: []stack.PortFinding{
+					{Kind: stack.PortFindingDanglingLock, NodePort: 31001, StackID: "stack-gone", Repaired: true},
+				},
+				Repaired: 1,
+			}, nil
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := client.GetPortReconcileReport(ctx, &stackv1.GetPortReconcileReportRequest{})
+	if err != nil {
+		t.Fatalf("get port reconcile report: %v", err)
+	}
+
+	report := resp.GetReport()
+	if report.GetLocks() != 2 || report.GetRepaired() != 1 || len(report.GetFindings()) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if finding := report.GetFindings()[0]; finding.GetKind() != "dangling_lock" || finding.GetNodePort() != 31001 {
+		t.Fatalf("unexpected finding: %+v", finding)
+	}
+}
+
+func TestGetCapacity(t *testing.T) {
+	fits := 3
+	service := stubStackService{
+		capacityFn: func(_ context.Context, cpu, memory string) (stack.CapacityReport, error) {
+			if cpu != "500m" || memory != "512Mi" {
+				return stack.CapacityReport{}, stack.ErrInvalidInput
+			}
+
+			return stack.CapacityReport{
+				CheckedAt: time.Now().UTC(),
+				Nodes: []stack.NodeCapacity{
+					{NodeID: "node-a", Ready: true, Schedulable: true, AllocatableCPUMilli: 2000, RequestedCPUMilli: 500, Pods: 1, Fits: &fits},
+				},
+				HeadroomCPUMilli: 1500,
+				Fits:             &fits,
+			}, nil
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := client.GetCapacity(ctx, &stackv1.GetCapacityRequest{Cpu: "500m", Memory: "512Mi"})
+	if err != nil {
+		t.Fatalf("get capacity: %v", err)
+	}
+
+	report := resp.GetReport()
+	if report.GetHeadroomCpuMilli() != 1500 || report.GetFits() != 3 || len(report.GetNodes()) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if node := report.GetNodes()[0]; node.GetNodeId() != "node-a" || node.GetPods() != 1 || !node.GetReady() || node.PublicIp != nil {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+
+	if _, err := client.GetCapacity(ctx, &stackv1.GetCapacityRequest{Cpu: "1"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
 }
 
 func TestCreateStackErrorMapping(t *testing.T) {
@@ -213,6 +375,165 @@ func TestCreateStackErrorMapping(t *testing.T) {
 	assertCode(t, err, codes.InvalidArgument)
 }
 
+func TestCreateStackFieldViolations(t *testing.T) {
+	service := stubStackService{
+		createFn: func(context.Context, stack.CreateInput) (stack.Stack, error) {
+			return stack.Stack{}, stack.ValidationErrors{
+				{Field: "spec.hostNetwork", Code: stack.CodeForbidden, Reason: "hostNetwork is forbidden in input"},
+				{Field: "spec.containers[1].ports[0].hostPort", Code: stack.CodeForbidden, Reason: "hostPort is forbidden"},
+			}
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	_, err := client.CreateStack(context.Background(), &stackv1.CreateStackRequest{PodSpec: "pod"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	st, _ := status.FromError(err)
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = br
+		}
+	}
+
+	if badRequest == nil || len(badRequest.FieldViolations) != 2 {
+		t.Fatalf("expected two field violations, got %v", st.Details())
+	}
+
+	violation := badRequest.FieldViolations[1]
+	if violation.Field != "spec.containers[1].ports[0].hostPort" || violation.Reason != stack.CodeForbidden || violation.Description != "hostPort is forbidden" {
+		t.Fatalf("unexpected field violation: %+v", violation)
+	}
+}
+
+func TestCreateStackPodSpecObject(t *testing.T) {
+	var got string
+	service := stubStackService{
+		createOrQueueFn: func(_ context.Context, in stack.CreateInput) (stack.Stack, *stack.QueueTicket, error) {
+			got = in.PodSpecYML
+			return stack.Stack{StackID: "stack-1"}, nil, nil
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	object, err := structpb.NewStruct(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"spec": map[string]any{
+			"containers": []any{map[string]any{"name": "app", "ports": []any{map[string]any{"containerPort": 8080}}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("struct: %v", err)
+	}
+
+	client := stackv1.NewStackServiceClient(conn)
+	if _, err := client.CreateStack(context.Background(), &stackv1.CreateStackRequest{PodSpecObject: object}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var pod corev1.Pod
+	if err := json.Unmarshal([]byte(got), &pod); err != nil || pod.Kind != "Pod" || pod.Spec.Containers[0].Ports[0].ContainerPort != 8080 {
+		t.Fatalf("expected pod spec as JSON, got %s (%v)", got, err)
+	}
+
+	_, err = client.CreateStack(context.Background(), &stackv1.CreateStackRequest{PodSpec: "pod", PodSpecObject: object})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestCreateStackQueued(t *testing.T) {
+	service := stubStackService{
+		createOrQueueFn: func(_ context.Context, in stack.CreateInput) (stack.Stack, *stack.QueueTicket, error) {
+			if !in.Queue {
+				t.Fatalf("expected queue flag to be passed through")
+			}
+
+			return stack.Stack{}, &stack.QueueTicket{TicketID: "ticket-1", Status: stack.TicketStatusQueued, Position: 3}, nil
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	resp, err := client.CreateStack(context.Background(), &stackv1.CreateStackRequest{PodSpec: "pod", Queue: true})
+	if err != nil {
+		t.Fatalf("create stack: %v", err)
+	}
+
+	if resp.GetStack() != nil {
+		t.Fatalf("expected no stack for a queued request, got %+v", resp.GetStack())
+	}
+
+	ticket := resp.GetTicket()
+	if ticket.GetTicketId() != "ticket-1" || ticket.GetPosition() != 3 || ticket.GetStatus() != stackv1.QueueTicketStatus_QUEUE_TICKET_STATUS_QUEUED {
+		t.Fatalf("unexpected ticket: %+v", ticket)
+	}
+}
+
+func TestValidateStack(t *testing.T) {
+	service := stubStackService{
+		validateStackFn: func(_ context.Context, in stack.CreateInput) (stack.DryRunResult, error) {
+			if in.PodSpecYML != "pod" || in.NodePool != "gvisor" {
+				t.Fatalf("unexpected input: %+v", in)
+			}
+
+			return stack.DryRunResult{
+				Valid:          true,
+				SanitizedYAML:  "sanitized",
+				RequestedMilli: 100,
+				RequestedBytes: 64 << 20,
+				TargetPorts:    []stack.PortSpec{{ContainerPort: 80, Protocol: "TCP"}},
+				NodePool:       "gvisor",
+				Changes:        []stack.FieldChange{{Path: "spec.restartPolicy", After: `"Never"`}},
+			}, nil
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	resp, err := client.ValidateStack(context.Background(), &stackv1.ValidateStackRequest{PodSpec: " pod ", NodePool: "gvisor"})
+	if err != nil {
+		t.Fatalf("validate stack: %v", err)
+	}
+
+	validation := resp.GetValidation()
+	if !validation.GetValid() || validation.GetSanitizedPodSpec() != "sanitized" || validation.GetRequestedCpuMilli() != 100 || len(validation.GetTargetPorts()) != 1 {
+		t.Fatalf("unexpected validation: %+v", validation)
+	}
+
+	if changes := validation.GetChanges(); len(changes) != 1 || changes[0].GetPath() != "spec.restartPolicy" || changes[0].GetAfter() != `"Never"` {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestCancelQueueTicketClosed(t *testing.T) {
+	service := stubStackService{
+		cancelQueueTicketFn: func(context.Context, string) (stack.QueueTicket, error) {
+			return stack.QueueTicket{}, stack.ErrTicketClosed
+		},
+	}
+
+	conn, cleanup := dialTestServer(t, service, config.APIKeyConfig{Enabled: false})
+	defer cleanup()
+
+	client := stackv1.NewStackServiceClient(conn)
+	_, err := client.CancelQueueTicket(context.Background(), &stackv1.CancelQueueTicketRequest{TicketId: "ticket-1"})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	assertCode(t, err, codes.FailedPrecondition)
+}
+
 func TestGetStackValidation(t *testing.T) {
 	conn, cleanup := dialTestServer(t, stubStackService{}, config.APIKeyConfig{Enabled: false})
 	defer cleanup()
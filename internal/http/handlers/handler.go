@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"smctf/internal/stack"
@@ -20,21 +23,93 @@ func New(svc *stack.Service) *Handler {
 }
 
 type createStackRequest struct {
-	PodSpec    string           `json:"pod_spec"`
-	TargetPort []stack.PortSpec `json:"target_port"`
+	PodSpec     podSpec          `json:"pod_spec"`
+	TargetPort  []stack.PortSpec `json:"target_port"`
+	OwnerID     string           `json:"owner_id"`
+	ChallengeID string           `json:"challenge_id"`
+	PortPool    string           `json:"port_pool"`
+	Priority    string           `json:"priority"`
+	Region      string           `json:"region"`
+	NodePool    string           `json:"node_pool"`
+	Queue       bool             `json:"queue"`
 }
 
-func (h *Handler) CreateStack(c *gin.Context) {
+// podSpec is a pod spec given as a YAML or JSON string, or as a JSON object.
+type podSpec string
+
+func (p *podSpec) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		*p = ""
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		*p = podSpec(trimmed)
+	default:
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return fmt.Errorf("pod_spec must be a string or an object: %w", err)
+		}
+		*p = podSpec(s)
+	}
+
+	return nil
+}
+
+// bindCreateStackRequest reads a JSON body, or a YAML manifest sent as application/yaml
+// with the other fields as query parameters.
+func bindCreateStackRequest(c *gin.Context) (createStackRequest, error) {
 	var req createStackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if !isYAMLContentType(c.ContentType()) {
+		err := c.ShouldBindJSON(&req)
+		return req, err
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return req, err
+	}
+
+	req.PodSpec = podSpec(body)
+	req.OwnerID = c.Query("owner_id")
+	req.ChallengeID = c.Query("challenge_id")
+	req.PortPool = c.Query("port_pool")
+	req.Priority = c.Query("priority")
+	req.Region = c.Query("region")
+	req.NodePool = c.Query("node_pool")
+	if raw := c.Query("queue"); raw != "" {
+		req.Queue, err = strconv.ParseBool(raw)
+	}
+
+	return req, err
+}
+
+func isYAMLContentType(contentType string) bool {
+	switch contentType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Handler) CreateStack(c *gin.Context) {
+	req, err := bindCreateStackRequest(c)
+	if err != nil {
 		_ = c.Error(fmt.Errorf("bind create stack request: %w", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json body"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
 
-	st, err := h.svc.Create(c.Request.Context(), stack.CreateInput{
-		PodSpecYML:  req.PodSpec,
+	st, ticket, err := h.svc.CreateOrQueue(c.Request.Context(), stack.CreateInput{
+		PodSpecYML:  string(req.PodSpec),
 		TargetPorts: req.TargetPort,
+		OwnerID:     req.OwnerID,
+		ChallengeID: req.ChallengeID,
+		PortPool:    req.PortPool,
+		Priority:    req.Priority,
+		Region:      req.Region,
+		NodePool:    req.NodePool,
+		Queue:       req.Queue,
 	})
 
 	if err != nil {
@@ -42,9 +117,40 @@ func (h *Handler) CreateStack(c *gin.Context) {
 		return
 	}
 
+	if ticket != nil {
+		c.JSON(http.StatusAccepted, ticket)
+		return
+	}
+
 	c.JSON(http.StatusCreated, st)
 }
 
+func (h *Handler) ValidateStack(c *gin.Context) {
+	req, err := bindCreateStackRequest(c)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("bind validate stack request: %w", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	result, err := h.svc.ValidateStack(c.Request.Context(), stack.CreateInput{
+		PodSpecYML:  string(req.PodSpec),
+		TargetPorts: req.TargetPort,
+		OwnerID:     req.OwnerID,
+		ChallengeID: req.ChallengeID,
+		PortPool:    req.PortPool,
+		Priority:    req.Priority,
+		Region:      req.Region,
+		NodePool:    req.NodePool,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *Handler) GetStack(c *gin.Context) {
 	stackID := c.Param("stack_id")
 	st, err := h.svc.GetDetails(c.Request.Context(), stackID)
@@ -146,6 +252,46 @@ func (h *Handler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *Handler) GetPortReconcileReport(c *gin.Context) {
+	report, err := h.svc.PortReconcileReport(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) GetCapacity(c *gin.Context) {
+	report, err := h.svc.Capacity(c.Request.Context(), c.Query("cpu"), c.Query("memory"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) GetQueueTicket(c *gin.Context) {
+	ticketID := c.Param("ticket_id")
+	ticket, err := h.svc.GetQueueTicket(c.Request.Context(), ticketID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (h *Handler) CancelQueueTicket(c *gin.Context) {
+	ticketID := c.Param("ticket_id")
+	ticket, err := h.svc.CancelQueueTicket(c.Request.Context(), ticketID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
 func (h *Handler) writeError(c *gin.Context, err error) {
 	_ = c.Error(err)
 
@@ -153,9 +299,17 @@ func (h *Handler) writeError(c *gin.Context, err error) {
 	case errors.Is(err, stack.ErrNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	case errors.Is(err, stack.ErrInvalidInput), errors.Is(err, stack.ErrPodSpecInvalid):
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		body := gin.H{"error": err.Error(), "details": stack.Violations(err)}
+		var fieldErr *stack.FieldError
+		if errors.As(err, &fieldErr) {
+			body["field"] = fieldErr.Field
+		}
+
+		c.JSON(http.StatusBadRequest, body)
 	case errors.Is(err, stack.ErrNoAvailableNodePort), errors.Is(err, stack.ErrClusterSaturated):
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, stack.ErrTicketClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
@@ -49,6 +49,7 @@ func NewRouter(cfg config.Config, logger *logging.Logger, service *stack.Service
 	})
 
 	api.POST("/stacks", h.CreateStack)
+	api.POST("/stacks/validate", h.ValidateStack)
 	api.GET("/stacks", h.ListStacks)
 	api.GET("/stacks/:stack_id", h.GetStack)
 	api.GET("/stacks/:stack_id/status", h.GetStackStatusSummary)
@@ -56,6 +57,10 @@ func NewRouter(cfg config.Config, logger *logging.Logger, service *stack.Service
 	api.POST("/stacks/batch-delete", h.CreateBatchDeleteJob)
 	api.GET("/stacks/batch-delete/:job_id", h.GetBatchDeleteJob)
 	api.GET("/stats", h.GetStats)
+	api.GET("/ports/reconcile", h.GetPortReconcileReport)
+	api.GET("/capacity", h.GetCapacity)
+	api.GET("/queue/:ticket_id", h.GetQueueTicket)
+	api.DELETE("/queue/:ticket_id", h.CancelQueueTicket)
 
 	attachFrontendRoutes(r)
 
@@ -0,0 +1,207 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"smctf/internal/config"
+)
+
+// capacityCache holds the quota or node budget of each cluster, by cluster id, and the
+// stacks that hold capacity, so routing, admission and preemption do not scan the
+// repository on every create.
+type capacityCache struct {
+	mu      sync.Mutex
+	budgets map[string]cachedBudget
+
+	stacks          []Stack
+	stacksExpiresAt time.Time
+}
+
+type cachedBudget struct {
+	budget    CapacityBudget
+	byPool    map[string]CapacityBudget
+	expiresAt time.Time
+}
+
+// admit rejects a create up front when the requested resources do not fit the remaining
+// capacity budget of the cluster it was routed to, or of its node pool there, before
+// ports are reserved or anything is sent to the API server. The check is best effort: if
+// the budget or usage cannot be read the create is let through and Kubernetes remains the
+// final authority.
+func (s *Service) admit(ctx context.Context, cluster Cluster, nodePool string, cpuMilli, memBytes int64) error {
+	budget, limited, err := s.capacityBudget(ctx, cluster, nodePool)
+	if err != nil {
+		slog.Warn("capacity admission skipped", slog.String("capacity_source", s.cfg.CapacitySource), slog.Any("error", err))
+		return nil
+	}
+
+	if !limited {
+		return nil
+	}
+
+	used, err := s.reservedCapacity(ctx, cluster, nodePool)
+	if err != nil {
+		slog.Warn("capacity admission skipped", slog.String("capacity_source", s.cfg.CapacitySource), slog.Any("error", err))
+		return nil
+	}
+
+	cpuFull := budget.CPUMilli > 0 && used.CPUMilli+cpuMilli > budget.CPUMilli
+	memFull := budget.MemoryBytes > 0 && used.MemoryBytes+memBytes > budget.MemoryBytes
+	if !cpuFull && !memFull {
+		return nil
+	}
+
+	scope := s.cfg.CapacitySource + " capacity"
+	if pool, scoped := s.admissionPool(nodePool); scoped {
+		scope = fmt.Sprintf("%s of node pool %q", scope, pool)
+	}
+
+	return fmt.Errorf("%w: requested %dm cpu / %d bytes memory exceeds %s (used %dm / %d bytes of %dm / %d bytes)",
+		ErrClusterSaturated, cpuMilli, memBytes, scope,
+		used.CPUMilli, used.MemoryBytes, budget.CPUMilli, budget.MemoryBytes)
+}
+
+// capacityBudget returns the budget of a cluster, or of a node pool in it when
+// admission is scoped to pools. STACK_CAPACITY_CPU and STACK_CAPACITY_MEMORY apply to
+// each cluster; quota and node budgets are read from the cluster and cached for
+// STACK_CAPACITY_CACHE_TTL to keep admission off the API server's hot path.
+func (s *Service) capacityBudget(ctx context.Context, cluster Cluster, nodePool string) (CapacityBudget, bool, error) {
+	switch s.cfg.CapacitySource {
+	case config.CapacitySourceStatic:
+		return CapacityBudget{CPUMilli: s.cfg.CapacityCPUMilli, MemoryBytes: s.cfg.CapacityMemoryBytes}, true, nil
+	case config.CapacitySourceQuota, config.CapacitySourceNodes:
+	default:
+		return CapacityBudget{}, false, nil
+	}
+
+	now := s.now()
+	s.capacity.mu.Lock()
+	defer s.capacity.mu.Unlock()
+
+	cached, ok := s.capacity.budgets[cluster.ID]
+	if !ok || !now.Before(cached.expiresAt) {
+		var err error
+		if s.cfg.CapacitySource == config.CapacitySourceQuota {
+			cached.budget, err = cluster.Client.QuotaCapacity(ctx, s.cfg.Namespace)
+		} else {
+			cached.budget, cached.byPool, err = cluster.Client.AllocatableCapacity(ctx)
+		}
+
+		if err != nil {
+			return CapacityBudget{}, false, err
+		}
+
+		if s.capacity.budgets == nil {
+			s.capacity.budgets = make(map[string]cachedBudget)
+		}
+		cached.expiresAt = now.Add(s.cfg.CapacityCacheTTL)
+		s.capacity.budgets[cluster.ID] = cached
+	}
+
+	if pool, scoped := s.admissionPool(nodePool); scoped {
+		return cached.byPool[pool], true, nil
+	}
+
+	return cached.budget, true, nil
+}
+
+// admissionPool returns the node pool admission is scoped to. With STACK_NODE_POOLS and
+// the nodes source every pool has its own budget and usage; otherwise the whole cluster
+// is one budget.
+func (s *Service) admissionPool(nodePool string) (string, bool) {
+	if s.cfg.CapacitySource != config.CapacitySourceNodes || len(s.cfg.NodePools) == 0 {
+		return "", false
+	}
+
+	return nodePool, true
+}
+
+// inAdmissionScope reports whether a stack counts against the budget of a create routed
+// to cluster and nodePool.
+func (s *Service) inAdmissionScope(st Stack, cluster Cluster, nodePool string) bool {
+	if s.clusterID(st.ClusterID) != cluster.ID {
+		return false
+	}
+
+	pool, scoped := s.admissionPool(nodePool)
+	return !scoped || st.NodePool == pool
+}
+
+// reservedCapacity sums the resources of the stacks in a create's admission scope whose
+// pods still hold them.
+func (s *Service) reservedCapacity(ctx context.Context, cluster Cluster, nodePool string) (CapacityBudget, error) {
+	items, err := s.activeStacks(ctx)
+	if err != nil {
+		return CapacityBudget{}, err
+	}
+
+	var used CapacityBudget
+	for _, st := range items {
+		if !s.inAdmissionScope(st, cluster, nodePool) {
+			continue
+		}
+
+		used.CPUMilli += st.RequestedMilli
+		used.MemoryBytes += st.RequestedBytes
+	}
+
+	return used, nil
+}
+
+// activeStacks returns the stacks whose pods still hold resources. The repository is
+// scanned at most once per STACK_CAPACITY_CACHE_TTL; in between, stacks this replica
+// creates or deletes are added to or dropped from the cached list.
+func (s *Service) activeStacks(ctx context.Context) ([]Stack, error) {
+	now := s.now()
+	s.capacity.mu.Lock()
+	if now.Before(s.capacity.stacksExpiresAt) {
+		out := slices.Clone(s.capacity.stacks)
+		s.capacity.mu.Unlock()
+		return out, nil
+	}
+	s.capacity.mu.Unlock()
+
+	items, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := slices.DeleteFunc(items, func(st Stack) bool { return !holdsCapacity(st) })
+
+	s.capacity.mu.Lock()
+	defer s.capacity.mu.Unlock()
+
+	s.capacity.stacks = slices.Clone(active)
+	s.capacity.stacksExpiresAt = now.Add(s.cfg.CapacityCacheTTL)
+
+	return active, nil
+}
+
+func (s *Service) noteActiveStack(st Stack) {
+	s.capacity.mu.Lock()
+	defer s.capacity.mu.Unlock()
+
+	s.capacity.stacks = append(s.capacity.stacks, st)
+}
+
+func (s *Service) forgetActiveStack(stackID string) {
+	s.capacity.mu.Lock()
+	defer s.capacity.mu.Unlock()
+
+	s.capacity.stacks = slices.DeleteFunc(s.capacity.stacks, func(st Stack) bool { return st.StackID == stackID })
+}
+
+// holdsCapacity reports whether a stack's pod may still hold its requested resources.
+func holdsCapacity(st Stack) bool {
+	switch st.Status {
+	case StatusStopped, StatusFailed, StatusNodeDeleted, StatusPreempted:
+		return false
+	}
+
+	return true
+}
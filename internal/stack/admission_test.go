@@ -0,0 +1,170 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func TestAdmissionStaticCapacityRejectsBeforeReservingPorts(t *testing.T) {
+	svc, repo, _ := newTestService(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceStatic
+		cfg.CapacityCPUMilli = 150
+		cfg.CapacityMemoryBytes = 1 << 30
+	})
+
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	_, err := createTestStack(svc, CreateInput{})
+	if !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected ErrClusterSaturated, got %v", err)
+	}
+
+	if used, _ := repo.UsedNodePortCount(context.Background(), 30000, 30010); used != 1 {
+		t.Fatalf("expected rejected create to reserve no ports, %d used", used)
+	}
+}
+
+func TestAdmissionIgnoresStoppedStacks(t *testing.T) {
+	svc, repo, _ := newTestService(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceStatic
+		cfg.CapacityCPUMilli = 150
+		cfg.CapacityMemoryBytes = 1 << 30
+	})
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	if err := repo.UpdateStatus(context.Background(), st.StackID, StatusStopped, ""); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("expected stopped stack not to count, got %v", err)
+	}
+}
+
+// listCountingRepository counts the full repository scans.
+type listCountingRepository struct {
+	*InMemoryRepository
+	lists int
+}
+
+func (r *listCountingRepository) ListAll(ctx context.Context) ([]Stack, error) {
+	r.lists++
+	return r.InMemoryRepository.ListAll(ctx)
+}
+
+func TestAdmissionReadsStacksOncePerCacheTTL(t *testing.T) {
+	repo := &listCountingRepository{InMemoryRepository: NewInMemoryRepository(1)}
+	svc := NewService(testStackConfig(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceStatic
+		cfg.CapacityCPUMilli = 150
+		cfg.CapacityMemoryBytes = 1 << 30
+		cfg.CapacityCacheTTL = time.Minute
+	}), repo, NewMockKubernetesClient(1))
+
+	first, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected the cached usage to include the first stack, got %v", err)
+	}
+
+	if err := svc.Delete(context.Background(), first.StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("expected the deleted stack to free the cached usage, got %v", err)
+	}
+
+	if repo.lists != 1 {
+		t.Fatalf("expected one scan within the cache TTL, got %d", repo.lists)
+	}
+}
+
+func TestAdmissionQuotaCapacityIsCached(t *testing.T) {
+	svc, _, k8s := newTestService(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceQuota
+		cfg.CapacityCacheTTL = time.Minute
+	})
+	k8s.quota = CapacityBudget{MemoryBytes: 100 << 20}
+
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	k8s.quota = CapacityBudget{}
+	if _, err := createTestStack(svc, CreateInput{}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected cached quota to saturate, got %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(2 * time.Minute) }
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("expected refreshed quota without limits to admit, got %v", err)
+	}
+}
+
+func TestAdmissionNodeAllocatableCapacity(t *testing.T) {
+	svc, _, k8s := newTestService(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceNodes
+	})
+	k8s.perNode = CapacityBudget{CPUMilli: 50, MemoryBytes: 1 << 30}
+
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("expected three nodes of 50m to fit 100m, got %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected ErrClusterSaturated, got %v", err)
+	}
+}
+
+func TestAdmissionIsPerCluster(t *testing.T) {
+	svc, repo, a, b := newClusterTestService(config.ClusterRoutingRegion, preemptionTestConfig)
+	ctx := context.Background()
+	create := func(region, priority string) (Stack, error) {
+		return createTestStack(svc, CreateInput{Region: region, Priority: priority})
+	}
+
+	seoul, err := create("ap-northeast-2", "player")
+	if err != nil {
+		t.Fatalf("create in eks-a: %v", err)
+	}
+
+	if _, err := create("ap-northeast-2", "player"); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected eks-a to be saturated, got %v", err)
+	}
+
+	virginia, err := create("us-east-1", "player")
+	if err != nil {
+		t.Fatalf("expected eks-b to admit while eks-a is full, got %v", err)
+	}
+
+	admin, err := create("us-east-1", "admin")
+	if err != nil {
+		t.Fatalf("expected admin create to preempt in eks-b, got %v", err)
+	}
+
+	if admin.ClusterID != "eks-b" || len(b.pods) != 1 || len(a.pods) != 1 {
+		t.Fatalf("expected the admin stack to replace the eks-b stack, got %q with %d and %d pods", admin.ClusterID, len(a.pods), len(b.pods))
+	}
+
+	if st, _, _ := repo.Get(ctx, virginia.StackID); st.Status != StatusPreempted {
+		t.Fatalf("expected the eks-b stack to be preempted, got %q", st.Status)
+	}
+
+	if st, _, _ := repo.Get(ctx, seoul.StackID); st.Status == StatusPreempted {
+		t.Fatalf("expected the eks-a stack to be left alone")
+	}
+}
@@ -0,0 +1,114 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Capacity reports every stack node of every cluster with its allocatable resources and
+// what stack pods request of it, from one node and one pod list per cluster. With cpu
+// and memory set, it also counts how many more stacks of that size fit on each node.
+func (s *Service) Capacity(ctx context.Context, cpu, memory string) (CapacityReport, error) {
+	probe, err := parseCapacityProbe(cpu, memory)
+	if err != nil {
+		return CapacityReport{}, err
+	}
+
+	report := CapacityReport{CheckedAt: s.now(), Nodes: []NodeCapacity{}}
+	if probe != nil {
+		report.Fits = new(int)
+	}
+
+	for _, cluster := range s.clusters {
+		nodes, err := cluster.Client.ListNodeCapacity(ctx)
+		if err != nil {
+			return CapacityReport{}, fmt.Errorf("cluster %q: %w", cluster.ID, err)
+		}
+
+		for _, node := range nodes {
+			node.ClusterID = cluster.ID
+			node.HeadroomCPUMilli = max(node.AllocatableCPUMilli-node.RequestedCPUMilli, 0)
+			node.HeadroomMemoryBytes = max(node.AllocatableMemoryBytes-node.RequestedMemoryBytes, 0)
+
+			usable := node.Ready && node.Schedulable
+			if probe != nil {
+				fits := 0
+				if usable {
+					fits = int(min(node.HeadroomCPUMilli/probe.CPUMilli, node.HeadroomMemoryBytes/probe.MemoryBytes))
+				}
+				node.Fits = &fits
+				*report.Fits += fits
+			}
+
+			if usable {
+				report.AllocatableCPUMilli += node.AllocatableCPUMilli
+				report.AllocatableMemoryBytes += node.AllocatableMemoryBytes
+				report.RequestedCPUMilli += node.RequestedCPUMilli
+				report.RequestedMemoryBytes += node.RequestedMemoryBytes
+				report.HeadroomCPUMilli += node.HeadroomCPUMilli
+				report.HeadroomMemoryBytes += node.HeadroomMemoryBytes
+			}
+
+			report.Nodes = append(report.Nodes, node)
+		}
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		if report.Nodes[i].ClusterID != report.Nodes[j].ClusterID {
+			return report.Nodes[i].ClusterID < report.Nodes[j].ClusterID
+		}
+
+		return report.Nodes[i].NodeID < report.Nodes[j].NodeID
+	})
+
+	return report, nil
+}
+
+// parseCapacityProbe reads the stack size a capacity report counts fits for. Both or
+// neither must be set.
+func parseCapacityProbe(cpu, memory string) (*CapacityBudget, error) {
+	cpu = strings.TrimSpace(cpu)
+	memory = strings.TrimSpace(memory)
+	if cpu == "" && memory == "" {
+		return nil, nil
+	}
+
+	if cpu == "" || memory == "" {
+		return nil, fmt.Errorf("%w: cpu and memory must be set together", ErrInvalidInput)
+	}
+
+	cpuQ, err := resource.ParseQuantity(cpu)
+	if err != nil || cpuQ.MilliValue() <= 0 {
+		return nil, fmt.Errorf("%w: invalid cpu %q", ErrInvalidInput, cpu)
+	}
+
+	memQ, err := resource.ParseQuantity(memory)
+	if err != nil || memQ.Value() <= 0 {
+		return nil, fmt.Errorf("%w: invalid memory %q", ErrInvalidInput, memory)
+	}
+
+	return &CapacityBudget{CPUMilli: cpuQ.MilliValue(), MemoryBytes: memQ.Value()}, nil
+}
+
+// podRequests is what a pod reserves on its node: the sum over its containers or the
+// largest init container, whichever is more, taking the larger of request and limit
+// like the validator does.
+func podRequests(spec corev1.PodSpec) (int64, int64) {
+	var cpuMilli, memBytes, initMilli, initBytes int64
+	for _, c := range spec.Containers {
+		cpuMilli += max64(getMilli(c.Resources.Requests, corev1.ResourceCPU), getMilli(c.Resources.Limits, corev1.ResourceCPU))
+		memBytes += max64(getBytes(c.Resources.Requests, corev1.ResourceMemory), getBytes(c.Resources.Limits, corev1.ResourceMemory))
+	}
+
+	for _, c := range spec.InitContainers {
+		initMilli = max64(initMilli, max64(getMilli(c.Resources.Requests, corev1.ResourceCPU), getMilli(c.Resources.Limits, corev1.ResourceCPU)))
+		initBytes = max64(initBytes, max64(getBytes(c.Resources.Requests, corev1.ResourceMemory), getBytes(c.Resources.Limits, corev1.ResourceMemory)))
+	}
+
+	return max64(cpuMilli, initMilli), max64(memBytes, initBytes)
+}
@@ -0,0 +1,59 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func TestCapacityReport(t *testing.T) {
+	k8s := NewMockKubernetesClient(1)
+	svc := NewService(config.StackConfig{
+		Namespace:         "stacks",
+		StackTTL:          time.Hour,
+		SchedulerInterval: time.Second,
+		NodePortMin:       30000,
+		NodePortMax:       30020,
+		PlacementStrategy: config.PlacementBinpack,
+	}, NewInMemoryRepository(1), k8s)
+
+	for range 2 {
+		if _, err := createTestStack(svc, CreateInput{}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	k8s.mu.Lock()
+	k8s.nodes["worker-c"] = false
+	k8s.mu.Unlock()
+
+	report, err := svc.Capacity(context.Background(), "1", "1Gi")
+	if err != nil {
+		t.Fatalf("capacity: %v", err)
+	}
+
+	if len(report.Nodes) != 3 || report.Nodes[0].NodeID != "worker-a" || report.Nodes[2].Ready {
+		t.Fatalf("unexpected nodes: %+v", report.Nodes)
+	}
+
+	hot := report.Nodes[0]
+	if hot.Pods != 2 || hot.RequestedCPUMilli != 200 || hot.HeadroomCPUMilli != 3800 || *hot.Fits != 3 {
+		t.Fatalf("unexpected hot node: %+v", hot)
+	}
+
+	if report.AllocatableCPUMilli != 8000 || report.HeadroomCPUMilli != 7800 || *report.Fits != 7 || *report.Nodes[2].Fits != 0 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+
+	report, err = svc.Capacity(context.Background(), "", "")
+	if err != nil || report.Fits != nil || report.Nodes[0].Fits != nil {
+		t.Fatalf("expected no fits without a stack size, got %+v (%v)", report, err)
+	}
+
+	if _, err := svc.Capacity(context.Background(), "500m", ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected cpu without memory to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"smctf/internal/config"
+)
+
+// clusterAnnotation lets a challenge's pod spec pin its stacks to one cluster.
+const clusterAnnotation = "smctf.io/cluster"
+
+// Cluster is a Kubernetes cluster stacks are provisioned in. The single cluster of a
+// deployment without STACK_CLUSTERS has an empty ID.
+type Cluster struct {
+	ID       string
+	Region   string
+	PortPool string
+	Client   KubernetesClientAPI
+}
+
+// clientFor returns the client of the cluster a stack lives in. Stacks without a cluster
+// id predate STACK_CLUSTERS and belong to the first cluster.
+func (s *Service) clientFor(clusterID string) (KubernetesClientAPI, error) {
+	if clusterID == "" {
+		return s.clusters[0].Client, nil
+	}
+
+	for _, cluster := range s.clusters {
+		if cluster.ID == clusterID {
+			return cluster.Client, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown cluster %q", clusterID)
+}
+
+// clusterID maps a stack's cluster id to the configured cluster it belongs to.
+func (s *Service) clusterID(id string) string {
+	if id == "" {
+		return s.clusters[0].ID
+	}
+
+	return id
+}
+
+// deleteStackResources removes a stack's pod and Service from the cluster it lives in.
+func (s *Service) deleteStackResources(ctx context.Context, st Stack) error {
+	k8s, err := s.clientFor(st.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
+		return err
+	}
+
+	s.forgetActiveStack(st.StackID)
+	return nil
+}
+
+// routeCluster picks the cluster a new stack is provisioned in: the cluster pinned by the
+// pod spec, otherwise the least loaded cluster in the requested region that serves the
+// requested port pool.
+func (s *Service) routeCluster(ctx context.Context, pinned, region, portPool string) (Cluster, error) {
+	region = strings.TrimSpace(region)
+
+	if pinned != "" {
+		pinField := "metadata.annotations[" + clusterAnnotation + "]"
+		for _, cluster := range s.clusters {
+			if cluster.ID != pinned {
+				continue
+			}
+
+			if region != "" && cluster.Region != region {
+				return Cluster{}, &FieldError{Field: "region", Code: CodeInvalid, Reason: fmt.Sprintf("cluster %q pinned by %s is not in region %q", pinned, clusterAnnotation, region), Err: ErrInvalidInput}
+			}
+
+			if !servesPortPool(cluster, portPool) {
+				return Cluster{}, &FieldError{Field: "port_pool", Code: CodeInvalid, Reason: fmt.Sprintf("cluster %q pinned by %s does not serve port_pool %q", pinned, clusterAnnotation, portPool), Err: ErrInvalidInput}
+			}
+
+			return cluster, nil
+		}
+
+		return Cluster{}, &FieldError{Field: pinField, Code: CodeUnknown, Reason: fmt.Sprintf("unknown cluster %q in %s annotation", pinned, clusterAnnotation)}
+	}
+
+	if region == "" && s.cfg.ClusterRouting == config.ClusterRoutingRegion {
+		return Cluster{}, &FieldError{Field: "region", Code: CodeRequired, Reason: "region is required", Err: ErrInvalidInput}
+	}
+
+	candidates := make([]Cluster, 0, len(s.clusters))
+	for _, cluster := range s.clusters {
+		if region != "" && cluster.Region != region {
+			continue
+		}
+
+		if servesPortPool(cluster, portPool) {
+			candidates = append(candidates, cluster)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if region != "" {
+			return Cluster{}, &FieldError{Field: "region", Code: CodeUnknown, Reason: fmt.Sprintf("no cluster in region %q serves the request", region), Err: ErrInvalidInput}
+		}
+
+		return Cluster{}, &FieldError{Field: "port_pool", Code: CodeUnknown, Reason: fmt.Sprintf("no cluster serves port_pool %q", portPool), Err: ErrInvalidInput}
+	case 1:
+		return candidates[0], nil
+	}
+
+	load, err := s.loadByCluster(ctx)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	best := candidates[0]
+	for _, cluster := range candidates[1:] {
+		if load[cluster.ID].lessThan(load[best.ID]) {
+			best = cluster
+		}
+	}
+
+	return best, nil
+}
+
+func (s *Service) hasClusterPortPools() bool {
+	for _, cluster := range s.clusters {
+		if cluster.PortPool != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// servesPortPool reports whether a cluster takes stacks of an explicitly requested port
+// pool; clusters without their own pool take any.
+func servesPortPool(cluster Cluster, portPool string) bool {
+	return portPool == "" || cluster.PortPool == "" || cluster.PortPool == portPool
+}
+
+// clusterLoad is what the active stacks of a cluster reserve; routing prefers the cluster
+// with the least reserved CPU, then memory, then stacks.
+type clusterLoad struct {
+	cpuMilli int64
+	memBytes int64
+	stacks   int
+}
+
+func (l clusterLoad) lessThan(other clusterLoad) bool {
+	if l.cpuMilli != other.cpuMilli {
+		return l.cpuMilli < other.cpuMilli
+	}
+
+	if l.memBytes != other.memBytes {
+		return l.memBytes < other.memBytes
+	}
+
+	return l.stacks < other.stacks
+}
+
+// loadByCluster sums the resources reserved by the stacks of each cluster.
+func (s *Service) loadByCluster(ctx context.Context) (map[string]clusterLoad, error) {
+	items, err := s.activeStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	load := make(map[string]clusterLoad, len(s.clusters))
+	for _, st := range items {
+		id := s.clusterID(st.ClusterID)
+		l := load[id]
+		l.cpuMilli += st.RequestedMilli
+		l.memBytes += st.RequestedBytes
+		l.stacks++
+		load[id] = l
+	}
+
+	return load, nil
+}
@@ -0,0 +1,152 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func newClusterTestService(routing string, mutate ...func(cfg *config.StackConfig)) (*Service, *InMemoryRepository, *MockKubernetesClient, *MockKubernetesClient) {
+	cfg := testStackConfig(func(cfg *config.StackConfig) {
+		cfg.NodePortMax = 30020
+		cfg.NodePortPools = []config.NodePortPool{
+			{Name: "seoul", Min: 30000, Max: 30010},
+			{Name: "virginia", Min: 30011, Max: 30020},
+		}
+		cfg.ClusterRouting = routing
+		for _, m := range mutate {
+			m(cfg)
+		}
+	})
+
+	repo := NewInMemoryRepository(1)
+	a := NewMockKubernetesClient(1)
+	b := NewMockKubernetesClient(2)
+	svc := NewServiceWithClusters(cfg, repo, []Cluster{
+		{ID: "eks-a", Region: "ap-northeast-2", PortPool: "seoul", Client: a},
+		{ID: "eks-b", Region: "us-east-1", PortPool: "virginia", Client: b},
+	})
+
+	return svc, repo, a, b
+}
+
+func TestClusterRoutingLeastLoaded(t *testing.T) {
+	svc, _, a, b := newClusterTestService(config.ClusterRoutingLeastLoaded)
+
+	first, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	second, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("second create: %v", err)
+	}
+
+	if first.ClusterID != "eks-a" || second.ClusterID != "eks-b" {
+		t.Fatalf("expected stacks spread over both clusters, got %q and %q", first.ClusterID, second.ClusterID)
+	}
+
+	if second.PortPool != "virginia" || second.Ports[0].NodePort < 30011 {
+		t.Fatalf("expected cluster port pool to be used, got %s %+v", second.PortPool, second.Ports)
+	}
+
+	if len(a.pods) != 1 || len(b.pods) != 1 {
+		t.Fatalf("expected one pod per cluster, got %d and %d", len(a.pods), len(b.pods))
+	}
+
+	if err := svc.Delete(context.Background(), second.StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if len(a.pods) != 1 || len(b.pods) != 0 {
+		t.Fatalf("expected delete to reach the stack's cluster, got %d and %d pods", len(a.pods), len(b.pods))
+	}
+}
+
+func TestClusterRoutingRegionAndPin(t *testing.T) {
+	svc, _, _, _ := newClusterTestService(config.ClusterRoutingRegion)
+
+	if _, err := createTestStack(svc, CreateInput{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected region to be required, got %v", err)
+	}
+
+	st, err := createTestStack(svc, CreateInput{Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("create in region: %v", err)
+	}
+
+	if st.ClusterID != "eks-b" {
+		t.Fatalf("expected us-east-1 stack in eks-b, got %q", st.ClusterID)
+	}
+
+	pinned := strings.Replace(testPodSpec, "  name: p\n", "  name: p\n  annotations:\n    smctf.io/cluster: eks-a\n", 1)
+	if _, err := createTestStack(svc, CreateInput{PodSpecYML: pinned, Region: "us-east-1"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected pin outside the requested region to be rejected, got %v", err)
+	}
+
+	st, err = createTestStack(svc, CreateInput{PodSpecYML: pinned, Region: "ap-northeast-2"})
+	if err != nil {
+		t.Fatalf("create pinned: %v", err)
+	}
+
+	if st.ClusterID != "eks-a" {
+		t.Fatalf("expected pinned stack in eks-a, got %q", st.ClusterID)
+	}
+
+	unknown := strings.Replace(pinned, "eks-a", "eks-c", 1)
+	if _, err := createTestStack(svc, CreateInput{PodSpecYML: unknown, Region: "ap-northeast-2"}); !errors.Is(err, ErrPodSpecInvalid) {
+		t.Fatalf("expected unknown cluster pin to be rejected, got %v", err)
+	}
+}
+
+func TestCleanupScansEveryCluster(t *testing.T) {
+	svc, repo, a, b := newClusterTestService(config.ClusterRoutingLeastLoaded)
+	ctx := context.Background()
+
+	first, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	second, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("second create: %v", err)
+	}
+
+	b.mu.Lock()
+	b.pods["orphan"] = podState{namespace: "stacks", podID: "orphan", nodeID: "worker-a", status: StatusRunning, createdAt: time.Now().Add(-time.Hour)}
+	delete(b.pods, second.PodID)
+	b.mu.Unlock()
+
+	svc.CleanupExpiredAndOrphaned(ctx)
+
+	if _, ok := b.pods["orphan"]; ok {
+		t.Fatalf("expected orphan pod in the second cluster to be removed")
+	}
+
+	if _, ok, _ := repo.Get(ctx, second.StackID); ok {
+		t.Fatalf("expected stack without its pod to be removed")
+	}
+
+	if _, ok, _ := repo.Get(ctx, first.StackID); !ok {
+		t.Fatalf("expected stack in the first cluster to be kept")
+	}
+
+	if len(a.pods) != 1 {
+		t.Fatalf("expected first cluster pod to be kept, got %d pods", len(a.pods))
+	}
+
+	stats, err := svc.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	if stats.Clusters["eks-a"].ActiveStacks != 1 || stats.Clusters["eks-b"].TotalStacks != 0 || stats.Clusters["eks-b"].Region != "us-east-1" {
+		t.Fatalf("unexpected cluster stats: %+v", stats.Clusters)
+	}
+}
@@ -0,0 +1,131 @@
+package stack
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxConfigMapNameLength leaves room for the pod name that scopeConfigMaps prefixes.
+const maxConfigMapNameLength = 200
+
+// validateConfigMaps checks the ConfigMaps sent with a pod spec and returns their names.
+// Together they may hold at most STACK_MAX_CONFIGMAP_SIZE of keys and values.
+func (v *Validator) validateConfigMaps(errs *ValidationErrors, configMaps []corev1.ConfigMap) map[string]struct{} {
+	names := make(map[string]struct{}, len(configMaps))
+	var size int64
+	for i := range configMaps {
+		cm := &configMaps[i]
+		field := fmt.Sprintf("config_maps[%d]", i)
+		if cm.Namespace != "" {
+			errs.add(field+".metadata.namespace", CodeForbidden, "namespace is forbidden in input")
+		}
+
+		switch _, dup := names[cm.Name]; {
+		case cm.Name == "":
+			errs.add(field+".metadata.name", CodeRequired, "ConfigMap name is required")
+		case len(cm.Name) > maxConfigMapNameLength || len(validation.IsDNS1123Subdomain(cm.Name)) > 0:
+			errs.add(field+".metadata.name", CodeInvalid, fmt.Sprintf("must be a DNS subdomain of at most %d characters", maxConfigMapNameLength))
+		case dup:
+			errs.add(field+".metadata.name", CodeDuplicate, fmt.Sprintf("duplicate ConfigMap %q", cm.Name))
+		}
+		names[cm.Name] = struct{}{}
+
+		for _, key := range slices.Sorted(maps.Keys(cm.Data)) {
+			validateConfigMapKey(errs, fmt.Sprintf("%s.data[%s]", field, key), key)
+			size += int64(len(key) + len(cm.Data[key]))
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(cm.BinaryData)) {
+			keyField := fmt.Sprintf("%s.binaryData[%s]", field, key)
+			if _, ok := cm.Data[key]; ok {
+				errs.add(keyField, CodeDuplicate, fmt.Sprintf("key %q is also in data", key))
+				continue
+			}
+
+			validateConfigMapKey(errs, keyField, key)
+			size += int64(len(key) + len(cm.BinaryData[key]))
+		}
+	}
+
+	if v.cfg.MaxConfigMapBytes > 0 && size > v.cfg.MaxConfigMapBytes {
+		errs.add("config_maps", CodeExceedsLimit, fmt.Sprintf("%s exceeds the maximum of %s", formatBytes(size), formatBytes(v.cfg.MaxConfigMapBytes)))
+	}
+
+	return names
+}
+
+func validateConfigMapKey(errs *ValidationErrors, field, key string) {
+	if msgs := validation.IsConfigMapKey(key); len(msgs) > 0 {
+		errs.add(field, CodeInvalid, strings.Join(msgs, "; "))
+	}
+}
+
+// validateConfigMapRefs lets a pod use only the ConfigMaps sent with it, so it cannot read
+// those of other stacks in its namespace.
+func validateConfigMapRefs(errs *ValidationErrors, pod *corev1.Pod, names map[string]struct{}) {
+	visitConfigMapRefs(pod, func(field string, name *string) {
+		if _, ok := names[*name]; !ok {
+			errs.add(field, CodeNotAllowed, fmt.Sprintf("ConfigMap %q is not part of the pod spec", *name))
+		}
+	})
+}
+
+// visitConfigMapRefs calls fn with every ConfigMap name the pod refers to.
+func visitConfigMapRefs(pod *corev1.Pod, fn func(field string, name *string)) {
+	for i := range pod.Spec.Volumes {
+		vol := &pod.Spec.Volumes[i]
+		field := fmt.Sprintf("spec.volumes[%d]", i)
+		if vol.ConfigMap != nil {
+			fn(field+".configMap.name", &vol.ConfigMap.Name)
+		}
+
+		if vol.Projected != nil {
+			for j := range vol.Projected.Sources {
+				if src := vol.Projected.Sources[j].ConfigMap; src != nil {
+					fn(fmt.Sprintf("%s.projected.sources[%d].configMap.name", field, j), &src.Name)
+				}
+			}
+		}
+	}
+
+	visitContainers := func(prefix string, containers []corev1.Container) {
+		for i := range containers {
+			c := &containers[i]
+			for j := range c.EnvFrom {
+				if ref := c.EnvFrom[j].ConfigMapRef; ref != nil {
+					fn(fmt.Sprintf("%s[%d].envFrom[%d].configMapRef.name", prefix, i, j), &ref.Name)
+				}
+			}
+
+			for j := range c.Env {
+				if from := c.Env[j].ValueFrom; from != nil && from.ConfigMapKeyRef != nil {
+					fn(fmt.Sprintf("%s[%d].env[%d].valueFrom.configMapKeyRef.name", prefix, i, j), &from.ConfigMapKeyRef.Name)
+				}
+			}
+		}
+	}
+
+	visitContainers("spec.initContainers", pod.Spec.InitContainers)
+	visitContainers("spec.containers", pod.Spec.Containers)
+}
+
+// scopeConfigMaps names a stack's ConfigMaps after its pod, so stacks sharing a namespace
+// cannot clash, and points the pod at the new names.
+func scopeConfigMaps(pod *corev1.Pod, configMaps []corev1.ConfigMap, podName string) []corev1.ConfigMap {
+	out := make([]corev1.ConfigMap, 0, len(configMaps))
+	for _, cm := range configMaps {
+		cm.Name = podName + "-" + cm.Name
+		out = append(out, cm)
+	}
+
+	visitConfigMapRefs(pod, func(_ string, name *string) {
+		*name = podName + "-" + *name
+	})
+
+	return out
+}
@@ -0,0 +1,128 @@
+package stack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"smctf/internal/config"
+)
+
+const configMapTestPodSpec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  volumes:
+    - name: conf
+      configMap:
+        name: nginx-conf
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 1337
+      envFrom:
+        - configMapRef:
+            name: env
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx-conf
+data:
+  default.conf: "server { listen 1337; }"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: env
+data:
+  MODE: ctf
+`
+
+func TestCreateWithConfigMaps(t *testing.T) {
+	svc, _, k8s := newTestService(func(cfg *config.StackConfig) {
+		cfg.VolumeAllowlist = []string{"configMap"}
+	})
+	ctx := context.Background()
+
+	st, err := svc.Create(ctx, CreateInput{
+		PodSpecYML:  configMapTestPodSpec,
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP"}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got := k8s.pods[st.PodID].configMaps
+	if len(got) != 2 || got[0] != st.StackID+"-nginx-conf" || got[1] != st.StackID+"-env" {
+		t.Fatalf("expected ConfigMaps scoped to the stack, got %v", got)
+	}
+
+	if !strings.Contains(st.PodSpecYAML, "name: nginx-conf") {
+		t.Fatalf("expected stored pod spec to keep the submitted names:\n%s", st.PodSpecYAML)
+	}
+}
+
+func TestValidatorConfigMapRefs(t *testing.T) {
+	v := NewValidator(config.StackConfig{VolumeAllowlist: []string{"configMap", "projected"}, MaxConfigMapBytes: 64})
+	ports := []PortSpec{{ContainerPort: 1337, Protocol: "TCP"}}
+
+	if _, err := v.ValidatePodSpec(configMapTestPodSpec, ports); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		field string
+		code  string
+	}{
+		{
+			"foreign volume",
+			strings.Replace(configMapTestPodSpec, "name: nginx-conf\n  containers", "name: other-stack\n  containers", 1),
+			"spec.volumes[0].configMap.name", CodeNotAllowed,
+		},
+		{
+			"foreign env",
+			strings.Replace(configMapTestPodSpec, "envFrom:\n        - configMapRef:\n            name: env", "env:\n        - name: FLAG\n          valueFrom:\n            configMapKeyRef:\n              name: flags\n              key: flag", 1),
+			"spec.containers[0].env[0].valueFrom.configMapKeyRef.name", CodeNotAllowed,
+		},
+		{
+			"foreign projection",
+			strings.Replace(configMapTestPodSpec, "configMap:\n        name: nginx-conf", "projected:\n        sources:\n          - configMap:\n              name: flags", 1),
+			"spec.volumes[0].projected.sources[0].configMap.name", CodeNotAllowed,
+		},
+		{
+			"namespace",
+			strings.Replace(configMapTestPodSpec, "name: env\ndata", "name: env\n  namespace: kube-system\ndata", 1),
+			"config_maps[1].metadata.namespace", CodeForbidden,
+		},
+		{
+			"key",
+			strings.Replace(configMapTestPodSpec, "MODE: ctf", "../MODE: ctf", 1),
+			"config_maps[1].data[../MODE]", CodeInvalid,
+		},
+		{
+			"size",
+			strings.Replace(configMapTestPodSpec, "MODE: ctf", "MODE: "+strings.Repeat("a", 64), 1),
+			"config_maps", CodeExceedsLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidatePodSpec(tt.spec, ports)
+			violations := Violations(err)
+			if len(violations) != 1 || violations[0].Field != tt.field || violations[0].Code != tt.code {
+				t.Fatalf("expected %s violation on %s, got %+v", tt.code, tt.field, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package stack
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	SchemeTCP   = "tcp"
+	SchemeUDP   = "udp"
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+)
+
+const maxPortNameLength = 63
+
+func normalizeScheme(scheme, protocol string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(scheme))
+	if s == "" {
+		if protocol == "UDP" {
+			return SchemeUDP, nil
+		}
+
+		return SchemeTCP, nil
+	}
+
+	switch s {
+	case SchemeUDP:
+		if protocol != "UDP" {
+			return "", fmt.Errorf("%w: scheme udp requires protocol UDP", ErrInvalidInput)
+		}
+	case SchemeTCP, SchemeHTTP, SchemeHTTPS:
+		if protocol != "TCP" {
+			return "", fmt.Errorf("%w: scheme %s requires protocol TCP", ErrInvalidInput, s)
+		}
+	default:
+		return "", fmt.Errorf("%w: scheme must be one of tcp, udp, http, https", ErrInvalidInput)
+	}
+
+	return s, nil
+}
+
+func normalizePortName(name string) (string, error) {
+	n := strings.TrimSpace(name)
+	if len(n) > maxPortNameLength {
+		return "", fmt.Errorf("%w: port name exceeds %d characters", ErrInvalidInput, maxPortNameLength)
+	}
+
+	for _, r := range n {
+		if r < 0x20 || r == 0x7f {
+			return "", fmt.Errorf("%w: port name contains control characters", ErrInvalidInput)
+		}
+	}
+
+	return n, nil
+}
+
+func renderConnections(host *string, ports []PortMapping) []Connection {
+	if host == nil || *host == "" || len(ports) == 0 {
+		return nil
+	}
+
+	out := make([]Connection, 0, len(ports))
+	for _, p := range ports {
+		scheme := p.Scheme
+		if scheme == "" {
+			scheme = SchemeTCP
+			if strings.EqualFold(p.Protocol, "UDP") {
+				scheme = SchemeUDP
+			}
+		}
+
+		out = append(out, Connection{
+			Name:          p.Name,
+			ContainerPort: p.ContainerPort,
+			Protocol:      p.Protocol,
+			NodePort:      p.NodePort,
+			Scheme:        scheme,
+			Host:          *host,
+			Rendered:      renderConnection(scheme, *host, p.NodePort),
+		})
+	}
+
+	return out
+}
+
+func renderConnection(scheme, host string, port int) string {
+	portStr := strconv.Itoa(port)
+	switch scheme {
+	case SchemeHTTP, SchemeHTTPS:
+		return scheme + "://" + net.JoinHostPort(host, portStr) + "/"
+	case SchemeUDP:
+		return "nc -u " + host + " " + portStr
+	default:
+		return "nc " + host + " " + portStr
+	}
+}
@@ -0,0 +1,74 @@
+package stack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderConnections(t *testing.T) {
+	host := "203.0.113.5"
+	conns := renderConnections(&host, []PortMapping{
+		{ContainerPort: 1337, Protocol: "TCP", NodePort: 31005},
+		{ContainerPort: 80, Protocol: "TCP", NodePort: 31006, Scheme: SchemeHTTP, Name: "web"},
+		{ContainerPort: 53, Protocol: "UDP", NodePort: 31007},
+	})
+
+	want := []string{"nc 203.0.113.5 31005", "http://203.0.113.5:31006/", "nc -u 203.0.113.5 31007"}
+	if len(conns) != len(want) {
+		t.Fatalf("expected %d connections, got %d", len(want), len(conns))
+	}
+
+	for i, w := range want {
+		if conns[i].Rendered != w {
+			t.Fatalf("connection %d: expected %q, got %q", i, w, conns[i].Rendered)
+		}
+	}
+
+	if conns[1].Name != "web" || conns[2].Scheme != SchemeUDP {
+		t.Fatalf("unexpected connection metadata: %+v", conns)
+	}
+}
+
+func TestRenderConnectionsIPv6(t *testing.T) {
+	host := "2001:db8::1"
+	conns := renderConnections(&host, []PortMapping{{ContainerPort: 443, Protocol: "TCP", NodePort: 31443, Scheme: SchemeHTTPS}})
+	if len(conns) != 1 || conns[0].Rendered != "https://[2001:db8::1]:31443/" {
+		t.Fatalf("unexpected ipv6 rendering: %+v", conns)
+	}
+}
+
+func TestRenderConnectionsWithoutHost(t *testing.T) {
+	if conns := renderConnections(nil, []PortMapping{{ContainerPort: 80, Protocol: "TCP", NodePort: 31000}}); conns != nil {
+		t.Fatalf("expected nil connections without host, got %+v", conns)
+	}
+}
+
+func TestNormalizeScheme(t *testing.T) {
+	cases := []struct {
+		scheme   string
+		protocol string
+		want     string
+		wantErr  bool
+	}{
+		{"", "TCP", SchemeTCP, false},
+		{"", "UDP", SchemeUDP, false},
+		{"HTTP", "TCP", SchemeHTTP, false},
+		{"https", "UDP", "", true},
+		{"udp", "TCP", "", true},
+		{"ftp", "TCP", "", true},
+	}
+
+	for _, tc := range cases {
+		got, err := normalizeScheme(tc.scheme, tc.protocol)
+		if tc.wantErr {
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("normalizeScheme(%q, %q): expected invalid input, got %v", tc.scheme, tc.protocol, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tc.want {
+			t.Fatalf("normalizeScheme(%q, %q) = %q, %v; want %q", tc.scheme, tc.protocol, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package stack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	demandSourceQueued   = "queued"
+	demandSourceRejected = "rejected"
+
+	// balloonLabelSelector matches the placeholder pods sized to pending demand. They are
+	// not stack pods, so the orphan sweep leaves them alone.
+	balloonLabelSelector = "app.kubernetes.io/name=smctf-balloon"
+	balloonPodPrefix     = "smctf-balloon-"
+)
+
+// demandItem is a stack that could not be placed for lack of cluster capacity.
+type demandItem struct {
+	key      string
+	nodePool string
+	region   string
+	portPool string
+	cpuMilli int64
+	memBytes int64
+	at       time.Time
+}
+
+// RejectedDemand is a create rejected as saturated. It is kept in the repository for
+// STACK_DEMAND_WINDOW, so the replica reporting demand sees the rejections of all replicas.
+type RejectedDemand struct {
+	Key            string
+	NodePool       string
+	Region         string
+	PortPool       string
+	RequestedMilli int64
+	RequestedBytes int64
+	RejectedAt     time.Time
+}
+
+// rejectedDemandKey identifies a create, so repeated rejections of one owner's create for
+// the same challenge and pool count once. Anonymous creates share a key per challenge and
+// pool.
+func rejectedDemandKey(in CreateInput) string {
+	return url.PathEscape(in.OwnerID) + "/" + url.PathEscape(in.ChallengeID) + "/" + url.PathEscape(in.NodePool)
+}
+
+func (s *Service) recordRejectedDemand(ctx context.Context, in CreateInput, valid ValidationResult) {
+	err := s.repo.SaveRejectedDemand(ctx, RejectedDemand{
+		Key:            rejectedDemandKey(in),
+		NodePool:       in.NodePool,
+		Region:         in.Region,
+		PortPool:       in.PortPool,
+		RequestedMilli: valid.RequestedMilli,
+		RequestedBytes: valid.RequestedBytes,
+		RejectedAt:     s.now(),
+	})
+	if err != nil {
+		slog.Warn("save rejected demand failed", slog.String("owner_id", in.OwnerID), slog.Any("error", err))
+	}
+}
+
+// forgetRejectedDemand drops an earlier rejection once the same create succeeds.
+func (s *Service) forgetRejectedDemand(ctx context.Context, in CreateInput) {
+	if err := s.repo.DeleteRejectedDemand(ctx, rejectedDemandKey(in)); err != nil {
+		slog.Warn("delete rejected demand failed", slog.String("owner_id", in.OwnerID), slog.Any("error", err))
+	}
+}
+
+// rejectedDemand returns the rejections within STACK_DEMAND_WINDOW, oldest first, and
+// deletes the older ones.
+func (s *Service) rejectedDemand(ctx context.Context) ([]demandItem, error) {
+	rejections, err := s.repo.ListRejectedDemand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := s.now().Add(-s.cfg.DemandWindow)
+	out := make([]demandItem, 0, len(rejections))
+	for _, d := range rejections {
+		if !d.RejectedAt.After(cutoff) {
+			if err := s.repo.DeleteRejectedDemand(ctx, d.Key); err != nil {
+				slog.Warn("delete expired rejected demand failed", slog.Any("error", err))
+			}
+			continue
+		}
+
+		out = append(out, rejectedDemandItem(d))
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].at.Before(out[j].at) })
+	return out, nil
+}
+
+func rejectedDemandItem(d RejectedDemand) demandItem {
+	return demandItem{
+		key:      d.Key,
+		nodePool: d.NodePool,
+		region:   d.Region,
+		portPool: d.PortPool,
+		cpuMilli: d.RequestedMilli,
+		memBytes: d.RequestedBytes,
+		at:       d.RejectedAt,
+	}
+}
+
+func (s *Service) demandPools() []string {
+	if len(s.cfg.NodePools) == 0 {
+		return []string{""}
+	}
+
+	out := make([]string, 0, len(s.cfg.NodePools))
+	for _, pool := range s.cfg.NodePools {
+		out = append(out, pool.Name)
+	}
+
+	return out
+}
+
+// ReportDemand publishes the pending demand gauges from the queue and recent rejections
+// and, with STACK_BALLOON_ENABLED, sizes the balloon pods to that demand. The scheduler
+// calls it after processing the queue.
+func (s *Service) ReportDemand(ctx context.Context) {
+	tickets, err := s.repo.ListQueueTickets(ctx)
+	if err != nil {
+		slog.Error("list queue tickets for demand failed", slog.Any("error", err))
+		return
+	}
+
+	queued := make([]demandItem, 0, len(tickets))
+	for _, ticket := range fairQueueOrder(ticketsWithStatus(tickets, TicketStatusQueued)) {
+		queued = append(queued, demandItem{
+			key:      ticket.TicketID,
+			nodePool: ticket.NodePool,
+			region:   ticket.Region,
+			portPool: ticket.PortPool,
+			cpuMilli: ticket.RequestedMilli,
+			memBytes: ticket.RequestedBytes,
+			at:       ticket.CreatedAt,
+		})
+	}
+
+	rejected, err := s.rejectedDemand(ctx)
+	if err != nil {
+		slog.Error("list rejected demand failed", slog.Any("error", err))
+		return
+	}
+
+	pools := s.demandPools()
+	recordPendingDemand(demandSourceQueued, queued, pools)
+	recordPendingDemand(demandSourceRejected, rejected, pools)
+
+	if s.cfg.BalloonEnabled {
+		s.reconcileBalloons(ctx, append(queued, rejected...))
+	}
+}
+
+// reconcileBalloons keeps one balloon pod per pending stack, up to STACK_BALLOON_MAX with
+// queued stacks first. Balloons request what their stack would and run at the low
+// STACK_BALLOON_PRIORITY_CLASS, so a pending balloon makes the cluster autoscaler add a
+// node and a stack pod later preempts the balloon holding that room.
+func (s *Service) reconcileBalloons(ctx context.Context, items []demandItem) {
+	if len(items) > s.cfg.BalloonMax {
+		items = items[:s.cfg.BalloonMax]
+	}
+
+	desired := make(map[string]map[string]demandItem, len(s.clusters))
+	for _, item := range items {
+		// Tickets queued before their size was recorded cannot be sized.
+		if item.cpuMilli == 0 && item.memBytes == 0 {
+			continue
+		}
+
+		cluster := s.demandCluster(item)
+		if desired[cluster.ID] == nil {
+			desired[cluster.ID] = make(map[string]demandItem)
+		}
+		desired[cluster.ID][balloonPodName(item.key)] = item
+	}
+
+	for _, cluster := range s.clusters {
+		existing, err := cluster.Client.ListBalloons(ctx, s.cfg.Namespace)
+		if err != nil {
+			slog.Warn("list balloon pods failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			continue
+		}
+
+		want := desired[cluster.ID]
+		have := make(map[string]struct{}, len(existing))
+		for _, name := range existing {
+			have[name] = struct{}{}
+			if _, ok := want[name]; ok {
+				continue
+			}
+
+			if err := cluster.Client.DeletePodAndService(ctx, s.cfg.Namespace, name, ""); err != nil {
+				slog.Warn("delete balloon pod failed", slog.String("cluster_id", cluster.ID), slog.String("pod_id", name), slog.Any("error", err))
+			}
+		}
+
+		for name, item := range want {
+			if _, ok := have[name]; ok {
+				continue
+			}
+
+			err := cluster.Client.CreateBalloon(ctx, BalloonRequest{
+				Namespace:         s.cfg.Namespace,
+				Name:              name,
+				NodePool:          item.nodePool,
+				CPUMilli:          item.cpuMilli,
+				MemoryBytes:       item.memBytes,
+				Image:             s.cfg.BalloonImage,
+				PriorityClassName: s.cfg.BalloonPriorityClass,
+			})
+			if err != nil {
+				slog.Warn("create balloon pod failed", slog.String("cluster_id", cluster.ID), slog.String("pod_id", name), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// releaseBalloon deletes the balloon holding room for a pending stack right before the
+// stack is created, so stacks whose PriorityClass never preempts can use that room.
+func (s *Service) releaseBalloon(ctx context.Context, item demandItem) {
+	if !s.cfg.BalloonEnabled {
+		return
+	}
+
+	cluster := s.demandCluster(item)
+	name := balloonPodName(item.key)
+	if err := cluster.Client.DeletePodAndService(ctx, s.cfg.Namespace, name, ""); err != nil {
+		slog.Warn("release balloon pod failed", slog.String("cluster_id", cluster.ID), slog.String("pod_id", name), slog.Any("error", err))
+	}
+}
+
+// rejectedDemandFor returns the recorded rejection of a create, if any.
+func (s *Service) rejectedDemandFor(ctx context.Context, in CreateInput) (demandItem, bool) {
+	d, ok, err := s.repo.GetRejectedDemand(ctx, rejectedDemandKey(in))
+	if err != nil {
+		slog.Warn("get rejected demand failed", slog.String("owner_id", in.OwnerID), slog.Any("error", err))
+		return demandItem{}, false
+	}
+
+	if !ok {
+		return demandItem{}, false
+	}
+
+	return rejectedDemandItem(d), true
+}
+
+// demandCluster picks the cluster a balloon goes to: the first one in the stack's region
+// that serves its port pool, or the first cluster.
+func (s *Service) demandCluster(item demandItem) Cluster {
+	for _, cluster := range s.clusters {
+		if item.region != "" && cluster.Region != item.region {
+			continue
+		}
+
+		if servesPortPool(cluster, item.portPool) {
+			return cluster
+		}
+	}
+
+	return s.clusters[0]
+}
+
+func balloonPodName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return balloonPodPrefix + hex.EncodeToString(sum[:8])
+}
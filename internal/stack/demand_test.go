@@ -0,0 +1,113 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPendingDemandAndBalloons(t *testing.T) {
+	svc, _, k8s := newTestService(func(cfg *config.StackConfig) {
+		cfg.CapacitySource = config.CapacitySourceStatic
+		cfg.CapacityCPUMilli = 150
+		cfg.CapacityMemoryBytes = 1 << 30
+		cfg.QueueMaxLength = 10
+		cfg.QueueMaxPerOwner = 1
+		cfg.QueueWaitTimeout = time.Hour
+		cfg.DemandWindow = time.Minute
+		cfg.BalloonEnabled = true
+		cfg.BalloonPriorityClass = "smctf-balloon"
+		cfg.BalloonImage = "registry.k8s.io/pause:3.10"
+		cfg.BalloonMax = 5
+	})
+	ctx := context.Background()
+
+	running, err := svc.Create(ctx, testCreateInput(CreateInput{OwnerID: "team-a", Queue: true}))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	rejected := testCreateInput(CreateInput{OwnerID: "team-b", Queue: true})
+	rejected.Queue = false
+	for range 2 {
+		if _, err := svc.Create(ctx, rejected); !errors.Is(err, ErrClusterSaturated) {
+			t.Fatalf("expected saturation, got %v", err)
+		}
+	}
+
+	svc.ReportDemand(ctx)
+	if got := testutil.ToFloat64(stackPendingStacks.WithLabelValues("", demandSourceRejected)); got != 1 {
+		t.Fatalf("expected a retried rejection to count once, got %v", got)
+	}
+
+	if _, ticket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-c", Queue: true})); err != nil || ticket == nil {
+		t.Fatalf("expected queue ticket, got %v (%v)", ticket, err)
+	}
+
+	svc.ReportDemand(ctx)
+	if got := testutil.ToFloat64(stackPendingStacks.WithLabelValues("", demandSourceQueued)); got != 1 {
+		t.Fatalf("expected one queued stack, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(stackPendingCPU.WithLabelValues("", demandSourceQueued)); got != 0.1 {
+		t.Fatalf("expected 0.1 pending cores, got %v", got)
+	}
+
+	if len(k8s.balloons) != 2 {
+		t.Fatalf("expected a balloon per pending stack, got %d", len(k8s.balloons))
+	}
+
+	for _, b := range k8s.balloons {
+		if b.CPUMilli != 100 || b.MemoryBytes != 64<<20 || b.PriorityClassName != "smctf-balloon" {
+			t.Fatalf("unexpected balloon: %+v", b)
+		}
+	}
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(2 * time.Minute) }
+	svc.ReportDemand(ctx)
+	if got := testutil.ToFloat64(stackPendingStacks.WithLabelValues("", demandSourceRejected)); got != 0 || len(k8s.balloons) != 1 {
+		t.Fatalf("expected rejection to age out, got %v pending and %d balloons", got, len(k8s.balloons))
+	}
+
+	if err := svc.Delete(ctx, running.StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	svc.ProcessQueue(ctx)
+	svc.ReportDemand(ctx)
+	if got := testutil.ToFloat64(stackPendingStacks.WithLabelValues("", demandSourceQueued)); got != 0 || len(k8s.balloons) != 0 {
+		t.Fatalf("expected demand to be met, got %v queued and %d balloons", got, len(k8s.balloons))
+	}
+}
+
+func TestRejectedDemandIsSharedAcrossReplicas(t *testing.T) {
+	leader, repo, k8s := newTestService(func(cfg *config.StackConfig) {
+		queueTestConfig(cfg)
+		cfg.DemandWindow = time.Minute
+	})
+	replica := NewService(leader.cfg, repo, k8s)
+	ctx := context.Background()
+
+	if _, err := leader.Create(ctx, testCreateInput(CreateInput{OwnerID: "team-a", Queue: true})); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	for _, owner := range []string{"team-b", "", ""} {
+		in := testCreateInput(CreateInput{OwnerID: owner, Queue: true})
+		in.Queue = false
+		in.ChallengeID = "web"
+		if _, err := replica.Create(ctx, in); !errors.Is(err, ErrClusterSaturated) {
+			t.Fatalf("expected saturation, got %v", err)
+		}
+	}
+
+	leader.ReportDemand(ctx)
+	if got := testutil.ToFloat64(stackPendingStacks.WithLabelValues("", demandSourceRejected)); got != 2 {
+		t.Fatalf("expected the leader to report both rejections of the other replica, got %v", got)
+	}
+}
@@ -0,0 +1,226 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// DryRunResult is what a create request would provision, without provisioning anything.
+type DryRunResult struct {
+	Valid                   bool          `json:"valid"`
+	SanitizedYAML           string        `json:"sanitized_pod_spec,omitempty"`
+	RequestedMilli          int64         `json:"requested_cpu_milli"`
+	RequestedBytes          int64         `json:"requested_memory_bytes"`
+	RequestedEphemeralBytes int64         `json:"requested_ephemeral_storage_bytes"`
+	TargetPorts             []PortSpec    `json:"target_ports"`
+	NodePool                string        `json:"node_pool,omitempty"`
+	Changes                 []FieldChange `json:"changes"`
+	Violations              []Violation   `json:"violations"`
+}
+
+// FieldChange is a pod spec field the server rewrote. Before and After hold the JSON
+// value and are empty when the field was absent.
+type FieldChange struct {
+	Path   string `json:"path"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// dryRunStackID stands in for the stack id in the names a dry run reports.
+const dryRunStackID = "<stack_id>"
+
+// ValidateStack runs the checks of Create on a request, routing included, and reports the
+// pod it would create, its resource accounting and what the server changed. Invalid
+// requests are reported as violations, not as an error.
+func (s *Service) ValidateStack(ctx context.Context, in CreateInput) (DryRunResult, error) {
+	valid, pool, err := s.prepareCreate(&in)
+	if err == nil {
+		_, _, err = s.routeCreate(ctx, in, valid, pool)
+	}
+
+	if err != nil {
+		if !errors.Is(err, ErrInvalidInput) && !errors.Is(err, ErrPodSpecInvalid) {
+			return DryRunResult{}, err
+		}
+
+		return DryRunResult{
+			TargetPorts: []PortSpec{},
+			Changes:     []FieldChange{},
+			Violations:  Violations(err),
+		}, nil
+	}
+
+	provisioned, err := s.dryRunPodSpec(in, valid)
+	if err != nil {
+		return DryRunResult{}, err
+	}
+
+	return DryRunResult{
+		Valid:                   true,
+		SanitizedYAML:           provisioned,
+		RequestedMilli:          valid.RequestedMilli,
+		RequestedBytes:          valid.RequestedBytes,
+		RequestedEphemeralBytes: valid.RequestedEphemeralBytes,
+		TargetPorts:             valid.TargetPorts,
+		NodePool:                in.NodePool,
+		Changes:                 podSpecChanges(in.PodSpecYML, provisioned),
+		Violations:              []Violation{},
+	}, nil
+}
+
+// dryRunPodSpec applies what CreatePodAndService changes on top of the sanitized pod
+// spec: names, labels, node pool, placement, priority class and ConfigMap references.
+func (s *Service) dryRunPodSpec(in CreateInput, valid ValidationResult) (string, error) {
+	var pod corev1.Pod
+	if err := sigsyaml.Unmarshal([]byte(valid.SanitizedYAML), &pod); err != nil {
+		return "", fmt.Errorf("decode pod spec: %w", err)
+	}
+
+	namespace, namespaceLabels := s.stackNamespace(in.OwnerID, dryRunStackID)
+	var pool config.NodePool
+	for _, candidate := range nodePoolsFor(s.cfg) {
+		if candidate.Name == in.NodePool {
+			pool = candidate
+			break
+		}
+	}
+
+	prepareStackPod(&pod, ProvisionRequest{
+		Namespace: namespace,
+		StackID:   dryRunStackID,
+		OwnerID:   in.OwnerID,
+		Placement: s.placementStrategy(valid.Placement),
+		NodePool:  in.NodePool,
+
+		PriorityClassName: s.priorityClassName(in.Priority),
+		NamespaceLabels:   namespaceLabels,
+		ConfigMaps:        valid.ConfigMaps,
+	}, pool)
+
+	out, err := sigsyaml.Marshal(&pod)
+	if err != nil {
+		return "", fmt.Errorf("encode pod spec: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// podSpecChanges lists the fields that differ between the submitted and the sanitized pod
+// spec, down to the deepest changed field.
+func podSpecChanges(raw, sanitized string) []FieldChange {
+	changes := []FieldChange{}
+
+	var before, after map[string]any
+	if err := decodePodSpec(raw, &before); err != nil {
+		return changes
+	}
+
+	if err := decodePodSpec(sanitized, &after); err != nil {
+		return changes
+	}
+
+	diffFields("", before, after, &changes)
+	return changes
+}
+
+// decodePodSpec decodes the Pod document of a pod spec.
+func decodePodSpec(spec string, out *map[string]any) error {
+	docs, err := manifestDocuments(spec)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		if kind, err := documentKind(doc); err == nil && strings.EqualFold(kind, "Pod") {
+			return json.Unmarshal(doc, out)
+		}
+	}
+
+	return errors.New("no pod document")
+}
+
+func diffFields(path string, before, after any, out *[]FieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if beforeIsMap && afterIsMap {
+		keys := slices.Collect(maps.Keys(beforeMap))
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			diffFields(joinFieldPath(path, key), beforeMap[key], afterMap[key], out)
+		}
+
+		return
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList {
+		for i := range max(len(beforeList), len(afterList)) {
+			var b, a any
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+
+			diffFields(fmt.Sprintf("%s[%d]", path, i), b, a, out)
+		}
+
+		return
+	}
+
+	beforeJSON, afterJSON := fieldJSON(before), fieldJSON(after)
+	if beforeJSON == afterJSON {
+		return
+	}
+
+	// Marshalling adds empty fields such as status and creationTimestamp.
+	if beforeJSON == "" && (afterJSON == "null" || afterJSON == "{}") {
+		return
+	}
+
+	*out = append(*out, FieldChange{Path: path, Before: beforeJSON, After: afterJSON})
+}
+
+func joinFieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func fieldJSON(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(raw)
+}
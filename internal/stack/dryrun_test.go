@@ -0,0 +1,171 @@
+package stack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"smctf/internal/config"
+)
+
+func TestValidateStackReportsChanges(t *testing.T) {
+	svc, repo, k8s := newTestService(nil)
+
+	result, err := svc.ValidateStack(context.Background(), CreateInput{
+		PodSpecYML: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: dry-run
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "250m"
+          memory: "128Mi"
+`,
+		TargetPorts: []PortSpec{{ContainerPort: 8080, Protocol: "tcp"}},
+	})
+	if err != nil {
+		t.Fatalf("validate stack: %v", err)
+	}
+
+	if !result.Valid || result.RequestedMilli != 250 || result.RequestedBytes != 128<<20 || len(result.Violations) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if result.TargetPorts[0].Protocol != "TCP" {
+		t.Fatalf("expected normalized target port, got %+v", result.TargetPorts)
+	}
+
+	changes := make(map[string]FieldChange, len(result.Changes))
+	for _, change := range result.Changes {
+		changes[change.Path] = change
+	}
+
+	if c := changes["spec.restartPolicy"]; c.Before != "" || c.After != `"Never"` {
+		t.Fatalf("expected restartPolicy change, got %+v", result.Changes)
+	}
+
+	if c := changes["spec.containers[0].resources.requests"]; c.After != `{"cpu":"250m","memory":"128Mi"}` {
+		t.Fatalf("expected resources change, got %+v", result.Changes)
+	}
+
+	if c := changes["spec.containers[0].securityContext"]; c.After == "" {
+		t.Fatalf("expected securityContext change, got %+v", result.Changes)
+	}
+
+	if _, ok := changes["status"]; ok {
+		t.Fatalf("expected empty status to be left out, got %+v", result.Changes)
+	}
+
+	if stacks, _ := repo.ListAll(context.Background()); len(stacks) != 0 || len(k8s.pods) != 0 {
+		t.Fatalf("expected nothing to be provisioned")
+	}
+}
+
+func TestValidateStackReportsViolations(t *testing.T) {
+	svc, _, _ := newTestService(nil)
+
+	in := testCreateInput(CreateInput{OwnerID: "team-a", Queue: true})
+	in.PodSpecYML = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: bad
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 1337
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+  volumes:
+    - name: host
+      hostPath:
+        path: /
+`
+	result, err := svc.ValidateStack(context.Background(), in)
+	if err != nil {
+		t.Fatalf("validate stack: %v", err)
+	}
+
+	if result.Valid || len(result.Violations) != 1 || result.Violations[0].Field != "spec.volumes[0]" {
+		t.Fatalf("expected hostPath violation, got %+v", result)
+	}
+}
+
+func TestValidateStackAppliesProvisioningRewrites(t *testing.T) {
+	svc, _ := newNodePoolTestService()
+	preemptionTestConfig(&svc.cfg)
+
+	result, err := svc.ValidateStack(context.Background(), testCreateInput(CreateInput{OwnerID: "team-a", NodePool: "arm64"}))
+	if err != nil {
+		t.Fatalf("validate stack: %v", err)
+	}
+
+	changes := make(map[string]FieldChange, len(result.Changes))
+	for _, change := range result.Changes {
+		changes[change.Path] = change
+	}
+
+	if c := changes["spec.nodeSelector"]; !result.Valid || c.After != `{"kubernetes.io/arch":"arm64"}` {
+		t.Fatalf("expected the node pool selector, got %+v", result)
+	}
+
+	if c := changes["spec.priorityClassName"]; c.After != `"smctf-player"` {
+		t.Fatalf("expected the default priority class, got %+v", result.Changes)
+	}
+
+	if !strings.Contains(result.SanitizedYAML, "priorityClassName: smctf-player") {
+		t.Fatalf("expected the sanitized pod spec to carry the rewrites, got %s", result.SanitizedYAML)
+	}
+}
+
+func TestValidateStackRoutesRequest(t *testing.T) {
+	svc, _, a, b := newClusterTestService(config.ClusterRoutingRegion)
+	ctx := context.Background()
+
+	pinned := strings.Replace(testPodSpec, "  name: p\n", "  name: p\n  annotations:\n    smctf.io/cluster: eks-c\n", 1)
+	tests := []struct {
+		name  string
+		in    CreateInput
+		field string
+	}{
+		{"no region", CreateInput{}, "region"},
+		{"unknown region", CreateInput{Region: "eu-west-1"}, "region"},
+		{"unknown pin", CreateInput{PodSpecYML: pinned, Region: "us-east-1"}, "metadata.annotations[smctf.io/cluster]"},
+		{
+			"node_port outside the cluster's pool",
+			CreateInput{Region: "us-east-1", TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", NodePort: 30005}}},
+			"target_port[0].node_port",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.ValidateStack(ctx, testCreateInput(tt.in))
+			if err != nil {
+				t.Fatalf("validate stack: %v", err)
+			}
+
+			if result.Valid || len(result.Violations) != 1 || result.Violations[0].Field != tt.field {
+				t.Fatalf("expected a %s violation, got %+v", tt.field, result)
+			}
+		})
+	}
+
+	if result, err := svc.ValidateStack(ctx, testCreateInput(CreateInput{Region: "us-east-1"})); err != nil || !result.Valid {
+		t.Fatalf("expected a routable request to be valid, got %+v / %v", result, err)
+	}
+
+	if len(a.pods) != 0 || len(b.pods) != 0 {
+		t.Fatalf("expected nothing to be provisioned")
+	}
+}
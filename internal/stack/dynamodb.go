@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"maps"
 	"math/rand"
+	"net/url"
 	"sort"
 	"strconv"
 	"sync"
@@ -36,16 +37,18 @@ type DynamoRepository struct {
 	table          string
 	consistentRead bool
 	portLockTTL    time.Duration
+	portCooldown   time.Duration
 	rand           *rand.Rand
 	randMu         sync.Mutex
 }
 
-func NewDynamoRepository(client *dynamodb.Client, table string, consistentRead bool, portLockTTL time.Duration) *DynamoRepository {
+func NewDynamoRepository(client *dynamodb.Client, table string, consistentRead bool, portLockTTL, portCooldown time.Duration) *DynamoRepository {
 	return &DynamoRepository{
 		client:         client,
 		table:          table,
 		consistentRead: consistentRead,
 		portLockTTL:    portLockTTL,
+		portCooldown:   portCooldown,
 		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
@@ -72,8 +75,8 @@ func (r *DynamoRepository) Create(ctx context.Context, st Stack) error {
 		items = append(items, ddtypes.TransactWriteItem{Update: &ddtypes.Update{
 			TableName:                 &r.table,
 			Key:                       keyPort,
-			UpdateExpression:          strPtr("SET stack_id = :sid, updated_at = :now"),
-			ConditionExpression:       strPtr("attribute_exists(pk) AND attribute_exists(sk) AND (attribute_not_exists(stack_id) OR stack_id = :empty)"),
+			UpdateExpression:          strPtr("SET stack_id = :sid, updated_at = :now REMOVE reused_cooling_until, reused_cooling_owner"),
+			ConditionExpression:       strPtr("attribute_exists(pk) AND attribute_exists(sk) AND (attribute_not_exists(stack_id) OR stack_id = :empty) AND attribute_not_exists(cooling_until)"),
 			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":sid": avS(st.StackID), ":now": avS(now), ":empty": avS("")},
 		}})
 	}
@@ -132,12 +135,55 @@ func (r *DynamoRepository) Delete(ctx context.Context, stackID string) (Stack, b
 			ConditionExpression: strPtr("attribute_exists(pk) AND attribute_exists(sk)"),
 		}},
 	}
-	for _, p := range st.Ports {
-		items = append(items, ddtypes.TransactWriteItem{Delete: &ddtypes.Delete{
-			TableName: &r.table,
-			Key:       map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(p.NodePort))},
-		}})
+	items = append(items, r.releaseStackPortItems(st.OwnerID, st.Ports)...)
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	if err != nil {
+		if txConditionFailedAt(err, 0) {
+			return Stack{}, false, nil
+		}
+		return Stack{}, false, err
+	}
+
+	return st, true, nil
+}
+
+// MarkPreempted turns a stack into a port-less preempted record and frees its ports in
+// the same transaction. The record stays until its TTL so callers can see why it ended.
+func (r *DynamoRepository) MarkPreempted(ctx context.Context, stackID, preemptedBy string) (Stack, bool, error) {
+	st, ok, err := r.Get(ctx, stackID)
+	if err != nil {
+		return Stack{}, false, err
+	}
+
+	if !ok || st.Status == StatusPreempted {
+		return Stack{}, false, nil
+	}
+
+	released := st.Ports
+	st.Status = StatusPreempted
+	st.PreemptedBy = preemptedBy
+	st.Ports = nil
+	st.UpdatedAt = time.Now().UTC()
+
+	item := stackToItem(st)
+	item[ddbPK] = avS(stackMetaPK(st.StackID))
+	item[ddbSK] = avS("META")
+	item["item_type"] = avS("stack_by_id")
+
+	items := []ddtypes.TransactWriteItem{
+		{Put: &ddtypes.Put{
+			TableName:                 &r.table,
+			Item:                      item,
+			ConditionExpression:       strPtr("attribute_exists(pk) AND attribute_exists(sk) AND #status <> :preempted"),
+			ExpressionAttributeNames:  map[string]string{"#status": "status"},
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":preempted": avS(string(StatusPreempted))},
+		}},
 	}
+	items = append(items, r.releaseStackPortItems(st.OwnerID, released)...)
 
 	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
 		TransactItems: items,
@@ -251,116 +297,43 @@ func (r *DynamoRepository) ListAll(ctx context.Context) ([]Stack, error) {
 	return out, nil
 }
 
-func (r *DynamoRepository) ReserveNodePort(ctx context.Context, min, max int) (int, error) {
-	total := max - min + 1
-	if total <= 0 {
-		return 0, ErrNoAvailableNodePort
+func (r *DynamoRepository) GetStickyNodePorts(ctx context.Context, ownerID, challengeID string) ([]PortMapping, error) {
+	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &r.table,
+		ConsistentRead: boolPtr(r.consistentRead),
+		Key: map[string]ddtypes.AttributeValue{
+			ddbPK: avS(stickyPK(ownerID, challengeID)),
+			ddbSK: avS("META"),
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	start := r.randomInt(total)
-	now := nowRFC3339()
-	nowUnix := time.Now().UTC().Unix()
-	staleBefore := nowUnix - int64(r.portLockTTL.Seconds())
-	for i := range total {
-		port := min + ((start + i) % total)
-		_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
-			TableName: &r.table,
-			Item: map[string]ddtypes.AttributeValue{
-				ddbPK:        avS("PORTS"),
-				ddbSK:        avS(portSK(port)),
-				"item_type":  avS("port_lock"),
-				"port":       avN(strconv.Itoa(port)),
-				"created_at": avS(now),
-				"locked_at":  avN(strconv.FormatInt(nowUnix, 10)),
-				"stack_id":   avS(""),
-			},
-			ConditionExpression: strPtr("attribute_not_exists(pk) AND attribute_not_exists(sk)"),
-		})
-
-		if err == nil {
-			return port, nil
-		}
-
-		var condErr *ddtypes.ConditionalCheckFailedException
-		if errors.As(err, &condErr) {
-			_, reclaimErr := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-				TableName: &r.table,
-				Key: map[string]ddtypes.AttributeValue{
-					ddbPK: avS("PORTS"),
-					ddbSK: avS(portSK(port)),
-				},
-				UpdateExpression:    strPtr("SET created_at = :now, locked_at = :locked_at"),
-				ConditionExpression: strPtr("(attribute_not_exists(stack_id) OR stack_id = :empty) AND (attribute_not_exists(locked_at) OR locked_at < :stale_before)"),
-				ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
-					":now":          avS(now),
-					":locked_at":    avN(strconv.FormatInt(nowUnix, 10)),
-					":empty":        avS(""),
-					":stale_before": avN(strconv.FormatInt(staleBefore, 10)),
-				},
-			})
-
-			if reclaimErr == nil {
-				return port, nil
-			}
-
-			continue
-		}
-
-		return 0, err
+	if len(resp.Item) == 0 {
+		return nil, nil
 	}
 
-	return 0, ErrNoAvailableNodePort
+	return attrPortMappings(resp.Item, "ports")
 }
 
-func (r *DynamoRepository) ReleaseNodePort(ctx context.Context, port int) error {
-	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+func (r *DynamoRepository) SaveStickyNodePorts(ctx context.Context, ownerID, challengeID string, ports []PortMapping) error {
+	_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: &r.table,
-		Key: map[string]ddtypes.AttributeValue{
-			ddbPK: avS("PORTS"),
-			ddbSK: avS(portSK(port)),
+		Item: map[string]ddtypes.AttributeValue{
+			ddbPK:          avS(stickyPK(ownerID, challengeID)),
+			ddbSK:          avS("META"),
+			"item_type":    avS("sticky_ports"),
+			"owner_id":     avS(ownerID),
+			"challenge_id": avS(challengeID),
+			"ports":        portMappingsToAttr(ports),
+			"updated_at":   avS(nowRFC3339()),
 		},
-		ConditionExpression:       strPtr("attribute_not_exists(stack_id) OR stack_id = :empty"),
-		ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":empty": avS("")},
 	})
 
-	var condErr *ddtypes.ConditionalCheckFailedException
-	if errors.As(err, &condErr) {
-		return nil
-	}
-
 	return err
 }
 
-func (r *DynamoRepository) UsedNodePortCount(ctx context.Context) (int, error) {
-	total := 0
-	var startKey map[string]ddtypes.AttributeValue
-
-	for {
-		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
-			TableName:              &r.table,
-			KeyConditionExpression: strPtr("pk = :pk"),
-			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
-				":pk": avS("PORTS"),
-			},
-			Select:            ddtypes.SelectCount,
-			ExclusiveStartKey: startKey,
-		})
-
-		if err != nil {
-			return 0, err
-		}
-
-		total += int(resp.Count)
-		if len(resp.LastEvaluatedKey) == 0 {
-			break
-		}
-
-		startKey = resp.LastEvaluatedKey
-	}
-
-	return total, nil
-}
-
 func (r *DynamoRepository) UpdateStatus(ctx context.Context, stackID string, status Status, nodeID string) error {
 	now := nowRFC3339()
 	values := map[string]ddtypes.AttributeValue{
@@ -446,10 +419,46 @@ func stackToItem(st Stack) map[string]ddtypes.AttributeValue {
 		"requested_memory_bytes": avN(strconv.FormatInt(st.RequestedBytes, 10)),
 	}
 
+	if st.RequestedEphemeralBytes > 0 {
+		item["requested_ephemeral_storage_bytes"] = avN(strconv.FormatInt(st.RequestedEphemeralBytes, 10))
+	}
+
 	if st.NodePublicIP != nil {
 		item["node_public_ip"] = avS(*st.NodePublicIP)
 	}
 
+	if st.OwnerID != "" {
+		item["owner_id"] = avS(st.OwnerID)
+	}
+
+	if st.ChallengeID != "" {
+		item["challenge_id"] = avS(st.ChallengeID)
+	}
+
+	if st.PortPool != "" {
+		item["port_pool"] = avS(st.PortPool)
+	}
+
+	if st.Placement != "" {
+		item["placement"] = avS(st.Placement)
+	}
+
+	if st.Priority != "" {
+		item["priority"] = avS(st.Priority)
+	}
+
+	if st.PreemptedBy != "" {
+		item["preempted_by"] = avS(st.PreemptedBy)
+	}
+
+	if st.ClusterID != "" {
+		item["cluster_id"] = avS(st.ClusterID)
+	}
+
+	if st.NodePool != "" {
+		item["node_pool"] = avS(st.NodePool)
+	}
+
 	return item
 }
 
@@ -485,23 +494,41 @@ func stackFromItem(item map[string]ddtypes.AttributeValue) (Stack, error) {
 
 	cpuMilli, _ := attrInt64(item, "requested_cpu_milli")
 	memBytes, _ := attrInt64(item, "requested_memory_bytes")
+	ephemeralBytes, _ := attrInt64(item, "requested_ephemeral_storage_bytes")
+	ownerID, _ := attrString(item, "owner_id")
+	challengeID, _ := attrString(item, "challenge_id")
+	portPool, _ := attrString(item, "port_pool")
+	placement, _ := attrString(item, "placement")
+	priority, _ := attrString(item, "priority")
+	preemptedBy, _ := attrString(item, "preempted_by")
+	clusterID, _ := attrString(item, "cluster_id")
+	nodePool, _ := attrString(item, "node_pool")
 
 	return Stack{
-		StackID:        stackID,
-		PodID:          podID,
-		Namespace:      namespace,
-		NodeID:         nodeID,
-		NodePublicIP:   nodePublicIP,
-		PodSpecYAML:    podSpec,
-		TargetPorts:    targetPorts,
-		Ports:          portMappings,
-		ServiceName:    serviceName,
-		Status:         Status(statusStr),
-		TTLExpiresAt:   ttlAt,
-		CreatedAt:      createdAt,
-		UpdatedAt:      updatedAt,
-		RequestedMilli: cpuMilli,
-		RequestedBytes: memBytes,
+		StackID:                 stackID,
+		PodID:                   podID,
+		Namespace:               namespace,
+		NodeID:                  nodeID,
+		NodePublicIP:            nodePublicIP,
+		PodSpecYAML:             podSpec,
+		TargetPorts:             targetPorts,
+		Ports:                   portMappings,
+		ServiceName:             serviceName,
+		Status:                  Status(statusStr),
+		TTLExpiresAt:            ttlAt,
+		CreatedAt:               createdAt,
+		UpdatedAt:               updatedAt,
+		RequestedMilli:          cpuMilli,
+		RequestedBytes:          memBytes,
+		RequestedEphemeralBytes: ephemeralBytes,
+		OwnerID:                 ownerID,
+		ChallengeID:             challengeID,
+		PortPool:                portPool,
+		Placement:               placement,
+		Priority:                priority,
+		PreemptedBy:             preemptedBy,
+		ClusterID:               clusterID,
+		NodePool:                nodePool,
 	}, nil
 }
 
@@ -594,7 +621,14 @@ func attrPortSpecs(item map[string]ddtypes.AttributeValue, key string) ([]PortSp
 		}
 
 		proto, _ := attrString(m.Value, "protocol")
-		out = append(out, PortSpec{ContainerPort: port, Protocol: proto})
+		scheme, _ := attrString(m.Value, "scheme")
+		name, _ := attrString(m.Value, "name")
+		nodePort, _ := attrInt(m.Value, "node_port")
+		sticky := false
+		if b, ok := m.Value["sticky"].(*ddtypes.AttributeValueMemberBOOL); ok {
+			sticky = b.Value
+		}
+		out = append(out, PortSpec{ContainerPort: port, Protocol: proto, Scheme: scheme, Name: name, NodePort: nodePort, Sticky: sticky})
 	}
 
 	return out, nil
@@ -629,7 +663,9 @@ func attrPortMappings(item map[string]ddtypes.AttributeValue, key string) ([]Por
 		}
 
 		proto, _ := attrString(m.Value, "protocol")
-		out = append(out, PortMapping{ContainerPort: port, Protocol: proto, NodePort: nodePort})
+		scheme, _ := attrString(m.Value, "scheme")
+		name, _ := attrString(m.Value, "name")
+		out = append(out, PortMapping{ContainerPort: port, Protocol: proto, NodePort: nodePort, Scheme: scheme, Name: name})
 	}
 
 	return out, nil
@@ -638,10 +674,18 @@ func attrPortMappings(item map[string]ddtypes.AttributeValue, key string) ([]Por
 func portSpecsToAttr(ports []PortSpec) ddtypes.AttributeValue {
 	list := make([]ddtypes.AttributeValue, 0, len(ports))
 	for _, p := range ports {
-		list = append(list, &ddtypes.AttributeValueMemberM{Value: map[string]ddtypes.AttributeValue{
+		entry := map[string]ddtypes.AttributeValue{
 			"container_port": avN(strconv.Itoa(p.ContainerPort)),
 			"protocol":       avS(p.Protocol),
-		}})
+		}
+		putPortLabels(entry, p.Scheme, p.Name)
+		if p.NodePort != 0 {
+			entry["node_port"] = avN(strconv.Itoa(p.NodePort))
+		}
+		if p.Sticky {
+			entry["sticky"] = &ddtypes.AttributeValueMemberBOOL{Value: true}
+		}
+		list = append(list, &ddtypes.AttributeValueMemberM{Value: entry})
 	}
 
 	return &ddtypes.AttributeValueMemberL{Value: list}
@@ -650,16 +694,28 @@ func portSpecsToAttr(ports []PortSpec) ddtypes.AttributeValue {
 func portMappingsToAttr(ports []PortMapping) ddtypes.AttributeValue {
 	list := make([]ddtypes.AttributeValue, 0, len(ports))
 	for _, p := range ports {
-		list = append(list, &ddtypes.AttributeValueMemberM{Value: map[string]ddtypes.AttributeValue{
+		entry := map[string]ddtypes.AttributeValue{
 			"container_port": avN(strconv.Itoa(p.ContainerPort)),
 			"protocol":       avS(p.Protocol),
 			"node_port":      avN(strconv.Itoa(p.NodePort)),
-		}})
+		}
+		putPortLabels(entry, p.Scheme, p.Name)
+		list = append(list, &ddtypes.AttributeValueMemberM{Value: entry})
 	}
 
 	return &ddtypes.AttributeValueMemberL{Value: list}
 }
 
+func putPortLabels(entry map[string]ddtypes.AttributeValue, scheme, name string) {
+	if scheme != "" {
+		entry["scheme"] = avS(scheme)
+	}
+
+	if name != "" {
+		entry["name"] = avS(name)
+	}
+}
+
 func copyItem(src map[string]ddtypes.AttributeValue) map[string]ddtypes.AttributeValue {
 	out := make(map[string]ddtypes.AttributeValue, len(src))
 	maps.Copy(out, src)
@@ -674,6 +730,11 @@ func stackSK(stackID string) string     { return "STACK#" + stackID }
 func portSK(port int) string            { return "PORT#" + strconv.Itoa(port) }
 func jobPK(jobID string) string         { return "JOB#" + jobID }
 
+// stickyPK escapes the IDs, so a # inside one cannot shift the separator.
+func stickyPK(ownerID, challengeID string) string {
+	return "STICKY#" + url.PathEscape(ownerID) + "#" + url.PathEscape(challengeID)
+}
+
 func avS(v string) ddtypes.AttributeValue { return &ddtypes.AttributeValueMemberS{Value: v} }
 func avN(v string) ddtypes.AttributeValue { return &ddtypes.AttributeValueMemberN{Value: v} }
 func (r *DynamoRepository) randomInt(limit int) int {
@@ -0,0 +1,143 @@
+package stack
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Rejected demand shares the DEMAND partition so the replica reporting demand reads the
+// rejections of every replica with one query.
+
+const ddbDemandPK = "DEMAND"
+
+func (r *DynamoRepository) SaveRejectedDemand(ctx context.Context, demand RejectedDemand) error {
+	_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &r.table,
+		Item:      rejectedDemandToItem(demand),
+	})
+
+	return err
+}
+
+func (r *DynamoRepository) GetRejectedDemand(ctx context.Context, key string) (RejectedDemand, bool, error) {
+	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &r.table,
+		ConsistentRead: boolPtr(r.consistentRead),
+		Key: map[string]ddtypes.AttributeValue{
+			ddbPK: avS(ddbDemandPK),
+			ddbSK: avS(rejectedDemandSK(key)),
+		},
+	})
+	if err != nil {
+		return RejectedDemand{}, false, err
+	}
+
+	if len(resp.Item) == 0 {
+		return RejectedDemand{}, false, nil
+	}
+
+	demand, err := rejectedDemandFromItem(resp.Item)
+	if err != nil {
+		return RejectedDemand{}, false, err
+	}
+
+	return demand, true, nil
+}
+
+func (r *DynamoRepository) ListRejectedDemand(ctx context.Context) ([]RejectedDemand, error) {
+	out := make([]RejectedDemand, 0)
+	var startKey map[string]ddtypes.AttributeValue
+
+	for {
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &r.table,
+			ConsistentRead:         boolPtr(r.consistentRead),
+			KeyConditionExpression: strPtr("pk = :pk"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":pk": avS(ddbDemandPK),
+			},
+			ExclusiveStartKey: startKey,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			demand, err := rejectedDemandFromItem(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, demand)
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		startKey = resp.LastEvaluatedKey
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (r *DynamoRepository) DeleteRejectedDemand(ctx context.Context, key string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &r.table,
+		Key: map[string]ddtypes.AttributeValue{
+			ddbPK: avS(ddbDemandPK),
+			ddbSK: avS(rejectedDemandSK(key)),
+		},
+	})
+
+	return err
+}
+
+func rejectedDemandToItem(demand RejectedDemand) map[string]ddtypes.AttributeValue {
+	return map[string]ddtypes.AttributeValue{
+		ddbPK:                    avS(ddbDemandPK),
+		ddbSK:                    avS(rejectedDemandSK(demand.Key)),
+		"item_type":              avS("rejected_demand"),
+		"demand_key":             avS(demand.Key),
+		"node_pool":              avS(demand.NodePool),
+		"region":                 avS(demand.Region),
+		"port_pool":              avS(demand.PortPool),
+		"requested_cpu_milli":    avN(strconv.FormatInt(demand.RequestedMilli, 10)),
+		"requested_memory_bytes": avN(strconv.FormatInt(demand.RequestedBytes, 10)),
+		"rejected_at":            avS(demand.RejectedAt.UTC().Format(time.RFC3339Nano)),
+	}
+}
+
+func rejectedDemandFromItem(item map[string]ddtypes.AttributeValue) (RejectedDemand, error) {
+	key, err := attrString(item, "demand_key")
+	if err != nil {
+		return RejectedDemand{}, err
+	}
+	nodePool, _ := attrString(item, "node_pool")
+	region, _ := attrString(item, "region")
+	portPool, _ := attrString(item, "port_pool")
+	cpuMilli, _ := attrInt64(item, "requested_cpu_milli")
+	memBytes, _ := attrInt64(item, "requested_memory_bytes")
+	rejectedAt, err := attrTime(item, "rejected_at")
+	if err != nil {
+		return RejectedDemand{}, err
+	}
+
+	return RejectedDemand{
+		Key:            key,
+		NodePool:       nodePool,
+		Region:         region,
+		PortPool:       portPool,
+		RequestedMilli: cpuMilli,
+		RequestedBytes: memBytes,
+		RejectedAt:     rejectedAt,
+	}, nil
+}
+
+func rejectedDemandSK(key string) string { return "REJECTED#" + key }
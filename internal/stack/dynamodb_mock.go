@@ -14,21 +14,57 @@ type RepositoryClientAPI interface {
 	Get(ctx context.Context, stackID string) (Stack, bool, error)
 	Delete(ctx context.Context, stackID string) (Stack, bool, error)
 	ListAll(ctx context.Context) ([]Stack, error)
-	ReserveNodePort(ctx context.Context, min, max int) (int, error)
+	ReserveNodePorts(ctx context.Context, ownerID string, min, max int, want []int) ([]int, error)
 	ReleaseNodePort(ctx context.Context, port int) error
-	UsedNodePortCount(ctx context.Context) (int, error)
+	ReclaimNodePorts(ctx context.Context, min, max int) (int, error)
+	UsedNodePortCount(ctx context.Context, min, max int) (int, error)
 	UpdateStatus(ctx context.Context, stackID string, status Status, nodeID string) error
+	MarkPreempted(ctx context.Context, stackID, preemptedBy string) (Stack, bool, error)
 	CreateBatchDeleteJob(ctx context.Context, job BatchDeleteJob) error
 	UpdateBatchDeleteJob(ctx context.Context, job BatchDeleteJob) error
 	GetBatchDeleteJob(ctx context.Context, jobID string) (BatchDeleteJob, bool, error)
+	GetStickyNodePorts(ctx context.Context, ownerID, challengeID string) ([]PortMapping, error)
+	SaveStickyNodePorts(ctx context.Context, ownerID, challengeID string, ports []PortMapping) error
+	ListPortLocks(ctx context.Context) ([]PortLock, error)
+	LockNodePorts(ctx context.Context, stackID string, ports []int) error
+	DeletePortLock(ctx context.Context, lock PortLock) error
+	CreateQueueTicket(ctx context.Context, ticket QueueTicket) error
+	GetQueueTicket(ctx context.Context, ticketID string) (QueueTicket, bool, error)
+	ListQueueTickets(ctx context.Context) ([]QueueTicket, error)
+	UpdateQueueTicket(ctx context.Context, ticket QueueTicket, from TicketStatus) error
+	DeleteQueueTicket(ctx context.Context, ticketID string) error
+	SaveRejectedDemand(ctx context.Context, demand RejectedDemand) error
+	GetRejectedDemand(ctx context.Context, key string) (RejectedDemand, bool, error)
+	ListRejectedDemand(ctx context.Context) ([]RejectedDemand, error)
+	DeleteRejectedDemand(ctx context.Context, key string) error
+}
+
+type coolingPort struct {
+	until   time.Time
+	ownerID string
 }
 
 type InMemoryRepository struct {
-	mu     sync.RWMutex
-	stacks map[string]Stack
-	ports  map[int]string
-	jobs   map[string]BatchDeleteJob
-	rand   *rand.Rand
+	mu           sync.RWMutex
+	stacks       map[string]Stack
+	ports        map[int]string
+	lockedAt     map[int]time.Time
+	used         portBitmap
+	cooling      map[int]coolingPort
+	portCooldown time.Duration
+	jobs         map[string]BatchDeleteJob
+	sticky       map[string][]PortMapping
+	tickets      map[string]QueueTicket
+	rand         *rand.Rand
+	now          func() time.Time
+	demand       map[string]RejectedDemand
+
+	// reusedCooling holds the cool-down of reserved ports that came out of cooling, so
+	// ReleaseNodePort can put them back if the create fails.
+	reusedCooling map[int]coolingPort
+	// portLockTTL is how long a reservation may stay without a stack before
+	// ReclaimNodePorts frees it; zero keeps such reservations.
+	portLockTTL time.Duration
 }
 
 func NewInMemoryRepository(seed int64) *InMemoryRepository {
@@ -37,10 +73,18 @@ func NewInMemoryRepository(seed int64) *InMemoryRepository {
 	}
 
 	return &InMemoryRepository{
-		stacks: make(map[string]Stack),
-		ports:  make(map[int]string),
-		jobs:   make(map[string]BatchDeleteJob),
-		rand:   rand.New(rand.NewSource(seed)),
+		stacks:   make(map[string]Stack),
+		ports:    make(map[int]string),
+		lockedAt: make(map[int]time.Time),
+		cooling:  make(map[int]coolingPort),
+		jobs:     make(map[string]BatchDeleteJob),
+		sticky:   make(map[string][]PortMapping),
+		tickets:  make(map[string]QueueTicket),
+		rand:     rand.New(rand.NewSource(seed)),
+		now:      time.Now,
+		demand:   make(map[string]RejectedDemand),
+
+		reusedCooling: make(map[int]coolingPort),
 	}
 }
 
@@ -65,6 +109,7 @@ func (r *InMemoryRepository) Create(_ context.Context, st Stack) error {
 	r.stacks[st.StackID] = st
 	for _, p := range st.Ports {
 		r.ports[p.NodePort] = st.StackID
+		delete(r.reusedCooling, p.NodePort)
 	}
 
 	return nil
@@ -88,10 +133,40 @@ func (r *InMemoryRepository) Delete(_ context.Context, stackID string) (Stack, b
 	}
 
 	delete(r.stacks, stackID)
+	r.releasePortsLocked(st)
+
+	return st, true, nil
+}
+
+func (r *InMemoryRepository) releasePortsLocked(st Stack) {
 	for _, p := range st.Ports {
 		delete(r.ports, p.NodePort)
+		delete(r.lockedAt, p.NodePort)
+		if r.portCooldown > 0 {
+			r.cooling[p.NodePort] = coolingPort{until: r.now().Add(r.portCooldown), ownerID: st.OwnerID}
+			continue
+		}
+
+		r.used.clear(p.NodePort)
+	}
+}
+
+func (r *InMemoryRepository) MarkPreempted(_ context.Context, stackID, preemptedBy string) (Stack, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, ok := r.stacks[stackID]
+	if !ok || st.Status == StatusPreempted {
+		return Stack{}, false, nil
 	}
 
+	r.releasePortsLocked(st)
+	st.Status = StatusPreempted
+	st.PreemptedBy = preemptedBy
+	st.Ports = nil
+	st.UpdatedAt = r.now().UTC()
+	r.stacks[stackID] = st
+
 	return st, true, nil
 }
 
@@ -111,25 +186,49 @@ func (r *InMemoryRepository) ListAll(_ context.Context) ([]Stack, error) {
 	return result, nil
 }
 
-func (r *InMemoryRepository) ReserveNodePort(_ context.Context, min, max int) (int, error) {
+func (r *InMemoryRepository) ReserveNodePort(ctx context.Context, min, max int) (int, error) {
+	ports, err := r.ReserveNodePorts(ctx, "", min, max, []int{0})
+	if err != nil {
+		return 0, err
+	}
+
+	return ports[0], nil
+}
+
+func (r *InMemoryRepository) ReserveNodePorts(_ context.Context, ownerID string, min, max int, want []int) ([]int, error) {
+	if err := validatePortReservation(min, max, want); err != nil {
+		return nil, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	total := max - min + 1
-	if total <= 0 {
-		return 0, ErrNoAvailableNodePort
+	r.reclaimCoolingLocked(min, max)
+
+	reusable := make(map[int]struct{})
+	for _, port := range want {
+		if c, ok := r.cooling[port]; ok && ownerID != "" && c.ownerID == ownerID {
+			reusable[port] = struct{}{}
+		}
 	}
 
-	start := min + r.rand.Intn(total)
-	for i := range total {
-		port := min + ((start - min + i) % total)
-		if _, exists := r.ports[port]; !exists {
-			r.ports[port] = ""
-			return port, nil
+	ports, ok := pickFreePorts(&r.used, min, max, r.rand.Intn(max-min+1), want, reusable)
+	if !ok {
+		return nil, ErrNoAvailableNodePort
+	}
+
+	now := r.now()
+	for _, port := range ports {
+		if _, ok := reusable[port]; ok {
+			r.reusedCooling[port] = r.cooling[port]
 		}
+		delete(r.cooling, port)
+		r.used.set(port)
+		r.ports[port] = ""
+		r.lockedAt[port] = now
 	}
 
-	return 0, ErrNoAvailableNodePort
+	return ports, nil
 }
 
 func (r *InMemoryRepository) ReleaseNodePort(_ context.Context, port int) error {
@@ -138,16 +237,71 @@ func (r *InMemoryRepository) ReleaseNodePort(_ context.Context, port int) error
 
 	if owner, exists := r.ports[port]; exists && owner == "" {
 		delete(r.ports, port)
+		delete(r.lockedAt, port)
+		if c, ok := r.reusedCooling[port]; ok {
+			delete(r.reusedCooling, port)
+			r.cooling[port] = c
+			return nil
+		}
+
+		r.used.clear(port)
 	}
 
 	return nil
 }
 
-func (r *InMemoryRepository) UsedNodePortCount(_ context.Context) (int, error) {
+// ReclaimNodePorts frees ports whose cool-down has passed, and ports that were reserved
+// but never attached to a stack within the port lock TTL, like DynamoRepository does.
+func (r *InMemoryRepository) ReclaimNodePorts(_ context.Context, min, max int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reclaimCoolingLocked(min, max) + r.reclaimStaleLocked(min, max), nil
+}
+
+func (r *InMemoryRepository) reclaimStaleLocked(min, max int) int {
+	if r.portLockTTL <= 0 {
+		return 0
+	}
+
+	staleBefore := r.now().Add(-r.portLockTTL)
+	reclaimed := 0
+	for port, stackID := range r.ports {
+		if port < min || port > max || stackID != "" || !r.lockedAt[port].Before(staleBefore) {
+			continue
+		}
+
+		delete(r.ports, port)
+		delete(r.lockedAt, port)
+		delete(r.reusedCooling, port)
+		r.used.clear(port)
+		reclaimed++
+	}
+
+	return reclaimed
+}
+
+func (r *InMemoryRepository) reclaimCoolingLocked(min, max int) int {
+	now := r.now()
+	reclaimed := 0
+	for port, c := range r.cooling {
+		if port < min || port > max || now.Before(c.until) {
+			continue
+		}
+
+		delete(r.cooling, port)
+		r.used.clear(port)
+		reclaimed++
+	}
+
+	return reclaimed
+}
+
+func (r *InMemoryRepository) UsedNodePortCount(_ context.Context, min, max int) (int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return len(r.ports), nil
+	return r.used.count(min, max), nil
 }
 
 func (r *InMemoryRepository) UpdateStatus(_ context.Context, stackID string, status Status, nodeID string) error {
@@ -201,3 +355,170 @@ func (r *InMemoryRepository) GetBatchDeleteJob(_ context.Context, jobID string)
 	job, ok := r.jobs[jobID]
 	return job, ok, nil
 }
+
+func (r *InMemoryRepository) GetStickyNodePorts(_ context.Context, ownerID, challengeID string) ([]PortMapping, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]PortMapping(nil), r.sticky[stickyPK(ownerID, challengeID)]...), nil
+}
+
+func (r *InMemoryRepository) SaveStickyNodePorts(_ context.Context, ownerID, challengeID string, ports []PortMapping) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sticky[stickyPK(ownerID, challengeID)] = append([]PortMapping(nil), ports...)
+	return nil
+}
+
+func (r *InMemoryRepository) ListPortLocks(_ context.Context) ([]PortLock, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locks := make([]PortLock, 0, len(r.ports)+len(r.cooling))
+	for port, stackID := range r.ports {
+		locks = append(locks, PortLock{Port: port, StackID: stackID, LockedAt: r.lockedAt[port]})
+	}
+
+	for port, c := range r.cooling {
+		locks = append(locks, PortLock{Port: port, CoolingUntil: c.until})
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Port < locks[j].Port })
+
+	return locks, nil
+}
+
+func (r *InMemoryRepository) LockNodePorts(_ context.Context, stackID string, ports []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, port := range ports {
+		if owner := r.ports[port]; owner != "" && owner != stackID {
+			return ErrNoAvailableNodePort
+		}
+	}
+
+	now := r.now()
+	for _, port := range ports {
+		delete(r.cooling, port)
+		r.used.set(port)
+		r.ports[port] = stackID
+		r.lockedAt[port] = now
+	}
+
+	return nil
+}
+
+func (r *InMemoryRepository) DeletePortLock(_ context.Context, lock PortLock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stackID, exists := r.ports[lock.Port]
+	if !exists || stackID != lock.StackID || !r.lockedAt[lock.Port].Equal(lock.LockedAt) {
+		return nil
+	}
+
+	delete(r.ports, lock.Port)
+	delete(r.lockedAt, lock.Port)
+	r.used.clear(lock.Port)
+
+	return nil
+}
+
+func (r *InMemoryRepository) CreateQueueTicket(_ context.Context, ticket QueueTicket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tickets[ticket.TicketID]; exists {
+		return fmt.Errorf("ticket id already exists")
+	}
+
+	r.tickets[ticket.TicketID] = ticket
+	return nil
+}
+
+func (r *InMemoryRepository) GetQueueTicket(_ context.Context, ticketID string) (QueueTicket, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ticket, ok := r.tickets[ticketID]
+	return ticket, ok, nil
+}
+
+func (r *InMemoryRepository) ListQueueTickets(_ context.Context) ([]QueueTicket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]QueueTicket, 0, len(r.tickets))
+	for _, ticket := range r.tickets {
+		result = append(result, ticket)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].TicketID < result[j].TicketID })
+
+	return result, nil
+}
+
+func (r *InMemoryRepository) UpdateQueueTicket(_ context.Context, ticket QueueTicket, from TicketStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.tickets[ticket.TicketID]
+	if !exists {
+		return ErrNotFound
+	}
+
+	if current.Status != from {
+		return ErrTicketClosed
+	}
+
+	r.tickets[ticket.TicketID] = ticket
+	return nil
+}
+
+func (r *InMemoryRepository) DeleteQueueTicket(_ context.Context, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tickets, ticketID)
+	return nil
+}
+
+func (r *InMemoryRepository) SaveRejectedDemand(_ context.Context, demand RejectedDemand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.demand[demand.Key] = demand
+	return nil
+}
+
+func (r *InMemoryRepository) GetRejectedDemand(_ context.Context, key string) (RejectedDemand, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	demand, ok := r.demand[key]
+	return demand, ok, nil
+}
+
+func (r *InMemoryRepository) ListRejectedDemand(_ context.Context) ([]RejectedDemand, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]RejectedDemand, 0, len(r.demand))
+	for _, demand := range r.demand {
+		result = append(result, demand)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	return result, nil
+}
+
+func (r *InMemoryRepository) DeleteRejectedDemand(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.demand, key)
+	return nil
+}
@@ -0,0 +1,596 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Port allocation state lives in two places that are always written in the same transaction:
+//   - PORTS / PORT#<n>: the lock item that ties a port to a stack (stack_id is empty while reserved
+//     or cooling down after its stack was deleted).
+//   - PORTBITMAP / SHARD#<n>: a number set of used ports per 1024-port shard, read in one query
+//     to pick free ports without probing them one by one. Cooling ports stay marked as used.
+
+const (
+	ddbPortBitmapPK        = "PORTBITMAP"
+	maxReservationAttempts = 5
+)
+
+func (r *DynamoRepository) ReserveNodePort(ctx context.Context, min, max int) (int, error) {
+	ports, err := r.ReserveNodePorts(ctx, "", min, max, []int{0})
+	if err != nil {
+		return 0, err
+	}
+
+	return ports[0], nil
+}
+
+func (r *DynamoRepository) ReserveNodePorts(ctx context.Context, ownerID string, min, max int, want []int) ([]int, error) {
+	if err := validatePortReservation(min, max, want); err != nil {
+		return nil, err
+	}
+
+	if len(want) == 0 {
+		return nil, nil
+	}
+
+	reclaimed := false
+	attempts := 0
+	defer func() {
+		if attempts > 0 {
+			portReservationAttempts.Observe(float64(attempts))
+		}
+	}()
+
+	for range maxReservationAttempts {
+		used, err := r.loadPortBitmap(ctx, min, max)
+		if err != nil {
+			return nil, err
+		}
+
+		reusable, err := r.reusableCoolingPorts(ctx, ownerID, used, min, max, want)
+		if err != nil {
+			return nil, err
+		}
+
+		ports, ok := pickFreePorts(used, min, max, r.randomInt(max-min+1), want, reusable)
+		if !ok {
+			if reclaimed {
+				return nil, ErrNoAvailableNodePort
+			}
+
+			n, err := r.ReclaimNodePorts(ctx, min, max)
+			if err != nil {
+				return nil, err
+			}
+
+			if n == 0 {
+				return nil, ErrNoAvailableNodePort
+			}
+
+			reclaimed = true
+			continue
+		}
+
+		attempts++
+		err = r.commitPortReservation(ctx, ownerID, ports, reusable)
+		if err == nil {
+			return ports, nil
+		}
+
+		var txErr *ddtypes.TransactionCanceledException
+		if !errors.As(err, &txErr) {
+			return nil, err
+		}
+		portReservationConflicts.Inc()
+
+		// A lock that exists without its bitmap bit (e.g. written before the bitmap
+		// existed) is added to the bitmap so the next attempt skips it.
+		for idx, port := range ports {
+			if _, reused := reusable[port]; reused {
+				continue
+			}
+
+			if txConditionFailedAt(err, idx) {
+				if healErr := r.markPortsUsed(ctx, []int{port}); healErr != nil {
+					slog.Warn("mark locked nodeport as used failed", slog.Int("node_port", port), slog.Any("error", healErr))
+				}
+			}
+		}
+	}
+
+	return nil, ErrNoAvailableNodePort
+}
+
+// reusableCoolingPorts returns the requested ports that are cooling down after a stack of
+// the same owner was deleted; the quarantine only protects against handing a port to
+// another owner.
+func (r *DynamoRepository) reusableCoolingPorts(ctx context.Context, ownerID string, used *portBitmap, min, max int, want []int) (map[int]struct{}, error) {
+	reusable := make(map[int]struct{})
+	if ownerID == "" {
+		return reusable, nil
+	}
+
+	for _, port := range want {
+		if port < min || port > max || !used.has(port) {
+			continue
+		}
+
+		resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      &r.table,
+			ConsistentRead: boolPtr(r.consistentRead),
+			Key:            map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(port))},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		stackID, _ := attrString(resp.Item, "stack_id")
+		coolingOwner, _ := attrString(resp.Item, "cooling_owner")
+		if _, cooling := resp.Item["cooling_until"]; cooling && stackID == "" && coolingOwner == ownerID {
+			reusable[port] = struct{}{}
+		}
+	}
+
+	return reusable, nil
+}
+
+func (r *DynamoRepository) commitPortReservation(ctx context.Context, ownerID string, ports []int, reusable map[int]struct{}) error {
+	now := nowRFC3339()
+	nowUnix := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+
+	items := make([]ddtypes.TransactWriteItem, 0, len(ports)*2)
+	fresh := make([]int, 0, len(ports))
+	for _, port := range ports {
+		if _, ok := reusable[port]; ok {
+			items = append(items, ddtypes.TransactWriteItem{Update: &ddtypes.Update{
+				TableName:           &r.table,
+				Key:                 map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(port))},
+				UpdateExpression:    strPtr("SET created_at = :now, locked_at = :locked_at, reused_cooling_until = cooling_until, reused_cooling_owner = cooling_owner REMOVE cooling_until, cooling_owner"),
+				ConditionExpression: strPtr("stack_id = :empty AND cooling_owner = :owner AND attribute_exists(cooling_until)"),
+				ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+					":now":       avS(now),
+					":locked_at": avN(nowUnix),
+					":empty":     avS(""),
+					":owner":     avS(ownerID),
+				},
+			}})
+			continue
+		}
+
+		fresh = append(fresh, port)
+		items = append(items, ddtypes.TransactWriteItem{Put: &ddtypes.Put{
+			TableName: &r.table,
+			Item: map[string]ddtypes.AttributeValue{
+				ddbPK:        avS("PORTS"),
+				ddbSK:        avS(portSK(port)),
+				"item_type":  avS("port_lock"),
+				"port":       avN(strconv.Itoa(port)),
+				"created_at": avS(now),
+				"locked_at":  avN(nowUnix),
+				"stack_id":   avS(""),
+			},
+			ConditionExpression: strPtr("attribute_not_exists(pk) AND attribute_not_exists(sk)"),
+		}})
+	}
+	items = append(items, r.portBitmapUpdates("ADD", fresh, true)...)
+	portReservationItems.Observe(float64(len(items)))
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	return err
+}
+
+// ReleaseNodePort frees a port reserved for a create that failed. A port that came out of
+// cooling goes back to cooling until its original cooling_until instead.
+func (r *DynamoRepository) ReleaseNodePort(ctx context.Context, port int) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &r.table,
+		Key:                       map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(port))},
+		UpdateExpression:          strPtr("SET cooling_until = reused_cooling_until, cooling_owner = reused_cooling_owner REMOVE reused_cooling_until, reused_cooling_owner"),
+		ConditionExpression:       strPtr("stack_id = :empty AND attribute_exists(reused_cooling_until)"),
+		ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":empty": avS("")},
+	})
+	if err == nil {
+		return nil
+	}
+
+	var condErr *ddtypes.ConditionalCheckFailedException
+	if !errors.As(err, &condErr) {
+		return err
+	}
+
+	items := []ddtypes.TransactWriteItem{
+		{Delete: &ddtypes.Delete{
+			TableName: &r.table,
+			Key: map[string]ddtypes.AttributeValue{
+				ddbPK: avS("PORTS"),
+				ddbSK: avS(portSK(port)),
+			},
+			ConditionExpression:       strPtr("(attribute_not_exists(stack_id) OR stack_id = :empty) AND attribute_not_exists(cooling_until)"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":empty": avS("")},
+		}},
+	}
+	items = append(items, r.portBitmapUpdates("DELETE", []int{port}, false)...)
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	if txConditionFailedAt(err, 0) {
+		return nil
+	}
+
+	return err
+}
+
+func (r *DynamoRepository) UsedNodePortCount(ctx context.Context, min, max int) (int, error) {
+	used, err := r.loadPortBitmap(ctx, min, max)
+	if err != nil {
+		return 0, err
+	}
+
+	return used.count(min, max), nil
+}
+
+func (r *DynamoRepository) loadPortBitmap(ctx context.Context, min, max int) (*portBitmap, error) {
+	used := &portBitmap{}
+	var startKey map[string]ddtypes.AttributeValue
+
+	for {
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &r.table,
+			ConsistentRead:         boolPtr(r.consistentRead),
+			KeyConditionExpression: strPtr("pk = :pk AND sk BETWEEN :from AND :to"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":pk":   avS(ddbPortBitmapPK),
+				":from": avS(portShardSK(portShard(min))),
+				":to":   avS(portShardSK(portShard(max))),
+			},
+			ExclusiveStartKey: startKey,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			set, ok := item["used"].(*ddtypes.AttributeValueMemberNS)
+			if !ok {
+				continue
+			}
+
+			for _, v := range set.Value {
+				port, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("port bitmap entry %q parse failed", v)
+				}
+				used.set(port)
+			}
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		startKey = resp.LastEvaluatedKey
+	}
+
+	return used, nil
+}
+
+func (r *DynamoRepository) markPortsUsed(ctx context.Context, ports []int) error {
+	for _, update := range r.portBitmapUpdates("ADD", ports, false) {
+		if _, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 update.Update.TableName,
+			Key:                       update.Update.Key,
+			UpdateExpression:          update.Update.UpdateExpression,
+			ExpressionAttributeValues: update.Update.ExpressionAttributeValues,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseStackPortItems frees the ports of a deleted stack. With a cool-down configured the
+// locks are kept, detached from the stack, until cooling_until has passed.
+func (r *DynamoRepository) releaseStackPortItems(ownerID string, ports []PortMapping) []ddtypes.TransactWriteItem {
+	items := make([]ddtypes.TransactWriteItem, 0, len(ports))
+	nodePorts := make([]int, 0, len(ports))
+
+	if r.portCooldown <= 0 {
+		for _, p := range ports {
+			items = append(items, ddtypes.TransactWriteItem{Delete: &ddtypes.Delete{
+				TableName: &r.table,
+				Key:       map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(p.NodePort))},
+			}})
+			nodePorts = append(nodePorts, p.NodePort)
+		}
+
+		return append(items, r.portBitmapUpdates("DELETE", nodePorts, false)...)
+	}
+
+	now := time.Now().UTC()
+	for _, p := range ports {
+		items = append(items, ddtypes.TransactWriteItem{Update: &ddtypes.Update{
+			TableName:        &r.table,
+			Key:              map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(p.NodePort))},
+			UpdateExpression: strPtr("SET stack_id = :empty, #port = :port, locked_at = :now, cooling_until = :until, cooling_owner = :owner, updated_at = :updated"),
+			ExpressionAttributeNames: map[string]string{
+				"#port": "port",
+			},
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":empty":   avS(""),
+				":port":    avN(strconv.Itoa(p.NodePort)),
+				":now":     avN(strconv.FormatInt(now.Unix(), 10)),
+				":until":   avN(strconv.FormatInt(now.Add(r.portCooldown).Unix(), 10)),
+				":owner":   avS(ownerID),
+				":updated": avS(now.Format(time.RFC3339)),
+			},
+		}})
+		nodePorts = append(nodePorts, p.NodePort)
+	}
+
+	// Re-mark the ports in case the bitmap lost them; cooling ports must not be picked.
+	return append(items, r.portBitmapUpdates("ADD", nodePorts, false)...)
+}
+
+// ReclaimNodePorts frees ports whose cool-down has passed, and ports that were reserved
+// but never attached to a stack within the port lock TTL (e.g. the reserving process died).
+func (r *DynamoRepository) ReclaimNodePorts(ctx context.Context, min, max int) (int, error) {
+	nowUnix := time.Now().UTC().Unix()
+	staleBefore := strconv.FormatInt(nowUnix-int64(r.portLockTTL.Seconds()), 10)
+	now := strconv.FormatInt(nowUnix, 10)
+	reclaimable := "(attribute_not_exists(stack_id) OR stack_id = :empty) AND " +
+		"((attribute_exists(cooling_until) AND cooling_until <= :now) OR (attribute_not_exists(cooling_until) AND locked_at < :stale_before))"
+	reclaimed := 0
+	var startKey map[string]ddtypes.AttributeValue
+
+	for {
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &r.table,
+			KeyConditionExpression: strPtr("pk = :pk"),
+			FilterExpression:       strPtr("#port BETWEEN :min AND :max AND " + reclaimable),
+			ExpressionAttributeNames: map[string]string{
+				"#port": "port",
+			},
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":pk":           avS("PORTS"),
+				":min":          avN(strconv.Itoa(min)),
+				":max":          avN(strconv.Itoa(max)),
+				":empty":        avS(""),
+				":now":          avN(now),
+				":stale_before": avN(staleBefore),
+			},
+			ExclusiveStartKey: startKey,
+		})
+
+		if err != nil {
+			return reclaimed, err
+		}
+
+		for _, item := range resp.Items {
+			port, err := attrInt(item, "port")
+			if err != nil {
+				continue
+			}
+
+			items := []ddtypes.TransactWriteItem{
+				{Delete: &ddtypes.Delete{
+					TableName:           &r.table,
+					Key:                 map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(port))},
+					ConditionExpression: strPtr(reclaimable),
+					ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+						":empty":        avS(""),
+						":now":          avN(now),
+						":stale_before": avN(staleBefore),
+					},
+				}},
+			}
+			items = append(items, r.portBitmapUpdates("DELETE", []int{port}, false)...)
+
+			if _, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items}); err != nil {
+				if txConditionFailedAt(err, 0) {
+					continue
+				}
+
+				return reclaimed, err
+			}
+
+			reclaimed++
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		startKey = resp.LastEvaluatedKey
+	}
+
+	return reclaimed, nil
+}
+
+func (r *DynamoRepository) ListPortLocks(ctx context.Context) ([]PortLock, error) {
+	locks := make([]PortLock, 0)
+	var startKey map[string]ddtypes.AttributeValue
+
+	for {
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &r.table,
+			ConsistentRead:         boolPtr(r.consistentRead),
+			KeyConditionExpression: strPtr("pk = :pk"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":pk": avS("PORTS"),
+			},
+			ExclusiveStartKey: startKey,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			port, err := attrInt(item, "port")
+			if err != nil {
+				continue
+			}
+
+			lock := PortLock{Port: port}
+			lock.StackID, _ = attrString(item, "stack_id")
+			if lockedAt, err := attrInt64(item, "locked_at"); err == nil {
+				lock.LockedAt = time.Unix(lockedAt, 0).UTC()
+			}
+			if coolingUntil, err := attrInt64(item, "cooling_until"); err == nil {
+				lock.CoolingUntil = time.Unix(coolingUntil, 0).UTC()
+			}
+
+			locks = append(locks, lock)
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		startKey = resp.LastEvaluatedKey
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Port < locks[j].Port })
+
+	return locks, nil
+}
+
+// LockNodePorts ties ports to a stack whose lock items are missing or detached, e.g. ports
+// a Service of the stack holds that the repository does not know about.
+func (r *DynamoRepository) LockNodePorts(ctx context.Context, stackID string, ports []int) error {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	items := make([]ddtypes.TransactWriteItem, 0, len(ports)+1)
+	for _, port := range ports {
+		items = append(items, ddtypes.TransactWriteItem{Put: &ddtypes.Put{
+			TableName: &r.table,
+			Item: map[string]ddtypes.AttributeValue{
+				ddbPK:        avS("PORTS"),
+				ddbSK:        avS(portSK(port)),
+				"item_type":  avS("port_lock"),
+				"port":       avN(strconv.Itoa(port)),
+				"created_at": avS(now.Format(time.RFC3339)),
+				"updated_at": avS(now.Format(time.RFC3339)),
+				"locked_at":  avN(strconv.FormatInt(now.Unix(), 10)),
+				"stack_id":   avS(stackID),
+			},
+			ConditionExpression:       strPtr("attribute_not_exists(pk) OR attribute_not_exists(stack_id) OR stack_id = :empty OR stack_id = :sid"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":empty": avS(""), ":sid": avS(stackID)},
+		}})
+	}
+	items = append(items, r.portBitmapUpdates("ADD", ports, false)...)
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	for idx := range ports {
+		if txConditionFailedAt(err, idx) {
+			return ErrNoAvailableNodePort
+		}
+	}
+
+	return err
+}
+
+// DeletePortLock removes a lock only if it is still in the state it was listed in, so a
+// port reserved or attached again in the meantime is left alone.
+func (r *DynamoRepository) DeletePortLock(ctx context.Context, lock PortLock) error {
+	cond := "stack_id = :sid AND attribute_not_exists(cooling_until) AND "
+	values := map[string]ddtypes.AttributeValue{":sid": avS(lock.StackID)}
+	if lock.LockedAt.IsZero() {
+		cond += "attribute_not_exists(locked_at)"
+	} else {
+		cond += "locked_at = :locked_at"
+		values[":locked_at"] = avN(strconv.FormatInt(lock.LockedAt.Unix(), 10))
+	}
+
+	items := []ddtypes.TransactWriteItem{
+		{Delete: &ddtypes.Delete{
+			TableName:                 &r.table,
+			Key:                       map[string]ddtypes.AttributeValue{ddbPK: avS("PORTS"), ddbSK: avS(portSK(lock.Port))},
+			ConditionExpression:       strPtr(cond),
+			ExpressionAttributeValues: values,
+		}},
+	}
+	items = append(items, r.portBitmapUpdates("DELETE", []int{lock.Port}, false)...)
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	if txConditionFailedAt(err, 0) {
+		return nil
+	}
+
+	return err
+}
+
+// portBitmapUpdates builds one shard update per touched shard. action is ADD or DELETE;
+// with guard set, an ADD fails if any of the ports is already marked used.
+func (r *DynamoRepository) portBitmapUpdates(action string, ports []int, guard bool) []ddtypes.TransactWriteItem {
+	byShard := make(map[int][]int)
+	for _, port := range ports {
+		byShard[portShard(port)] = append(byShard[portShard(port)], port)
+	}
+
+	shards := make([]int, 0, len(byShard))
+	for shard := range byShard {
+		shards = append(shards, shard)
+	}
+	sort.Ints(shards)
+
+	items := make([]ddtypes.TransactWriteItem, 0, len(shards))
+	for _, shard := range shards {
+		set := make([]string, 0, len(byShard[shard]))
+		values := map[string]ddtypes.AttributeValue{}
+		var cond string
+		for i, port := range byShard[shard] {
+			set = append(set, strconv.Itoa(port))
+			if guard {
+				key := ":p" + strconv.Itoa(i)
+				values[key] = avN(strconv.Itoa(port))
+				if cond != "" {
+					cond += " AND "
+				}
+				cond += "NOT contains(used, " + key + ")"
+			}
+		}
+		values[":ports"] = &ddtypes.AttributeValueMemberNS{Value: set}
+
+		update := &ddtypes.Update{
+			TableName:                 &r.table,
+			Key:                       map[string]ddtypes.AttributeValue{ddbPK: avS(ddbPortBitmapPK), ddbSK: avS(portShardSK(shard))},
+			UpdateExpression:          strPtr(action + " used :ports"),
+			ExpressionAttributeValues: values,
+		}
+		if cond != "" {
+			update.ConditionExpression = strPtr(cond)
+		}
+
+		items = append(items, ddtypes.TransactWriteItem{Update: update})
+	}
+
+	return items
+}
+
+func portShardSK(shard int) string { return fmt.Sprintf("SHARD#%03d", shard) }
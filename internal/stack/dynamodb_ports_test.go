@@ -0,0 +1,129 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newDynamoLocalRepository creates a throwaway table on the DynamoDB Local instance at
+// DYNAMODB_LOCAL_ENDPOINT, e.g. http://localhost:8000, and skips without one.
+func newDynamoLocalRepository(tb testing.TB) *DynamoRepository {
+	tb.Helper()
+	endpoint := os.Getenv("DYNAMODB_LOCAL_ENDPOINT")
+	if endpoint == "" {
+		tb.Skip("DYNAMODB_LOCAL_ENDPOINT is not set")
+	}
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}),
+	})
+
+	ctx := context.Background()
+	table := fmt.Sprintf("smctf-bench-%d", time.Now().UnixNano())
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(table),
+		BillingMode: ddtypes.BillingModePayPerRequest,
+		AttributeDefinitions: []ddtypes.AttributeDefinition{
+			{AttributeName: aws.String(ddbPK), AttributeType: ddtypes.ScalarAttributeTypeS},
+			{AttributeName: aws.String(ddbSK), AttributeType: ddtypes.ScalarAttributeTypeS},
+		},
+		KeySchema: []ddtypes.KeySchemaElement{
+			{AttributeName: aws.String(ddbPK), KeyType: ddtypes.KeyTypeHash},
+			{AttributeName: aws.String(ddbSK), KeyType: ddtypes.KeyTypeRange},
+		},
+	})
+	if err != nil {
+		tb.Fatalf("create table: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		_, _ = client.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: aws.String(table)})
+	})
+
+	return NewDynamoRepository(client, table, true, time.Minute, 0)
+}
+
+func histogramTotals(tb testing.TB, h prometheus.Histogram) (float64, uint64) {
+	tb.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		tb.Fatalf("read histogram: %v", err)
+	}
+
+	return m.GetHistogram().GetSampleSum(), m.GetHistogram().GetSampleCount()
+}
+
+// BenchmarkDynamoReserveNodePorts measures the DynamoDB reservation path, the bitmap
+// query, the transaction and its retries, for a 24-port stack at several pool
+// utilizations. The table is filled and each reservation released outside the timer.
+//
+//	docker run -p 8000:8000 amazon/dynamodb-local
+//	DYNAMODB_LOCAL_ENDPOINT=http://localhost:8000 go test ./internal/stack -run '^$' -bench DynamoReserve
+func BenchmarkDynamoReserveNodePorts(b *testing.B) {
+	const (
+		min      = 30000
+		max      = 32767
+		perStack = 24
+	)
+
+	for _, utilization := range []int{10, 50, 95} {
+		b.Run(fmt.Sprintf("utilization=%d%%", utilization), func(b *testing.B) {
+			repo := newDynamoLocalRepository(b)
+			ctx := context.Background()
+			for fill := (max - min + 1) * utilization / 100; fill > 0; fill -= perStack {
+				chunk := perStack
+				if fill < chunk {
+					chunk = fill
+				}
+
+				if _, err := repo.ReserveNodePorts(ctx, "", min, max, make([]int, chunk)); err != nil {
+					b.Fatalf("fill error: %v", err)
+				}
+			}
+
+			attemptsBefore, reservationsBefore := histogramTotals(b, portReservationAttempts)
+			itemsBefore, transactionsBefore := histogramTotals(b, portReservationItems)
+
+			want := make([]int, perStack)
+			b.ResetTimer()
+			for range b.N {
+				ports, err := repo.ReserveNodePorts(ctx, "", min, max, want)
+				if err != nil {
+					b.Fatalf("reserve error: %v", err)
+				}
+
+				b.StopTimer()
+				for _, port := range ports {
+					if err := repo.ReleaseNodePort(ctx, port); err != nil {
+						b.Fatalf("release error: %v", err)
+					}
+				}
+				b.StartTimer()
+			}
+			b.StopTimer()
+
+			attempts, reservations := histogramTotals(b, portReservationAttempts)
+			items, transactions := histogramTotals(b, portReservationItems)
+			if n := float64(reservations - reservationsBefore); n > 0 {
+				b.ReportMetric((attempts-attemptsBefore)/n, "attempts/op")
+			}
+
+			if n := float64(transactions - transactionsBefore); n > 0 {
+				b.ReportMetric((items-itemsBefore)/n, "items/txn")
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Queue tickets share the QUEUE partition so the whole queue is read with one query.
+// Ticket ids start with their creation time, which keeps the sort key in FIFO order.
+
+const ddbQueuePK = "QUEUE"
+
+func (r *DynamoRepository) CreateQueueTicket(ctx context.Context, ticket QueueTicket) error {
+	_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &r.table,
+		Item:                ticketToItem(ticket),
+		ConditionExpression: strPtr("attribute_not_exists(pk) AND attribute_not_exists(sk)"),
+	})
+
+	return err
+}
+
+func (r *DynamoRepository) GetQueueTicket(ctx context.Context, ticketID string) (QueueTicket, bool, error) {
+	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &r.table,
+		ConsistentRead: boolPtr(r.consistentRead),
+		Key: map[string]ddtypes.AttributeValue{
+			ddbPK: avS(ddbQueuePK),
+			ddbSK: avS(ticketSK(ticketID)),
+		},
+	})
+	if err != nil {
+		return QueueTicket{}, false, err
+	}
+
+	if len(resp.Item) == 0 {
+		return QueueTicket{}, false, nil
+	}
+
+	ticket, err := ticketFromItem(resp.Item)
+	if err != nil {
+		return QueueTicket{}, false, err
+	}
+
+	return ticket, true, nil
+}
+
+func (r *DynamoRepository) ListQueueTickets(ctx context.Context) ([]QueueTicket, error) {
+	out := make([]QueueTicket, 0)
+	var startKey map[string]ddtypes.AttributeValue
+
+	for {
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              &r.table,
+			ConsistentRead:         boolPtr(r.consistentRead),
+			KeyConditionExpression: strPtr("pk = :pk"),
+			ExpressionAttributeValues: map[string]ddtypes.AttributeValue{
+				":pk": avS(ddbQueuePK),
+			},
+			ExclusiveStartKey: startKey,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			ticket, err := ticketFromItem(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ticket)
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		startKey = resp.LastEvaluatedKey
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].TicketID < out[j].TicketID })
+	return out, nil
+}
+
+// UpdateQueueTicket replaces a ticket only while it is still in status from, so a ticket
+// cancelled by its owner is never provisioned and a provisioned one cannot be cancelled.
+func (r *DynamoRepository) UpdateQueueTicket(ctx context.Context, ticket QueueTicket, from TicketStatus) error {
+	_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 &r.table,
+		Item:                      ticketToItem(ticket),
+		ConditionExpression:       strPtr("attribute_exists(pk) AND attribute_exists(sk) AND #status = :from"),
+		ExpressionAttributeNames:  map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]ddtypes.AttributeValue{":from": avS(string(from))},
+	})
+	if err != nil {
+		var condErr *ddtypes.ConditionalCheckFailedException
+		if !errors.As(err, &condErr) {
+			return err
+		}
+
+		_, ok, getErr := r.GetQueueTicket(ctx, ticket.TicketID)
+		if getErr != nil {
+			return getErr
+		}
+
+		if !ok {
+			return ErrNotFound
+		}
+
+		return ErrTicketClosed
+	}
+
+	return nil
+}
+
+func (r *DynamoRepository) DeleteQueueTicket(ctx context.Context, ticketID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &r.table,
+		Key: map[string]ddtypes.AttributeValue{
+			ddbPK: avS(ddbQueuePK),
+			ddbSK: avS(ticketSK(ticketID)),
+		},
+	})
+
+	return err
+}
+
+func ticketToItem(ticket QueueTicket) map[string]ddtypes.AttributeValue {
+	item := map[string]ddtypes.AttributeValue{
+		ddbPK:                    avS(ddbQueuePK),
+		ddbSK:                    avS(ticketSK(ticket.TicketID)),
+		"item_type":              avS("queue_ticket"),
+		"ticket_id":              avS(ticket.TicketID),
+		"status":                 avS(string(ticket.Status)),
+		"owner_id":               avS(ticket.OwnerID),
+		"challenge_id":           avS(ticket.ChallengeID),
+		"port_pool":              avS(ticket.PortPool),
+		"priority":               avS(ticket.Priority),
+		"region":                 avS(ticket.Region),
+		"node_pool":              avS(ticket.NodePool),
+		"pod_spec":               avS(ticket.PodSpecYML),
+		"target_ports":           portSpecsToAttr(ticket.TargetPorts),
+		"requested_cpu_milli":    avN(strconv.FormatInt(ticket.RequestedMilli, 10)),
+		"requested_memory_bytes": avN(strconv.FormatInt(ticket.RequestedBytes, 10)),
+		"created_at":             avS(ticket.CreatedAt.UTC().Format(time.RFC3339Nano)),
+		"updated_at":             avS(ticket.UpdatedAt.UTC().Format(time.RFC3339Nano)),
+	}
+
+	if ticket.StackID != "" {
+		item["stack_id"] = avS(ticket.StackID)
+	}
+
+	if ticket.Error != "" {
+		item["error"] = avS(ticket.Error)
+	}
+
+	return item
+}
+
+func ticketFromItem(item map[string]ddtypes.AttributeValue) (QueueTicket, error) {
+	ticketID, err := attrString(item, "ticket_id")
+	if err != nil {
+		return QueueTicket{}, err
+	}
+	statusStr, _ := attrString(item, "status")
+	ownerID, _ := attrString(item, "owner_id")
+	challengeID, _ := attrString(item, "challenge_id")
+	portPool, _ := attrString(item, "port_pool")
+	priority, _ := attrString(item, "priority")
+	region, _ := attrString(item, "region")
+	nodePool, _ := attrString(item, "node_pool")
+	podSpec, _ := attrString(item, "pod_spec")
+	stackID, _ := attrString(item, "stack_id")
+	errMsg, _ := attrString(item, "error")
+	cpuMilli, _ := attrInt64(item, "requested_cpu_milli")
+	memBytes, _ := attrInt64(item, "requested_memory_bytes")
+	targetPorts, err := attrPortSpecs(item, "target_ports")
+	if err != nil {
+		return QueueTicket{}, err
+	}
+	createdAt, err := attrTime(item, "created_at")
+	if err != nil {
+		return QueueTicket{}, err
+	}
+	updatedAt, err := attrTime(item, "updated_at")
+	if err != nil {
+		return QueueTicket{}, err
+	}
+
+	return QueueTicket{
+		TicketID:    ticketID,
+		Status:      TicketStatus(statusStr),
+		OwnerID:     ownerID,
+		ChallengeID: challengeID,
+		PortPool:    portPool,
+		Priority:    priority,
+		Region:      region,
+		NodePool:    nodePool,
+		StackID:     stackID,
+		Error:       errMsg,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		PodSpecYML:  podSpec,
+		TargetPorts: targetPorts,
+
+		RequestedMilli: cpuMilli,
+		RequestedBytes: memBytes,
+	}, nil
+}
+
+func ticketSK(ticketID string) string { return "TICKET#" + ticketID }
@@ -1,6 +1,10 @@
 package stack
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrNotFound            = errors.New("stack not found")
@@ -8,4 +12,118 @@ var (
 	ErrPodSpecInvalid      = errors.New("invalid pod spec")
 	ErrNoAvailableNodePort = errors.New("no available nodeport")
 	ErrClusterSaturated    = errors.New("cluster saturated")
+	ErrTicketClosed        = errors.New("queue ticket is no longer queued")
+)
+
+// Violation codes tell clients what kind of problem a FieldError is.
+const (
+	CodeRequired     = "required"
+	CodeForbidden    = "forbidden"
+	CodeInvalid      = "invalid"
+	CodeDuplicate    = "duplicate"
+	CodeUnknown      = "unknown"
+	CodeNotAllowed   = "not_allowed"
+	CodeExceedsLimit = "exceeds_limit"
 )
+
+// FieldError is a violation of one field, such as spec.containers[0].image. It matches
+// ErrPodSpecInvalid, or ErrInvalidInput for request fields such as target_port.
+type FieldError struct {
+	Field  string
+	Code   string
+	Reason string
+	// Err is the sentinel the violation matches, ErrPodSpecInvalid when nil.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %s: %s", e.Unwrap(), e.Field, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	if e.Err == nil {
+		return ErrPodSpecInvalid
+	}
+
+	return e.Err
+}
+
+// ValidationErrors is every violation found in a create request, in the order of the
+// request. errors.As finds the first one.
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		msgs = append(msgs, fieldErr.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	out := make([]error, 0, len(e))
+	for _, fieldErr := range e {
+		out = append(out, fieldErr)
+	}
+
+	return out
+}
+
+// add records a pod spec violation.
+func (e *ValidationErrors) add(field, code, reason string) {
+	*e = append(*e, &FieldError{Field: field, Code: code, Reason: reason})
+}
+
+// addInput records a violation of a request field other than the pod spec.
+func (e *ValidationErrors) addInput(field, code, reason string) {
+	*e = append(*e, &FieldError{Field: field, Code: code, Reason: reason, Err: ErrInvalidInput})
+}
+
+// addInputErr records an ErrInvalidInput error from a helper as a violation of field.
+func (e *ValidationErrors) addInputErr(field, code string, err error) {
+	e.addInput(field, code, strings.TrimPrefix(err.Error(), ErrInvalidInput.Error()+": "))
+}
+
+func (e *ValidationErrors) append(fieldErr *FieldError) {
+	if fieldErr != nil {
+		*e = append(*e, fieldErr)
+	}
+}
+
+// err returns the violations as an error, nil without any.
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Violation is one reason a create request is rejected, for API responses. Field and
+// Code are empty for errors that are not about one field.
+type Violation struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Violations lists the violations of an ErrInvalidInput or ErrPodSpecInvalid error.
+func Violations(err error) []Violation {
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		out := make([]Violation, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			out = append(out, Violation{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldErr.Reason})
+		}
+
+		return out
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return []Violation{{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldErr.Reason}}
+	}
+
+	return []Violation{{Code: CodeInvalid, Message: err.Error()}}
+}
@@ -13,7 +13,10 @@ import (
 
 func NewRepositoryFromConfig(ctx context.Context, cfg config.StackConfig) (RepositoryClientAPI, error) {
 	if cfg.UseMockRepository {
-		return NewInMemoryRepository(0), nil
+		repo := NewInMemoryRepository(0)
+		repo.portCooldown = cfg.NodePortCooldown
+		repo.portLockTTL = cfg.PortLockTTL
+		return repo, nil
 	}
 
 	loadOpts := []func(*awscfg.LoadOptions) error{
@@ -40,6 +43,7 @@ func NewRepositoryFromConfig(ctx context.Context, cfg config.StackConfig) (Repos
 		cfg.DynamoTableName,
 		cfg.DynamoConsistentRead,
 		cfg.PortLockTTL,
+		cfg.NodePortCooldown,
 	), nil
 }
 
@@ -50,3 +54,39 @@ func NewKubernetesClientFromConfig(cfg config.StackConfig) (KubernetesClientAPI,
 
 	return NewKubernetesClient(cfg)
 }
+
+// NewClustersFromConfig builds a client per STACK_CLUSTERS entry, or a single cluster
+// from the top-level Kubernetes settings when no clusters are configured.
+func NewClustersFromConfig(cfg config.StackConfig) ([]Cluster, error) {
+	if len(cfg.Clusters) == 0 {
+		k8s, err := NewKubernetesClientFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Cluster{{Client: k8s}}, nil
+	}
+
+	clusters := make([]Cluster, 0, len(cfg.Clusters))
+	for _, cc := range cfg.Clusters {
+		clusterCfg := cfg
+		if cc.KubeConfigPath != "" {
+			clusterCfg.KubeConfigPath = cc.KubeConfigPath
+		}
+		if cc.KubeContext != "" {
+			clusterCfg.KubeContext = cc.KubeContext
+		}
+		if cc.StackNodeRole != "" {
+			clusterCfg.StackNodeRole = cc.StackNodeRole
+		}
+
+		k8s, err := NewKubernetesClientFromConfig(clusterCfg)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cc.ID, err)
+		}
+
+		clusters = append(clusters, Cluster{ID: cc.ID, Region: cc.Region, PortPool: cc.PortPool, Client: k8s})
+	}
+
+	return clusters, nil
+}
@@ -0,0 +1,214 @@
+package stack
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// hardeningProfileAnnotation selects a STACK_HARDENING_PROFILES entry for a pod spec.
+	hardeningProfileAnnotation = "smctf.io/hardening-profile"
+	// capabilitiesAnnotation lists the capabilities a pod spec adds, from its profile's
+	// allowlist.
+	capabilitiesAnnotation = "smctf.io/capabilities"
+	// writablePathsAnnotation lists paths that get an emptyDir in every container, so
+	// they stay writable under a read-only root filesystem.
+	writablePathsAnnotation = "smctf.io/writable-paths"
+
+	writableVolumePrefix = "smctf-writable-"
+)
+
+// applyHardening sets the security contexts of the pod and its containers. Without a
+// hardening profile the pod gets the built-in baseline: unprivileged, no privilege
+// escalation and RuntimeDefault seccomp.
+func (v *Validator) applyHardening(errs *ValidationErrors, pod *corev1.Pod) {
+	profile, err := v.hardeningProfile(pod.Annotations[hardeningProfileAnnotation])
+	if err != nil {
+		errs.append(err)
+		return
+	}
+
+	capabilities := requestedCapabilities(errs, profile, pod.Annotations[capabilitiesAnnotation])
+	addWritablePaths(errs, pod, pod.Annotations[writablePathsAnnotation], v.cfg.WritablePathSizeBytes)
+
+	seccomp := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	if profile != nil {
+		seccomp = seccompProfile(profile.SeccompProfile)
+		if profile.PIDLimit > 0 {
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[v.cfg.PIDLimitAnnotation] = strconv.FormatInt(profile.PIDLimit, 10)
+		}
+	}
+
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{SeccompProfile: seccomp}
+
+	for i := range pod.Spec.InitContainers {
+		pod.Spec.InitContainers[i].SecurityContext = hardenedContainerSecurityContext(profile, capabilities)
+	}
+
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].SecurityContext = hardenedContainerSecurityContext(profile, capabilities)
+	}
+}
+
+// hardeningProfile returns the profile named by the pod spec, or
+// STACK_HARDENING_PROFILE_DEFAULT; nil means the built-in baseline.
+func (v *Validator) hardeningProfile(name string) (*config.HardeningProfile, *FieldError) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = v.cfg.DefaultHardeningProfile
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	for i := range v.cfg.HardeningProfiles {
+		if v.cfg.HardeningProfiles[i].Name == name {
+			return &v.cfg.HardeningProfiles[i], nil
+		}
+	}
+
+	return nil, &FieldError{Field: "metadata.annotations[" + hardeningProfileAnnotation + "]", Code: CodeUnknown, Reason: fmt.Sprintf("unknown hardening profile %q", name)}
+}
+
+func requestedCapabilities(errs *ValidationErrors, profile *config.HardeningProfile, raw string) []corev1.Capability {
+	field := "metadata.annotations[" + capabilitiesAnnotation + "]"
+	var out []corev1.Capability
+	for item := range strings.SplitSeq(raw, ",") {
+		name := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(item)), "CAP_")
+		if name == "" {
+			continue
+		}
+
+		if profile == nil || !slices.Contains(profile.AllowedCapabilities, name) {
+			errs.add(field, CodeNotAllowed, fmt.Sprintf("capability %s is not allowed by the hardening profile", name))
+			continue
+		}
+
+		out = append(out, corev1.Capability(name))
+	}
+
+	return out
+}
+
+// addWritablePaths mounts an emptyDir of STACK_WRITABLE_PATH_SIZE at each declared path in
+// every container.
+func addWritablePaths(errs *ValidationErrors, pod *corev1.Pod, raw string, sizeBytes int64) {
+	field := "metadata.annotations[" + writablePathsAnnotation + "]"
+	var paths []string
+	for item := range strings.SplitSeq(raw, ",") {
+		p := strings.TrimSpace(item)
+		if p == "" {
+			continue
+		}
+
+		if !path.IsAbs(p) || path.Clean(p) != p || p == "/" {
+			errs.add(field, CodeInvalid, fmt.Sprintf("%q must be a clean absolute path other than /", p))
+			continue
+		}
+
+		if slices.Contains(paths, p) {
+			errs.add(field, CodeDuplicate, fmt.Sprintf("duplicate path %q", p))
+			continue
+		}
+		paths = append(paths, p)
+	}
+
+	for i := range pod.Spec.Volumes {
+		if strings.HasPrefix(pod.Spec.Volumes[i].Name, writableVolumePrefix) {
+			errs.add(fmt.Sprintf("spec.volumes[%d].name", i), CodeForbidden, fmt.Sprintf("volume names starting with %s are reserved", writableVolumePrefix))
+		}
+	}
+
+	for i, p := range paths {
+		name := fmt.Sprintf("%s%d", writableVolumePrefix, i)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: resource.NewQuantity(sizeBytes, resource.BinarySI)}},
+		})
+
+		for j := range pod.Spec.InitContainers {
+			pod.Spec.InitContainers[j].VolumeMounts = append(pod.Spec.InitContainers[j].VolumeMounts, corev1.VolumeMount{Name: name, MountPath: p})
+		}
+
+		for j := range pod.Spec.Containers {
+			pod.Spec.Containers[j].VolumeMounts = append(pod.Spec.Containers[j].VolumeMounts, corev1.VolumeMount{Name: name, MountPath: p})
+		}
+	}
+}
+
+func hardenedContainerSecurityContext(profile *config.HardeningProfile, capabilities []corev1.Capability) *corev1.SecurityContext {
+	sc := &corev1.SecurityContext{
+		Privileged:               boolPtr(false),
+		AllowPrivilegeEscalation: boolPtr(false),
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+
+	if profile == nil {
+		return sc
+	}
+
+	sc.SeccompProfile = seccompProfile(profile.SeccompProfile)
+	sc.AppArmorProfile = appArmorProfile(profile.AppArmorProfile)
+	if profile.DropAllCapabilities || len(capabilities) > 0 {
+		sc.Capabilities = &corev1.Capabilities{Add: capabilities}
+		if profile.DropAllCapabilities {
+			sc.Capabilities.Drop = []corev1.Capability{"ALL"}
+		}
+	}
+
+	if profile.RunAsNonRoot {
+		sc.RunAsNonRoot = boolPtr(true)
+	}
+
+	if profile.RunAsUser != nil {
+		sc.RunAsUser = int64Ptr(*profile.RunAsUser)
+	}
+
+	if profile.RunAsGroup != nil {
+		sc.RunAsGroup = int64Ptr(*profile.RunAsGroup)
+	}
+
+	if profile.ReadOnlyRootFilesystem {
+		sc.ReadOnlyRootFilesystem = boolPtr(true)
+	}
+
+	return sc
+}
+
+func seccompProfile(value string) *corev1.SeccompProfile {
+	if localhost, ok := strings.CutPrefix(value, "localhost/"); ok {
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhost}
+	}
+
+	if value == string(corev1.SeccompProfileTypeUnconfined) {
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+	}
+
+	return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+}
+
+func appArmorProfile(value string) *corev1.AppArmorProfile {
+	switch {
+	case value == "":
+		return nil
+	case strings.HasPrefix(value, "localhost/"):
+		localhost := strings.TrimPrefix(value, "localhost/")
+		return &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeLocalhost, LocalhostProfile: &localhost}
+	case value == string(corev1.AppArmorProfileTypeUnconfined):
+		return &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeUnconfined}
+	default:
+		return &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeRuntimeDefault}
+	}
+}
@@ -0,0 +1,146 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const hardeningTestPodSpec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hardened
+  annotations:
+%s
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+
+func newHardeningTestValidator() *Validator {
+	uid := int64(1000)
+	return NewValidator(config.StackConfig{
+		HardeningProfiles: []config.HardeningProfile{
+			{
+				Name:                   "web",
+				DropAllCapabilities:    true,
+				RunAsNonRoot:           true,
+				RunAsUser:              &uid,
+				ReadOnlyRootFilesystem: true,
+				PIDLimit:               128,
+				SeccompProfile:         "localhost/profiles/web.json",
+				AppArmorProfile:        "RuntimeDefault",
+			},
+			{
+				Name:                "pwn",
+				DropAllCapabilities: true,
+				AllowedCapabilities: []string{"SYS_PTRACE"},
+				SeccompProfile:      "RuntimeDefault",
+			},
+		},
+		DefaultHardeningProfile: "web",
+		PIDLimitAnnotation:      "example.com/pids-limit",
+	})
+}
+
+func validateHardeningTestPod(t *testing.T, v *Validator, annotations string) (corev1.Pod, error) {
+	t.Helper()
+
+	res, err := v.ValidatePodSpec(fmt.Sprintf(hardeningTestPodSpec, annotations), []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}})
+	if err != nil {
+		return corev1.Pod{}, err
+	}
+
+	var pod corev1.Pod
+	if err := sigsyaml.Unmarshal([]byte(res.SanitizedYAML), &pod); err != nil {
+		t.Fatalf("decode sanitized pod: %v", err)
+	}
+
+	return pod, nil
+}
+
+func TestHardeningDefaultProfile(t *testing.T) {
+	pod, err := validateHardeningTestPod(t, newHardeningTestValidator(), `    smctf.io/writable-paths: /tmp,/var/cache/nginx`)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	sc := pod.Spec.Containers[0].SecurityContext
+	if !*sc.RunAsNonRoot || *sc.RunAsUser != 1000 || !*sc.ReadOnlyRootFilesystem || sc.Capabilities.Drop[0] != "ALL" || len(sc.Capabilities.Add) != 0 {
+		t.Fatalf("unexpected security context: %+v", sc)
+	}
+
+	if sc.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost || *pod.Spec.SecurityContext.SeccompProfile.LocalhostProfile != "profiles/web.json" {
+		t.Fatalf("expected localhost seccomp profile, got %+v", sc.SeccompProfile)
+	}
+
+	if sc.AppArmorProfile == nil || sc.AppArmorProfile.Type != corev1.AppArmorProfileTypeRuntimeDefault {
+		t.Fatalf("expected RuntimeDefault AppArmor profile, got %+v", sc.AppArmorProfile)
+	}
+
+	mounts := pod.Spec.Containers[0].VolumeMounts
+	if len(pod.Spec.Volumes) != 2 || pod.Spec.Volumes[0].EmptyDir == nil || len(mounts) != 2 || mounts[1].MountPath != "/var/cache/nginx" {
+		t.Fatalf("expected emptyDir mounts for writable paths, got %+v / %+v", pod.Spec.Volumes, mounts)
+	}
+
+	if pod.Annotations["example.com/pids-limit"] != "128" {
+		t.Fatalf("expected pid limit annotation, got %v", pod.Annotations)
+	}
+}
+
+func TestHardeningCapabilityAllowlist(t *testing.T) {
+	v := newHardeningTestValidator()
+
+	pod, err := validateHardeningTestPod(t, v, "    smctf.io/hardening-profile: pwn\n    smctf.io/capabilities: CAP_SYS_PTRACE")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	sc := pod.Spec.Containers[0].SecurityContext
+	if len(sc.Capabilities.Add) != 1 || sc.Capabilities.Add[0] != "SYS_PTRACE" || sc.RunAsNonRoot != nil || sc.ReadOnlyRootFilesystem != nil {
+		t.Fatalf("unexpected pwn security context: %+v", sc)
+	}
+
+	var fieldErr *FieldError
+	_, err = validateHardeningTestPod(t, v, "    smctf.io/hardening-profile: pwn\n    smctf.io/capabilities: SYS_ADMIN")
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "metadata.annotations[smctf.io/capabilities]" {
+		t.Fatalf("expected capability outside the allowlist to be rejected, got %v", err)
+	}
+
+	if _, err := validateHardeningTestPod(t, v, "    smctf.io/hardening-profile: kernel"); !errors.As(err, &fieldErr) {
+		t.Fatalf("expected unknown profile to be rejected, got %v", err)
+	}
+
+	if _, err := validateHardeningTestPod(t, v, "    smctf.io/writable-paths: tmp"); !errors.As(err, &fieldErr) {
+		t.Fatalf("expected relative writable path to be rejected, got %v", err)
+	}
+}
+
+func TestHardeningBaselineRejectsCapabilities(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	if _, err := validateHardeningTestPod(t, v, "    smctf.io/capabilities: NET_ADMIN"); !errors.Is(err, ErrPodSpecInvalid) {
+		t.Fatalf("expected capabilities without a profile to be rejected, got %v", err)
+	}
+
+	pod, err := validateHardeningTestPod(t, v, "    smctf.io/placement: spread")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if sc := pod.Spec.Containers[0].SecurityContext; sc.Capabilities != nil || sc.RunAsNonRoot != nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Fatalf("expected built-in baseline, got %+v", sc)
+	}
+}
@@ -0,0 +1,87 @@
+package stack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"smctf/internal/config"
+)
+
+var sha256DigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// validateImage applies STACK_IMAGE_ALLOWLIST and STACK_IMAGE_DIGEST_POLICY to the image
+// of the container at field, e.g. spec.containers[0].image.
+func (v *Validator) validateImage(field, image string) *FieldError {
+	repository, digest, hasDigest := strings.Cut(strings.TrimSpace(image), "@")
+
+	if v.cfg.ImageDigestPolicy == config.ImageDigestPolicyRequire && !hasDigest {
+		return &FieldError{Field: field, Code: CodeRequired, Reason: fmt.Sprintf("image %q must be pinned with an @sha256: digest", image)}
+	}
+
+	if hasDigest && !sha256DigestPattern.MatchString(digest) {
+		return &FieldError{Field: field, Code: CodeInvalid, Reason: fmt.Sprintf("image %q has an invalid digest, expected sha256: and 64 hex characters", image)}
+	}
+
+	if len(v.imageAllowlist) == 0 {
+		return nil
+	}
+
+	name := normalizeImageRepository(repository)
+	for _, entry := range v.imageAllowlist {
+		if name == entry || strings.HasPrefix(name, entry+"/") {
+			return nil
+		}
+	}
+
+	return &FieldError{Field: field, Code: CodeNotAllowed, Reason: fmt.Sprintf("image %q is not from an allowed registry or repository", image)}
+}
+
+// normalizeImageRepository returns the fully qualified repository of an image reference
+// without its tag, the way the container runtime resolves it: references without a
+// registry host are Docker Hub images, and single-name ones are in library/.
+func normalizeImageRepository(ref string) string {
+	if slash := strings.LastIndex(ref, "/"); strings.LastIndex(ref, ":") > slash {
+		ref = ref[:strings.LastIndex(ref, ":")]
+	}
+
+	host, rest, found := strings.Cut(ref, "/")
+	if host == "docker.io" || host == "index.docker.io" {
+		if !strings.Contains(rest, "/") {
+			return "docker.io/library/" + rest
+		}
+
+		return "docker.io/" + rest
+	}
+
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return ref
+	}
+
+	if !found {
+		return "docker.io/library/" + ref
+	}
+
+	return "docker.io/" + host + "/" + rest
+}
+
+// normalizeImageAllowlistEntry qualifies a STACK_IMAGE_ALLOWLIST entry the way
+// normalizeImageRepository does an image, so nginx and docker.io/nginx both mean
+// docker.io/library/nginx. Bare registry hosts such as ghcr.io are kept as they are.
+func normalizeImageAllowlistEntry(entry string) string {
+	entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+	if !strings.Contains(entry, "/") && (strings.ContainsAny(entry, ".:") || entry == "localhost") {
+		if entry == "index.docker.io" {
+			return "docker.io"
+		}
+
+		return entry
+	}
+
+	// docker.io/library is the namespace of all official images, not an image in it.
+	if name := normalizeImageRepository(entry); name != "docker.io/library/library" {
+		return name
+	}
+
+	return "docker.io/library"
+}
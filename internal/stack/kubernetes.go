@@ -15,7 +15,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -27,7 +29,10 @@ import (
 type KubernetesClient struct {
 	client            kubernetes.Interface
 	schedulingTimeout time.Duration
-	stackNodeRole     string
+	nodePools         []config.NodePool
+	addressPolicy     NodeAddressPolicy
+	addressCache      *nodeAddressCache
+	templates         namespaceTemplates
 }
 
 type KubernetesClientAPI interface {
@@ -37,10 +42,21 @@ type KubernetesClientAPI interface {
 	ListPods(ctx context.Context, namespace string) ([]string, error)
 	ListPodsWithCreation(ctx context.Context, namespace string) (map[string]PodInfo, error)
 	ListServices(ctx context.Context, namespace string) ([]string, error)
+	ListServicesWithNodePorts(ctx context.Context, namespace string) ([]ServiceInfo, error)
+	DeleteService(ctx context.Context, namespace, serviceName string) error
 	NodeExists(ctx context.Context, nodeID string) (bool, error)
 	HasIngressNetworkPolicy(ctx context.Context) (bool, error)
 	GetNodePublicIP(ctx context.Context, nodeID string) (*string, error)
-	CountSchedulableNodes(ctx context.Context) (int, error)
+	CountSchedulableNodes(ctx context.Context) (map[string]int, error)
+	QuotaCapacity(ctx context.Context, namespace string) (CapacityBudget, error)
+	AllocatableCapacity(ctx context.Context) (CapacityBudget, map[string]CapacityBudget, error)
+	ListNodeCapacity(ctx context.Context) ([]NodeCapacity, error)
+	RecordEvent(ctx context.Context, namespace, podID, reason, message string) error
+	ListStackNamespaces(ctx context.Context) (map[string]time.Time, error)
+	DeleteNamespace(ctx context.Context, namespace string) error
+	ListBalloons(ctx context.Context, namespace string) ([]string, error)
+	CreateBalloon(ctx context.Context, req BalloonRequest) error
+	RuntimeClassExists(ctx context.Context, name string) (bool, error)
 }
 
 type ProvisionRequest struct {
@@ -49,6 +65,62 @@ type ProvisionRequest struct {
 	PodName    string
 	PodSpecYML string
 	Ports      []PortMapping
+	OwnerID    string
+	Placement  string
+	// NodePool is the STACK_NODE_POOLS entry the pod is scheduled on, "" without pools.
+	NodePool string
+	// PriorityClassName is set by the server from the request's priority tier.
+	PriorityClassName string
+	// NamespaceLabels is set when Namespace is managed by the server; a missing namespace
+	// is then created with these labels and the namespace templates.
+	NamespaceLabels map[string]string
+	// ConfigMaps are created next to the pod and owned by it, see scopeConfigMaps.
+	ConfigMaps []corev1.ConfigMap
+}
+
+// prepareStackPod turns a sanitized pod spec into the pod CreatePodAndService creates:
+// named and labelled after the stack, on its node pool, with its placement and priority
+// class. It returns the request's ConfigMaps renamed after the pod.
+func prepareStackPod(pod *corev1.Pod, req ProvisionRequest, pool config.NodePool) []corev1.ConfigMap {
+	podName := req.PodName
+	if podName == "" {
+		podName = req.StackID
+	}
+
+	configMaps := scopeConfigMaps(pod, req.ConfigMaps, podName)
+	labels := make(map[string]string)
+	if len(pod.Labels) > 0 {
+		maps.Copy(labels, pod.Labels)
+	}
+	labels["app.kubernetes.io/name"] = "smctf-stack"
+	labels["app.kubernetes.io/instance"] = req.StackID
+	labels["smctf.io/stack-id"] = req.StackID
+
+	pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+	pod.ObjectMeta = metav1.ObjectMeta{
+		Name:        podName,
+		Namespace:   req.Namespace,
+		Labels:      labels,
+		Annotations: pod.Annotations,
+	}
+
+	applyNodePool(pod, pool)
+	applyPlacement(pod, req.Placement, req.OwnerID, len(req.NamespaceLabels) > 0)
+	pod.Spec.PriorityClassName = req.PriorityClassName
+
+	return configMaps
+}
+
+// BalloonRequest is a placeholder pod holding room for a stack that is waiting for
+// capacity. Balloons are removed with DeletePodAndService and no service name.
+type BalloonRequest struct {
+	Namespace         string
+	Name              string
+	NodePool          string
+	CPUMilli          int64
+	MemoryBytes       int64
+	Image             string
+	PriorityClassName string
 }
 
 type ProvisionResult struct {
@@ -59,10 +131,26 @@ type ProvisionResult struct {
 }
 
 type PodInfo struct {
+	Namespace string
 	CreatedAt time.Time
 	StackID   string
 }
 
+// CapacityBudget is an amount of CPU and memory stacks may use; a zero field means the
+// source does not limit that resource.
+type CapacityBudget struct {
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+type ServiceInfo struct {
+	Name      string
+	Namespace string
+	StackID   string
+	CreatedAt time.Time
+	NodePorts []int
+}
+
 func NewKubernetesClient(cfg config.StackConfig) (*KubernetesClient, error) {
 	restCfg, err := buildKubeConfig(cfg)
 	if err != nil {
@@ -76,10 +164,18 @@ func NewKubernetesClient(cfg config.StackConfig) (*KubernetesClient, error) {
 		return nil, fmt.Errorf("new kubernetes client: %w", err)
 	}
 
+	templates, err := loadNamespaceTemplates(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &KubernetesClient{
 		client:            client,
 		schedulingTimeout: cfg.SchedulingTimeout,
-		stackNodeRole:     cfg.StackNodeRole,
+		nodePools:         nodePoolsFor(cfg),
+		addressPolicy:     newNodeAddressPolicy(cfg.NodeAddressTypes, cfg.NodeAddressFamily, cfg.NodeAddressOverrideKey),
+		addressCache:      newNodeAddressCache(cfg.NodeAddressCacheTTL),
+		templates:         templates,
 	}, nil
 }
 
@@ -115,8 +211,15 @@ func buildKubeConfig(cfg config.StackConfig) (*rest.Config, error) {
 }
 
 func (c *KubernetesClient) CreatePodAndService(ctx context.Context, req ProvisionRequest) (ProvisionResult, error) {
-	if err := c.ensureNamespace(ctx, req.Namespace); err != nil {
-		return ProvisionResult{}, err
+	var nsErr error
+	if req.NamespaceLabels != nil {
+		nsErr = c.ensureStackNamespace(ctx, req.Namespace, req.NamespaceLabels)
+	} else {
+		nsErr = c.ensureNamespace(ctx, req.Namespace)
+	}
+
+	if nsErr != nil {
+		return ProvisionResult{}, nsErr
 	}
 
 	var pod corev1.Pod
@@ -124,39 +227,44 @@ func (c *KubernetesClient) CreatePodAndService(ctx context.Context, req Provisio
 		return ProvisionResult{}, fmt.Errorf("decode pod spec: %w", err)
 	}
 
-	podName := req.PodName
-	if podName == "" {
-		podName = req.StackID
+	pool, ok := c.nodePool(req.NodePool)
+	if !ok {
+		return ProvisionResult{}, fmt.Errorf("unknown node pool %q", req.NodePool)
 	}
 
+	configMaps := prepareStackPod(&pod, req, pool)
+	podName := pod.Name
+	labels := pod.Labels
 	serviceName := "svc-" + req.StackID
-	labels := make(map[string]string)
-	if len(pod.Labels) > 0 {
-		maps.Copy(labels, pod.Labels)
-	}
-	labels["app.kubernetes.io/name"] = "smctf-stack"
-	labels["app.kubernetes.io/instance"] = req.StackID
-	labels["smctf.io/stack-id"] = req.StackID
-
-	pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
-	pod.ObjectMeta = metav1.ObjectMeta{
-		Name:        podName,
-		Namespace:   req.Namespace,
-		Labels:      labels,
-		Annotations: pod.Annotations,
-	}
-
-	if pod.Spec.NodeSelector == nil {
-		pod.Spec.NodeSelector = map[string]string{}
-	}
-
-	pod.Spec.NodeSelector["role"] = c.stackNodeRole
 
 	createdPod, err := c.client.CoreV1().Pods(req.Namespace).Create(ctx, &pod, metav1.CreateOptions{})
 	if err != nil {
 		return ProvisionResult{}, fmt.Errorf("create pod: %w", err)
 	}
 
+	// The pod owns its ConfigMaps, so deleting it deletes them too. The kubelet retries
+	// mounting them until they exist.
+	owner := metav1.OwnerReference{APIVersion: "v1", Kind: "Pod", Name: podName, UID: createdPod.UID}
+	for _, cm := range configMaps {
+		cmLabels := make(map[string]string, len(cm.Labels)+len(labels))
+		maps.Copy(cmLabels, cm.Labels)
+		maps.Copy(cmLabels, labels)
+
+		cm.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+		cm.ObjectMeta = metav1.ObjectMeta{
+			Name:            cm.Name,
+			Namespace:       req.Namespace,
+			Labels:          cmLabels,
+			Annotations:     cm.Annotations,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		}
+
+		if _, err := c.client.CoreV1().ConfigMaps(req.Namespace).Create(ctx, &cm, metav1.CreateOptions{}); err != nil {
+			_ = c.client.CoreV1().Pods(req.Namespace).Delete(context.Background(), podName, metav1.DeleteOptions{GracePeriodSeconds: int64Ptr(0)})
+			return ProvisionResult{}, fmt.Errorf("create configmap: %w", err)
+		}
+	}
+
 	servicePorts := make([]corev1.ServicePort, 0, len(req.Ports))
 	for _, p := range req.Ports {
 		proto := corev1.ProtocolTCP
@@ -284,11 +392,15 @@ func (c *KubernetesClient) GetPodStatus(ctx context.Context, namespace, podID st
 		return StatusNodeDeleted, pod.Spec.NodeName, nil
 	}
 
+	if isPreemptedByScheduler(pod) {
+		return StatusPreempted, pod.Spec.NodeName, nil
+	}
+
 	return mapPodPhaseToStatus(pod.Status.Phase), pod.Spec.NodeName, nil
 }
 
 func (c *KubernetesClient) ListPods(ctx context.Context, namespace string) ([]string, error) {
-	podList, err := c.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	podList, err := c.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: stackLabelSelector})
 	if err != nil {
 		return nil, fmt.Errorf("list pods: %w", err)
 	}
@@ -306,7 +418,7 @@ func (c *KubernetesClient) ListPods(ctx context.Context, namespace string) ([]st
 }
 
 func (c *KubernetesClient) ListPodsWithCreation(ctx context.Context, namespace string) (map[string]PodInfo, error) {
-	podList, err := c.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	podList, err := c.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: stackLabelSelector})
 	if err != nil {
 		return nil, fmt.Errorf("list pods: %w", err)
 	}
@@ -318,6 +430,7 @@ func (c *KubernetesClient) ListPodsWithCreation(ctx context.Context, namespace s
 		}
 
 		out[item.Name] = PodInfo{
+			Namespace: item.Namespace,
 			CreatedAt: item.CreationTimestamp.Time,
 			StackID:   item.Labels["smctf.io/stack-id"],
 		}
@@ -327,7 +440,7 @@ func (c *KubernetesClient) ListPodsWithCreation(ctx context.Context, namespace s
 }
 
 func (c *KubernetesClient) ListServices(ctx context.Context, namespace string) ([]string, error) {
-	svcList, err := c.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	svcList, err := c.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: stackLabelSelector})
 	if err != nil {
 		return nil, fmt.Errorf("list services: %w", err)
 	}
@@ -343,6 +456,46 @@ func (c *KubernetesClient) ListServices(ctx context.Context, namespace string) (
 	return out, nil
 }
 
+func (c *KubernetesClient) ListServicesWithNodePorts(ctx context.Context, namespace string) ([]ServiceInfo, error) {
+	svcList, err := c.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: stackLabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+
+	out := make([]ServiceInfo, 0, len(svcList.Items))
+	for _, item := range svcList.Items {
+		if item.Name == "" {
+			continue
+		}
+
+		nodePorts := make([]int, 0, len(item.Spec.Ports))
+		for _, port := range item.Spec.Ports {
+			if port.NodePort != 0 {
+				nodePorts = append(nodePorts, int(port.NodePort))
+			}
+		}
+
+		out = append(out, ServiceInfo{
+			Name:      item.Name,
+			Namespace: item.Namespace,
+			StackID:   item.Labels["smctf.io/stack-id"],
+			CreatedAt: item.CreationTimestamp.Time,
+			NodePorts: nodePorts,
+		})
+	}
+
+	return out, nil
+}
+
+func (c *KubernetesClient) DeleteService(ctx context.Context, namespace, serviceName string) error {
+	err := c.client.CoreV1().Services(namespace).Delete(ctx, serviceName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete service: %w", err)
+	}
+
+	return nil
+}
+
 func (c *KubernetesClient) NodeExists(ctx context.Context, nodeID string) (bool, error) {
 	if nodeID == "" {
 		return false, nil
@@ -360,6 +513,19 @@ func (c *KubernetesClient) NodeExists(ctx context.Context, nodeID string) (bool,
 	return false, err
 }
 
+func (c *KubernetesClient) RuntimeClassExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.client.NodeV1().RuntimeClasses().Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return true, nil
+	}
+
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("get runtimeclass: %w", err)
+}
+
 func (c *KubernetesClient) HasIngressNetworkPolicy(ctx context.Context) (bool, error) {
 	policies, err := c.client.NetworkingV1().NetworkPolicies("").List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -383,49 +549,256 @@ func (c *KubernetesClient) GetNodePublicIP(ctx context.Context, nodeID string) (
 		return nil, nil
 	}
 
+	if cached, ok := c.addressCache.get(nodeID); ok {
+		return cached, nil
+	}
+
 	node, err := c.client.CoreV1().Nodes().Get(ctx, nodeID, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
+			c.addressCache.set(nodeID, nil)
 			return nil, nil
 		}
 
 		return nil, err
 	}
 
-	for _, addr := range node.Status.Addresses {
-		if addr.Type == corev1.NodeExternalIP && addr.Address != "" {
-			return &addr.Address, nil
+	address := c.addressPolicy.Resolve(node)
+	c.addressCache.set(nodeID, address)
+
+	return address, nil
+}
+
+func (c *KubernetesClient) nodePool(name string) (config.NodePool, bool) {
+	for _, pool := range c.nodePools {
+		if pool.Name == name {
+			return pool, true
 		}
 	}
 
-	return nil, nil
+	return config.NodePool{}, false
 }
 
-func (c *KubernetesClient) CountSchedulableNodes(ctx context.Context) (int, error) {
+// poolNodes returns the nodes of any node pool, ready or not.
+func (c *KubernetesClient) poolNodes(ctx context.Context) ([]corev1.Node, error) {
+	// A single pool narrows the list on the server; several pools cannot be expressed as
+	// one label selector and are matched here.
 	selector := ""
-	if c.stackNodeRole != "" {
-		selector = fmt.Sprintf("role=%s", c.stackNodeRole)
+	if len(c.nodePools) == 1 {
+		selector = labels.SelectorFromSet(c.nodePools[0].Labels).String()
 	}
 
 	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector})
 	if err != nil {
-		return 0, fmt.Errorf("list nodes: %w", err)
+		return nil, fmt.Errorf("list nodes: %w", err)
 	}
 
-	count := 0
+	out := make([]corev1.Node, 0, len(nodes.Items))
 	for _, node := range nodes.Items {
-		if node.Spec.Unschedulable {
-			continue
+		for _, pool := range c.nodePools {
+			if nodeInPool(node, pool) {
+				out = append(out, node)
+				break
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// schedulableNodes returns the ready, schedulable nodes of any node pool.
+func (c *KubernetesClient) schedulableNodes(ctx context.Context) ([]corev1.Node, error) {
+	nodes, err := c.poolNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]corev1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.Spec.Unschedulable && isNodeReady(node.Status.Conditions) {
+			out = append(out, node)
+		}
+	}
+
+	return out, nil
+}
+
+// CountSchedulableNodes counts the ready, schedulable nodes of each node pool. A node
+// matching the labels of several pools is counted in each of them.
+func (c *KubernetesClient) CountSchedulableNodes(ctx context.Context) (map[string]int, error) {
+	nodes, err := c.schedulableNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(c.nodePools))
+	for _, pool := range c.nodePools {
+		counts[pool.Name] = 0
+		for _, node := range nodes {
+			if nodeInPool(node, pool) {
+				counts[pool.Name]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// QuotaCapacity returns the tightest cpu and memory hard limits of the ResourceQuotas in
+// the namespace.
+func (c *KubernetesClient) QuotaCapacity(ctx context.Context, namespace string) (CapacityBudget, error) {
+	quotas, err := c.client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return CapacityBudget{}, fmt.Errorf("list resource quotas: %w", err)
+	}
+
+	var budget CapacityBudget
+	for _, quota := range quotas.Items {
+		for _, name := range []corev1.ResourceName{corev1.ResourceLimitsCPU, corev1.ResourceRequestsCPU, corev1.ResourceCPU} {
+			if q, ok := quota.Spec.Hard[name]; ok {
+				budget.CPUMilli = tighterLimit(budget.CPUMilli, q.MilliValue())
+			}
+		}
+
+		for _, name := range []corev1.ResourceName{corev1.ResourceLimitsMemory, corev1.ResourceRequestsMemory, corev1.ResourceMemory} {
+			if q, ok := quota.Spec.Hard[name]; ok {
+				budget.MemoryBytes = tighterLimit(budget.MemoryBytes, q.Value())
+			}
+		}
+	}
+
+	return budget, nil
+}
+
+// AllocatableCapacity sums the allocatable cpu and memory of the ready, schedulable
+// stack nodes, in total and per node pool. The total counts every node once.
+func (c *KubernetesClient) AllocatableCapacity(ctx context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	nodes, err := c.schedulableNodes(ctx)
+	if err != nil {
+		return CapacityBudget{}, nil, err
+	}
+
+	var total CapacityBudget
+	byPool := make(map[string]CapacityBudget, len(c.nodePools))
+	for _, node := range nodes {
+		cpuMilli := node.Status.Allocatable.Cpu().MilliValue()
+		memBytes := node.Status.Allocatable.Memory().Value()
+		total.CPUMilli += cpuMilli
+		total.MemoryBytes += memBytes
+
+		for _, pool := range c.nodePools {
+			if nodeInPool(node, pool) {
+				budget := byPool[pool.Name]
+				budget.CPUMilli += cpuMilli
+				budget.MemoryBytes += memBytes
+				byPool[pool.Name] = budget
+			}
 		}
+	}
 
-		if !isNodeReady(node.Status.Conditions) {
+	return total, byPool, nil
+}
+
+// ListNodeCapacity reports the nodes of every node pool with the resources requested by
+// the stack pods running on them.
+func (c *KubernetesClient) ListNodeCapacity(ctx context.Context) ([]NodeCapacity, error) {
+	nodes, err := c.poolNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := c.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: stackLabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	type usage struct {
+		cpuMilli int64
+		memBytes int64
+		pods     int
+	}
+
+	byNode := make(map[string]usage)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
 			continue
 		}
 
-		count++
+		cpuMilli, memBytes := podRequests(pod.Spec)
+		u := byNode[pod.Spec.NodeName]
+		u.cpuMilli += cpuMilli
+		u.memBytes += memBytes
+		u.pods++
+		byNode[pod.Spec.NodeName] = u
 	}
 
-	return count, nil
+	out := make([]NodeCapacity, 0, len(nodes))
+	for _, node := range nodes {
+		var pools []string
+		for _, pool := range c.nodePools {
+			if pool.Name != "" && nodeInPool(node, pool) {
+				pools = append(pools, pool.Name)
+			}
+		}
+
+		u := byNode[node.Name]
+		out = append(out, NodeCapacity{
+			NodeID:                 node.Name,
+			NodePools:              pools,
+			PublicIP:               c.addressPolicy.Resolve(&node),
+			Ready:                  isNodeReady(node.Status.Conditions),
+			Schedulable:            !node.Spec.Unschedulable,
+			AllocatableCPUMilli:    node.Status.Allocatable.Cpu().MilliValue(),
+			AllocatableMemoryBytes: node.Status.Allocatable.Memory().Value(),
+			RequestedCPUMilli:      u.cpuMilli,
+			RequestedMemoryBytes:   u.memBytes,
+			Pods:                   u.pods,
+		})
+	}
+
+	return out, nil
+}
+
+// tighterLimit keeps the smaller of two limits where current may be unset (zero). A zero
+// hard limit is kept as 1 so it still rejects every stack instead of reading as unlimited.
+func tighterLimit(current, v int64) int64 {
+	v = max(v, 1)
+	if current == 0 || v < current {
+		return v
+	}
+
+	return current
+}
+
+// RecordEvent records a Warning event on a stack pod so the reason shows up in
+// `kubectl get events` even after the pod is gone.
+func (c *KubernetesClient) RecordEvent(ctx context.Context, namespace, podID, reason, message string) error {
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", podID, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  namespace,
+			Name:       podID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "smctf"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	if _, err := c.client.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+
+	return nil
 }
 
 func (c *KubernetesClient) ensureNamespace(ctx context.Context, ns string) error {
@@ -446,6 +819,170 @@ func (c *KubernetesClient) ensureNamespace(ctx context.Context, ns string) error
 	return nil
 }
 
+// ensureStackNamespace creates a namespace managed by the server and the objects of the
+// namespace templates in it. Objects that already exist are left as they are.
+func (c *KubernetesClient) ensureStackNamespace(ctx context.Context, ns string, labels map[string]string) error {
+	existing, err := c.client.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if existing.Status.Phase == corev1.NamespaceTerminating {
+			return fmt.Errorf("namespace %s is terminating", ns)
+		}
+	case apierrors.IsNotFound(err):
+		_, err = c.client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: labels}}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create namespace: %w", err)
+		}
+	default:
+		return err
+	}
+
+	templates := c.templates
+	if templates.networkPolicy != nil {
+		policy := templates.networkPolicy.DeepCopy()
+		policy.ObjectMeta = namespacedTemplateMeta(policy.ObjectMeta, ns, "smctf-default-deny")
+		if _, err := c.client.NetworkingV1().NetworkPolicies(ns).Create(ctx, policy, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create networkpolicy: %w", err)
+		}
+	}
+
+	if templates.quota != nil {
+		quota := templates.quota.DeepCopy()
+		quota.ObjectMeta = namespacedTemplateMeta(quota.ObjectMeta, ns, "smctf-quota")
+		if _, err := c.client.CoreV1().ResourceQuotas(ns).Create(ctx, quota, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create resourcequota: %w", err)
+		}
+	}
+
+	if templates.limitRange != nil {
+		limitRange := templates.limitRange.DeepCopy()
+		limitRange.ObjectMeta = namespacedTemplateMeta(limitRange.ObjectMeta, ns, "smctf-limits")
+		if _, err := c.client.CoreV1().LimitRanges(ns).Create(ctx, limitRange, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create limitrange: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// namespacedTemplateMeta keeps a template's name, labels and annotations and places the
+// object in the stack namespace.
+func namespacedTemplateMeta(meta metav1.ObjectMeta, ns, defaultName string) metav1.ObjectMeta {
+	name := meta.Name
+	if name == "" {
+		name = defaultName
+	}
+
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   ns,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+}
+
+// ListStackNamespaces returns the namespaces managed by the server that are not already
+// being deleted, with their creation time.
+func (c *KubernetesClient) ListStackNamespaces(ctx context.Context) (map[string]time.Time, error) {
+	list, err := c.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: managedByLabel + "=" + managedByValue})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	out := make(map[string]time.Time, len(list.Items))
+	for _, item := range list.Items {
+		if item.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+
+		out[item.Name] = item.CreationTimestamp.Time
+	}
+
+	return out, nil
+}
+
+func (c *KubernetesClient) DeleteNamespace(ctx context.Context, namespace string) error {
+	err := c.client.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete namespace: %w", err)
+	}
+
+	return nil
+}
+
+// ListBalloons returns the names of the balloon pods in a namespace.
+func (c *KubernetesClient) ListBalloons(ctx context.Context, namespace string) ([]string, error) {
+	podList, err := c.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: balloonLabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("list balloon pods: %w", err)
+	}
+
+	out := make([]string, 0, len(podList.Items))
+	for _, item := range podList.Items {
+		out = append(out, item.Name)
+	}
+
+	return out, nil
+}
+
+// CreateBalloon creates a pause pod with the requests of a pending stack on the stack's
+// node pool. Unlike stack pods it does not wait to be scheduled: staying pending is what
+// makes the cluster autoscaler add a node.
+func (c *KubernetesClient) CreateBalloon(ctx context.Context, req BalloonRequest) error {
+	if err := c.ensureNamespace(ctx, req.Namespace); err != nil {
+		return err
+	}
+
+	pool, ok := c.nodePool(req.NodePool)
+	if !ok {
+		return fmt.Errorf("unknown node pool %q", req.NodePool)
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": "smctf-balloon"}
+	if req.NodePool != "" {
+		labels[nodePoolAnnotation] = req.NodePool
+	}
+
+	pod := corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      req.Name,
+			Namespace: req.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			PriorityClassName:             req.PriorityClassName,
+			TerminationGracePeriodSeconds: int64Ptr(0),
+			AutomountServiceAccountToken:  boolPtr(false),
+			Containers: []corev1.Container{{
+				Name:  "balloon",
+				Image: req.Image,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    *resource.NewMilliQuantity(req.CPUMilli, resource.DecimalSI),
+						corev1.ResourceMemory: *resource.NewQuantity(req.MemoryBytes, resource.BinarySI),
+					},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: boolPtr(false),
+					RunAsNonRoot:             boolPtr(true),
+					RunAsUser:                int64Ptr(65535),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+			}},
+		},
+	}
+
+	applyNodePool(&pod, pool)
+
+	_, err := c.client.CoreV1().Pods(req.Namespace).Create(ctx, &pod, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create balloon pod: %w", err)
+	}
+
+	return nil
+}
+
 func mapPodPhaseToStatus(phase corev1.PodPhase) Status {
 	switch phase {
 	case corev1.PodRunning:
@@ -461,6 +998,16 @@ func mapPodPhaseToStatus(phase corev1.PodPhase) Status {
 	}
 }
 
+func isPreemptedByScheduler(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.DisruptionTarget && cond.Status == corev1.ConditionTrue && cond.Reason == "PreemptionByScheduler" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isNodeReady(conditions []corev1.NodeCondition) bool {
 	for _, cond := range conditions {
 		if cond.Type != corev1.NodeReady {
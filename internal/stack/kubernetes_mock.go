@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 type MockKubernetesClient struct {
@@ -14,7 +20,34 @@ type MockKubernetesClient struct {
 	nodes    map[string]bool
 	nodeIPs  map[string]*string
 	pods     map[string]podState
-	services map[string]string
+	services map[string]serviceState
+	quota    CapacityBudget
+	perNode  CapacityBudget
+	events   []mockEvent
+
+	namespaces map[string]mockNamespace
+	// nodePools maps nodes to a node pool; without it every node is in the unnamed pool.
+	nodePools map[string]string
+	balloons  map[string]BalloonRequest
+}
+
+type mockNamespace struct {
+	labels    map[string]string
+	createdAt time.Time
+}
+
+type mockEvent struct {
+	namespace string
+	podID     string
+	reason    string
+	message   string
+}
+
+type serviceState struct {
+	namespace string
+	stackID   string
+	nodePorts []int
+	createdAt time.Time
 }
 
 type podState struct {
@@ -25,6 +58,13 @@ type podState struct {
 	status    Status
 	createdAt time.Time
 	stackID   string
+	ownerID   string
+	priority  string
+	nodePool  string
+	cpuMilli  int64
+	memBytes  int64
+	// configMaps are the names of the ConfigMaps the pod owns.
+	configMaps []string
 }
 
 func NewMockKubernetesClient(seed int64) *MockKubernetesClient {
@@ -44,8 +84,11 @@ func NewMockKubernetesClient(seed int64) *MockKubernetesClient {
 			"worker-b": nil,
 			"worker-c": strPtr("203.0.113.12"),
 		},
-		pods:     make(map[string]podState),
-		services: make(map[string]string),
+		pods:       make(map[string]podState),
+		services:   make(map[string]serviceState),
+		namespaces: make(map[string]mockNamespace),
+		balloons:   make(map[string]BalloonRequest),
+		perNode:    CapacityBudget{CPUMilli: 4000, MemoryBytes: 8 << 30},
 	}
 }
 
@@ -53,7 +96,14 @@ func (m *MockKubernetesClient) CreatePodAndService(_ context.Context, req Provis
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	nodeID, err := m.pickNodeLocked()
+	var pod corev1.Pod
+	_ = sigsyaml.Unmarshal([]byte(req.PodSpecYML), &pod)
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string)
+	}
+	applyPlacement(&pod, req.Placement, req.OwnerID, req.NamespaceLabels != nil)
+
+	nodeID, err := m.pickNodeLocked(&pod, req.Namespace, req.NodePool)
 	if err != nil {
 		return ProvisionResult{}, err
 	}
@@ -66,6 +116,17 @@ func (m *MockKubernetesClient) CreatePodAndService(_ context.Context, req Provis
 	podID := fmt.Sprintf("stack-%s", podName)
 	serviceName := fmt.Sprintf("svc-%s", req.StackID)
 
+	if _, ok := m.namespaces[req.Namespace]; !ok && req.NamespaceLabels != nil {
+		m.namespaces[req.Namespace] = mockNamespace{labels: req.NamespaceLabels, createdAt: time.Now().UTC()}
+	}
+
+	cpuMilli, memBytes := podRequests(pod.Spec)
+
+	var configMaps []string
+	for _, cm := range scopeConfigMaps(&pod, req.ConfigMaps, podName) {
+		configMaps = append(configMaps, cm.Name)
+	}
+
 	m.pods[podID] = podState{
 		namespace: req.Namespace,
 		podID:     podID,
@@ -74,8 +135,26 @@ func (m *MockKubernetesClient) CreatePodAndService(_ context.Context, req Provis
 		status:    StatusRunning,
 		createdAt: time.Now().UTC(),
 		stackID:   req.StackID,
+		ownerID:   req.OwnerID,
+		priority:  req.PriorityClassName,
+		nodePool:  req.NodePool,
+		cpuMilli:  cpuMilli,
+		memBytes:  memBytes,
+
+		configMaps: configMaps,
+	}
+
+	nodePorts := make([]int, 0, len(req.Ports))
+	for _, p := range req.Ports {
+		nodePorts = append(nodePorts, p.NodePort)
+	}
+
+	m.services[serviceName] = serviceState{
+		namespace: req.Namespace,
+		stackID:   req.StackID,
+		nodePorts: nodePorts,
+		createdAt: time.Now().UTC(),
 	}
-	m.services[serviceName] = req.Namespace
 
 	return ProvisionResult{
 		PodID:       podID,
@@ -90,8 +169,8 @@ func (m *MockKubernetesClient) DeletePodAndService(_ context.Context, namespace,
 	defer m.mu.Unlock()
 
 	if serviceName != "" {
-		if svcNS, ok := m.services[serviceName]; ok {
-			if svcNS != namespace {
+		if svc, ok := m.services[serviceName]; ok {
+			if svc.namespace != namespace {
 				return fmt.Errorf("service namespace mismatch")
 			}
 
@@ -99,6 +178,10 @@ func (m *MockKubernetesClient) DeletePodAndService(_ context.Context, namespace,
 		}
 	}
 
+	if b, ok := m.balloons[podID]; ok && b.Namespace == namespace {
+		delete(m.balloons, podID)
+	}
+
 	p, ok := m.pods[podID]
 	if ok {
 		if p.namespace != namespace {
@@ -108,7 +191,7 @@ func (m *MockKubernetesClient) DeletePodAndService(_ context.Context, namespace,
 		delete(m.pods, podID)
 
 		if p.service != "" {
-			if svcNS, svcOK := m.services[p.service]; svcOK && svcNS == namespace {
+			if svc, svcOK := m.services[p.service]; svcOK && svc.namespace == namespace {
 				delete(m.services, p.service)
 			}
 		}
@@ -138,7 +221,7 @@ func (m *MockKubernetesClient) ListPods(_ context.Context, namespace string) ([]
 
 	out := make([]string, 0)
 	for _, p := range m.pods {
-		if p.namespace == namespace {
+		if namespace == "" || p.namespace == namespace {
 			out = append(out, p.podID)
 		}
 	}
@@ -151,8 +234,9 @@ func (m *MockKubernetesClient) ListPodsWithCreation(_ context.Context, namespace
 
 	out := make(map[string]PodInfo)
 	for _, p := range m.pods {
-		if p.namespace == namespace {
+		if namespace == "" || p.namespace == namespace {
 			out[p.podID] = PodInfo{
+				Namespace: p.namespace,
 				CreatedAt: p.createdAt,
 				StackID:   p.stackID,
 			}
@@ -167,8 +251,8 @@ func (m *MockKubernetesClient) ListServices(_ context.Context, namespace string)
 	defer m.mu.RUnlock()
 
 	out := make([]string, 0)
-	for svcName, svcNS := range m.services {
-		if svcNS == namespace {
+	for svcName, svc := range m.services {
+		if namespace == "" || svc.namespace == namespace {
 			out = append(out, svcName)
 		}
 	}
@@ -176,6 +260,41 @@ func (m *MockKubernetesClient) ListServices(_ context.Context, namespace string)
 	return out, nil
 }
 
+func (m *MockKubernetesClient) ListServicesWithNodePorts(_ context.Context, namespace string) ([]ServiceInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]ServiceInfo, 0)
+	for svcName, svc := range m.services {
+		if namespace == "" || svc.namespace == namespace {
+			out = append(out, ServiceInfo{
+				Name:      svcName,
+				Namespace: svc.namespace,
+				StackID:   svc.stackID,
+				CreatedAt: svc.createdAt,
+				NodePorts: append([]int(nil), svc.nodePorts...),
+			})
+		}
+	}
+
+	return out, nil
+}
+
+func (m *MockKubernetesClient) DeleteService(_ context.Context, namespace, serviceName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if svc, ok := m.services[serviceName]; ok {
+		if svc.namespace != namespace {
+			return fmt.Errorf("service namespace mismatch")
+		}
+
+		delete(m.services, serviceName)
+	}
+
+	return nil
+}
+
 func (m *MockKubernetesClient) NodeExists(_ context.Context, nodeID string) (bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -187,6 +306,10 @@ func (m *MockKubernetesClient) HasIngressNetworkPolicy(_ context.Context) (bool,
 	return true, nil
 }
 
+func (m *MockKubernetesClient) RuntimeClassExists(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
 func (m *MockKubernetesClient) GetNodePublicIP(_ context.Context, nodeID string) (*string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -198,31 +321,225 @@ func (m *MockKubernetesClient) GetNodePublicIP(_ context.Context, nodeID string)
 	return ip, nil
 }
 
-func (m *MockKubernetesClient) CountSchedulableNodes(_ context.Context) (int, error) {
+func (m *MockKubernetesClient) CountSchedulableNodes(_ context.Context) (map[string]int, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	count := 0
-	for _, alive := range m.nodes {
+	counts := make(map[string]int)
+	for id, alive := range m.nodes {
 		if alive {
-			count++
+			counts[m.nodePools[id]]++
 		}
 	}
 
-	return count, nil
+	return counts, nil
 }
 
-func (m *MockKubernetesClient) pickNodeLocked() (string, error) {
+func (m *MockKubernetesClient) QuotaCapacity(_ context.Context, _ string) (CapacityBudget, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.quota, nil
+}
+
+func (m *MockKubernetesClient) AllocatableCapacity(_ context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var total CapacityBudget
+	byPool := make(map[string]CapacityBudget)
+	for id, alive := range m.nodes {
+		if !alive {
+			continue
+		}
+
+		total.CPUMilli += m.perNode.CPUMilli
+		total.MemoryBytes += m.perNode.MemoryBytes
+
+		budget := byPool[m.nodePools[id]]
+		budget.CPUMilli += m.perNode.CPUMilli
+		budget.MemoryBytes += m.perNode.MemoryBytes
+		byPool[m.nodePools[id]] = budget
+	}
+
+	return total, byPool, nil
+}
+
+func (m *MockKubernetesClient) ListNodeCapacity(_ context.Context) ([]NodeCapacity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]NodeCapacity, 0, len(m.nodes))
+	for id, alive := range m.nodes {
+		node := NodeCapacity{
+			NodeID:                 id,
+			PublicIP:               m.nodeIPs[id],
+			Ready:                  alive,
+			Schedulable:            alive,
+			AllocatableCPUMilli:    m.perNode.CPUMilli,
+			AllocatableMemoryBytes: m.perNode.MemoryBytes,
+		}
+		if pool := m.nodePools[id]; pool != "" {
+			node.NodePools = []string{pool}
+		}
+
+		for _, p := range m.pods {
+			if p.nodeID == id {
+				node.RequestedCPUMilli += p.cpuMilli
+				node.RequestedMemoryBytes += p.memBytes
+				node.Pods++
+			}
+		}
+
+		out = append(out, node)
+	}
+
+	return out, nil
+}
+
+// pickNodeLocked imitates the scheduler for the constraints applyPlacement sets: a random
+// node without any, otherwise the least (spread) or most (binpack) loaded node, skipping
+// nodes that run a stack of the owner under a required anti-affinity and nodes outside
+// the requested node pool. Like the scheduler, topology spread and affinity terms
+// without a namespace selector only count pods in the pod's own namespace.
+func (m *MockKubernetesClient) pickNodeLocked(pod *corev1.Pod, namespace, nodePool string) (string, error) {
+	inNamespace := func(p podState) bool { return p.namespace == namespace }
+	termScope := func(term corev1.PodAffinityTerm) func(podState) bool {
+		if term.NamespaceSelector != nil {
+			return func(podState) bool { return true }
+		}
+
+		return inNamespace
+	}
+
+	var (
+		order      string
+		scope      = inNamespace
+		ownerHash  string
+		ownerScope = inNamespace
+	)
+	if len(pod.Spec.TopologySpreadConstraints) > 0 {
+		order = config.PlacementSpread
+	}
+
+	if affinity := pod.Spec.Affinity; affinity != nil {
+		if anti := affinity.PodAntiAffinity; anti != nil {
+			for _, term := range anti.PreferredDuringSchedulingIgnoredDuringExecution {
+				order, scope = config.PlacementSpread, termScope(term.PodAffinityTerm)
+			}
+
+			for _, term := range anti.RequiredDuringSchedulingIgnoredDuringExecution {
+				order, scope = config.PlacementSpread, termScope(term)
+				ownerHash, ownerScope = term.LabelSelector.MatchLabels[ownerHashLabel], termScope(term)
+			}
+		}
+
+		if aff := affinity.PodAffinity; aff != nil {
+			for _, term := range aff.PreferredDuringSchedulingIgnoredDuringExecution {
+				order, scope = config.PlacementBinpack, termScope(term.PodAffinityTerm)
+			}
+		}
+	}
+
+	load := make(map[string]int)
+	ownerNodes := make(map[string]bool)
+	for _, p := range m.pods {
+		if scope(p) {
+			load[p.nodeID]++
+		}
+
+		if ownerHash != "" && p.ownerID != "" && ownerLabelValue(p.ownerID) == ownerHash && ownerScope(p) {
+			ownerNodes[p.nodeID] = true
+		}
+	}
+
 	healthy := make([]string, 0)
 	for id, alive := range m.nodes {
-		if alive {
-			healthy = append(healthy, id)
+		if !alive || ownerNodes[id] {
+			continue
+		}
+
+		if m.nodePools != nil && m.nodePools[id] != nodePool {
+			continue
 		}
+
+		healthy = append(healthy, id)
 	}
 
 	if len(healthy) == 0 {
 		return "", fmt.Errorf("no schedulable nodes")
 	}
 
+	sort.Strings(healthy)
+	switch order {
+	case config.PlacementSpread:
+		sort.SliceStable(healthy, func(i, j int) bool { return load[healthy[i]] < load[healthy[j]] })
+		return healthy[0], nil
+	case config.PlacementBinpack:
+		sort.SliceStable(healthy, func(i, j int) bool { return load[healthy[i]] > load[healthy[j]] })
+		return healthy[0], nil
+	}
+
 	return healthy[m.rand.Intn(len(healthy))], nil
 }
+
+func (m *MockKubernetesClient) RecordEvent(_ context.Context, namespace, podID, reason, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, mockEvent{namespace: namespace, podID: podID, reason: reason, message: message})
+	return nil
+}
+
+func (m *MockKubernetesClient) ListStackNamespaces(_ context.Context) (map[string]time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]time.Time, len(m.namespaces))
+	for name, ns := range m.namespaces {
+		out[name] = ns.createdAt
+	}
+
+	return out, nil
+}
+
+func (m *MockKubernetesClient) DeleteNamespace(_ context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.namespaces, namespace)
+	for podID, p := range m.pods {
+		if p.namespace == namespace {
+			delete(m.pods, podID)
+		}
+	}
+	for name, svc := range m.services {
+		if svc.namespace == namespace {
+			delete(m.services, name)
+		}
+	}
+
+	return nil
+}
+
+func (m *MockKubernetesClient) ListBalloons(_ context.Context, namespace string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]string, 0, len(m.balloons))
+	for name, b := range m.balloons {
+		if b.Namespace == namespace {
+			out = append(out, name)
+		}
+	}
+
+	return out, nil
+}
+
+func (m *MockKubernetesClient) CreateBalloon(_ context.Context, req BalloonRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.balloons[req.Name] = req
+	return nil
+}
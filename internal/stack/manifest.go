@@ -0,0 +1,113 @@
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// targetPortsAnnotation declares the target ports on the pod itself, as a JSON list in
+// the target_port format. It is read when a request has no target_port.
+const targetPortsAnnotation = "smctf.io/target-ports"
+
+// parseManifest reads a pod spec given as YAML or JSON. It may be a multi-document
+// stream holding exactly one Pod plus supporting ConfigMaps.
+func parseManifest(raw string) (corev1.Pod, []corev1.ConfigMap, *FieldError) {
+	docs, err := manifestDocuments(raw)
+	if err != nil {
+		return corev1.Pod{}, nil, &FieldError{Field: "pod_spec", Code: CodeInvalid, Reason: "yaml parse failed"}
+	}
+
+	var (
+		pod        corev1.Pod
+		pods       int
+		configMaps []corev1.ConfigMap
+	)
+	for i, doc := range docs {
+		field := "kind"
+		if len(docs) > 1 {
+			field = fmt.Sprintf("pod_spec[%d].kind", i)
+		}
+
+		kind, err := documentKind(doc)
+		if err != nil {
+			return corev1.Pod{}, nil, &FieldError{Field: "pod_spec", Code: CodeInvalid, Reason: "yaml parse failed"}
+		}
+
+		switch {
+		case strings.EqualFold(kind, "Pod"):
+			pods++
+			err = json.Unmarshal(doc, &pod)
+		case strings.EqualFold(kind, "ConfigMap") && len(docs) > 1:
+			var cm corev1.ConfigMap
+			err = json.Unmarshal(doc, &cm)
+			configMaps = append(configMaps, cm)
+		case len(docs) > 1:
+			return corev1.Pod{}, nil, &FieldError{Field: field, Code: CodeNotAllowed, Reason: fmt.Sprintf("kind must be Pod or ConfigMap, got %q", kind)}
+		default:
+			return corev1.Pod{}, nil, &FieldError{Field: field, Code: CodeInvalid, Reason: "kind must be Pod"}
+		}
+
+		if err != nil {
+			return corev1.Pod{}, nil, &FieldError{Field: "pod_spec", Code: CodeInvalid, Reason: "yaml parse failed"}
+		}
+	}
+
+	if pods != 1 {
+		return corev1.Pod{}, nil, &FieldError{Field: "pod_spec", Code: CodeInvalid, Reason: fmt.Sprintf("pod_spec must hold exactly one Pod, got %d", pods)}
+	}
+
+	return pod, configMaps, nil
+}
+
+// manifestDocuments splits a YAML or JSON stream into JSON documents, skipping empty ones.
+func manifestDocuments(raw string) ([]json.RawMessage, error) {
+	var docs []json.RawMessage
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(raw), 4096)
+	for {
+		var doc json.RawMessage
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+
+			return nil, err
+		}
+
+		if trimmed := bytes.TrimSpace(doc); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+
+		docs = append(docs, doc)
+	}
+}
+
+func documentKind(doc json.RawMessage) (string, error) {
+	var meta struct {
+		Kind string `json:"kind"`
+	}
+	err := json.Unmarshal(doc, &meta)
+	return meta.Kind, err
+}
+
+// annotatedTargetPorts returns the target ports declared with targetPortsAnnotation.
+func annotatedTargetPorts(errs *ValidationErrors, pod corev1.Pod) []PortSpec {
+	raw := strings.TrimSpace(pod.Annotations[targetPortsAnnotation])
+	if raw == "" {
+		return nil
+	}
+
+	var ports []PortSpec
+	if err := json.Unmarshal([]byte(raw), &ports); err != nil {
+		errs.addInput("metadata.annotations["+targetPortsAnnotation+"]", CodeInvalid, "must be a JSON list of target ports")
+		return nil
+	}
+
+	return ports
+}
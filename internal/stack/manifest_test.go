@@ -0,0 +1,105 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+
+	"smctf/internal/config"
+)
+
+func TestValidatorMultiDocumentManifest(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	res, err := v.ValidatePodSpec(`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx-conf
+data:
+  default.conf: "server { listen 8080; }"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  annotations:
+    smctf.io/target-ports: '[{"container_port": 8080, "protocol": "TCP", "name": "web"}]'
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(res.TargetPorts) != 1 || res.TargetPorts[0].ContainerPort != 8080 || res.TargetPorts[0].Name != "web" {
+		t.Fatalf("expected annotated target port, got %+v", res.TargetPorts)
+	}
+
+	if len(res.ConfigMaps) != 1 || res.ConfigMaps[0].Name != "nginx-conf" {
+		t.Fatalf("expected supporting ConfigMap, got %+v", res.ConfigMaps)
+	}
+}
+
+func TestValidatorJSONPodSpec(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	res, err := v.ValidatePodSpec(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"web"},"spec":{"containers":[{"name":"app","image":"nginx:latest","ports":[{"containerPort":8080}],"resources":{"limits":{"cpu":"100m","memory":"64Mi"}}}]}}`,
+		[]PortSpec{{ContainerPort: 8080, Protocol: "TCP"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.RequestedMilli != 100 || res.RequestedBytes != 64<<20 {
+		t.Fatalf("unexpected resources: %d %d", res.RequestedMilli, res.RequestedBytes)
+	}
+}
+
+func TestValidatorRejectsManifestKinds(t *testing.T) {
+	pod := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+	targets := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+	tests := []struct {
+		name    string
+		spec    string
+		targets []PortSpec
+		field   string
+	}{
+		{"secret", pod + "---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: flag\n", targets, "pod_spec[1].kind"},
+		{"two pods", pod + "---" + pod, targets, "pod_spec"},
+		{"no pod", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n", targets, "pod_spec"},
+		{"unnamed configmap", pod + "---\napiVersion: v1\nkind: ConfigMap\ndata:\n  a: b\n", targets, "config_maps[0].metadata.name"},
+		{"bad annotation", strings.Replace(pod, "name: web\n", "name: web\n  annotations:\n    smctf.io/target-ports: '8080'\n", 1), nil, "metadata.annotations[smctf.io/target-ports]"},
+	}
+
+	v := NewValidator(config.StackConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidatePodSpec(tt.spec, tt.targets)
+			violations := Violations(err)
+			if len(violations) == 0 || violations[0].Field != tt.field {
+				t.Fatalf("expected violation on %s, got %+v", tt.field, violations)
+			}
+		})
+	}
+}
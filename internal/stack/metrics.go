@@ -0,0 +1,146 @@
+package stack
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var portFindingKinds = []PortFindingKind{
+	PortFindingDanglingLock,
+	PortFindingUnknownServicePort,
+	PortFindingStaleReservation,
+	PortFindingMissingStackLock,
+	PortFindingConflictingLock,
+}
+
+var (
+	portLedgerFindings = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "smctf",
+		Subsystem: "port_ledger",
+		Name:      "findings",
+		Help:      "Port ledger differences found by the last reconciliation pass, by kind.",
+	}, []string{"kind"})
+
+	portLedgerRepairs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "smctf",
+		Subsystem: "port_ledger",
+		Name:      "repairs_total",
+		Help:      "Port ledger differences repaired by reconciliation, by kind.",
+	}, []string{"kind"})
+
+	portLedgerRepairFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "smctf",
+		Subsystem: "port_ledger",
+		Name:      "repair_failures_total",
+		Help:      "Port ledger repairs that failed, by kind.",
+	}, []string{"kind"})
+
+	portLedgerLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "smctf",
+		Subsystem: "port_ledger",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "Unix time of the last port ledger reconciliation pass.",
+	})
+
+	portReservationAttempts = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "smctf",
+		Subsystem: "port_reservation",
+		Name:      "attempts",
+		Help:      "DynamoDB transactions written per node port reservation, including retries after a conditional check failed.",
+		Buckets:   []float64{1, 2, 3, 4, 5},
+	})
+
+	portReservationItems = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "smctf",
+		Subsystem: "port_reservation",
+		Name:      "transaction_items",
+		Help:      "Items in a DynamoDB node port reservation transaction: port locks plus bitmap shards.",
+		Buckets:   prometheus.ExponentialBuckets(2, 2, 6),
+	})
+
+	portReservationConflicts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "smctf",
+		Subsystem: "port_reservation",
+		Name:      "conflicts_total",
+		Help:      "DynamoDB node port reservation transactions canceled by a conditional check.",
+	})
+
+	stackPreemptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "smctf",
+		Subsystem: "stack",
+		Name:      "preemptions_total",
+		Help:      "Stacks preempted for a higher priority tier, by the tier of the preempted stack.",
+	}, []string{"priority"})
+
+	stackPendingStacks = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "smctf",
+		Subsystem: "stack",
+		Name:      "pending_stacks",
+		Help:      "Stacks waiting for cluster capacity, by node pool and source (queued or rejected).",
+	}, []string{"node_pool", "source"})
+
+	stackPendingCPU = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "smctf",
+		Subsystem: "stack",
+		Name:      "pending_cpu_cores",
+		Help:      "CPU requested by stacks waiting for cluster capacity, by node pool and source.",
+	}, []string{"node_pool", "source"})
+
+	stackPendingMemory = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "smctf",
+		Subsystem: "stack",
+		Name:      "pending_memory_bytes",
+		Help:      "Memory requested by stacks waiting for cluster capacity, by node pool and source.",
+	}, []string{"node_pool", "source"})
+)
+
+func recordPortReconcileReport(report PortReconcileReport) {
+	counts := make(map[PortFindingKind]int, len(portFindingKinds))
+	for _, finding := range report.Findings {
+		counts[finding.Kind]++
+		if report.DryRun {
+			continue
+		}
+
+		if finding.Repaired {
+			portLedgerRepairs.WithLabelValues(string(finding.Kind)).Inc()
+		} else if finding.Error != "" {
+			portLedgerRepairFailures.WithLabelValues(string(finding.Kind)).Inc()
+		}
+	}
+
+	for _, kind := range portFindingKinds {
+		portLedgerFindings.WithLabelValues(string(kind)).Set(float64(counts[kind]))
+	}
+
+	portLedgerLastRun.Set(float64(report.CheckedAt.Unix()))
+}
+
+// recordPendingDemand sets the pending demand gauges of one source. Every pool in pools
+// is set, so a pool whose demand was met drops back to zero.
+func recordPendingDemand(source string, items []demandItem, pools []string) {
+	type total struct {
+		stacks   int
+		cpuMilli int64
+		memBytes int64
+	}
+
+	totals := make(map[string]total, len(pools))
+	for _, pool := range pools {
+		totals[pool] = total{}
+	}
+
+	for _, item := range items {
+		t := totals[item.nodePool]
+		t.stacks++
+		t.cpuMilli += item.cpuMilli
+		t.memBytes += item.memBytes
+		totals[item.nodePool] = t
+	}
+
+	for pool, t := range totals {
+		stackPendingStacks.WithLabelValues(pool, source).Set(float64(t.stacks))
+		stackPendingCPU.WithLabelValues(pool, source).Set(float64(t.cpuMilli) / 1000)
+		stackPendingMemory.WithLabelValues(pool, source).Set(float64(t.memBytes))
+	}
+}
@@ -10,6 +10,7 @@ const (
 	StatusStopped     Status = "stopped"
 	StatusFailed      Status = "failed"
 	StatusNodeDeleted Status = "node_deleted"
+	StatusPreempted   Status = "preempted"
 )
 
 type Stack struct {
@@ -28,22 +29,90 @@ type Stack struct {
 	UpdatedAt      time.Time     `json:"updated_at"`
 	RequestedMilli int64         `json:"requested_cpu_milli"`
 	RequestedBytes int64         `json:"requested_memory_bytes"`
+	// RequestedEphemeralBytes is the node disk the stack may use: container
+	// ephemeral-storage plus disk-backed emptyDir sizeLimits.
+	RequestedEphemeralBytes int64        `json:"requested_ephemeral_storage_bytes"`
+	OwnerID                 string       `json:"owner_id,omitempty"`
+	ChallengeID             string       `json:"challenge_id,omitempty"`
+	PortPool                string       `json:"port_pool,omitempty"`
+	Placement               string       `json:"placement,omitempty"`
+	Priority                string       `json:"priority,omitempty"`
+	PreemptedBy             string       `json:"preempted_by,omitempty"`
+	ClusterID               string       `json:"cluster_id,omitempty"`
+	NodePool                string       `json:"node_pool,omitempty"`
+	Connection              []Connection `json:"connection"`
 }
 
 type PortSpec struct {
 	ContainerPort int    `json:"container_port"`
 	Protocol      string `json:"protocol"`
+	Scheme        string `json:"scheme,omitempty"`
+	Name          string `json:"name,omitempty"`
+	NodePort      int    `json:"node_port,omitempty"`
+	Sticky        bool   `json:"sticky,omitempty"`
 }
 
 type PortMapping struct {
 	ContainerPort int    `json:"container_port"`
 	Protocol      string `json:"protocol"`
 	NodePort      int    `json:"node_port"`
+	Scheme        string `json:"scheme,omitempty"`
+	Name          string `json:"name,omitempty"`
+}
+
+type Connection struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"container_port"`
+	Protocol      string `json:"protocol"`
+	NodePort      int    `json:"node_port"`
+	Scheme        string `json:"scheme"`
+	Host          string `json:"host"`
+	Rendered      string `json:"rendered"`
 }
 
 type CreateInput struct {
 	PodSpecYML  string
 	TargetPorts []PortSpec
+	OwnerID     string
+	ChallengeID string
+	PortPool    string
+	Priority    string
+	Region      string
+	NodePool    string
+	Queue       bool
+}
+
+type TicketStatus string
+
+const (
+	TicketStatusQueued       TicketStatus = "queued"
+	TicketStatusProvisioning TicketStatus = "provisioning"
+	TicketStatusProvisioned  TicketStatus = "provisioned"
+	TicketStatusFailed       TicketStatus = "failed"
+	TicketStatusCancelled    TicketStatus = "cancelled"
+	TicketStatusExpired      TicketStatus = "expired"
+)
+
+type QueueTicket struct {
+	TicketID    string       `json:"ticket_id"`
+	Status      TicketStatus `json:"status"`
+	Position    int          `json:"position"`
+	ETA         *time.Time   `json:"eta"`
+	OwnerID     string       `json:"owner_id"`
+	ChallengeID string       `json:"challenge_id,omitempty"`
+	PortPool    string       `json:"port_pool,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Region      string       `json:"region,omitempty"`
+	NodePool    string       `json:"node_pool,omitempty"`
+	StackID     string       `json:"stack_id,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	PodSpecYML  string       `json:"-"`
+	TargetPorts []PortSpec   `json:"-"`
+
+	RequestedMilli int64 `json:"-"`
+	RequestedBytes int64 `json:"-"`
 }
 
 type JobStatus string
@@ -73,12 +142,121 @@ type BatchDeleteJob struct {
 }
 
 type Stats struct {
-	TotalStacks         int            `json:"total_stacks"`
-	ActiveStacks        int            `json:"active_stacks"`
-	NodeDistribution    map[string]int `json:"node_distribution"`
-	UsedNodePorts       int            `json:"used_node_ports"`
-	ReservedCPUMilli    int64          `json:"reserved_cpu_milli"`
-	ReservedMemoryBytes int64          `json:"reserved_memory_bytes"`
+	TotalStacks         int                          `json:"total_stacks"`
+	ActiveStacks        int                          `json:"active_stacks"`
+	NodeDistribution    map[string]int               `json:"node_distribution"`
+	UsedNodePorts       int                          `json:"used_node_ports"`
+	NodePortPools       map[string]NodePortPoolUsage `json:"node_port_pools"`
+	ReservedCPUMilli    int64                        `json:"reserved_cpu_milli"`
+	ReservedMemoryBytes int64                        `json:"reserved_memory_bytes"`
+	// ReservedEphemeralBytes sums RequestedEphemeralBytes like the CPU and memory totals.
+	ReservedEphemeralBytes int64 `json:"reserved_ephemeral_storage_bytes"`
+	// PlacementStrategy is the configured default; PlacementDistribution breaks the
+	// node distribution of active stacks down by the strategy they were placed with.
+	PlacementStrategy     string                    `json:"placement_strategy"`
+	PlacementDistribution map[string]map[string]int `json:"placement_distribution"`
+	// Clusters is keyed by cluster id and is empty without STACK_CLUSTERS.
+	Clusters map[string]ClusterUsage `json:"clusters"`
+	// NodePools is keyed by node pool and is empty without STACK_NODE_POOLS.
+	NodePools map[string]NodePoolUsage `json:"node_pools"`
+}
+
+type ClusterUsage struct {
+	Region              string `json:"region,omitempty"`
+	TotalStacks         int    `json:"total_stacks"`
+	ActiveStacks        int    `json:"active_stacks"`
+	ReservedCPUMilli    int64  `json:"reserved_cpu_milli"`
+	ReservedMemoryBytes int64  `json:"reserved_memory_bytes"`
+}
+
+// NodePoolUsage combines the live schedulable nodes and allocatable resources of a node
+// pool with the stacks placed on it.
+type NodePoolUsage struct {
+	Nodes                  int   `json:"nodes"`
+	AllocatableCPUMilli    int64 `json:"allocatable_cpu_milli"`
+	AllocatableMemoryBytes int64 `json:"allocatable_memory_bytes"`
+	TotalStacks            int   `json:"total_stacks"`
+	ActiveStacks           int   `json:"active_stacks"`
+	ReservedCPUMilli       int64 `json:"reserved_cpu_milli"`
+	ReservedMemoryBytes    int64 `json:"reserved_memory_bytes"`
+}
+
+// CapacityReport lists the stack nodes of every cluster. The totals only count ready,
+// schedulable nodes, and the headroom is summed per node since a stack has to fit on
+// one node.
+type CapacityReport struct {
+	CheckedAt              time.Time      `json:"checked_at"`
+	Nodes                  []NodeCapacity `json:"nodes"`
+	AllocatableCPUMilli    int64          `json:"allocatable_cpu_milli"`
+	AllocatableMemoryBytes int64          `json:"allocatable_memory_bytes"`
+	RequestedCPUMilli      int64          `json:"requested_cpu_milli"`
+	RequestedMemoryBytes   int64          `json:"requested_memory_bytes"`
+	HeadroomCPUMilli       int64          `json:"headroom_cpu_milli"`
+	HeadroomMemoryBytes    int64          `json:"headroom_memory_bytes"`
+	// Fits is only set when the report was asked for a stack size.
+	Fits *int `json:"fits,omitempty"`
+}
+
+// NodeCapacity is one stack node. Requested and Pods only cover stack pods.
+type NodeCapacity struct {
+	NodeID                 string   `json:"node_id"`
+	ClusterID              string   `json:"cluster_id,omitempty"`
+	NodePools              []string `json:"node_pools,omitempty"`
+	PublicIP               *string  `json:"public_ip"`
+	Ready                  bool     `json:"ready"`
+	Schedulable            bool     `json:"schedulable"`
+	AllocatableCPUMilli    int64    `json:"allocatable_cpu_milli"`
+	AllocatableMemoryBytes int64    `json:"allocatable_memory_bytes"`
+	RequestedCPUMilli      int64    `json:"requested_cpu_milli"`
+	RequestedMemoryBytes   int64    `json:"requested_memory_bytes"`
+	HeadroomCPUMilli       int64    `json:"headroom_cpu_milli"`
+	HeadroomMemoryBytes    int64    `json:"headroom_memory_bytes"`
+	Pods                   int      `json:"pods"`
+	Fits                   *int     `json:"fits,omitempty"`
+}
+
+type NodePortPoolUsage struct {
+	Min      int `json:"min"`
+	Max      int `json:"max"`
+	Used     int `json:"used"`
+	Capacity int `json:"capacity"`
+}
+
+type PortLock struct {
+	Port         int
+	StackID      string
+	LockedAt     time.Time
+	CoolingUntil time.Time
+}
+
+type PortFindingKind string
+
+const (
+	PortFindingDanglingLock       PortFindingKind = "dangling_lock"
+	PortFindingUnknownServicePort PortFindingKind = "unknown_service_port"
+	PortFindingStaleReservation   PortFindingKind = "stale_reservation"
+	PortFindingMissingStackLock   PortFindingKind = "missing_stack_lock"
+	PortFindingConflictingLock    PortFindingKind = "conflicting_lock"
+)
+
+type PortFinding struct {
+	Kind        PortFindingKind `json:"kind"`
+	NodePort    int             `json:"node_port"`
+	StackID     string          `json:"stack_id,omitempty"`
+	ServiceName string          `json:"service_name,omitempty"`
+	Repaired    bool            `json:"repaired"`
+	Error       string          `json:"error,omitempty"`
+}
+
+type PortReconcileReport struct {
+	CheckedAt    time.Time     `json:"checked_at"`
+	DryRun       bool          `json:"dry_run"`
+	Locks        int           `json:"locks"`
+	ServicePorts int           `json:"service_ports"`
+	StackPorts   int           `json:"stack_ports"`
+	Findings     []PortFinding `json:"findings"`
+	Repaired     int           `json:"repaired"`
+	Failed       int           `json:"failed"`
 }
 
 type StackStatusSummary struct {
@@ -88,4 +266,5 @@ type StackStatusSummary struct {
 	TargetPorts  []PortSpec    `json:"-"`
 	Ports        []PortMapping `json:"ports"`
 	NodePublicIP *string       `json:"node_public_ip"`
+	Connection   []Connection  `json:"connection"`
 }
@@ -0,0 +1,251 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	managedByLabel     = "app.kubernetes.io/managed-by"
+	managedByValue     = "smctf"
+	namespaceModeLabel = "smctf.io/namespace-mode"
+	stackIDLabel       = "smctf.io/stack-id"
+
+	// stackLabelSelector matches the pods and Services of every stack, in any namespace.
+	stackLabelSelector = "app.kubernetes.io/name=smctf-stack"
+
+	// namespaceSweepGrace matches the orphan pod grace, so a namespace a Create has just
+	// made is not removed before its stack record is written.
+	namespaceSweepGrace = 2 * time.Minute
+)
+
+// stackNamespace returns the namespace a new stack goes into and, for namespaces the
+// server manages, the labels the namespace is created with.
+func (s *Service) stackNamespace(ownerID, stackID string) (string, map[string]string) {
+	switch s.cfg.NamespaceMode {
+	case config.NamespaceModeOwner:
+		hash := ownerLabelValue(ownerID)
+		return s.cfg.NamespacePrefix + "owner-" + hash, map[string]string{
+			managedByLabel:     managedByValue,
+			namespaceModeLabel: config.NamespaceModeOwner,
+			ownerHashLabel:     hash,
+		}
+	case config.NamespaceModeStack:
+		return s.cfg.NamespacePrefix + stackID, map[string]string{
+			managedByLabel:     managedByValue,
+			namespaceModeLabel: config.NamespaceModeStack,
+			stackIDLabel:       stackID,
+		}
+	default:
+		return s.cfg.Namespace, nil
+	}
+}
+
+func (s *Service) isolatedNamespaces() bool {
+	return s.cfg.NamespaceMode == config.NamespaceModeOwner || s.cfg.NamespaceMode == config.NamespaceModeStack
+}
+
+// scanNamespace is where cleanup and reconciliation look for stack resources: all
+// namespaces once stacks get their own.
+func (s *Service) scanNamespace() string {
+	if s.isolatedNamespaces() {
+		return metav1.NamespaceAll
+	}
+
+	return s.cfg.Namespace
+}
+
+// sweepNamespaces deletes managed namespaces without stack records and stack pods. It is
+// the only place namespaces are deleted: a create may be about to use a namespace whose
+// last stack was just deleted, so a namespace is only removed once it is older than
+// namespaceSweepGrace and holds no pod of an in-flight create.
+func (s *Service) sweepNamespaces(ctx context.Context, now time.Time) (int, int) {
+	if !s.isolatedNamespaces() {
+		return 0, 0
+	}
+
+	items, err := s.repo.ListAll(ctx)
+	if err != nil {
+		slog.Error("list stacks for namespace sweep failed", slog.Any("error", err))
+		return 0, 1
+	}
+
+	inUse := make(map[string]map[string]struct{}, len(s.clusters))
+	for _, st := range items {
+		if st.Status == StatusPreempted {
+			continue
+		}
+
+		clusterID := s.clusterID(st.ClusterID)
+		if inUse[clusterID] == nil {
+			inUse[clusterID] = make(map[string]struct{})
+		}
+		inUse[clusterID][st.Namespace] = struct{}{}
+	}
+
+	removed := 0
+	failures := 0
+	graceCutoff := now.Add(-namespaceSweepGrace)
+	for _, cluster := range s.clusters {
+		namespaces, err := cluster.Client.ListStackNamespaces(ctx)
+		if err != nil {
+			failures++
+			slog.Error("list stack namespaces failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			continue
+		}
+
+		pods, err := cluster.Client.ListPodsWithCreation(ctx, metav1.NamespaceAll)
+		if err != nil {
+			failures++
+			slog.Error("list stack pods for namespace sweep failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			continue
+		}
+
+		withPods := make(map[string]struct{}, len(pods))
+		for _, pod := range pods {
+			withPods[pod.Namespace] = struct{}{}
+		}
+
+		for name, createdAt := range namespaces {
+			if _, ok := inUse[cluster.ID][name]; ok {
+				continue
+			}
+
+			if _, ok := withPods[name]; ok {
+				continue
+			}
+
+			if !createdAt.IsZero() && createdAt.After(graceCutoff) {
+				continue
+			}
+
+			if err := cluster.Client.DeleteNamespace(ctx, name); err != nil {
+				failures++
+				slog.Error("delete empty stack namespace failed", slog.String("cluster_id", cluster.ID), slog.String("namespace", name), slog.Any("error", err))
+				continue
+			}
+
+			removed++
+		}
+	}
+
+	return removed, failures
+}
+
+// namespaceTemplates are the objects created in every namespace the server manages.
+type namespaceTemplates struct {
+	networkPolicy *networkingv1.NetworkPolicy
+	quota         *corev1.ResourceQuota
+	limitRange    *corev1.LimitRange
+}
+
+// loadNamespaceTemplates reads the STACK_NAMESPACE_*_TEMPLATE files. Without a network
+// policy template the built-in default-deny policy is used; quota and limit range are
+// only created when configured.
+func loadNamespaceTemplates(cfg config.StackConfig) (namespaceTemplates, error) {
+	out := namespaceTemplates{networkPolicy: defaultDenyNetworkPolicy(cfg)}
+
+	if cfg.NamespaceNetworkPolicyTemplate != "" {
+		var policy networkingv1.NetworkPolicy
+		if err := readNamespaceTemplate(cfg.NamespaceNetworkPolicyTemplate, "NetworkPolicy", &policy, &policy.TypeMeta); err != nil {
+			return namespaceTemplates{}, err
+		}
+		out.networkPolicy = &policy
+	}
+
+	if cfg.NamespaceQuotaTemplate != "" {
+		var quota corev1.ResourceQuota
+		if err := readNamespaceTemplate(cfg.NamespaceQuotaTemplate, "ResourceQuota", &quota, &quota.TypeMeta); err != nil {
+			return namespaceTemplates{}, err
+		}
+		out.quota = &quota
+	}
+
+	if cfg.NamespaceLimitRangeTemplate != "" {
+		var limitRange corev1.LimitRange
+		if err := readNamespaceTemplate(cfg.NamespaceLimitRangeTemplate, "LimitRange", &limitRange, &limitRange.TypeMeta); err != nil {
+			return namespaceTemplates{}, err
+		}
+		out.limitRange = &limitRange
+	}
+
+	return out, nil
+}
+
+func readNamespaceTemplate(path, kind string, into any, typeMeta *metav1.TypeMeta) error {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("read %s template: %w", kind, err)
+	}
+
+	if err := sigsyaml.UnmarshalStrict(raw, into); err != nil {
+		return fmt.Errorf("decode %s template %s: %w", kind, path, err)
+	}
+
+	if typeMeta.Kind != "" && typeMeta.Kind != kind {
+		return fmt.Errorf("%s template %s has kind %q", kind, path, typeMeta.Kind)
+	}
+
+	return nil
+}
+
+// defaultDenyNetworkPolicy only lets stack pods talk to pods in their own namespace and
+// to cluster DNS. Ingress from outside the cluster, which is how players reach NodePorts,
+// stays allowed; STACK_NAMESPACE_INGRESS_EXCEPT_CIDRS cuts the pod CIDRs out of it, so
+// pods of other namespaces are not let in by CNIs that match pod IPs against ipBlocks.
+func defaultDenyNetworkPolicy(cfg config.StackConfig) *networkingv1.NetworkPolicy {
+	dnsPort := intstr.FromInt(53)
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+
+	var exceptV4, exceptV6 []string
+	for _, cidr := range cfg.NamespaceIngressExceptCIDRs {
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() == nil {
+			exceptV6 = append(exceptV6, cidr)
+		} else {
+			exceptV4 = append(exceptV4, cidr)
+		}
+	}
+
+	from := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+		{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0", Except: exceptV4}},
+	}
+	if cfg.NamespaceIPv6 {
+		from = append(from, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "::/0", Except: exceptV6}})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "smctf-default-deny"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: from}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+				{
+					To: []networkingv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &udp, Port: &dnsPort},
+						{Protocol: &tcp, Port: &dnsPort},
+					},
+				},
+			},
+		},
+	}
+}
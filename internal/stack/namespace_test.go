@@ -0,0 +1,193 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func namespaceTestConfig(mode string) func(cfg *config.StackConfig) {
+	return func(cfg *config.StackConfig) {
+		cfg.StackTTL = time.Minute
+		cfg.NamespaceMode = mode
+		cfg.NamespacePrefix = "ctf-"
+	}
+}
+
+func TestNamespacePerStack(t *testing.T) {
+	svc, _, k8s := newTestService(namespaceTestConfig(config.NamespaceModeStack))
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if st.Namespace != "ctf-"+st.StackID {
+		t.Fatalf("expected per-stack namespace, got %q", st.Namespace)
+	}
+
+	ns, ok := k8s.namespaces[st.Namespace]
+	if !ok || ns.labels[managedByLabel] != managedByValue || ns.labels[stackIDLabel] != st.StackID {
+		t.Fatalf("expected managed namespace with labels, got %+v", k8s.namespaces)
+	}
+
+	if err := svc.Delete(context.Background(), st.StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if removed, _ := svc.sweepNamespaces(context.Background(), time.Now()); removed != 0 {
+		t.Fatalf("expected namespace to be kept within the sweep grace, removed %d", removed)
+	}
+
+	svc.sweepNamespaces(context.Background(), time.Now().Add(namespaceSweepGrace+time.Minute))
+	if _, ok := k8s.namespaces[st.Namespace]; ok {
+		t.Fatalf("expected namespace to be swept after its stack")
+	}
+}
+
+func TestNamespacePerOwnerIsSharedUntilLastStack(t *testing.T) {
+	svc, _, k8s := newTestService(namespaceTestConfig(config.NamespaceModeOwner))
+
+	if _, err := createTestStack(svc, CreateInput{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected owner_id to be required, got %v", err)
+	}
+
+	first, err := createTestStack(svc, CreateInput{OwnerID: "team-1"})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	second, err := createTestStack(svc, CreateInput{OwnerID: "team-1"})
+	if err != nil {
+		t.Fatalf("second create: %v", err)
+	}
+
+	if first.Namespace != second.Namespace || !strings.HasPrefix(first.Namespace, "ctf-owner-") {
+		t.Fatalf("expected one owner namespace, got %q and %q", first.Namespace, second.Namespace)
+	}
+
+	afterGrace := time.Now().Add(namespaceSweepGrace + time.Minute)
+	if err := svc.Delete(context.Background(), first.StackID); err != nil {
+		t.Fatalf("delete first: %v", err)
+	}
+
+	svc.sweepNamespaces(context.Background(), afterGrace)
+	if _, ok := k8s.namespaces[first.Namespace]; !ok {
+		t.Fatalf("expected namespace to be kept while the owner has a stack")
+	}
+
+	if err := svc.Delete(context.Background(), second.StackID); err != nil {
+		t.Fatalf("delete second: %v", err)
+	}
+
+	svc.sweepNamespaces(context.Background(), afterGrace)
+	if _, ok := k8s.namespaces[first.Namespace]; ok {
+		t.Fatalf("expected namespace to be swept after the owner's last stack")
+	}
+}
+
+func TestNamespaceSweepKeepsNamespacesWithPods(t *testing.T) {
+	svc, repo, k8s := newTestService(namespaceTestConfig(config.NamespaceModeStack))
+	ctx := context.Background()
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// A create that has made its pod but not yet saved its record.
+	if _, _, err := repo.Delete(ctx, st.StackID); err != nil {
+		t.Fatalf("delete record: %v", err)
+	}
+
+	svc.sweepNamespaces(ctx, time.Now().Add(namespaceSweepGrace+time.Minute))
+	if _, ok := k8s.namespaces[st.Namespace]; !ok {
+		t.Fatalf("expected namespace holding a stack pod to be kept")
+	}
+}
+
+func TestCleanupSweepsNamespacesAndOrphansAcrossNamespaces(t *testing.T) {
+	svc, repo, k8s := newTestService(namespaceTestConfig(config.NamespaceModeStack))
+	ctx := context.Background()
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	k8s.mu.Lock()
+	k8s.pods["orphan"] = podState{namespace: "ctf-stack-gone", podID: "orphan", nodeID: "worker-a", status: StatusRunning, createdAt: time.Now().Add(-time.Hour)}
+	k8s.mu.Unlock()
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(3 * time.Minute) }
+	svc.CleanupExpiredAndOrphaned(ctx)
+
+	if _, ok, _ := repo.Get(ctx, st.StackID); ok {
+		t.Fatalf("expected expired stack to be removed")
+	}
+
+	if _, ok := k8s.namespaces[st.Namespace]; ok {
+		t.Fatalf("expected namespace of the expired stack to be swept")
+	}
+
+	if _, ok := k8s.pods["orphan"]; ok {
+		t.Fatalf("expected orphan pod in another namespace to be removed")
+	}
+}
+
+func TestLoadNamespaceTemplates(t *testing.T) {
+	dir := t.TempDir()
+	quotaPath := filepath.Join(dir, "quota.yaml")
+	quota := "apiVersion: v1\nkind: ResourceQuota\nspec:\n  hard:\n    requests.cpu: \"2\"\n"
+	if err := os.WriteFile(quotaPath, []byte(quota), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	templates, err := loadNamespaceTemplates(config.StackConfig{NamespaceQuotaTemplate: quotaPath})
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+
+	if templates.networkPolicy == nil || templates.networkPolicy.Name != "smctf-default-deny" {
+		t.Fatalf("expected built-in default-deny policy, got %+v", templates.networkPolicy)
+	}
+
+	if q := templates.quota.Spec.Hard["requests.cpu"]; q.MilliValue() != 2000 || templates.limitRange != nil {
+		t.Fatalf("unexpected templates: %+v", templates)
+	}
+
+	if _, err := loadNamespaceTemplates(config.StackConfig{NamespaceLimitRangeTemplate: quotaPath}); err == nil {
+		t.Fatalf("expected template of the wrong kind to be rejected")
+	}
+}
+
+func TestDefaultDenyNetworkPolicyIngressExcept(t *testing.T) {
+	policy := defaultDenyNetworkPolicy(config.StackConfig{
+		NamespaceIngressExceptCIDRs: []string{"10.244.0.0/16", "fd00:10:244::/56"},
+		NamespaceIPv6:               true,
+	})
+
+	from := policy.Spec.Ingress[0].From
+	if len(from) != 3 {
+		t.Fatalf("expected pod selector and two ip blocks, got %+v", from)
+	}
+
+	v4, v6 := from[1].IPBlock, from[2].IPBlock
+	if v4.CIDR != "0.0.0.0/0" || len(v4.Except) != 1 || v4.Except[0] != "10.244.0.0/16" {
+		t.Fatalf("unexpected IPv4 block: %+v", v4)
+	}
+
+	if v6.CIDR != "::/0" || len(v6.Except) != 1 || v6.Except[0] != "fd00:10:244::/56" {
+		t.Fatalf("unexpected IPv6 block: %+v", v6)
+	}
+
+	if from := defaultDenyNetworkPolicy(config.StackConfig{}).Spec.Ingress[0].From; len(from) != 2 || from[1].IPBlock.Except != nil {
+		t.Fatalf("expected a single IPv4 block without excepts, got %+v", from)
+	}
+}
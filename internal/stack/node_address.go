@@ -0,0 +1,142 @@
+package stack
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type NodeAddressPolicy struct {
+	Types       []corev1.NodeAddressType
+	Family      string
+	OverrideKey string
+}
+
+func newNodeAddressPolicy(types []string, family, overrideKey string) NodeAddressPolicy {
+	out := NodeAddressPolicy{
+		Types:       make([]corev1.NodeAddressType, 0, len(types)),
+		Family:      strings.ToLower(strings.TrimSpace(family)),
+		OverrideKey: strings.TrimSpace(overrideKey),
+	}
+
+	for _, t := range types {
+		out.Types = append(out.Types, corev1.NodeAddressType(t))
+	}
+
+	if len(out.Types) == 0 {
+		out.Types = []corev1.NodeAddressType{corev1.NodeExternalIP}
+	}
+
+	return out
+}
+
+// Resolve returns the address clients should use to reach NodePorts on the node.
+// An override label/annotation wins, then the configured address types are tried
+// in order, preferring the configured IP family within each type.
+func (p NodeAddressPolicy) Resolve(node *corev1.Node) *string {
+	if node == nil {
+		return nil
+	}
+
+	if p.OverrideKey != "" {
+		if v := strings.TrimSpace(node.Labels[p.OverrideKey]); v != "" {
+			return &v
+		}
+
+		if v := strings.TrimSpace(node.Annotations[p.OverrideKey]); v != "" {
+			return &v
+		}
+	}
+
+	for _, addrType := range p.Types {
+		var fallback string
+		for _, addr := range node.Status.Addresses {
+			if addr.Type != addrType || addr.Address == "" {
+				continue
+			}
+
+			if p.matchesFamily(addr.Address) {
+				out := addr.Address
+				return &out
+			}
+
+			if fallback == "" {
+				fallback = addr.Address
+			}
+		}
+
+		if fallback != "" {
+			return &fallback
+		}
+	}
+
+	return nil
+}
+
+func (p NodeAddressPolicy) matchesFamily(address string) bool {
+	switch p.Family {
+	case "ipv4":
+		ip := net.ParseIP(address)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(address)
+		return ip != nil && ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+type nodeAddressCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]nodeAddressEntry
+}
+
+type nodeAddressEntry struct {
+	address   *string
+	expiresAt time.Time
+}
+
+func newNodeAddressCache(ttl time.Duration) *nodeAddressCache {
+	return &nodeAddressCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]nodeAddressEntry),
+	}
+}
+
+func (c *nodeAddressCache) get(nodeID string) (*string, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[nodeID]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, nodeID)
+		return nil, false
+	}
+
+	return entry.address, true
+}
+
+func (c *nodeAddressCache) set(nodeID string, address *string) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[nodeID] = nodeAddressEntry{address: address, expiresAt: c.now().Add(c.ttl)}
+}
@@ -0,0 +1,110 @@
+package stack
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(labels, annotations map[string]string, addrs ...corev1.NodeAddress) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: labels, Annotations: annotations},
+		Status:     corev1.NodeStatus{Addresses: addrs},
+	}
+}
+
+func TestNodeAddressPolicyDefaultsToExternalIP(t *testing.T) {
+	p := newNodeAddressPolicy(nil, "any", "")
+	node := testNode(nil, nil,
+		corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.5"},
+		corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.5"},
+	)
+
+	got := p.Resolve(node)
+	if got == nil || *got != "203.0.113.5" {
+		t.Fatalf("expected external ip, got %v", got)
+	}
+
+	if got := p.Resolve(testNode(nil, nil, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.5"})); got != nil {
+		t.Fatalf("expected nil without external ip, got %q", *got)
+	}
+}
+
+func TestNodeAddressPolicyFallsBackThroughTypes(t *testing.T) {
+	p := newNodeAddressPolicy([]string{"ExternalIP", "InternalIP"}, "any", "")
+	node := testNode(nil, nil, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.5"})
+
+	got := p.Resolve(node)
+	if got == nil || *got != "10.0.0.5" {
+		t.Fatalf("expected internal ip fallback, got %v", got)
+	}
+}
+
+func TestNodeAddressPolicyPrefersFamilyWithinType(t *testing.T) {
+	node := testNode(nil, nil,
+		corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.5"},
+		corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "2001:db8::5"},
+		corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "fd00::5"},
+	)
+
+	got := newNodeAddressPolicy([]string{"ExternalIP", "InternalIP"}, "ipv6", "").Resolve(node)
+	if got == nil || *got != "2001:db8::5" {
+		t.Fatalf("expected external ipv6, got %v", got)
+	}
+
+	got = newNodeAddressPolicy([]string{"ExternalIP", "InternalIP"}, "ipv4", "").Resolve(node)
+	if got == nil || *got != "203.0.113.5" {
+		t.Fatalf("expected external ipv4, got %v", got)
+	}
+
+	v4Only := testNode(nil, nil, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.5"})
+	got = newNodeAddressPolicy([]string{"ExternalIP"}, "ipv6", "").Resolve(v4Only)
+	if got == nil || *got != "203.0.113.5" {
+		t.Fatalf("expected ipv4 fallback when ipv6 is missing, got %v", got)
+	}
+}
+
+func TestNodeAddressPolicyOverride(t *testing.T) {
+	p := newNodeAddressPolicy([]string{"ExternalIP"}, "any", "smctf.io/public-ip")
+
+	fromLabel := testNode(map[string]string{"smctf.io/public-ip": "198.51.100.7"}, nil,
+		corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.5"},
+	)
+	if got := p.Resolve(fromLabel); got == nil || *got != "198.51.100.7" {
+		t.Fatalf("expected label override, got %v", got)
+	}
+
+	fromAnnotation := testNode(nil, map[string]string{"smctf.io/public-ip": "2001:db8::7"})
+	if got := p.Resolve(fromAnnotation); got == nil || *got != "2001:db8::7" {
+		t.Fatalf("expected annotation override, got %v", got)
+	}
+}
+
+func TestNodeAddressCacheExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newNodeAddressCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.set("node-a", strPtr("203.0.113.5"))
+	if got, ok := cache.get("node-a"); !ok || got == nil || *got != "203.0.113.5" {
+		t.Fatalf("expected cached address, got %v ok=%v", got, ok)
+	}
+
+	cache.set("node-b", nil)
+	if got, ok := cache.get("node-b"); !ok || got != nil {
+		t.Fatalf("expected cached nil address, got %v ok=%v", got, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("node-a"); ok {
+		t.Fatalf("expected cache entry to expire")
+	}
+
+	disabled := newNodeAddressCache(0)
+	disabled.set("node-a", strPtr("203.0.113.5"))
+	if _, ok := disabled.get("node-a"); ok {
+		t.Fatalf("expected disabled cache to miss")
+	}
+}
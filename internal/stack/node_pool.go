@@ -0,0 +1,182 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// nodePoolAnnotation lets a challenge's pod spec require a node pool, e.g. arm64 for
+// ARM-only binaries.
+const nodePoolAnnotation = "smctf.io/node-pool"
+
+// resolveNodePool picks the node pool of a create. A pool required by the pod spec wins
+// and a request may only repeat it; otherwise the requested pool or STACK_NODE_POOL_DEFAULT
+// is used. Without STACK_NODE_POOLS the result is "", the STACK_NODE_ROLE nodes.
+func (s *Service) resolveNodePool(fromSpec, requested string) (string, *FieldError) {
+	annotationField := "metadata.annotations[" + nodePoolAnnotation + "]"
+	if len(s.cfg.NodePools) == 0 {
+		if fromSpec != "" {
+			return "", &FieldError{Field: annotationField, Code: CodeNotAllowed, Reason: fmt.Sprintf("%s annotation requires STACK_NODE_POOLS", nodePoolAnnotation)}
+		}
+
+		if requested != "" {
+			return "", &FieldError{Field: "node_pool", Code: CodeNotAllowed, Reason: "node pools are not configured", Err: ErrInvalidInput}
+		}
+
+		return "", nil
+	}
+
+	if fromSpec != "" {
+		if !s.hasNodePool(fromSpec) {
+			return "", &FieldError{Field: annotationField, Code: CodeUnknown, Reason: fmt.Sprintf("unknown node pool %q in %s annotation", fromSpec, nodePoolAnnotation)}
+		}
+
+		if requested != "" && requested != fromSpec {
+			return "", &FieldError{Field: "node_pool", Code: CodeInvalid, Reason: fmt.Sprintf("node_pool %q conflicts with %q required by %s", requested, fromSpec, nodePoolAnnotation), Err: ErrInvalidInput}
+		}
+
+		return fromSpec, nil
+	}
+
+	if requested != "" {
+		if !s.hasNodePool(requested) {
+			return "", &FieldError{Field: "node_pool", Code: CodeUnknown, Reason: fmt.Sprintf("unknown node_pool %q", requested), Err: ErrInvalidInput}
+		}
+
+		return requested, nil
+	}
+
+	return s.cfg.DefaultNodePoolName(), nil
+}
+
+func (s *Service) hasNodePool(name string) bool {
+	for _, pool := range s.cfg.NodePools {
+		if pool.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nodePoolStats reports schedulable nodes, allocatable resources and stack usage per node
+// pool, summed over the clusters. Clusters whose nodes cannot be read are skipped.
+func (s *Service) nodePoolStats(ctx context.Context, items []Stack) map[string]NodePoolUsage {
+	out := make(map[string]NodePoolUsage, len(s.cfg.NodePools))
+	if len(s.cfg.NodePools) == 0 {
+		return out
+	}
+
+	for _, pool := range s.cfg.NodePools {
+		out[pool.Name] = NodePoolUsage{}
+	}
+
+	for _, cluster := range s.clusters {
+		counts, err := cluster.Client.CountSchedulableNodes(ctx)
+		if err != nil {
+			slog.Warn("count schedulable nodes for stats failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			continue
+		}
+
+		_, allocatable, err := cluster.Client.AllocatableCapacity(ctx)
+		if err != nil {
+			slog.Warn("read allocatable capacity for stats failed", slog.String("cluster_id", cluster.ID), slog.Any("error", err))
+			continue
+		}
+
+		for name, usage := range out {
+			usage.Nodes += counts[name]
+			usage.AllocatableCPUMilli += allocatable[name].CPUMilli
+			usage.AllocatableMemoryBytes += allocatable[name].MemoryBytes
+			out[name] = usage
+		}
+	}
+
+	for _, st := range items {
+		usage, ok := out[st.NodePool]
+		if !ok {
+			continue
+		}
+
+		usage.TotalStacks++
+		if st.Status == StatusRunning || st.Status == StatusCreating {
+			usage.ActiveStacks++
+		}
+		usage.ReservedCPUMilli += st.RequestedMilli
+		usage.ReservedMemoryBytes += st.RequestedBytes
+		out[st.NodePool] = usage
+	}
+
+	return out
+}
+
+// nodePoolsFor returns the configured node pools, or a single unnamed pool of the
+// STACK_NODE_ROLE nodes. Pools without a RuntimeClass get STACK_RUNTIME_CLASS.
+func nodePoolsFor(cfg config.StackConfig) []config.NodePool {
+	if len(cfg.NodePools) == 0 {
+		return []config.NodePool{{Labels: map[string]string{"role": cfg.StackNodeRole}, RuntimeClass: cfg.RuntimeClass}}
+	}
+
+	pools := slices.Clone(cfg.NodePools)
+	for i := range pools {
+		if pools[i].RuntimeClass == "" {
+			pools[i].RuntimeClass = cfg.RuntimeClass
+		}
+	}
+
+	return pools
+}
+
+// RuntimeClassNames returns the RuntimeClasses stack pods may run with, so startup can
+// check that they exist.
+func RuntimeClassNames(cfg config.StackConfig) []string {
+	var out []string
+	for _, pool := range nodePoolsFor(cfg) {
+		if pool.RuntimeClass != "" && !slices.Contains(out, pool.RuntimeClass) {
+			out = append(out, pool.RuntimeClass)
+		}
+	}
+
+	return out
+}
+
+// applyNodePool makes the pod select the pool's node labels, overriding pod spec entries
+// with the same key, tolerate the pool's taints and run with the pool's RuntimeClass.
+func applyNodePool(pod *corev1.Pod, pool config.NodePool) {
+	if pool.RuntimeClass != "" {
+		pod.Spec.RuntimeClassName = &pool.RuntimeClass
+	}
+
+	if pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+
+	for k, v := range pool.Labels {
+		pod.Spec.NodeSelector[k] = v
+	}
+
+	for _, t := range pool.Tolerations {
+		toleration := corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOpEqual,
+			Value:    t.Value,
+			Effect:   corev1.TaintEffect(t.Effect),
+		}
+		if t.Value == "" {
+			toleration.Operator = corev1.TolerationOpExists
+		}
+
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+	}
+}
+
+func nodeInPool(node corev1.Node, pool config.NodePool) bool {
+	return labels.SelectorFromSet(pool.Labels).Matches(labels.Set(node.Labels))
+}
@@ -0,0 +1,166 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newNodePoolTestService() (*Service, *MockKubernetesClient) {
+	svc, _, k8s := newTestService(func(cfg *config.StackConfig) {
+		cfg.NodePortMax = 30020
+		cfg.NodePools = []config.NodePool{
+			{Name: "amd64", Labels: map[string]string{"kubernetes.io/arch": "amd64"}},
+			{Name: "arm64", Labels: map[string]string{"kubernetes.io/arch": "arm64"}},
+		}
+	})
+	k8s.nodePools = map[string]string{"worker-a": "arm64", "worker-b": "amd64", "worker-c": "amd64"}
+
+	return svc, k8s
+}
+
+func TestNodePoolSelection(t *testing.T) {
+	svc, _ := newNodePoolTestService()
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create default pool: %v", err)
+	}
+
+	if st.NodePool != "amd64" || st.NodeID == "worker-a" {
+		t.Fatalf("expected default pool amd64, got %q on %s", st.NodePool, st.NodeID)
+	}
+
+	armOnly := strings.Replace(testPodSpec, "  name: p\n", "  name: p\n  annotations:\n    smctf.io/node-pool: arm64\n", 1)
+	st, err = createTestStack(svc, CreateInput{PodSpecYML: armOnly})
+	if err != nil {
+		t.Fatalf("create arm64 pool: %v", err)
+	}
+
+	if st.NodePool != "arm64" || st.NodeID != "worker-a" {
+		t.Fatalf("expected arm64 stack on worker-a, got %q on %s", st.NodePool, st.NodeID)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{PodSpecYML: armOnly, NodePool: "amd64"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected request pool conflicting with the pod spec to be rejected, got %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{NodePool: "gvisor"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected unknown node pool to be rejected, got %v", err)
+	}
+
+	stats, err := svc.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	arm := stats.NodePools["arm64"]
+	if arm.Nodes != 1 || arm.AllocatableCPUMilli != 4000 || arm.ActiveStacks != 1 || stats.NodePools["amd64"].Nodes != 2 {
+		t.Fatalf("unexpected node pool stats: %+v", stats.NodePools)
+	}
+}
+
+func TestNodePoolRequiresConfiguredPools(t *testing.T) {
+	svc, _, _ := newTestService(placementTestConfig(config.PlacementNone))
+
+	if _, err := createTestStack(svc, CreateInput{NodePool: "arm64"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected node_pool without STACK_NODE_POOLS to be rejected, got %v", err)
+	}
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if st.NodePool != "" {
+		t.Fatalf("expected no node pool, got %q", st.NodePool)
+	}
+}
+
+func TestApplyNodePool(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Spec.NodeSelector = map[string]string{"kubernetes.io/arch": "amd64", "disk": "ssd"}
+
+	applyNodePool(&pod, config.NodePool{
+		Name:   "arm64",
+		Labels: map[string]string{"kubernetes.io/arch": "arm64"},
+		Tolerations: []config.NodePoolToleration{
+			{Key: "arch", Value: "arm64", Effect: "NoSchedule"},
+			{Key: "dedicated"},
+		},
+	})
+
+	if pod.Spec.NodeSelector["kubernetes.io/arch"] != "arm64" || pod.Spec.NodeSelector["disk"] != "ssd" {
+		t.Fatalf("unexpected node selector: %v", pod.Spec.NodeSelector)
+	}
+
+	tolerations := pod.Spec.Tolerations
+	if len(tolerations) != 2 || tolerations[0].Operator != corev1.TolerationOpEqual || tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+		t.Fatalf("unexpected tolerations: %+v", tolerations)
+	}
+
+	if tolerations[1].Operator != corev1.TolerationOpExists || tolerations[1].Effect != "" {
+		t.Fatalf("expected key-only toleration to use Exists, got %+v", tolerations[1])
+	}
+
+	if pod.Spec.RuntimeClassName != nil {
+		t.Fatalf("expected no runtime class, got %q", *pod.Spec.RuntimeClassName)
+	}
+}
+
+func TestNodePoolRuntimeClass(t *testing.T) {
+	cfg := config.StackConfig{
+		RuntimeClass: "gvisor",
+		NodePools: []config.NodePool{
+			{Name: "web", Labels: map[string]string{"role": "web"}},
+			{Name: "pwn", Labels: map[string]string{"role": "pwn"}, RuntimeClass: "kata"},
+			{Name: "pwn-arm", Labels: map[string]string{"role": "pwn-arm"}, RuntimeClass: "kata"},
+		},
+	}
+
+	pools := nodePoolsFor(cfg)
+	if pools[0].RuntimeClass != "gvisor" || pools[1].RuntimeClass != "kata" || cfg.NodePools[0].RuntimeClass != "" {
+		t.Fatalf("expected STACK_RUNTIME_CLASS to fill in pools without one, got %+v", pools)
+	}
+
+	if got := RuntimeClassNames(cfg); !slices.Equal(got, []string{"gvisor", "kata"}) {
+		t.Fatalf("unexpected runtime classes: %v", got)
+	}
+
+	pod := corev1.Pod{}
+	applyNodePool(&pod, pools[1])
+	if pod.Spec.RuntimeClassName == nil || *pod.Spec.RuntimeClassName != "kata" {
+		t.Fatalf("expected kata runtime class, got %v", pod.Spec.RuntimeClassName)
+	}
+
+	if got := RuntimeClassNames(config.StackConfig{StackNodeRole: "stack"}); len(got) != 0 {
+		t.Fatalf("expected no runtime classes by default, got %v", got)
+	}
+}
+
+func TestAdmissionIsPerNodePool(t *testing.T) {
+	svc, k8s := newNodePoolTestService()
+	svc.cfg.CapacitySource = config.CapacitySourceNodes
+	k8s.perNode = CapacityBudget{CPUMilli: 150, MemoryBytes: 1 << 30}
+
+	if _, err := createTestStack(svc, CreateInput{NodePool: "arm64"}); err != nil {
+		t.Fatalf("first arm64 create: %v", err)
+	}
+
+	// The amd64 nodes have room, but none of it is arm64 capacity.
+	if _, err := createTestStack(svc, CreateInput{NodePool: "arm64"}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected the arm64 pool to be saturated, got %v", err)
+	}
+
+	for i := range 2 {
+		if _, err := createTestStack(svc, CreateInput{NodePool: "amd64"}); err != nil {
+			t.Fatalf("amd64 create %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// placementAnnotation lets a challenge's pod spec pick its own placement strategy.
+	placementAnnotation = "smctf.io/placement"
+	ownerHashLabel      = "smctf.io/owner-hash"
+	hostnameTopologyKey = "kubernetes.io/hostname"
+)
+
+// placementStrategy resolves the strategy of a create: the pod spec annotation wins over
+// STACK_PLACEMENT_STRATEGY.
+func (s *Service) placementStrategy(fromSpec string) string {
+	if fromSpec != "" {
+		return fromSpec
+	}
+
+	if s.cfg.PlacementStrategy != "" {
+		return s.cfg.PlacementStrategy
+	}
+
+	return config.PlacementNone
+}
+
+// applyPlacement replaces the pod's inter-pod affinity and topology spread constraints
+// with the ones of the given strategy. Node affinity is left to the pod spec. Affinity
+// terms match stacks in every namespace. Topology spread only counts pods in the pod's
+// own namespace, so with isolated namespaces spread is a preferred anti-affinity instead.
+func applyPlacement(pod *corev1.Pod, strategy, ownerID string, isolated bool) {
+	if ownerID != "" {
+		pod.Labels[ownerHashLabel] = ownerLabelValue(ownerID)
+	}
+
+	if strategy == config.PlacementOwnerAntiAffinity && ownerID == "" {
+		strategy = config.PlacementSpread
+	}
+
+	switch strategy {
+	case config.PlacementSpread, config.PlacementBinpack, config.PlacementOwnerAntiAffinity:
+	default:
+		return
+	}
+
+	pod.Spec.TopologySpreadConstraints = nil
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	pod.Spec.Affinity.PodAffinity = nil
+	pod.Spec.Affinity.PodAntiAffinity = nil
+
+	stacks := &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "smctf-stack"}}
+	allNamespaces := &metav1.LabelSelector{}
+
+	switch {
+	case strategy == config.PlacementSpread && isolated:
+		pod.Spec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector:     stacks,
+					NamespaceSelector: allNamespaces,
+					TopologyKey:       hostnameTopologyKey,
+				},
+			}},
+		}
+	case strategy == config.PlacementSpread:
+		pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       hostnameTopologyKey,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     stacks,
+		}}
+	case strategy == config.PlacementBinpack:
+		pod.Spec.Affinity.PodAffinity = &corev1.PodAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector:     stacks,
+					NamespaceSelector: allNamespaces,
+					TopologyKey:       hostnameTopologyKey,
+				},
+			}},
+		}
+	case strategy == config.PlacementOwnerAntiAffinity:
+		pod.Spec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{ownerHashLabel: ownerLabelValue(ownerID)}},
+				NamespaceSelector: allNamespaces,
+				TopologyKey:       hostnameTopologyKey,
+			}},
+		}
+	}
+
+	if pod.Spec.Affinity.NodeAffinity == nil && pod.Spec.Affinity.PodAffinity == nil && pod.Spec.Affinity.PodAntiAffinity == nil {
+		pod.Spec.Affinity = nil
+	}
+}
+
+// ownerLabelValue hashes an owner id into a valid label value.
+func ownerLabelValue(ownerID string) string {
+	sum := sha256.Sum256([]byte(ownerID))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,183 @@
+package stack
+
+import (
+	"context"
+	"testing"
+
+	"smctf/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func placementTestConfig(strategy string) func(cfg *config.StackConfig) {
+	return func(cfg *config.StackConfig) {
+		cfg.NodePortMax = 30020
+		cfg.PlacementStrategy = strategy
+	}
+}
+
+func TestApplyPlacementSpread(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Labels = map[string]string{}
+	pod.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+
+	applyPlacement(&pod, config.PlacementSpread, "", false)
+
+	if len(pod.Spec.TopologySpreadConstraints) != 1 || pod.Spec.TopologySpreadConstraints[0].TopologyKey != hostnameTopologyKey {
+		t.Fatalf("expected hostname spread constraint, got %+v", pod.Spec.TopologySpreadConstraints)
+	}
+
+	if pod.Spec.Affinity != nil {
+		t.Fatalf("expected user pod affinity to be replaced, got %+v", pod.Spec.Affinity)
+	}
+}
+
+func TestApplyPlacementSpreadIsolatedNamespaces(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Labels = map[string]string{}
+
+	applyPlacement(&pod, config.PlacementSpread, "", true)
+
+	if len(pod.Spec.TopologySpreadConstraints) != 0 || pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		t.Fatalf("expected spread as pod anti-affinity, got %+v", pod.Spec)
+	}
+
+	terms := pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(terms) != 1 || terms[0].PodAffinityTerm.NamespaceSelector == nil || terms[0].PodAffinityTerm.TopologyKey != hostnameTopologyKey {
+		t.Fatalf("expected preferred hostname anti-affinity across namespaces, got %+v", terms)
+	}
+}
+
+func TestApplyPlacementOwnerAntiAffinity(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Labels = map[string]string{}
+	pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+
+	applyPlacement(&pod, config.PlacementOwnerAntiAffinity, "team/42", false)
+
+	hash := pod.Labels[ownerHashLabel]
+	if hash == "" || hash != ownerLabelValue("team/42") {
+		t.Fatalf("expected owner hash label, got %v", pod.Labels)
+	}
+
+	terms := pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(terms) != 1 || terms[0].LabelSelector.MatchLabels[ownerHashLabel] != hash || terms[0].NamespaceSelector == nil {
+		t.Fatalf("expected required anti-affinity on owner hash across namespaces, got %+v", terms)
+	}
+
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		t.Fatalf("expected node affinity to be kept")
+	}
+}
+
+func TestApplyPlacementNoneLeavesPodAlone(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Labels = map[string]string{}
+	pod.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+
+	applyPlacement(&pod, config.PlacementNone, "", false)
+
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAffinity == nil || len(pod.Spec.TopologySpreadConstraints) != 0 {
+		t.Fatalf("expected pod to be left unchanged, got %+v", pod.Spec)
+	}
+}
+
+func TestPlacementSpreadDistribution(t *testing.T) {
+	svc, _, _ := newTestService(placementTestConfig(config.PlacementSpread))
+	for i := 0; i < 6; i++ {
+		if _, err := createTestStack(svc, CreateInput{}); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	stats, err := svc.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	if stats.PlacementStrategy != config.PlacementSpread {
+		t.Fatalf("expected spread strategy, got %q", stats.PlacementStrategy)
+	}
+
+	spread := stats.PlacementDistribution[config.PlacementSpread]
+	if len(spread) != 3 {
+		t.Fatalf("expected stacks on all three nodes, got %v", spread)
+	}
+
+	for node, count := range spread {
+		if count != 2 {
+			t.Fatalf("expected two stacks on %s, got %v", node, spread)
+		}
+	}
+}
+
+func TestPlacementBinpackDistribution(t *testing.T) {
+	svc, _, _ := newTestService(placementTestConfig(config.PlacementBinpack))
+	for i := 0; i < 4; i++ {
+		if _, err := createTestStack(svc, CreateInput{}); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	stats, err := svc.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	if binpack := stats.PlacementDistribution[config.PlacementBinpack]; len(binpack) != 1 {
+		t.Fatalf("expected stacks on one node, got %v", binpack)
+	}
+}
+
+func TestPlacementOwnerAntiAffinity(t *testing.T) {
+	svc, _, _ := newTestService(placementTestConfig(config.PlacementOwnerAntiAffinity))
+	nodes := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		st, err := createTestStack(svc, CreateInput{OwnerID: "team-a"})
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+
+		if nodes[st.NodeID] {
+			t.Fatalf("owner already has a stack on %s", st.NodeID)
+		}
+		nodes[st.NodeID] = true
+
+		if st.Placement != config.PlacementOwnerAntiAffinity {
+			t.Fatalf("expected placement to be recorded, got %q", st.Placement)
+		}
+	}
+
+	if _, err := createTestStack(svc, CreateInput{OwnerID: "team-a"}); err == nil {
+		t.Fatalf("expected a fourth stack of the owner to be unschedulable")
+	}
+
+	if _, err := createTestStack(svc, CreateInput{OwnerID: "team-b"}); err != nil {
+		t.Fatalf("expected another owner to be schedulable: %v", err)
+	}
+}
+
+func TestPlacementSpreadAcrossStackNamespaces(t *testing.T) {
+	svc, _, _ := newTestService(func(cfg *config.StackConfig) {
+		placementTestConfig(config.PlacementSpread)(cfg)
+		namespaceTestConfig(config.NamespaceModeStack)(cfg)
+	})
+
+	first, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	second, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("second create: %v", err)
+	}
+
+	if first.Namespace == second.Namespace {
+		t.Fatalf("expected a namespace per stack, got %q", first.Namespace)
+	}
+
+	if first.NodeID == second.NodeID {
+		t.Fatalf("expected stacks in separate namespaces to spread, both on %s", first.NodeID)
+	}
+}
@@ -0,0 +1,135 @@
+package stack
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+const (
+	maxNodePort = 65535
+
+	// portBitmapShardSize is the number of ports tracked by a single bitmap shard item.
+	portBitmapShardSize = 1024
+
+	// maxPortsPerReservation keeps a reservation (one lock per port plus one update per
+	// touched shard) within the 100 item limit of a DynamoDB transaction.
+	maxPortsPerReservation = 48
+)
+
+// portBitmap tracks used ports by absolute port number.
+type portBitmap [(maxNodePort + 64) / 64]uint64
+
+func (b *portBitmap) has(port int) bool {
+	if port < 0 || port > maxNodePort {
+		return false
+	}
+
+	return b[port/64]&(1<<(uint(port)%64)) != 0
+}
+
+func (b *portBitmap) set(port int) {
+	if port < 0 || port > maxNodePort {
+		return
+	}
+
+	b[port/64] |= 1 << (uint(port) % 64)
+}
+
+func (b *portBitmap) clear(port int) {
+	if port < 0 || port > maxNodePort {
+		return
+	}
+
+	b[port/64] &^= 1 << (uint(port) % 64)
+}
+
+func (b *portBitmap) count(min, max int) int {
+	total := 0
+	for port := min; port <= max; {
+		if port%64 == 0 && port+63 <= max {
+			total += bits.OnesCount64(b[port/64])
+			port += 64
+			continue
+		}
+
+		if b.has(port) {
+			total++
+		}
+		port++
+	}
+
+	return total
+}
+
+// pickFreePorts chooses one free port per entry of want. Non-zero entries are used when
+// they are in range and free (or listed in reusable); every other entry gets the next free
+// port scanning from min+start. It reports false when the range cannot satisfy the request.
+func pickFreePorts(used *portBitmap, min, max, start int, want []int, reusable map[int]struct{}) ([]int, bool) {
+	total := max - min + 1
+	if total <= 0 || len(want) > total {
+		return nil, false
+	}
+
+	out := make([]int, len(want))
+	chosen := make(map[int]struct{}, len(want))
+	for i, port := range want {
+		if port < min || port > max {
+			continue
+		}
+
+		if _, ok := reusable[port]; used.has(port) && !ok {
+			continue
+		}
+
+		if _, dup := chosen[port]; dup {
+			continue
+		}
+
+		out[i] = port
+		chosen[port] = struct{}{}
+	}
+
+	offset := 0
+	for i := range out {
+		if out[i] != 0 {
+			continue
+		}
+
+		for offset < total {
+			port := min + (start+offset)%total
+			offset++
+
+			if used.has(port) {
+				continue
+			}
+
+			if _, dup := chosen[port]; dup {
+				continue
+			}
+
+			out[i] = port
+			chosen[port] = struct{}{}
+			break
+		}
+
+		if out[i] == 0 {
+			return nil, false
+		}
+	}
+
+	return out, true
+}
+
+func validatePortReservation(min, max int, want []int) error {
+	if min < 1 || max > maxNodePort || min > max {
+		return ErrNoAvailableNodePort
+	}
+
+	if len(want) > maxPortsPerReservation {
+		return fmt.Errorf("%w: too many ports in one reservation (max %d)", ErrInvalidInput, maxPortsPerReservation)
+	}
+
+	return nil
+}
+
+func portShard(port int) int { return port / portBitmapShardSize }
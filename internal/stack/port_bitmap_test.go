@@ -0,0 +1,309 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPickFreePortsPrefersRequested(t *testing.T) {
+	var used portBitmap
+	used.set(30001)
+
+	ports, ok := pickFreePorts(&used, 30000, 30009, 0, []int{30005, 30001, 0}, nil)
+	if !ok {
+		t.Fatalf("expected ports to be picked")
+	}
+
+	if ports[0] != 30005 {
+		t.Fatalf("expected requested port 30005, got %d", ports[0])
+	}
+
+	seen := map[int]struct{}{}
+	for _, port := range ports {
+		if port < 30000 || port > 30009 || port == 30001 {
+			t.Fatalf("unexpected port %d", port)
+		}
+
+		if _, dup := seen[port]; dup {
+			t.Fatalf("duplicate port %d", port)
+		}
+		seen[port] = struct{}{}
+	}
+}
+
+func TestPickFreePortsExhausted(t *testing.T) {
+	var used portBitmap
+	for port := 30000; port <= 30008; port++ {
+		used.set(port)
+	}
+
+	if _, ok := pickFreePorts(&used, 30000, 30009, 3, []int{0}, nil); !ok {
+		t.Fatalf("expected last free port to be picked")
+	}
+
+	if _, ok := pickFreePorts(&used, 30000, 30009, 3, []int{0, 0}, nil); ok {
+		t.Fatalf("expected exhaustion with two ports requested")
+	}
+}
+
+func TestPortBitmapCount(t *testing.T) {
+	var used portBitmap
+	for _, port := range []int{30000, 30063, 30064, 30200, 31000} {
+		used.set(port)
+	}
+
+	if got := used.count(30000, 30200); got != 4 {
+		t.Fatalf("expected 4 used ports, got %d", got)
+	}
+
+	used.clear(30063)
+	if got := used.count(30000, 30999); got != 3 {
+		t.Fatalf("expected 3 used ports, got %d", got)
+	}
+}
+
+func TestInMemoryReserveNodePortsIsAllOrNothing(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	ctx := context.Background()
+
+	if _, err := repo.ReserveNodePorts(ctx, "", 30000, 30002, []int{0, 0}); err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "", 30000, 30002, []int{0, 0}); !errors.Is(err, ErrNoAvailableNodePort) {
+		t.Fatalf("expected no available nodeport, got %v", err)
+	}
+
+	used, err := repo.UsedNodePortCount(ctx, 30000, 30002)
+	if err != nil {
+		t.Fatalf("used count error: %v", err)
+	}
+
+	if used != 2 {
+		t.Fatalf("expected failed reservation to leave 2 used ports, got %d", used)
+	}
+}
+
+func BenchmarkInMemoryReserveNodePorts(b *testing.B) {
+	const (
+		min      = 30000
+		max      = 32767
+		perStack = 24
+	)
+
+	for _, utilization := range []int{10, 50, 95} {
+		b.Run(fmt.Sprintf("utilization=%d%%", utilization), func(b *testing.B) {
+			repo := NewInMemoryRepository(1)
+			ctx := context.Background()
+			fill := (max - min + 1) * utilization / 100
+			for range fill {
+				if _, err := repo.ReserveNodePort(ctx, min, max); err != nil {
+					b.Fatalf("fill error: %v", err)
+				}
+			}
+
+			want := make([]int, perStack)
+			b.ResetTimer()
+			for range b.N {
+				ports, err := repo.ReserveNodePorts(ctx, "", min, max, want)
+				if err != nil {
+					b.Fatalf("reserve error: %v", err)
+				}
+
+				b.StopTimer()
+				for _, port := range ports {
+					_ = repo.ReleaseNodePort(ctx, port)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func BenchmarkPickFreePorts(b *testing.B) {
+	const (
+		min      = 30000
+		max      = 32767
+		perStack = 24
+	)
+
+	for _, utilization := range []int{10, 50, 95} {
+		b.Run(fmt.Sprintf("utilization=%d%%", utilization), func(b *testing.B) {
+			var used portBitmap
+			total := max - min + 1
+			for i := range total * utilization / 100 {
+				// Spread used ports across the range so scans hit mixed words.
+				used.set(min + (i*7919)%total)
+			}
+
+			want := make([]int, perStack)
+			b.ResetTimer()
+			for i := range b.N {
+				if _, ok := pickFreePorts(&used, min, max, i%total, want, nil); !ok {
+					b.Fatalf("pick failed")
+				}
+			}
+		})
+	}
+}
+
+func TestInMemoryDeletedPortsCoolDown(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	repo.portCooldown = time.Minute
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	port, err := repo.ReserveNodePort(ctx, 30000, 30000)
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	st := Stack{StackID: "stack-1", OwnerID: "team-1", Ports: []PortMapping{{ContainerPort: 80, Protocol: "TCP", NodePort: port}}}
+	if err := repo.Create(ctx, st); err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if _, _, err := repo.Delete(ctx, st.StackID); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "team-2", 30000, 30000, []int{port}); !errors.Is(err, ErrNoAvailableNodePort) {
+		t.Fatalf("expected cooling port to be unavailable to another owner, got %v", err)
+	}
+
+	if used, _ := repo.UsedNodePortCount(ctx, 30000, 30000); used != 1 {
+		t.Fatalf("expected cooling port to count as used, got %d", used)
+	}
+
+	now = now.Add(time.Minute)
+	reclaimed, err := repo.ReclaimNodePorts(ctx, 30000, 30000)
+	if err != nil || reclaimed != 1 {
+		t.Fatalf("expected 1 reclaimed port, got %d (%v)", reclaimed, err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "team-2", 30000, 30000, []int{0}); err != nil {
+		t.Fatalf("expected port after cool-down, got %v", err)
+	}
+}
+
+func TestInMemoryReclaimsStaleReservations(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	repo.portLockTTL = time.Minute
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	leaked, err := repo.ReserveNodePort(ctx, 30000, 30001)
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if reclaimed, _ := repo.ReclaimNodePorts(ctx, 30000, 30001); reclaimed != 0 {
+		t.Fatalf("expected reservation within the lock TTL to be kept, reclaimed %d", reclaimed)
+	}
+
+	// A reservation attached to a stack is never stale.
+	port, err := repo.ReserveNodePort(ctx, 30000, 30001)
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	st := Stack{StackID: "stack-1", Ports: []PortMapping{{ContainerPort: 80, Protocol: "TCP", NodePort: port}}}
+	if err := repo.Create(ctx, st); err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	reclaimed, err := repo.ReclaimNodePorts(ctx, 30000, 30001)
+	if err != nil || reclaimed != 1 {
+		t.Fatalf("expected the leaked reservation to be reclaimed, got %d (%v)", reclaimed, err)
+	}
+
+	if used, _ := repo.UsedNodePortCount(ctx, 30000, 30001); used != 1 {
+		t.Fatalf("expected only the stack's port to stay used, got %d", used)
+	}
+
+	if got, err := repo.ReserveNodePorts(ctx, "", 30000, 30001, []int{leaked}); err != nil || got[0] != leaked {
+		t.Fatalf("expected reclaimed port %d to be reservable, got %v (%v)", leaked, got, err)
+	}
+}
+
+func TestInMemoryCoolingPortReusableBySameOwner(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	repo.portCooldown = time.Minute
+	ctx := context.Background()
+
+	port, err := repo.ReserveNodePort(ctx, 30000, 30010)
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	st := Stack{StackID: "stack-1", OwnerID: "team-1", Ports: []PortMapping{{ContainerPort: 80, Protocol: "TCP", NodePort: port}}}
+	if err := repo.Create(ctx, st); err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if _, _, err := repo.Delete(ctx, st.StackID); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+
+	ports, err := repo.ReserveNodePorts(ctx, "team-2", 30000, 30010, []int{port})
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	if ports[0] == port {
+		t.Fatalf("expected another owner to get a different port")
+	}
+
+	ports, err = repo.ReserveNodePorts(ctx, "team-1", 30000, 30010, []int{port})
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	if ports[0] != port {
+		t.Fatalf("expected same owner to reuse cooling port %d, got %d", port, ports[0])
+	}
+}
+
+func TestInMemoryReleasedReusedPortKeepsCoolDown(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	repo.portCooldown = time.Minute
+	ctx := context.Background()
+
+	port, err := repo.ReserveNodePort(ctx, 30000, 30000)
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	st := Stack{StackID: "stack-1", OwnerID: "team-1", Ports: []PortMapping{{ContainerPort: 80, Protocol: "TCP", NodePort: port}}}
+	if err := repo.Create(ctx, st); err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if _, _, err := repo.Delete(ctx, st.StackID); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "team-1", 30000, 30000, []int{port}); err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+
+	// The create fails, so the reservation is released again.
+	if err := repo.ReleaseNodePort(ctx, port); err != nil {
+		t.Fatalf("release error: %v", err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "team-2", 30000, 30000, []int{0}); !errors.Is(err, ErrNoAvailableNodePort) {
+		t.Fatalf("expected released port to stay cooling for other owners, got %v", err)
+	}
+
+	if _, err := repo.ReserveNodePorts(ctx, "team-1", 30000, 30000, []int{port}); err != nil {
+		t.Fatalf("expected same owner to reuse cooling port again, got %v", err)
+	}
+}
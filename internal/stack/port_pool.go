@@ -0,0 +1,77 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"smctf/internal/config"
+)
+
+func (s *Service) portPool(name string) (config.NodePortPool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = s.cfg.DefaultPortPool()
+	}
+
+	for _, pool := range s.cfg.PortPools() {
+		if pool.Name == name {
+			return pool, nil
+		}
+	}
+
+	return config.NodePortPool{}, fmt.Errorf("%w: unknown port_pool %q", ErrInvalidInput, name)
+}
+
+func validateRequestedNodePorts(errs *ValidationErrors, pool config.NodePortPool, targets []PortSpec) {
+	for i, target := range targets {
+		if target.NodePort == 0 {
+			continue
+		}
+
+		if target.NodePort < pool.Min || target.NodePort > pool.Max {
+			errs.addInput(fmt.Sprintf("target_port[%d].node_port", i), CodeInvalid, fmt.Sprintf("node_port must be within port_pool %s (%d-%d)", pool.Name, pool.Min, pool.Max))
+		}
+	}
+}
+
+func (s *Service) nodePortPoolUsage(ctx context.Context) (map[string]NodePortPoolUsage, int, error) {
+	pools := s.cfg.PortPools()
+	usage := make(map[string]NodePortPoolUsage, len(pools))
+	total := 0
+
+	for _, pool := range pools {
+		used, err := s.repo.UsedNodePortCount(ctx, pool.Min, pool.Max)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		usage[pool.Name] = NodePortPoolUsage{
+			Min:      pool.Min,
+			Max:      pool.Max,
+			Used:     used,
+			Capacity: pool.Max - pool.Min + 1,
+		}
+		total += used
+	}
+
+	return usage, total, nil
+}
+
+// reclaimNodePorts returns ports whose cool-down has passed (and stale reservations) to
+// every pool, so they are not only freed once a pool runs dry.
+func (s *Service) reclaimNodePorts(ctx context.Context) (int, int) {
+	reclaimed := 0
+	failures := 0
+	for _, pool := range s.cfg.PortPools() {
+		n, err := s.repo.ReclaimNodePorts(ctx, pool.Min, pool.Max)
+		reclaimed += n
+		if err != nil {
+			failures++
+			slog.Error("reclaim nodeports failed", slog.String("port_pool", pool.Name), slog.Any("error", err))
+		}
+	}
+
+	return reclaimed, failures
+}
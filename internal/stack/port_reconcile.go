@@ -0,0 +1,272 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// portReconcileGrace skips Services younger than this, matching the orphan pod grace, so
+// a Create that has not written its stack record yet is not mistaken for a leak.
+const portReconcileGrace = 2 * time.Minute
+
+type clusterService struct {
+	ServiceInfo
+	cluster Cluster
+}
+
+// ReconcileNodePorts compares the port locks in the repository, the nodePorts held by
+// stack Services and the ports of live stacks. With repair set, the differences are
+// fixed; conflicting locks are only reported.
+func (s *Service) ReconcileNodePorts(ctx context.Context, repair bool) (PortReconcileReport, error) {
+	now := s.now()
+	report := PortReconcileReport{CheckedAt: now, DryRun: !repair, Findings: []PortFinding{}}
+
+	// Locks are listed before stacks so a stack created in between shows up with its
+	// reservation rather than as a lock pointing at an unknown stack.
+	locks, err := s.repo.ListPortLocks(ctx)
+	if err != nil {
+		return PortReconcileReport{}, fmt.Errorf("list port locks: %w", err)
+	}
+
+	stacks, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return PortReconcileReport{}, fmt.Errorf("list stacks: %w", err)
+	}
+
+	// Ports are locked in one ledger, so the Services of every cluster are compared with it.
+	services := make([]clusterService, 0)
+	for _, cluster := range s.clusters {
+		items, err := cluster.Client.ListServicesWithNodePorts(ctx, s.scanNamespace())
+		if err != nil {
+			return PortReconcileReport{}, fmt.Errorf("list services: %w", err)
+		}
+
+		for _, svc := range items {
+			services = append(services, clusterService{ServiceInfo: svc, cluster: cluster})
+		}
+	}
+
+	report.Locks = len(locks)
+	lockByPort := make(map[int]PortLock, len(locks))
+	for _, lock := range locks {
+		lockByPort[lock.Port] = lock
+	}
+
+	liveStacks := make(map[string]Stack, len(stacks))
+	stackPorts := make(map[int]string)
+	for _, st := range stacks {
+		liveStacks[st.StackID] = st
+		for _, p := range st.Ports {
+			stackPorts[p.NodePort] = st.StackID
+			report.StackPorts++
+		}
+	}
+
+	staleBefore := now.Add(-s.cfg.PortLockTTL)
+	isStaleReservation := func(lock PortLock) bool {
+		return lock.StackID == "" && lock.CoolingUntil.IsZero() && lock.LockedAt.Before(staleBefore)
+	}
+
+	for _, st := range stacks {
+		for _, p := range st.Ports {
+			lock, ok := lockByPort[p.NodePort]
+			switch {
+			case ok && lock.StackID == st.StackID:
+				continue
+			case ok && lock.StackID != "":
+				report.add(PortFinding{Kind: PortFindingConflictingLock, NodePort: p.NodePort, StackID: st.StackID})
+				continue
+			}
+
+			finding := PortFinding{Kind: PortFindingMissingStackLock, NodePort: p.NodePort, StackID: st.StackID}
+			if repair {
+				finding.repaired(s.lockStackPorts(ctx, st.StackID, p.NodePort))
+			}
+			report.add(finding)
+		}
+	}
+
+	heldPorts := make(map[int]struct{})
+	for _, svc := range services {
+		report.ServicePorts += len(svc.NodePorts)
+
+		st, live := liveStacks[svc.StackID]
+		if live && st.ServiceName == svc.Name && s.clusterID(st.ClusterID) == svc.cluster.ID {
+			for _, port := range svc.NodePorts {
+				heldPorts[port] = struct{}{}
+				if _, known := stackPorts[port]; known {
+					continue
+				}
+
+				if lock, ok := lockByPort[port]; ok && lock.StackID == st.StackID {
+					continue
+				}
+
+				finding := PortFinding{Kind: PortFindingUnknownServicePort, NodePort: port, StackID: st.StackID, ServiceName: svc.Name}
+				if repair {
+					finding.repaired(s.lockStackPorts(ctx, st.StackID, port))
+				}
+				report.add(finding)
+			}
+
+			continue
+		}
+
+		if !svc.CreatedAt.IsZero() && svc.CreatedAt.After(now.Add(-portReconcileGrace)) {
+			for _, port := range svc.NodePorts {
+				heldPorts[port] = struct{}{}
+			}
+			continue
+		}
+
+		// A Service outside any live stack is only removed when none of its ports is backed
+		// by a fresh reservation or a live stack, i.e. no Create can still be using it.
+		inUse := false
+		for _, port := range svc.NodePorts {
+			lock, ok := lockByPort[port]
+			switch {
+			case !ok:
+			case lock.StackID == "" && lock.CoolingUntil.IsZero() && !isStaleReservation(lock):
+				inUse = true
+			case lock.StackID != "":
+				if _, live := liveStacks[lock.StackID]; live {
+					inUse = true
+				}
+			}
+		}
+
+		if inUse || len(svc.NodePorts) == 0 {
+			for _, port := range svc.NodePorts {
+				heldPorts[port] = struct{}{}
+			}
+			continue
+		}
+
+		var deleteErr error
+		if repair {
+			deleteErr = svc.cluster.Client.DeleteService(ctx, svc.Namespace, svc.Name)
+		}
+
+		if !repair || deleteErr != nil {
+			for _, port := range svc.NodePorts {
+				heldPorts[port] = struct{}{}
+			}
+		}
+
+		for _, port := range svc.NodePorts {
+			finding := PortFinding{Kind: PortFindingUnknownServicePort, NodePort: port, StackID: svc.StackID, ServiceName: svc.Name}
+			if repair {
+				finding.repaired(deleteErr)
+			}
+			report.add(finding)
+		}
+	}
+
+	for _, lock := range locks {
+		if _, owned := stackPorts[lock.Port]; owned {
+			continue
+		}
+
+		var kind PortFindingKind
+		switch {
+		case lock.StackID != "":
+			if _, live := liveStacks[lock.StackID]; live {
+				continue
+			}
+			kind = PortFindingDanglingLock
+		case isStaleReservation(lock):
+			kind = PortFindingStaleReservation
+		default:
+			continue
+		}
+
+		finding := PortFinding{Kind: kind, NodePort: lock.Port, StackID: lock.StackID}
+		if _, held := heldPorts[lock.Port]; repair && !held {
+			finding.repaired(s.deletePortLock(ctx, lock))
+		}
+		report.add(finding)
+	}
+
+	recordPortReconcileReport(report)
+
+	if repair {
+		s.reconcileMu.Lock()
+		s.lastReconcile = &report
+		s.reconcileMu.Unlock()
+	}
+
+	return report, nil
+}
+
+// PortReconcileReport returns the report of the last repairing pass run by the scheduler,
+// or a dry run when this process has not run one yet.
+func (s *Service) PortReconcileReport(ctx context.Context) (PortReconcileReport, error) {
+	s.reconcileMu.Lock()
+	last := s.lastReconcile
+	s.reconcileMu.Unlock()
+
+	if last != nil {
+		return *last, nil
+	}
+
+	return s.ReconcileNodePorts(ctx, false)
+}
+
+// lockStackPorts re-checks the stack before locking, so a stack deleted since it was
+// listed does not get its ports locked again.
+func (s *Service) lockStackPorts(ctx context.Context, stackID string, port int) error {
+	_, ok, err := s.repo.Get(ctx, stackID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: stack %s", ErrNotFound, stackID)
+	}
+
+	return s.repo.LockNodePorts(ctx, stackID, []int{port})
+}
+
+// deletePortLock re-checks that a dangling lock's stack is still gone; the lock itself is
+// only removed if it was not touched since it was listed.
+func (s *Service) deletePortLock(ctx context.Context, lock PortLock) error {
+	if lock.StackID != "" {
+		_, ok, err := s.repo.Get(ctx, lock.StackID)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return fmt.Errorf("stack %s exists", lock.StackID)
+		}
+	}
+
+	return s.repo.DeletePortLock(ctx, lock)
+}
+
+func (r *PortReconcileReport) add(finding PortFinding) {
+	r.Findings = append(r.Findings, finding)
+	if finding.Repaired {
+		r.Repaired++
+	} else if finding.Error != "" {
+		r.Failed++
+	}
+}
+
+func (f *PortFinding) repaired(err error) {
+	if err != nil {
+		f.Error = err.Error()
+		slog.Warn("port ledger repair failed",
+			slog.String("kind", string(f.Kind)),
+			slog.Int("node_port", f.NodePort),
+			slog.String("stack_id", f.StackID),
+			slog.String("service_name", f.ServiceName),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	f.Repaired = true
+}
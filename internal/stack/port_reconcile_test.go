@@ -0,0 +1,193 @@
+package stack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func reconcileTestConfig(cfg *config.StackConfig) {
+	cfg.PortLockTTL = 30 * time.Second
+}
+
+func findingKinds(report PortReconcileReport) map[PortFindingKind]int {
+	out := make(map[PortFindingKind]int)
+	for _, finding := range report.Findings {
+		out[finding.Kind]++
+	}
+
+	return out
+}
+
+func TestReconcileNodePortsCleanLedger(t *testing.T) {
+	svc, _, _ := newTestService(reconcileTestConfig)
+	if _, err := createTestStack(svc, CreateInput{}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	report, err := svc.ReconcileNodePorts(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(report.Findings) != 0 || report.Locks != 1 || report.ServicePorts != 1 || report.StackPorts != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestReconcileNodePortsRepairsLockOfDeletedStack(t *testing.T) {
+	svc, repo, k8s := newTestService(reconcileTestConfig)
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// The stack record is gone but its lock and Service were left behind.
+	delete(repo.stacks, st.StackID)
+	svc.now = func() time.Time { return time.Now().UTC().Add(10 * time.Minute) }
+
+	report, err := svc.ReconcileNodePorts(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	kinds := findingKinds(report)
+	if kinds[PortFindingDanglingLock] != 1 || kinds[PortFindingUnknownServicePort] != 1 || report.Repaired != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if used, _ := repo.UsedNodePortCount(context.Background(), 30000, 30010); used != 0 {
+		t.Fatalf("expected port to be freed, %d still used", used)
+	}
+
+	if services, _ := k8s.ListServices(context.Background(), "stacks"); len(services) != 0 {
+		t.Fatalf("expected orphan service to be deleted, got %v", services)
+	}
+}
+
+func TestReconcileNodePortsRemovesStaleReservationOnly(t *testing.T) {
+	svc, repo, _ := newTestService(reconcileTestConfig)
+
+	repo.now = func() time.Time { return time.Now().Add(-time.Minute) }
+	stale, err := repo.ReserveNodePort(context.Background(), 30000, 30010)
+	if err != nil {
+		t.Fatalf("reserve stale: %v", err)
+	}
+
+	repo.now = time.Now
+	fresh, err := repo.ReserveNodePort(context.Background(), 30000, 30010)
+	if err != nil {
+		t.Fatalf("reserve fresh: %v", err)
+	}
+
+	report, err := svc.ReconcileNodePorts(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(report.Findings) != 1 || report.Findings[0].Kind != PortFindingStaleReservation || report.Findings[0].NodePort != stale {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if _, ok := repo.ports[stale]; ok {
+		t.Fatalf("expected stale reservation %d to be removed", stale)
+	}
+
+	if _, ok := repo.ports[fresh]; !ok {
+		t.Fatalf("expected fresh reservation %d to be kept", fresh)
+	}
+}
+
+func TestReconcileNodePortsDeletesServiceHoldingUnknownPort(t *testing.T) {
+	svc, _, k8s := newTestService(reconcileTestConfig)
+	k8s.services["svc-ghost"] = serviceState{
+		namespace: "stacks",
+		stackID:   "ghost",
+		nodePorts: []int{30005},
+		createdAt: time.Now().Add(-time.Hour),
+	}
+	k8s.services["svc-young"] = serviceState{
+		namespace: "stacks",
+		stackID:   "young",
+		nodePorts: []int{30006},
+		createdAt: time.Now(),
+	}
+
+	report, err := svc.ReconcileNodePorts(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(report.Findings) != 1 || report.Findings[0].ServiceName != "svc-ghost" || !report.Findings[0].Repaired {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if _, ok := k8s.services["svc-ghost"]; ok {
+		t.Fatalf("expected svc-ghost to be deleted")
+	}
+
+	if _, ok := k8s.services["svc-young"]; !ok {
+		t.Fatalf("expected svc-young to be kept within the grace period")
+	}
+}
+
+func TestReconcileNodePortsRelocksStackPort(t *testing.T) {
+	svc, repo, _ := newTestService(reconcileTestConfig)
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	port := st.Ports[0].NodePort
+	delete(repo.ports, port)
+	repo.used.clear(port)
+
+	report, err := svc.ReconcileNodePorts(context.Background(), true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if kinds := findingKinds(report); kinds[PortFindingMissingStackLock] != 1 || report.Repaired != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if repo.ports[port] != st.StackID || !repo.used.has(port) {
+		t.Fatalf("expected port %d to be locked to %s again", port, st.StackID)
+	}
+}
+
+func TestPortReconcileReportDryRunUntilRepairPass(t *testing.T) {
+	svc, repo, _ := newTestService(reconcileTestConfig)
+
+	repo.now = func() time.Time { return time.Now().Add(-time.Minute) }
+	stale, err := repo.ReserveNodePort(context.Background(), 30000, 30010)
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+
+	report, err := svc.PortReconcileReport(context.Background())
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	if !report.DryRun || len(report.Findings) != 1 || report.Findings[0].Repaired {
+		t.Fatalf("expected unrepaired dry run finding, got %+v", report)
+	}
+
+	if _, ok := repo.ports[stale]; !ok {
+		t.Fatalf("dry run must not remove reservation %d", stale)
+	}
+
+	svc.CleanupExpiredAndOrphaned(context.Background())
+
+	report, err = svc.PortReconcileReport(context.Background())
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	if report.DryRun || report.Repaired != 1 {
+		t.Fatalf("expected last repair pass report, got %+v", report)
+	}
+}
@@ -0,0 +1,168 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"smctf/internal/config"
+)
+
+// resolvePriority maps a requested priority to a configured tier, applying the default
+// tier when none was requested.
+func (s *Service) resolvePriority(requested string) (string, *FieldError) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+	if len(s.cfg.PriorityTiers) == 0 {
+		if requested != "" {
+			return "", &FieldError{Field: "priority", Code: CodeNotAllowed, Reason: "priority tiers are not configured", Err: ErrInvalidInput}
+		}
+
+		return "", nil
+	}
+
+	if requested == "" {
+		return s.cfg.DefaultPriority(), nil
+	}
+
+	if s.priorityRank(requested) < 0 {
+		return "", &FieldError{Field: "priority", Code: CodeUnknown, Reason: fmt.Sprintf("unknown priority %q", requested), Err: ErrInvalidInput}
+	}
+
+	return requested, nil
+}
+
+// priorityRank returns the position of a tier from lowest to highest, or -1 for stacks
+// created without a tier.
+func (s *Service) priorityRank(name string) int {
+	return slices.IndexFunc(s.cfg.PriorityTiers, func(tier config.PriorityTier) bool { return tier.Name == name })
+}
+
+func (s *Service) priorityClassName(name string) string {
+	if rank := s.priorityRank(name); rank >= 0 {
+		return s.cfg.PriorityTiers[rank].ClassName
+	}
+
+	return ""
+}
+
+// preemptFor frees capacity in a cluster, or in its node pool when admission is scoped to
+// pools, for a create of the given priority by preempting stacks of lower tiers there,
+// lowest tier and youngest stack first. Nothing is preempted unless the victims together
+// free enough CPU and memory; it reports whether capacity was freed.
+func (s *Service) preemptFor(ctx context.Context, cluster Cluster, nodePool, priority, stackID string, cpuMilli, memBytes int64) bool {
+	rank := s.priorityRank(priority)
+	if rank <= 0 {
+		return false
+	}
+
+	budget, limited, err := s.capacityBudget(ctx, cluster, nodePool)
+	if err != nil || !limited {
+		return false
+	}
+
+	used, err := s.reservedCapacity(ctx, cluster, nodePool)
+	if err != nil {
+		return false
+	}
+
+	var need CapacityBudget
+	if budget.CPUMilli > 0 {
+		need.CPUMilli = used.CPUMilli + cpuMilli - budget.CPUMilli
+	}
+	if budget.MemoryBytes > 0 {
+		need.MemoryBytes = used.MemoryBytes + memBytes - budget.MemoryBytes
+	}
+
+	items, err := s.activeStacks(ctx)
+	if err != nil {
+		slog.Warn("list stacks for preemption failed", slog.Any("error", err))
+		return false
+	}
+
+	candidates := make([]Stack, 0)
+	for _, st := range items {
+		if !s.inAdmissionScope(st, cluster, nodePool) {
+			continue
+		}
+
+		if s.priorityRank(st.Priority) < rank {
+			candidates = append(candidates, st)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := s.priorityRank(candidates[i].Priority), s.priorityRank(candidates[j].Priority)
+		if ri != rj {
+			return ri < rj
+		}
+
+		return candidates[i].CreatedAt.After(candidates[j].CreatedAt)
+	})
+
+	var freed CapacityBudget
+	victims := make([]Stack, 0)
+	for _, st := range candidates {
+		if freed.CPUMilli >= need.CPUMilli && freed.MemoryBytes >= need.MemoryBytes {
+			break
+		}
+
+		victims = append(victims, st)
+		freed.CPUMilli += st.RequestedMilli
+		freed.MemoryBytes += st.RequestedBytes
+	}
+
+	if freed.CPUMilli < need.CPUMilli || freed.MemoryBytes < need.MemoryBytes {
+		return false
+	}
+
+	for _, st := range victims {
+		if err := s.preemptStack(ctx, st, stackID); err != nil {
+			slog.Error("preempt stack failed", slog.String("stack_id", st.StackID), slog.String("preempted_by", stackID), slog.Any("error", err))
+			return false
+		}
+	}
+
+	return true
+}
+
+// preemptStack removes a stack's pod and Service and keeps its record, without ports, in
+// the terminal preempted status until its TTL expires.
+func (s *Service) preemptStack(ctx context.Context, st Stack, preemptedBy string) error {
+	if err := s.deleteStackResources(ctx, st); err != nil {
+		return err
+	}
+
+	marked, ok, err := s.repo.MarkPreempted(ctx, st.StackID, preemptedBy)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return nil
+	}
+
+	priority := marked.Priority
+	if priority == "" {
+		priority = "none"
+	}
+	stackPreemptions.WithLabelValues(priority).Inc()
+
+	message := fmt.Sprintf("stack %s was preempted by %s", marked.StackID, preemptedBy)
+	if k8s, err := s.clientFor(marked.ClusterID); err != nil {
+		slog.Warn("record preemption event failed", slog.String("stack_id", marked.StackID), slog.Any("error", err))
+	} else if err := k8s.RecordEvent(ctx, marked.Namespace, marked.PodID, "Preempted", message); err != nil {
+		slog.Warn("record preemption event failed", slog.String("stack_id", marked.StackID), slog.Any("error", err))
+	}
+
+	slog.Warn("stack preempted",
+		slog.String("stack_id", marked.StackID),
+		slog.String("priority", marked.Priority),
+		slog.String("owner_id", marked.OwnerID),
+		slog.String("preempted_by", preemptedBy),
+	)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func preemptionTestConfig(cfg *config.StackConfig) {
+	cfg.CapacitySource = config.CapacitySourceStatic
+	cfg.CapacityCPUMilli = 150
+	cfg.CapacityMemoryBytes = 1 << 30
+	cfg.PriorityTiers = []config.PriorityTier{
+		{Name: "player", ClassName: "smctf-player"},
+		{Name: "admin", ClassName: "smctf-admin"},
+	}
+}
+
+func TestCreateAssignsDefaultPriorityClass(t *testing.T) {
+	svc, _, k8s := newTestService(preemptionTestConfig)
+
+	st, err := createTestStack(svc, CreateInput{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if st.Priority != "player" || k8s.pods[st.PodID].priority != "smctf-player" {
+		t.Fatalf("expected default player tier, got %q / %q", st.Priority, k8s.pods[st.PodID].priority)
+	}
+}
+
+func TestCreateRejectsUnknownPriority(t *testing.T) {
+	svc, _, _ := newTestService(preemptionTestConfig)
+	if _, err := createTestStack(svc, CreateInput{Priority: "root"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	plain, _, _ := newTestService(nil)
+	if _, err := createTestStack(plain, CreateInput{Priority: "admin"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without tiers, got %v", err)
+	}
+}
+
+func TestHigherPriorityPreemptsLowerPriority(t *testing.T) {
+	svc, repo, k8s := newTestService(preemptionTestConfig)
+	ctx := context.Background()
+
+	player, err := createTestStack(svc, CreateInput{Priority: "player"})
+	if err != nil {
+		t.Fatalf("player create: %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{Priority: "player"}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected player not to preempt player, got %v", err)
+	}
+
+	admin, err := createTestStack(svc, CreateInput{Priority: "admin"})
+	if err != nil {
+		t.Fatalf("admin create: %v", err)
+	}
+
+	got, err := svc.GetDetails(ctx, player.StackID)
+	if err != nil {
+		t.Fatalf("get preempted stack: %v", err)
+	}
+
+	if got.Status != StatusPreempted || got.PreemptedBy != admin.StackID || len(got.Ports) != 0 {
+		t.Fatalf("unexpected preempted stack: %+v", got)
+	}
+
+	if _, ok := k8s.pods[player.PodID]; ok {
+		t.Fatalf("expected preempted pod to be deleted")
+	}
+
+	if repo.ports[player.Ports[0].NodePort] == player.StackID {
+		t.Fatalf("expected preempted stack's port to be released")
+	}
+
+	if len(k8s.events) != 1 || k8s.events[0].reason != "Preempted" || k8s.events[0].podID != player.PodID {
+		t.Fatalf("expected a preemption event, got %+v", k8s.events)
+	}
+
+	svc.CleanupExpiredAndOrphaned(ctx)
+	if _, ok, _ := repo.Get(ctx, player.StackID); !ok {
+		t.Fatalf("expected preempted record to be kept until its ttl")
+	}
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(2 * time.Hour) }
+	svc.CleanupExpiredAndOrphaned(ctx)
+	if _, ok, _ := repo.Get(ctx, player.StackID); ok {
+		t.Fatalf("expected preempted record to be removed after its ttl")
+	}
+}
+
+func TestPreemptionSkipsWhenVictimsAreNotEnough(t *testing.T) {
+	svc, _, _ := newTestService(preemptionTestConfig)
+
+	if _, err := createTestStack(svc, CreateInput{Priority: "admin"}); err != nil {
+		t.Fatalf("admin create: %v", err)
+	}
+
+	if _, err := createTestStack(svc, CreateInput{Priority: "admin"}); !errors.Is(err, ErrClusterSaturated) {
+		t.Fatalf("expected admin not to preempt admin, got %v", err)
+	}
+}
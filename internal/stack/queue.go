@@ -0,0 +1,415 @@
+package stack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CreateOrQueue creates a stack, or, when the caller opted in with Queue and its target is
+// saturated, returns a queue ticket instead. Requests never jump ahead of tickets that are
+// already waiting for the same target.
+func (s *Service) CreateOrQueue(ctx context.Context, in CreateInput) (Stack, *QueueTicket, error) {
+	if !in.Queue {
+		st, err := s.Create(ctx, in)
+		return st, nil, err
+	}
+
+	if strings.TrimSpace(in.OwnerID) == "" {
+		return Stack{}, nil, fmt.Errorf("%w: queue requires owner_id", ErrInvalidInput)
+	}
+
+	valid, _, err := s.prepareCreate(&in)
+	if err != nil {
+		return Stack{}, nil, err
+	}
+
+	target, err := s.queueTarget(ctx, in, valid)
+	if err != nil {
+		return Stack{}, nil, err
+	}
+
+	tickets, err := s.repo.ListQueueTickets(ctx)
+	if err != nil {
+		return Stack{}, nil, err
+	}
+
+	queued := ticketsWithStatus(tickets, TicketStatusQueued)
+	if !s.hasQueuedTarget(ctx, queued, target) {
+		st, err := s.Create(ctx, in)
+		if !errors.Is(err, ErrClusterSaturated) {
+			return st, nil, err
+		}
+	}
+
+	ticket, err := s.enqueue(ctx, in, valid, queued)
+	if err != nil {
+		if errors.Is(err, ErrClusterSaturated) {
+			s.recordRejectedDemand(ctx, in, valid)
+		}
+
+		return Stack{}, nil, err
+	}
+
+	return Stack{}, &ticket, nil
+}
+
+func (s *Service) enqueue(ctx context.Context, in CreateInput, valid ValidationResult, queued []QueueTicket) (QueueTicket, error) {
+	if len(queued) >= s.cfg.QueueMaxLength {
+		return QueueTicket{}, fmt.Errorf("%w: queue is full", ErrClusterSaturated)
+	}
+
+	owned := 0
+	for _, ticket := range queued {
+		if ticket.OwnerID == in.OwnerID {
+			owned++
+		}
+	}
+
+	if owned >= s.cfg.QueueMaxPerOwner {
+		return QueueTicket{}, fmt.Errorf("%w: owner_id already has %d queued request(s)", ErrInvalidInput, owned)
+	}
+
+	now := s.now()
+	ticket := QueueTicket{
+		TicketID:    newTicketID(now),
+		Status:      TicketStatusQueued,
+		OwnerID:     in.OwnerID,
+		ChallengeID: in.ChallengeID,
+		PortPool:    in.PortPool,
+		Priority:    in.Priority,
+		Region:      in.Region,
+		NodePool:    in.NodePool,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		PodSpecYML:  in.PodSpecYML,
+		TargetPorts: in.TargetPorts,
+
+		RequestedMilli: valid.RequestedMilli,
+		RequestedBytes: valid.RequestedBytes,
+	}
+
+	if err := s.repo.CreateQueueTicket(ctx, ticket); err != nil {
+		return QueueTicket{}, err
+	}
+
+	order := fairQueueOrder(append(queued, ticket))
+	s.annotateTickets(ctx, order)
+	for _, item := range order {
+		if item.TicketID == ticket.TicketID {
+			return item, nil
+		}
+	}
+
+	return ticket, nil
+}
+
+func (s *Service) GetQueueTicket(ctx context.Context, ticketID string) (QueueTicket, error) {
+	ticket, ok, err := s.repo.GetQueueTicket(ctx, ticketID)
+	if err != nil {
+		return QueueTicket{}, err
+	}
+
+	if !ok {
+		return QueueTicket{}, ErrNotFound
+	}
+
+	if ticket.Status != TicketStatusQueued {
+		return ticket, nil
+	}
+
+	tickets, err := s.repo.ListQueueTickets(ctx)
+	if err != nil {
+		return QueueTicket{}, err
+	}
+
+	order := fairQueueOrder(ticketsWithStatus(tickets, TicketStatusQueued))
+	s.annotateTickets(ctx, order)
+	for _, item := range order {
+		if item.TicketID == ticketID {
+			return item, nil
+		}
+	}
+
+	return ticket, nil
+}
+
+func (s *Service) CancelQueueTicket(ctx context.Context, ticketID string) (QueueTicket, error) {
+	ticket, ok, err := s.repo.GetQueueTicket(ctx, ticketID)
+	if err != nil {
+		return QueueTicket{}, err
+	}
+
+	if !ok {
+		return QueueTicket{}, ErrNotFound
+	}
+
+	ticket.Status = TicketStatusCancelled
+	ticket.UpdatedAt = s.now()
+	if err := s.repo.UpdateQueueTicket(ctx, ticket, TicketStatusQueued); err != nil {
+		return QueueTicket{}, err
+	}
+
+	return ticket, nil
+}
+
+// ProcessQueue provisions queued tickets in fair order. A ticket whose target turns out
+// saturated holds back the later tickets for the same target only. It also expires tickets that waited longer than STACK_QUEUE_WAIT_TIMEOUT and
+// forgets finished tickets after the same period.
+func (s *Service) ProcessQueue(ctx context.Context) {
+	tickets, err := s.repo.ListQueueTickets(ctx)
+	if err != nil {
+		slog.Error("list queue tickets failed", slog.Any("error", err))
+		return
+	}
+
+	now := s.now()
+	cutoff := now.Add(-s.cfg.QueueWaitTimeout)
+	expired := 0
+	queued := make([]QueueTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		switch ticket.Status {
+		case TicketStatusQueued:
+			if ticket.CreatedAt.After(cutoff) {
+				queued = append(queued, ticket)
+				continue
+			}
+
+			ticket.Status = TicketStatusExpired
+			ticket.UpdatedAt = now
+			if err := s.repo.UpdateQueueTicket(ctx, ticket, TicketStatusQueued); err != nil && !errors.Is(err, ErrTicketClosed) {
+				slog.Error("expire queue ticket failed", slog.String("ticket_id", ticket.TicketID), slog.Any("error", err))
+				continue
+			}
+			expired++
+		case TicketStatusProvisioning:
+			// Left behind by a process that died while provisioning it.
+			if ticket.UpdatedAt.After(cutoff) {
+				continue
+			}
+
+			ticket.Status = TicketStatusFailed
+			ticket.Error = "provisioning interrupted"
+			ticket.UpdatedAt = now
+			if err := s.repo.UpdateQueueTicket(ctx, ticket, TicketStatusProvisioning); err != nil && !errors.Is(err, ErrTicketClosed) {
+				slog.Error("fail interrupted queue ticket failed", slog.String("ticket_id", ticket.TicketID), slog.Any("error", err))
+			}
+		default:
+			if ticket.UpdatedAt.After(cutoff) {
+				continue
+			}
+
+			if err := s.repo.DeleteQueueTicket(ctx, ticket.TicketID); err != nil {
+				slog.Error("delete finished queue ticket failed", slog.String("ticket_id", ticket.TicketID), slog.Any("error", err))
+			}
+		}
+	}
+
+	provisioned := 0
+	failed := 0
+	saturated := make(map[string]bool)
+	for _, ticket := range fairQueueOrder(queued) {
+		target := s.ticketTarget(ctx, ticket)
+		if saturated[target] {
+			continue
+		}
+
+		ticket.Status = TicketStatusProvisioning
+		ticket.UpdatedAt = s.now()
+		if err := s.repo.UpdateQueueTicket(ctx, ticket, TicketStatusQueued); err != nil {
+			if !errors.Is(err, ErrTicketClosed) && !errors.Is(err, ErrNotFound) {
+				slog.Error("claim queue ticket failed", slog.String("ticket_id", ticket.TicketID), slog.Any("error", err))
+			}
+			continue
+		}
+
+		s.releaseBalloon(ctx, demandItem{key: ticket.TicketID, region: ticket.Region, portPool: ticket.PortPool})
+		st, err := s.Create(ctx, ticketInput(ticket))
+
+		switch {
+		case errors.Is(err, ErrClusterSaturated), errors.Is(err, ErrNoAvailableNodePort):
+			ticket.Status = TicketStatusQueued
+			saturated[target] = true
+		case err != nil:
+			ticket.Status = TicketStatusFailed
+			ticket.Error = truncateError(err.Error())
+			failed++
+		default:
+			ticket.Status = TicketStatusProvisioned
+			ticket.StackID = st.StackID
+			provisioned++
+		}
+
+		ticket.UpdatedAt = s.now()
+		if err := s.repo.UpdateQueueTicket(ctx, ticket, TicketStatusProvisioning); err != nil {
+			slog.Error("queue ticket update failed", slog.String("ticket_id", ticket.TicketID), slog.String("status", string(ticket.Status)), slog.Any("error", err))
+		}
+	}
+
+	if len(queued) == 0 && expired == 0 {
+		return
+	}
+
+	slog.Info("queue processing completed",
+		slog.Int("queued", len(queued)),
+		slog.Int("provisioned", provisioned),
+		slog.Int("failed", failed),
+		slog.Int("expired", expired),
+		slog.Int("saturated", len(saturated)),
+	)
+}
+
+// queueTarget names what a request waits for: the cluster it routes to, its node pool and
+// its port pool.
+func (s *Service) queueTarget(ctx context.Context, in CreateInput, valid ValidationResult) (string, error) {
+	cluster, err := s.routeCluster(ctx, valid.Cluster, in.Region, in.PortPool)
+	if err != nil {
+		return "", err
+	}
+
+	portPool := in.PortPool
+	if portPool == "" {
+		portPool = cluster.PortPool
+	}
+
+	if portPool == "" {
+		portPool = s.cfg.DefaultPortPool()
+	}
+
+	return cluster.ID + "/" + in.NodePool + "/" + portPool, nil
+}
+
+// ticketTarget is the queue target of a ticket, or "" when the ticket no longer validates
+// or routes; Create then fails it with the reason.
+func (s *Service) ticketTarget(ctx context.Context, ticket QueueTicket) string {
+	in := ticketInput(ticket)
+	valid, _, err := s.prepareCreate(&in)
+	if err != nil {
+		return ""
+	}
+
+	target, err := s.queueTarget(ctx, in, valid)
+	if err != nil {
+		return ""
+	}
+
+	return target
+}
+
+func (s *Service) hasQueuedTarget(ctx context.Context, queued []QueueTicket, target string) bool {
+	for _, ticket := range queued {
+		if s.ticketTarget(ctx, ticket) == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ticketInput(ticket QueueTicket) CreateInput {
+	return CreateInput{
+		PodSpecYML:  ticket.PodSpecYML,
+		TargetPorts: ticket.TargetPorts,
+		OwnerID:     ticket.OwnerID,
+		ChallengeID: ticket.ChallengeID,
+		PortPool:    ticket.PortPool,
+		Priority:    ticket.Priority,
+		Region:      ticket.Region,
+		NodePool:    ticket.NodePool,
+		Queue:       true,
+	}
+}
+
+// kickQueue asks the scheduler to process the queue now instead of on its next tick,
+// e.g. after a delete freed capacity.
+func (s *Service) kickQueue() {
+	select {
+	case s.queueKick <- struct{}{}:
+	default:
+	}
+}
+
+// annotateTickets fills in positions and a rough ETA. Each position is expected to start
+// when the next running stack reaches its TTL; positions beyond the running stacks get
+// no ETA.
+func (s *Service) annotateTickets(ctx context.Context, order []QueueTicket) {
+	stacks, err := s.repo.ListAll(ctx)
+	if err != nil {
+		slog.Warn("list stacks for queue eta failed", slog.Any("error", err))
+	}
+
+	expiries := make([]time.Time, 0, len(stacks))
+	for _, st := range stacks {
+		if st.Status == StatusRunning || st.Status == StatusCreating {
+			expiries = append(expiries, st.TTLExpiresAt)
+		}
+	}
+	sort.Slice(expiries, func(i, j int) bool { return expiries[i].Before(expiries[j]) })
+
+	now := s.now()
+	for i := range order {
+		order[i].Position = i + 1
+		order[i].ETA = nil
+		if i < len(expiries) {
+			eta := expiries[i]
+			if eta.Before(now) {
+				eta = now
+			}
+			order[i].ETA = &eta
+		}
+	}
+}
+
+// fairQueueOrder returns queued tickets round-robin across owners, each owner's tickets
+// in FIFO order and owners ordered by their oldest ticket.
+func fairQueueOrder(queued []QueueTicket) []QueueTicket {
+	sorted := append([]QueueTicket(nil), queued...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TicketID < sorted[j].TicketID })
+
+	byOwner := make(map[string][]QueueTicket)
+	owners := make([]string, 0)
+	for _, ticket := range sorted {
+		if _, ok := byOwner[ticket.OwnerID]; !ok {
+			owners = append(owners, ticket.OwnerID)
+		}
+		byOwner[ticket.OwnerID] = append(byOwner[ticket.OwnerID], ticket)
+	}
+
+	out := make([]QueueTicket, 0, len(sorted))
+	for round := 0; len(out) < len(sorted); round++ {
+		for _, owner := range owners {
+			if round < len(byOwner[owner]) {
+				out = append(out, byOwner[owner][round])
+			}
+		}
+	}
+
+	return out
+}
+
+func ticketsWithStatus(tickets []QueueTicket, status TicketStatus) []QueueTicket {
+	out := make([]QueueTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.Status == status {
+			out = append(out, ticket)
+		}
+	}
+
+	return out
+}
+
+// newTicketID starts with the creation time so ticket ids sort in FIFO order.
+func newTicketID(now time.Time) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("ticket-%016x", now.UnixNano())
+	}
+
+	return fmt.Sprintf("ticket-%016x-%s", now.UnixNano(), hex.EncodeToString(buf))
+}
@@ -0,0 +1,224 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smctf/internal/config"
+)
+
+func queueTestConfig(cfg *config.StackConfig) {
+	cfg.CapacitySource = config.CapacitySourceStatic
+	cfg.CapacityCPUMilli = 150
+	cfg.CapacityMemoryBytes = 1 << 30
+	cfg.QueueMaxLength = 10
+	cfg.QueueMaxPerOwner = 1
+	cfg.QueueWaitTimeout = time.Minute
+}
+
+func TestCreateOrQueueCreatesWhenCapacityIsFree(t *testing.T) {
+	svc, _, _ := newTestService(queueTestConfig)
+
+	st, ticket, err := svc.CreateOrQueue(context.Background(), testCreateInput(CreateInput{OwnerID: "team-a", Queue: true}))
+	if err != nil {
+		t.Fatalf("create or queue: %v", err)
+	}
+
+	if ticket != nil || st.StackID == "" {
+		t.Fatalf("expected a stack without a ticket, got %+v / %+v", st, ticket)
+	}
+}
+
+func TestCreateOrQueueRequiresOwner(t *testing.T) {
+	svc, _, _ := newTestService(queueTestConfig)
+
+	if _, _, err := svc.CreateOrQueue(context.Background(), testCreateInput(CreateInput{Queue: true})); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCreateOrQueueProvisionsTicketAfterDelete(t *testing.T) {
+	svc, _, _ := newTestService(queueTestConfig)
+	ctx := context.Background()
+
+	first, _, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-a", Queue: true}))
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	_, ticketB, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-b", Queue: true}))
+	if err != nil || ticketB == nil {
+		t.Fatalf("expected team-b to be queued, got %v / %v", ticketB, err)
+	}
+
+	if ticketB.Position != 1 || ticketB.ETA == nil || !ticketB.ETA.Equal(first.TTLExpiresAt) {
+		t.Fatalf("unexpected ticket position/eta: %+v", ticketB)
+	}
+
+	if _, _, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-b", Queue: true})); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected per-owner limit, got %v", err)
+	}
+
+	_, ticketC, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-c", Queue: true}))
+	if err != nil || ticketC == nil || ticketC.Position != 2 {
+		t.Fatalf("expected team-c at position 2, got %+v / %v", ticketC, err)
+	}
+
+	if err := svc.Delete(ctx, first.StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	select {
+	case <-svc.queueKick:
+	default:
+		t.Fatalf("expected delete to kick the queue")
+	}
+
+	svc.ProcessQueue(ctx)
+
+	got, err := svc.GetQueueTicket(ctx, ticketB.TicketID)
+	if err != nil {
+		t.Fatalf("get ticket: %v", err)
+	}
+
+	if got.Status != TicketStatusProvisioned || got.StackID == "" {
+		t.Fatalf("expected team-b to be provisioned, got %+v", got)
+	}
+
+	if _, err := svc.GetDetails(ctx, got.StackID); err != nil {
+		t.Fatalf("expected provisioned stack to exist: %v", err)
+	}
+
+	got, err = svc.GetQueueTicket(ctx, ticketC.TicketID)
+	if err != nil {
+		t.Fatalf("get ticket: %v", err)
+	}
+
+	if got.Status != TicketStatusQueued || got.Position != 1 {
+		t.Fatalf("expected team-c to move to the front, got %+v", got)
+	}
+}
+
+func TestCancelQueueTicket(t *testing.T) {
+	svc, _, _ := newTestService(queueTestConfig)
+	ctx := context.Background()
+
+	if _, _, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-a", Queue: true})); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	_, ticket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-b", Queue: true}))
+	if err != nil || ticket == nil {
+		t.Fatalf("expected a ticket, got %v / %v", ticket, err)
+	}
+
+	cancelled, err := svc.CancelQueueTicket(ctx, ticket.TicketID)
+	if err != nil || cancelled.Status != TicketStatusCancelled {
+		t.Fatalf("expected cancelled ticket, got %+v / %v", cancelled, err)
+	}
+
+	if _, err := svc.CancelQueueTicket(ctx, ticket.TicketID); !errors.Is(err, ErrTicketClosed) {
+		t.Fatalf("expected ErrTicketClosed, got %v", err)
+	}
+
+	if _, err := svc.CancelQueueTicket(ctx, "ticket-missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestProcessQueueExpiresOldTickets(t *testing.T) {
+	svc, repo, _ := newTestService(queueTestConfig)
+	ctx := context.Background()
+
+	if _, _, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-a", Queue: true})); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	_, ticket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-b", Queue: true}))
+	if err != nil || ticket == nil {
+		t.Fatalf("expected a ticket, got %v / %v", ticket, err)
+	}
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(2 * time.Minute) }
+	svc.ProcessQueue(ctx)
+
+	if got := repo.tickets[ticket.TicketID]; got.Status != TicketStatusExpired {
+		t.Fatalf("expected expired ticket, got %+v", got)
+	}
+
+	svc.now = func() time.Time { return time.Now().UTC().Add(4 * time.Minute) }
+	svc.ProcessQueue(ctx)
+
+	if _, ok := repo.tickets[ticket.TicketID]; ok {
+		t.Fatalf("expected finished ticket to be removed")
+	}
+}
+
+func TestFairQueueOrderRoundRobinsOwners(t *testing.T) {
+	queued := []QueueTicket{
+		{TicketID: "ticket-1", OwnerID: "team-a"},
+		{TicketID: "ticket-2", OwnerID: "team-a"},
+		{TicketID: "ticket-3", OwnerID: "team-a"},
+		{TicketID: "ticket-4", OwnerID: "team-b"},
+		{TicketID: "ticket-5", OwnerID: "team-c"},
+		{TicketID: "ticket-6", OwnerID: "team-b"},
+	}
+
+	order := fairQueueOrder(queued)
+	want := []string{"ticket-1", "ticket-4", "ticket-5", "ticket-2", "ticket-6", "ticket-3"}
+	for i, id := range want {
+		if order[i].TicketID != id {
+			t.Fatalf("unexpected order at %d: got %s, want %s", i, order[i].TicketID, id)
+		}
+	}
+}
+
+func TestQueueSaturationIsPerTarget(t *testing.T) {
+	svc, k8s := newNodePoolTestService()
+	queueTestConfig(&svc.cfg)
+	svc.cfg.CapacitySource = config.CapacitySourceNodes
+	k8s.perNode = CapacityBudget{CPUMilli: 150, MemoryBytes: 1 << 30}
+	ctx := context.Background()
+
+	if _, _, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-a", NodePool: "arm64", Queue: true})); err != nil {
+		t.Fatalf("arm64 create: %v", err)
+	}
+
+	_, armTicket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-b", NodePool: "arm64", Queue: true}))
+	if err != nil || armTicket == nil {
+		t.Fatalf("expected the arm64 request to be queued, got %v / %v", armTicket, err)
+	}
+
+	// Tickets waiting for arm64 do not hold back amd64 requests.
+	amd := make([]Stack, 0, 3)
+	for _, owner := range []string{"team-c", "team-d", "team-e"} {
+		st, ticket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: owner, NodePool: "amd64", Queue: true}))
+		if err != nil || ticket != nil {
+			t.Fatalf("expected an amd64 stack for %s, got %v / %v", owner, ticket, err)
+		}
+		amd = append(amd, st)
+	}
+
+	_, amdTicket, err := svc.CreateOrQueue(ctx, testCreateInput(CreateInput{OwnerID: "team-f", NodePool: "amd64", Queue: true}))
+	if err != nil || amdTicket == nil {
+		t.Fatalf("expected the amd64 request to be queued, got %v / %v", amdTicket, err)
+	}
+
+	if err := svc.Delete(ctx, amd[0].StackID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	svc.ProcessQueue(ctx)
+
+	got, err := svc.GetQueueTicket(ctx, armTicket.TicketID)
+	if err != nil || got.Status != TicketStatusQueued {
+		t.Fatalf("expected the arm64 ticket to stay queued, got %+v / %v", got, err)
+	}
+
+	got, err = svc.GetQueueTicket(ctx, amdTicket.TicketID)
+	if err != nil || got.Status != TicketStatusProvisioned {
+		t.Fatalf("expected the amd64 ticket behind it to be provisioned, got %+v / %v", got, err)
+	}
+}
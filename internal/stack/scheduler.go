@@ -31,6 +31,8 @@ func (s *Scheduler) Run(ctx context.Context) {
 	defer ticker.Stop()
 
 	s.service.CleanupExpiredAndOrphaned(ctx)
+	s.service.ProcessQueue(ctx)
+	s.service.ReportDemand(ctx)
 
 	for {
 		select {
@@ -38,6 +40,11 @@ func (s *Scheduler) Run(ctx context.Context) {
 			return
 		case <-ticker.C:
 			s.service.CleanupExpiredAndOrphaned(ctx)
+			s.service.ProcessQueue(ctx)
+			s.service.ReportDemand(ctx)
+		case <-s.service.queueKick:
+			s.service.ProcessQueue(ctx)
+			s.service.ReportDemand(ctx)
 		}
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"smctf/internal/config"
@@ -18,35 +19,93 @@ import (
 type Service struct {
 	cfg       config.StackConfig
 	repo      RepositoryClientAPI
-	k8s       KubernetesClientAPI
+	clusters  []Cluster
 	validator *Validator
 	now       func() time.Time
+
+	reconcileMu   sync.Mutex
+	lastReconcile *PortReconcileReport
+
+	capacity capacityCache
+
+	queueKick chan struct{}
 }
 
 func NewService(cfg config.StackConfig, repo RepositoryClientAPI, k8s KubernetesClientAPI) *Service {
+	return NewServiceWithClusters(cfg, repo, []Cluster{{Client: k8s}})
+}
+
+// NewServiceWithClusters creates a service that provisions stacks across clusters. The
+// first cluster also owns stacks recorded without a cluster id.
+func NewServiceWithClusters(cfg config.StackConfig, repo RepositoryClientAPI, clusters []Cluster) *Service {
 	return &Service{
 		cfg:       cfg,
 		repo:      repo,
-		k8s:       k8s,
+		clusters:  clusters,
 		validator: NewValidator(cfg),
 		now: func() time.Time {
 			return time.Now().UTC()
 		},
+		queueKick: make(chan struct{}, 1),
 	}
 }
 
+// Create provisions a stack. A create rejected for lack of cluster capacity counts as
+// pending demand for STACK_DEMAND_WINDOW unless Queue is set, in which case the queue
+// accounts for it.
 func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
-	valid, err := s.validator.ValidatePodSpec(in.PodSpecYML, in.TargetPorts)
+	valid, pool, err := s.prepareCreate(&in)
+	if err != nil {
+		return Stack{}, err
+	}
+
+	item, rejected := s.rejectedDemandFor(ctx, in)
+	if rejected {
+		s.releaseBalloon(ctx, item)
+	}
+
+	st, err := s.create(ctx, in, valid, pool)
+	switch {
+	case err == nil && rejected:
+		s.forgetRejectedDemand(ctx, in)
+	case errors.Is(err, ErrClusterSaturated) && !in.Queue:
+		s.recordRejectedDemand(ctx, in, valid)
+	}
+
+	return st, err
+}
+
+func (s *Service) create(ctx context.Context, in CreateInput, valid ValidationResult, pool config.NodePortPool) (Stack, error) {
+	cluster, pool, err := s.routeCreate(ctx, in, valid, pool)
 	if err != nil {
 		return Stack{}, err
 	}
 
 	stackID := newStackID()
+	if err := s.admit(ctx, cluster, in.NodePool, valid.RequestedMilli, valid.RequestedBytes); err != nil {
+		if !errors.Is(err, ErrClusterSaturated) || !s.preemptFor(ctx, cluster, in.NodePool, in.Priority, stackID, valid.RequestedMilli, valid.RequestedBytes) {
+			return Stack{}, err
+		}
+
+		if err := s.admit(ctx, cluster, in.NodePool, valid.RequestedMilli, valid.RequestedBytes); err != nil {
+			return Stack{}, err
+		}
+	}
+
+	preferred := s.preferredNodePorts(ctx, pool, in.OwnerID, in.ChallengeID, valid.TargetPorts)
+
+	namespace, namespaceLabels := s.stackNamespace(in.OwnerID, stackID)
 	now := s.now()
+	placement := s.placementStrategy(valid.Placement)
 	var lastErr error
 
 	for attempt := range 2 {
-		ports, reservedPorts, reserveErr := s.reservePorts(ctx, valid.TargetPorts)
+		// A retry means Kubernetes rejected a nodeport we picked, so skip preferences.
+		if attempt > 0 {
+			preferred = nil
+		}
+
+		ports, reservedPorts, reserveErr := s.reservePorts(ctx, pool, in.OwnerID, valid.TargetPorts, preferred)
 		if reserveErr != nil {
 			return Stack{}, reserveErr
 		}
@@ -59,17 +118,25 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
 		}
 
 		st := Stack{
-			StackID:        stackID,
-			Namespace:      s.cfg.Namespace,
-			PodSpecYAML:    valid.SanitizedYAML,
-			TargetPorts:    valid.TargetPorts,
-			Ports:          ports,
-			Status:         StatusCreating,
-			CreatedAt:      now,
-			UpdatedAt:      now,
-			TTLExpiresAt:   now.Add(s.cfg.StackTTL),
-			RequestedMilli: valid.RequestedMilli,
-			RequestedBytes: valid.RequestedBytes,
+			StackID:                 stackID,
+			Namespace:               namespace,
+			PodSpecYAML:             valid.SanitizedYAML,
+			TargetPorts:             valid.TargetPorts,
+			Ports:                   ports,
+			Status:                  StatusCreating,
+			CreatedAt:               now,
+			UpdatedAt:               now,
+			TTLExpiresAt:            now.Add(s.cfg.StackTTL),
+			RequestedMilli:          valid.RequestedMilli,
+			RequestedBytes:          valid.RequestedBytes,
+			RequestedEphemeralBytes: valid.RequestedEphemeralBytes,
+			OwnerID:                 in.OwnerID,
+			ChallengeID:             in.ChallengeID,
+			PortPool:                pool.Name,
+			Placement:               placement,
+			Priority:                in.Priority,
+			ClusterID:               cluster.ID,
+			NodePool:                in.NodePool,
 		}
 
 		podName := stackID
@@ -77,12 +144,19 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
 			podName = fmt.Sprintf("%s-retry-%d", stackID, attempt)
 		}
 
-		result, err := s.k8s.CreatePodAndService(ctx, ProvisionRequest{
-			Namespace:  s.cfg.Namespace,
+		result, err := cluster.Client.CreatePodAndService(ctx, ProvisionRequest{
+			Namespace:  namespace,
 			StackID:    stackID,
 			PodName:    podName,
 			PodSpecYML: valid.SanitizedYAML,
 			Ports:      ports,
+			OwnerID:    in.OwnerID,
+			Placement:  placement,
+			NodePool:   in.NodePool,
+
+			PriorityClassName: s.priorityClassName(in.Priority),
+			NamespaceLabels:   namespaceLabels,
+			ConfigMaps:        valid.ConfigMaps,
 		})
 		if err != nil {
 			lastErr = err
@@ -100,15 +174,16 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
 		st.NodeID = result.NodeID
 		st.Status = result.Status
 
-		nodePublicIP, ipErr := s.k8s.GetNodePublicIP(ctx, st.NodeID)
+		nodePublicIP, ipErr := cluster.Client.GetNodePublicIP(ctx, st.NodeID)
 		if ipErr != nil {
 			slog.Warn("resolve node public ip failed", slog.String("stack_id", st.StackID), slog.String("node_id", st.NodeID), slog.Any("error", ipErr))
 		}
 
 		st.NodePublicIP = nodePublicIP
+		st.Connection = renderConnections(nodePublicIP, st.Ports)
 
 		if err := s.repo.Create(ctx, st); err != nil {
-			if k8sErr := s.k8s.DeletePodAndService(context.Background(), st.Namespace, st.PodID, st.ServiceName); k8sErr != nil {
+			if k8sErr := cluster.Client.DeletePodAndService(context.Background(), st.Namespace, st.PodID, st.ServiceName); k8sErr != nil {
 				slog.Error("rollback delete pod/service failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", k8sErr))
 			}
 
@@ -117,6 +192,8 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
 		}
 
 		releasePorts = false
+		s.noteActiveStack(st)
+		s.saveStickyNodePorts(ctx, st)
 		return st, nil
 	}
 
@@ -127,6 +204,81 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (Stack, error) {
 	return Stack{}, mapProvisionError(lastErr)
 }
 
+// routeCreate picks the cluster of a validated create and the port pool it takes ports
+// from, which a cluster with its own pool decides when the request names none. It only
+// reads, so dry runs route the same way.
+func (s *Service) routeCreate(ctx context.Context, in CreateInput, valid ValidationResult, pool config.NodePortPool) (Cluster, config.NodePortPool, error) {
+	cluster, err := s.routeCluster(ctx, valid.Cluster, in.Region, in.PortPool)
+	if err != nil {
+		return Cluster{}, config.NodePortPool{}, err
+	}
+
+	if in.PortPool == "" && cluster.PortPool != "" {
+		if pool, err = s.portPool(cluster.PortPool); err != nil {
+			return Cluster{}, config.NodePortPool{}, err
+		}
+	}
+
+	var errs ValidationErrors
+	validateRequestedNodePorts(&errs, pool, valid.TargetPorts)
+	if err := errs.err(); err != nil {
+		return Cluster{}, config.NodePortPool{}, err
+	}
+
+	return cluster, pool, nil
+}
+
+// prepareCreate validates a create request without touching the cluster or the port
+// ledger, normalizing the owner and challenge ids in place. Violations of the request
+// fields are reported together with those of the pod spec.
+func (s *Service) prepareCreate(in *CreateInput) (ValidationResult, config.NodePortPool, error) {
+	valid, err := s.validator.ValidatePodSpec(in.PodSpecYML, in.TargetPorts)
+	var errs ValidationErrors
+	if err != nil && !errors.As(err, &errs) {
+		return ValidationResult{}, config.NodePortPool{}, err
+	}
+
+	in.OwnerID = strings.TrimSpace(in.OwnerID)
+	in.ChallengeID = strings.TrimSpace(in.ChallengeID)
+	in.PortPool = strings.TrimSpace(in.PortPool)
+	in.Region = strings.TrimSpace(in.Region)
+	sticky := hasStickyTarget(valid.TargetPorts)
+	switch {
+	case in.OwnerID != "":
+	case s.cfg.NamespaceMode == config.NamespaceModeOwner:
+		errs.addInput("owner_id", CodeRequired, "owner_id is required with per-owner namespaces")
+	case sticky:
+		errs.addInput("owner_id", CodeRequired, "sticky target_port requires owner_id and challenge_id")
+	}
+
+	if sticky && in.ChallengeID == "" {
+		errs.addInput("challenge_id", CodeRequired, "sticky target_port requires owner_id and challenge_id")
+	}
+
+	priority, fieldErr := s.resolvePriority(in.Priority)
+	errs.append(fieldErr)
+	in.Priority = priority
+
+	nodePool, fieldErr := s.resolveNodePool(valid.NodePool, strings.TrimSpace(in.NodePool))
+	errs.append(fieldErr)
+	in.NodePool = nodePool
+
+	pool, err := s.portPool(in.PortPool)
+	if err != nil {
+		errs.addInputErr("port_pool", CodeUnknown, err)
+	} else if in.PortPool != "" || !s.hasClusterPortPools() {
+		// With per-cluster port pools the pool is only known once a cluster is picked,
+		// and Create checks requested nodeports then.
+		validateRequestedNodePorts(&errs, pool, valid.TargetPorts)
+	}
+
+	if err := errs.err(); err != nil {
+		return ValidationResult{}, config.NodePortPool{}, err
+	}
+
+	return valid, pool, nil
+}
+
 func (s *Service) GetDetails(ctx context.Context, stackID string) (Stack, error) {
 	if err := s.RefreshStatus(ctx, stackID); err != nil {
 		return Stack{}, err
@@ -168,13 +320,16 @@ func (s *Service) GetStatusSummary(ctx context.Context, stackID string) (StackSt
 		return StackStatusSummary{}, ErrNotFound
 	}
 
+	nodePublicIP := s.nodePublicIP(ctx, st.ClusterID, st.NodeID)
+
 	return StackStatusSummary{
 		StackID:      st.StackID,
 		Status:       st.Status,
 		TTL:          st.TTLExpiresAt,
 		TargetPorts:  st.TargetPorts,
 		Ports:        st.Ports,
-		NodePublicIP: s.nodePublicIP(ctx, st.NodeID),
+		NodePublicIP: nodePublicIP,
+		Connection:   renderConnections(nodePublicIP, st.Ports),
 	}, nil
 }
 
@@ -188,13 +343,25 @@ func (s *Service) RefreshStatus(ctx context.Context, stackID string) error {
 		return ErrNotFound
 	}
 
-	nodeExists, err := s.k8s.NodeExists(ctx, st.NodeID)
+	// Preempted stacks have no pod left; their record is kept as is until the TTL.
+	if st.Status == StatusPreempted {
+		return nil
+	}
+
+	k8s, err := s.clientFor(st.ClusterID)
+	if err != nil {
+		// The cluster was removed from STACK_CLUSTERS; keep the record until its TTL.
+		slog.Warn("refresh stack status skipped", slog.String("stack_id", st.StackID), slog.Any("error", err))
+		return nil
+	}
+
+	nodeExists, err := k8s.NodeExists(ctx, st.NodeID)
 	if err != nil {
 		return err
 	}
 
 	if !nodeExists {
-		if err := s.k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
+		if err := k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
 			slog.Error("delete pod/service on missing node failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
 		}
 
@@ -202,16 +369,18 @@ func (s *Service) RefreshStatus(ctx context.Context, stackID string) error {
 			slog.Error("delete stack from repository on missing node failed", slog.String("stack_id", st.StackID), slog.Any("error", err))
 		}
 
+		s.forgetActiveStack(st.StackID)
 		return ErrNotFound
 	}
 
-	status, nodeID, err := s.k8s.GetPodStatus(ctx, st.Namespace, st.PodID)
+	status, nodeID, err := k8s.GetPodStatus(ctx, st.Namespace, st.PodID)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			if _, _, deleteErr := s.repo.Delete(ctx, st.StackID); deleteErr != nil {
 				slog.Error("delete stack after missing pod failed", slog.String("stack_id", st.StackID), slog.Any("error", deleteErr))
 			}
 
+			s.forgetActiveStack(st.StackID)
 			return ErrNotFound
 		}
 
@@ -219,7 +388,7 @@ func (s *Service) RefreshStatus(ctx context.Context, stackID string) error {
 	}
 
 	if status == StatusNodeDeleted {
-		if err := s.k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
+		if err := k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
 			slog.Error("delete pod/service on node_deleted failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
 		}
 
@@ -227,9 +396,18 @@ func (s *Service) RefreshStatus(ctx context.Context, stackID string) error {
 			slog.Error("delete stack from repository on node_deleted failed", slog.String("stack_id", st.StackID), slog.Any("error", err))
 		}
 
+		s.forgetActiveStack(st.StackID)
 		return ErrNotFound
 	}
 
+	if status == StatusPreempted {
+		if err := s.preemptStack(ctx, st, "scheduler"); err != nil {
+			slog.Error("record scheduler preemption failed", slog.String("stack_id", st.StackID), slog.Any("error", err))
+		}
+
+		return nil
+	}
+
 	if err := s.repo.UpdateStatus(ctx, st.StackID, status, nodeID); err != nil {
 		slog.Error("update stack status failed", slog.String("stack_id", st.StackID), slog.String("status", string(status)), slog.String("node_id", nodeID), slog.Any("error", err))
 	}
@@ -247,13 +425,17 @@ func (s *Service) Delete(ctx context.Context, stackID string) error {
 		return ErrNotFound
 	}
 
-	if err := s.k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
+	if err := s.deleteStackResources(ctx, st); err != nil {
 		slog.Error("delete pod/service failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
 	}
 
-	_, _, err = s.repo.Delete(ctx, stackID)
+	if _, _, err = s.repo.Delete(ctx, stackID); err != nil {
+		return err
+	}
+
+	s.kickQueue()
 
-	return err
+	return nil
 }
 
 func (s *Service) ListAll(ctx context.Context) ([]Stack, error) {
@@ -294,24 +476,55 @@ func (s *Service) Stats(ctx context.Context) (Stats, error) {
 		return Stats{}, err
 	}
 
-	usedPorts, err := s.repo.UsedNodePortCount(ctx)
+	poolUsage, usedPorts, err := s.nodePortPoolUsage(ctx)
 	if err != nil {
 		return Stats{}, err
 	}
 
 	stats := Stats{
-		NodeDistribution: make(map[string]int),
-		UsedNodePorts:    usedPorts,
+		NodeDistribution:      make(map[string]int),
+		UsedNodePorts:         usedPorts,
+		NodePortPools:         poolUsage,
+		PlacementStrategy:     s.placementStrategy(""),
+		PlacementDistribution: make(map[string]map[string]int),
+		Clusters:              make(map[string]ClusterUsage),
+		NodePools:             s.nodePoolStats(ctx, items),
+	}
+
+	for _, cluster := range s.clusters {
+		if cluster.ID != "" {
+			stats.Clusters[cluster.ID] = ClusterUsage{Region: cluster.Region}
+		}
 	}
 
 	for _, st := range items {
 		stats.TotalStacks++
 		if st.Status == StatusRunning || st.Status == StatusCreating {
 			stats.ActiveStacks++
+
+			placement := st.Placement
+			if placement == "" {
+				placement = config.PlacementNone
+			}
+			if stats.PlacementDistribution[placement] == nil {
+				stats.PlacementDistribution[placement] = make(map[string]int)
+			}
+			stats.PlacementDistribution[placement][st.NodeID]++
 		}
 		stats.NodeDistribution[st.NodeID]++
 		stats.ReservedCPUMilli += st.RequestedMilli
 		stats.ReservedMemoryBytes += st.RequestedBytes
+		stats.ReservedEphemeralBytes += st.RequestedEphemeralBytes
+
+		if usage, ok := stats.Clusters[s.clusterID(st.ClusterID)]; ok {
+			usage.TotalStacks++
+			if st.Status == StatusRunning || st.Status == StatusCreating {
+				usage.ActiveStacks++
+			}
+			usage.ReservedCPUMilli += st.RequestedMilli
+			usage.ReservedMemoryBytes += st.RequestedBytes
+			stats.Clusters[s.clusterID(st.ClusterID)] = usage
+		}
 	}
 
 	return stats, nil
@@ -409,11 +622,18 @@ func (s *Service) attachNodePublicIP(ctx context.Context, st *Stack) {
 		return
 	}
 
-	st.NodePublicIP = s.nodePublicIP(ctx, st.NodeID)
+	st.NodePublicIP = s.nodePublicIP(ctx, st.ClusterID, st.NodeID)
+	st.Connection = renderConnections(st.NodePublicIP, st.Ports)
 }
 
-func (s *Service) nodePublicIP(ctx context.Context, nodeID string) *string {
-	ip, err := s.k8s.GetNodePublicIP(ctx, nodeID)
+func (s *Service) nodePublicIP(ctx context.Context, clusterID, nodeID string) *string {
+	k8s, err := s.clientFor(clusterID)
+	if err != nil {
+		slog.Warn("resolve node public ip failed", slog.String("node_id", nodeID), slog.Any("error", err))
+		return nil
+	}
+
+	ip, err := k8s.GetNodePublicIP(ctx, nodeID)
 	if err != nil {
 		slog.Warn("resolve node public ip failed", slog.String("node_id", nodeID), slog.Any("error", err))
 		return nil
@@ -452,7 +672,7 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 		if st.TTLExpiresAt.Before(now) || st.TTLExpiresAt.Equal(now) {
 			expiredTargets++
 			failed := false
-			if err := s.k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
+			if err := s.deleteStackResources(ctx, st); err != nil {
 				slog.Error("cleanup delete pod/service failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
 				failed = true
 			}
@@ -476,21 +696,27 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 		failures++
 		slog.Error("list stacks for orphan pod cleanup failed", slog.Any("error", err))
 	} else {
-		podIDs, podErr := s.k8s.ListPods(ctx, s.cfg.Namespace)
-		if podErr != nil {
-			resourceScanErrors++
-			failures++
-			slog.Error("list kubernetes pods for stack resource integrity failed", slog.String("namespace", s.cfg.Namespace), slog.Any("error", podErr))
-		}
+		podSets := make(map[string]map[string]struct{}, len(s.clusters))
+		serviceSets := make(map[string]map[string]struct{}, len(s.clusters))
+		for _, cluster := range s.clusters {
+			podIDs, podErr := cluster.Client.ListPods(ctx, s.scanNamespace())
+			if podErr != nil {
+				resourceScanErrors++
+				failures++
+				slog.Error("list kubernetes pods for stack resource integrity failed", slog.String("cluster_id", cluster.ID), slog.String("namespace", s.scanNamespace()), slog.Any("error", podErr))
+			}
 
-		serviceNames, svcErr := s.k8s.ListServices(ctx, s.cfg.Namespace)
-		if svcErr != nil {
-			resourceScanErrors++
-			failures++
-			slog.Error("list kubernetes services for stack resource integrity failed", slog.String("namespace", s.cfg.Namespace), slog.Any("error", svcErr))
-		}
+			serviceNames, svcErr := cluster.Client.ListServices(ctx, s.scanNamespace())
+			if svcErr != nil {
+				resourceScanErrors++
+				failures++
+				slog.Error("list kubernetes services for stack resource integrity failed", slog.String("cluster_id", cluster.ID), slog.String("namespace", s.scanNamespace()), slog.Any("error", svcErr))
+			}
+
+			if podErr != nil || svcErr != nil {
+				continue
+			}
 
-		if podErr == nil && svcErr == nil {
 			podSet := make(map[string]struct{}, len(podIDs))
 			for _, podID := range podIDs {
 				podSet[podID] = struct{}{}
@@ -501,30 +727,44 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 				serviceSet[serviceName] = struct{}{}
 			}
 
-			for _, st := range remainingStacks {
-				_, podExists := podSet[st.PodID]
-				_, serviceExists := serviceSet[st.ServiceName]
-				if podExists && serviceExists {
-					continue
-				}
+			podSets[cluster.ID] = podSet
+			serviceSets[cluster.ID] = serviceSet
+		}
 
-				missingResourceTargets++
-				failed := false
-				if err := s.k8s.DeletePodAndService(ctx, st.Namespace, st.PodID, st.ServiceName); err != nil {
-					slog.Error("cleanup delete stale stack resources failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
-					failed = true
-				}
+		for _, st := range remainingStacks {
+			if st.Status == StatusPreempted {
+				continue
+			}
 
-				if _, _, err := s.repo.Delete(ctx, st.StackID); err != nil {
-					slog.Error("cleanup delete stack with missing pod/service failed", slog.String("stack_id", st.StackID), slog.Bool("pod_exists", podExists), slog.Bool("service_exists", serviceExists), slog.Any("error", err))
-					failed = true
-				}
+			// Stacks of a cluster that could not be listed are left for the next pass.
+			clusterID := s.clusterID(st.ClusterID)
+			podSet, listed := podSets[clusterID]
+			if !listed {
+				continue
+			}
 
-				if failed {
-					failures++
-				} else {
-					cleaned++
-				}
+			_, podExists := podSet[st.PodID]
+			_, serviceExists := serviceSets[clusterID][st.ServiceName]
+			if podExists && serviceExists {
+				continue
+			}
+
+			missingResourceTargets++
+			failed := false
+			if err := s.deleteStackResources(ctx, st); err != nil {
+				slog.Error("cleanup delete stale stack resources failed", slog.String("stack_id", st.StackID), slog.String("pod_id", st.PodID), slog.String("service_name", st.ServiceName), slog.Any("error", err))
+				failed = true
+			}
+
+			if _, _, err := s.repo.Delete(ctx, st.StackID); err != nil {
+				slog.Error("cleanup delete stack with missing pod/service failed", slog.String("stack_id", st.StackID), slog.Bool("pod_exists", podExists), slog.Bool("service_exists", serviceExists), slog.Any("error", err))
+				failed = true
+			}
+
+			if failed {
+				failures++
+			} else {
+				cleaned++
 			}
 		}
 
@@ -534,31 +774,37 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 			failures++
 			slog.Error("list stacks after resource integrity cleanup failed", slog.Any("error", err))
 		} else {
-			registeredPods := make(map[string]struct{}, len(remainingStacks))
+			registeredPods := make(map[string]map[string]struct{}, len(s.clusters))
 			for _, st := range remainingStacks {
 				if st.PodID == "" {
 					continue
 				}
 
-				registeredPods[st.PodID] = struct{}{}
+				clusterID := s.clusterID(st.ClusterID)
+				if registeredPods[clusterID] == nil {
+					registeredPods[clusterID] = make(map[string]struct{})
+				}
+				registeredPods[clusterID][st.PodID] = struct{}{}
 			}
 
-			// Refs: https://github.com/nullforu/container-provisioner-k8s/pull/11
-			podsWithCreation, err := s.k8s.ListPodsWithCreation(ctx, s.cfg.Namespace)
-			if err != nil {
-				orphanScanErrors++
-				failures++
-				slog.Error("list kubernetes pods for orphan cleanup failed", slog.String("namespace", s.cfg.Namespace), slog.Any("error", err))
-			} else {
-				graceCutoff := now.Add(-2 * time.Minute)
+			graceCutoff := now.Add(-2 * time.Minute)
+			for _, cluster := range s.clusters {
+				// Refs: https://github.com/nullforu/container-provisioner-k8s/pull/11
+				podsWithCreation, err := cluster.Client.ListPodsWithCreation(ctx, s.scanNamespace())
+				if err != nil {
+					orphanScanErrors++
+					failures++
+					slog.Error("list kubernetes pods for orphan cleanup failed", slog.String("cluster_id", cluster.ID), slog.String("namespace", s.scanNamespace()), slog.Any("error", err))
+					continue
+				}
 
 				for podID, info := range podsWithCreation {
-					if _, ok := registeredPods[podID]; ok {
+					if _, ok := registeredPods[cluster.ID][podID]; ok {
 						continue
 					}
 
 					if !info.CreatedAt.IsZero() && info.CreatedAt.After(graceCutoff) {
-						slog.Info("skipping orphan pod cleanup for recently created pod", slog.String("pod_id", podID), slog.Time("created_at", info.CreatedAt), slog.Time("grace_cutoff", graceCutoff))
+						slog.Info("skipping orphan pod cleanup for recently created pod", slog.String("cluster_id", cluster.ID), slog.String("pod_id", podID), slog.Time("created_at", info.CreatedAt), slog.Time("grace_cutoff", graceCutoff))
 						continue
 					}
 
@@ -569,15 +815,20 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 							continue
 						}
 
-						if ok && st.PodID == podID {
+						if ok && st.PodID == podID && s.clusterID(st.ClusterID) == cluster.ID {
 							continue
 						}
 					}
 
+					namespace := info.Namespace
+					if namespace == "" {
+						namespace = s.cfg.Namespace
+					}
+
 					orphanPodTargets++
-					if err := s.k8s.DeletePodAndService(ctx, s.cfg.Namespace, podID, ""); err != nil {
+					if err := cluster.Client.DeletePodAndService(ctx, namespace, podID, ""); err != nil {
 						failures++
-						slog.Error("cleanup delete orphan pod failed", slog.String("namespace", s.cfg.Namespace), slog.String("pod_id", podID), slog.Any("error", err))
+						slog.Error("cleanup delete orphan pod failed", slog.String("cluster_id", cluster.ID), slog.String("namespace", namespace), slog.String("pod_id", podID), slog.Any("error", err))
 						continue
 					}
 
@@ -587,12 +838,33 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 		}
 	}
 
+	ledgerFindings := 0
+	ledgerRepaired := 0
+	if report, err := s.ReconcileNodePorts(ctx, true); err != nil {
+		failures++
+		slog.Error("reconcile node port ledger failed", slog.Any("error", err))
+	} else {
+		ledgerFindings = len(report.Findings)
+		ledgerRepaired = report.Repaired
+		failures += report.Failed
+	}
+
+	reclaimedPorts, reclaimFailures := s.reclaimNodePorts(ctx)
+	failures += reclaimFailures
+
+	removedNamespaces, namespaceFailures := s.sweepNamespaces(ctx, now)
+	failures += namespaceFailures
+
 	targets := expiredTargets + missingResourceTargets + orphanPodTargets
 	if targets == 0 {
 		slog.Info("cleanup loop completed",
 			slog.Int("scanned", scanned),
 			slog.Int("targets", 0),
 			slog.Int("cleaned", 0),
+			slog.Int("reclaimed_node_ports", reclaimedPorts),
+			slog.Int("port_ledger_findings", ledgerFindings),
+			slog.Int("port_ledger_repaired", ledgerRepaired),
+			slog.Int("removed_namespaces", removedNamespaces),
 			slog.Int("failures", failures),
 			slog.Int("resource_scan_errors", resourceScanErrors),
 			slog.Int("orphan_scan_errors", orphanScanErrors),
@@ -609,6 +881,10 @@ func (s *Service) CleanupExpiredAndOrphaned(ctx context.Context) {
 		slog.Int("missing_resource_targets", missingResourceTargets),
 		slog.Int("orphan_pod_targets", orphanPodTargets),
 		slog.Int("cleaned", cleaned),
+		slog.Int("reclaimed_node_ports", reclaimedPorts),
+		slog.Int("port_ledger_findings", ledgerFindings),
+		slog.Int("port_ledger_repaired", ledgerRepaired),
+		slog.Int("removed_namespaces", removedNamespaces),
 		slog.Int("failures", failures),
 		slog.Int("resource_scan_errors", resourceScanErrors),
 		slog.Int("orphan_scan_errors", orphanScanErrors),
@@ -654,28 +930,94 @@ func mapProvisionError(err error) error {
 	return fmt.Errorf("k8s provision failed: %w", err)
 }
 
-func (s *Service) reservePorts(ctx context.Context, targets []PortSpec) ([]PortMapping, []int, error) {
-	ports := make([]PortMapping, 0, len(targets))
-	reservedPorts := make([]int, 0, len(targets))
+func (s *Service) reservePorts(ctx context.Context, pool config.NodePortPool, ownerID string, targets []PortSpec, preferred map[string]int) ([]PortMapping, []int, error) {
+	want := make([]int, len(targets))
+	for i, target := range targets {
+		want[i] = preferred[portKey(target.ContainerPort, target.Protocol)]
+	}
 
-	for _, target := range targets {
-		nodePort, err := s.repo.ReserveNodePort(ctx, s.cfg.NodePortMin, s.cfg.NodePortMax)
-		if err != nil {
-			s.releasePorts(reservedPorts)
-			return nil, nil, err
+	reservedPorts, err := s.repo.ReserveNodePorts(ctx, ownerID, pool.Min, pool.Max, want)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ports := make([]PortMapping, 0, len(targets))
+	for i, target := range targets {
+		if want[i] != 0 && reservedPorts[i] != want[i] {
+			slog.Info("preferred nodeport unavailable, falling back to random", slog.Int("node_port", want[i]), slog.Int("container_port", target.ContainerPort))
 		}
 
-		reservedPorts = append(reservedPorts, nodePort)
 		ports = append(ports, PortMapping{
 			ContainerPort: target.ContainerPort,
 			Protocol:      target.Protocol,
-			NodePort:      nodePort,
+			NodePort:      reservedPorts[i],
+			Scheme:        target.Scheme,
+			Name:          target.Name,
 		})
 	}
 
 	return ports, reservedPorts, nil
 }
 
+// preferredNodePorts maps each target to the nodeport it should try first: an explicitly
+// requested port, or the port last used by the same owner and challenge for sticky targets.
+func (s *Service) preferredNodePorts(ctx context.Context, pool config.NodePortPool, ownerID, challengeID string, targets []PortSpec) map[string]int {
+	preferred := make(map[string]int)
+	var last map[string]int
+
+	for _, target := range targets {
+		key := portKey(target.ContainerPort, target.Protocol)
+		if target.NodePort != 0 {
+			preferred[key] = target.NodePort
+			continue
+		}
+
+		if !target.Sticky {
+			continue
+		}
+
+		if last == nil {
+			last = make(map[string]int)
+			mappings, err := s.repo.GetStickyNodePorts(ctx, ownerID, challengeID)
+			if err != nil {
+				slog.Warn("load sticky nodeports failed", slog.String("owner_id", ownerID), slog.String("challenge_id", challengeID), slog.Any("error", err))
+			}
+
+			for _, m := range mappings {
+				if m.NodePort >= pool.Min && m.NodePort <= pool.Max {
+					last[portKey(m.ContainerPort, m.Protocol)] = m.NodePort
+				}
+			}
+		}
+
+		if port, ok := last[key]; ok {
+			preferred[key] = port
+		}
+	}
+
+	return preferred
+}
+
+func (s *Service) saveStickyNodePorts(ctx context.Context, st Stack) {
+	if !hasStickyTarget(st.TargetPorts) {
+		return
+	}
+
+	if err := s.repo.SaveStickyNodePorts(ctx, st.OwnerID, st.ChallengeID, st.Ports); err != nil {
+		slog.Warn("save sticky nodeports failed", slog.String("stack_id", st.StackID), slog.String("owner_id", st.OwnerID), slog.String("challenge_id", st.ChallengeID), slog.Any("error", err))
+	}
+}
+
+func hasStickyTarget(targets []PortSpec) bool {
+	for _, target := range targets {
+		if target.Sticky {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *Service) releasePorts(reservedPorts []int) {
 	for _, reserved := range reservedPorts {
 		if err := s.repo.ReleaseNodePort(context.Background(), reserved); err != nil {
@@ -4,12 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"smctf/internal/config"
 )
 
+// newTestService creates a service on the in-memory repository and a mock cluster.
+// mutate, when set, adjusts the baseline config first.
+func newTestService(mutate func(cfg *config.StackConfig)) (*Service, *InMemoryRepository, *MockKubernetesClient) {
+	repo := NewInMemoryRepository(1)
+	k8s := NewMockKubernetesClient(1)
+
+	return NewService(testStackConfig(mutate), repo, k8s), repo, k8s
+}
+
+func testStackConfig(mutate func(cfg *config.StackConfig)) config.StackConfig {
+	cfg := config.StackConfig{
+		Namespace:         "stacks",
+		StackTTL:          time.Hour,
+		SchedulerInterval: time.Second,
+		NodePortMin:       30000,
+		NodePortMax:       30010,
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+
+	return cfg
+}
+
+const testPodSpec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: p
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 1337
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+
+// testCreateInput fills in testPodSpec and a single TCP target port where in leaves
+// them unset.
+func testCreateInput(in CreateInput) CreateInput {
+	if in.PodSpecYML == "" {
+		in.PodSpecYML = testPodSpec
+	}
+
+	if in.TargetPorts == nil {
+		in.TargetPorts = []PortSpec{{ContainerPort: 1337, Protocol: "TCP"}}
+	}
+
+	return in
+}
+
+func createTestStack(svc *Service, in CreateInput) (Stack, error) {
+	return svc.Create(context.Background(), testCreateInput(in))
+}
+
 func TestServiceCreateAndDelete(t *testing.T) {
 	repo := NewInMemoryRepository(1)
 	k8s := NewMockKubernetesClient(1)
@@ -157,6 +217,14 @@ func (r *retryingKubernetesClient) ListServices(_ context.Context, _ string) ([]
 	return nil, nil
 }
 
+func (r *retryingKubernetesClient) ListServicesWithNodePorts(_ context.Context, _ string) ([]ServiceInfo, error) {
+	return nil, nil
+}
+
+func (r *retryingKubernetesClient) DeleteService(_ context.Context, _, _ string) error {
+	return nil
+}
+
 func (r *retryingKubernetesClient) NodeExists(_ context.Context, _ string) (bool, error) {
 	return true, nil
 }
@@ -169,8 +237,44 @@ func (r *retryingKubernetesClient) GetNodePublicIP(_ context.Context, _ string)
 	return nil, nil
 }
 
-func (r *retryingKubernetesClient) CountSchedulableNodes(_ context.Context) (int, error) {
-	return 1, nil
+func (r *retryingKubernetesClient) CountSchedulableNodes(_ context.Context) (map[string]int, error) {
+	return map[string]int{"": 1}, nil
+}
+
+func (r *retryingKubernetesClient) QuotaCapacity(_ context.Context, _ string) (CapacityBudget, error) {
+	return CapacityBudget{}, nil
+}
+
+func (r *retryingKubernetesClient) AllocatableCapacity(_ context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	return CapacityBudget{}, nil, nil
+}
+
+func (r *retryingKubernetesClient) ListNodeCapacity(_ context.Context) ([]NodeCapacity, error) {
+	return nil, nil
+}
+
+func (r *retryingKubernetesClient) RecordEvent(_ context.Context, _, _, _, _ string) error {
+	return nil
+}
+
+func (r *retryingKubernetesClient) ListStackNamespaces(_ context.Context) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (r *retryingKubernetesClient) DeleteNamespace(_ context.Context, _ string) error {
+	return nil
+}
+
+func (r *retryingKubernetesClient) ListBalloons(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *retryingKubernetesClient) CreateBalloon(_ context.Context, _ BalloonRequest) error {
+	return nil
+}
+
+func (r *retryingKubernetesClient) RuntimeClassExists(_ context.Context, _ string) (bool, error) {
+	return true, nil
 }
 
 func TestServiceCreateRetriesOnNodePortAllocated(t *testing.T) {
@@ -211,7 +315,7 @@ spec:
 		t.Fatalf("expected 2 attempts, got %d", k8s.attempts)
 	}
 
-	used, err := repo.UsedNodePortCount(context.Background())
+	used, err := repo.UsedNodePortCount(context.Background(), 30000, 30010)
 	if err != nil {
 		t.Fatalf("used node ports error: %v", err)
 	}
@@ -431,6 +535,14 @@ func (p *podGoneKubernetesClient) ListServices(_ context.Context, _ string) ([]s
 	return nil, nil
 }
 
+func (p *podGoneKubernetesClient) ListServicesWithNodePorts(_ context.Context, _ string) ([]ServiceInfo, error) {
+	return nil, nil
+}
+
+func (p *podGoneKubernetesClient) DeleteService(_ context.Context, _, _ string) error {
+	return nil
+}
+
 func (p *podGoneKubernetesClient) NodeExists(_ context.Context, _ string) (bool, error) {
 	return true, nil
 }
@@ -443,8 +555,44 @@ func (p *podGoneKubernetesClient) GetNodePublicIP(_ context.Context, _ string) (
 	return nil, nil
 }
 
-func (p *podGoneKubernetesClient) CountSchedulableNodes(_ context.Context) (int, error) {
-	return 0, nil
+func (p *podGoneKubernetesClient) CountSchedulableNodes(_ context.Context) (map[string]int, error) {
+	return nil, nil
+}
+
+func (p *podGoneKubernetesClient) QuotaCapacity(_ context.Context, _ string) (CapacityBudget, error) {
+	return CapacityBudget{}, nil
+}
+
+func (p *podGoneKubernetesClient) AllocatableCapacity(_ context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	return CapacityBudget{}, nil, nil
+}
+
+func (p *podGoneKubernetesClient) ListNodeCapacity(_ context.Context) ([]NodeCapacity, error) {
+	return nil, nil
+}
+
+func (p *podGoneKubernetesClient) RecordEvent(_ context.Context, _, _, _, _ string) error {
+	return nil
+}
+
+func (p *podGoneKubernetesClient) ListStackNamespaces(_ context.Context) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (p *podGoneKubernetesClient) DeleteNamespace(_ context.Context, _ string) error {
+	return nil
+}
+
+func (p *podGoneKubernetesClient) ListBalloons(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (p *podGoneKubernetesClient) CreateBalloon(_ context.Context, _ BalloonRequest) error {
+	return nil
+}
+
+func (p *podGoneKubernetesClient) RuntimeClassExists(_ context.Context, _ string) (bool, error) {
+	return true, nil
 }
 
 func TestCleanupOrphanPodSkipsRepoBackedPods(t *testing.T) {
@@ -518,6 +666,14 @@ func (b *batchDeleteKubernetesClient) ListServices(_ context.Context, _ string)
 	return nil, nil
 }
 
+func (b *batchDeleteKubernetesClient) ListServicesWithNodePorts(_ context.Context, _ string) ([]ServiceInfo, error) {
+	return nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) DeleteService(_ context.Context, _, _ string) error {
+	return nil
+}
+
 func (b *batchDeleteKubernetesClient) NodeExists(_ context.Context, _ string) (bool, error) {
 	return true, nil
 }
@@ -530,8 +686,44 @@ func (b *batchDeleteKubernetesClient) GetNodePublicIP(_ context.Context, _ strin
 	return nil, nil
 }
 
-func (b *batchDeleteKubernetesClient) CountSchedulableNodes(_ context.Context) (int, error) {
-	return 0, nil
+func (b *batchDeleteKubernetesClient) CountSchedulableNodes(_ context.Context) (map[string]int, error) {
+	return nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) QuotaCapacity(_ context.Context, _ string) (CapacityBudget, error) {
+	return CapacityBudget{}, nil
+}
+
+func (b *batchDeleteKubernetesClient) AllocatableCapacity(_ context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	return CapacityBudget{}, nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) ListNodeCapacity(_ context.Context) ([]NodeCapacity, error) {
+	return nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) RecordEvent(_ context.Context, _, _, _, _ string) error {
+	return nil
+}
+
+func (b *batchDeleteKubernetesClient) ListStackNamespaces(_ context.Context) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) DeleteNamespace(_ context.Context, _ string) error {
+	return nil
+}
+
+func (b *batchDeleteKubernetesClient) ListBalloons(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (b *batchDeleteKubernetesClient) CreateBalloon(_ context.Context, _ BalloonRequest) error {
+	return nil
+}
+
+func (b *batchDeleteKubernetesClient) RuntimeClassExists(_ context.Context, _ string) (bool, error) {
+	return true, nil
 }
 
 func TestBatchDeleteHappyPath(t *testing.T) {
@@ -759,6 +951,14 @@ func (f *failingKubernetesClient) ListServices(_ context.Context, _ string) ([]s
 	return nil, nil
 }
 
+func (f *failingKubernetesClient) ListServicesWithNodePorts(_ context.Context, _ string) ([]ServiceInfo, error) {
+	return nil, nil
+}
+
+func (f *failingKubernetesClient) DeleteService(_ context.Context, _, _ string) error {
+	return nil
+}
+
 func (f *failingKubernetesClient) NodeExists(_ context.Context, _ string) (bool, error) {
 	return true, nil
 }
@@ -771,6 +971,238 @@ func (f *failingKubernetesClient) GetNodePublicIP(_ context.Context, _ string) (
 	return nil, nil
 }
 
-func (f *failingKubernetesClient) CountSchedulableNodes(_ context.Context) (int, error) {
-	return 0, nil
+func (f *failingKubernetesClient) CountSchedulableNodes(_ context.Context) (map[string]int, error) {
+	return nil, nil
+}
+
+func (f *failingKubernetesClient) QuotaCapacity(_ context.Context, _ string) (CapacityBudget, error) {
+	return CapacityBudget{}, nil
+}
+
+func (f *failingKubernetesClient) AllocatableCapacity(_ context.Context) (CapacityBudget, map[string]CapacityBudget, error) {
+	return CapacityBudget{}, nil, nil
+}
+
+func (f *failingKubernetesClient) ListNodeCapacity(_ context.Context) ([]NodeCapacity, error) {
+	return nil, nil
+}
+
+func (f *failingKubernetesClient) RecordEvent(_ context.Context, _, _, _, _ string) error {
+	return nil
+}
+
+func (f *failingKubernetesClient) ListStackNamespaces(_ context.Context) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (f *failingKubernetesClient) DeleteNamespace(_ context.Context, _ string) error {
+	return nil
+}
+
+func (f *failingKubernetesClient) ListBalloons(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *failingKubernetesClient) CreateBalloon(_ context.Context, _ BalloonRequest) error {
+	return nil
+}
+
+func (f *failingKubernetesClient) RuntimeClassExists(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+func TestServiceCreateReusesStickyNodePort(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	k8s := NewMockKubernetesClient(1)
+	svc := NewService(config.StackConfig{
+		Namespace:   "stacks",
+		StackTTL:    time.Hour,
+		NodePortMin: 30000,
+		NodePortMax: 30100,
+	}, repo, k8s)
+
+	in := testCreateInput(CreateInput{
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", Sticky: true}},
+		OwnerID:     "team-1",
+		ChallengeID: "pwn-1",
+	})
+
+	first, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if err := svc.Delete(context.Background(), first.StackID); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+
+	second, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("recreate error: %v", err)
+	}
+
+	if second.Ports[0].NodePort != first.Ports[0].NodePort {
+		t.Fatalf("expected sticky port %d, got %d", first.Ports[0].NodePort, second.Ports[0].NodePort)
+	}
+
+	if second.OwnerID != "team-1" || second.ChallengeID != "pwn-1" {
+		t.Fatalf("unexpected owner/challenge: %q/%q", second.OwnerID, second.ChallengeID)
+	}
+}
+
+func TestServiceCreateRequestedNodePortFallsBack(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	k8s := NewMockKubernetesClient(1)
+	svc := NewService(config.StackConfig{
+		Namespace:   "stacks",
+		StackTTL:    time.Hour,
+		NodePortMin: 30000,
+		NodePortMax: 30010,
+	}, repo, k8s)
+
+	in := testCreateInput(CreateInput{
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", NodePort: 30005}},
+	})
+
+	first, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if first.Ports[0].NodePort != 30005 {
+		t.Fatalf("expected requested port 30005, got %d", first.Ports[0].NodePort)
+	}
+
+	second, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("second create error: %v", err)
+	}
+
+	if second.Ports[0].NodePort == 30005 {
+		t.Fatalf("expected fallback to a random port")
+	}
+}
+
+func TestServiceCreateStickyRequiresOwnerAndChallenge(t *testing.T) {
+	svc := NewService(config.StackConfig{
+		Namespace:   "stacks",
+		StackTTL:    time.Hour,
+		NodePortMin: 30000,
+		NodePortMax: 30010,
+	}, NewInMemoryRepository(1), NewMockKubernetesClient(1))
+
+	_, err := createTestStack(svc, CreateInput{
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", Sticky: true}},
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+}
+
+func TestStickyPKEscapesSeparator(t *testing.T) {
+	if stickyPK("a#b", "c") == stickyPK("a", "b#c") {
+		t.Fatalf("expected owner and challenge IDs containing # not to collide")
+	}
+
+	if got := stickyPK("team-1", "web_1"); got != "STICKY#team-1#web_1" {
+		t.Fatalf("expected plain IDs to keep their key, got %s", got)
+	}
+}
+
+func TestServiceCreateUsesSelectedPortPool(t *testing.T) {
+	repo := NewInMemoryRepository(1)
+	k8s := NewMockKubernetesClient(1)
+	svc := NewService(config.StackConfig{
+		Namespace: "stacks",
+		StackTTL:  time.Hour,
+		NodePortPools: []config.NodePortPool{
+			{Name: "web", Min: 30000, Max: 30009},
+			{Name: "pwn", Min: 30010, Max: 30019},
+		},
+	}, repo, k8s)
+
+	st, err := createTestStack(svc, CreateInput{PortPool: "pwn"})
+	if err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+
+	if st.PortPool != "pwn" || st.Ports[0].NodePort < 30010 || st.Ports[0].NodePort > 30019 {
+		t.Fatalf("expected pwn pool port, got pool=%q port=%d", st.PortPool, st.Ports[0].NodePort)
+	}
+
+	stats, err := svc.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("stats error: %v", err)
+	}
+
+	if stats.UsedNodePorts != 1 || stats.NodePortPools["pwn"].Used != 1 || stats.NodePortPools["web"].Used != 0 {
+		t.Fatalf("unexpected pool usage: %+v", stats.NodePortPools)
+	}
+
+	if stats.NodePortPools["web"].Capacity != 10 {
+		t.Fatalf("expected web capacity 10, got %d", stats.NodePortPools["web"].Capacity)
+	}
+
+	_, err = createTestStack(svc, CreateInput{
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", NodePort: 30015}},
+		PortPool:    "web",
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected node_port outside pool to be rejected, got %v", err)
+	}
+
+	_, err = createTestStack(svc, CreateInput{PortPool: "misc"})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected unknown pool to be rejected, got %v", err)
+	}
+}
+
+func TestServiceCreateReportsRequestViolationsWithPodSpec(t *testing.T) {
+	svc, _, _ := newTestService(func(cfg *config.StackConfig) {
+		cfg.NamespaceMode = config.NamespaceModeOwner
+	})
+
+	_, err := createTestStack(svc, CreateInput{
+		PodSpecYML:  strings.Replace(testPodSpec, "nginx:latest", "", 1),
+		TargetPorts: []PortSpec{{ContainerPort: 1337, Protocol: "TCP", NodePort: 40000, Sticky: true}},
+		PortPool:    "default",
+		Priority:    "high",
+		NodePool:    "arm64",
+	})
+	if !errors.Is(err, ErrPodSpecInvalid) || !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected pod spec and input violations, got %v", err)
+	}
+
+	want := []Violation{
+		{Field: "spec.containers[0].image", Code: CodeRequired},
+		{Field: "owner_id", Code: CodeRequired},
+		{Field: "challenge_id", Code: CodeRequired},
+		{Field: "priority", Code: CodeNotAllowed},
+		{Field: "node_pool", Code: CodeNotAllowed},
+		{Field: "target_port[0].node_port", Code: CodeInvalid},
+	}
+	got := Violations(err)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), got)
+	}
+
+	for i := range want {
+		if got[i].Field != want[i].Field || got[i].Code != want[i].Code || got[i].Message == "" {
+			t.Fatalf("violation %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if _, err := createTestStack(svc, CreateInput{OwnerID: "team-1", PortPool: "misc"}); !hasViolation(err, "port_pool", CodeUnknown) {
+		t.Fatalf("expected unknown port_pool violation, got %v", err)
+	}
+}
+
+func hasViolation(err error, field, code string) bool {
+	for _, violation := range Violations(err) {
+		if violation.Field == field && violation.Code == code {
+			return true
+		}
+	}
+
+	return false
 }
@@ -13,90 +13,102 @@ import (
 
 type Validator struct {
 	cfg config.StackConfig
+	// imageAllowlist is STACK_IMAGE_ALLOWLIST with each entry normalized like an image.
+	imageAllowlist []string
 }
 
 func NewValidator(cfg config.StackConfig) *Validator {
-	return &Validator{cfg: cfg}
+	imageAllowlist := make([]string, 0, len(cfg.ImageAllowlist))
+	for _, entry := range cfg.ImageAllowlist {
+		imageAllowlist = append(imageAllowlist, normalizeImageAllowlistEntry(entry))
+	}
+
+	return &Validator{cfg: cfg, imageAllowlist: imageAllowlist}
 }
 
 type ValidationResult struct {
 	SanitizedYAML  string
 	RequestedMilli int64
 	RequestedBytes int64
-	TargetPorts    []PortSpec
+	// RequestedEphemeralBytes is the node disk the pod may use, see ephemeralStorageBytes.
+	RequestedEphemeralBytes int64
+	TargetPorts             []PortSpec
+	Placement               string
+	Cluster                 string
+	NodePool                string
+	// ConfigMaps are the supporting ConfigMaps of a multi-document pod spec.
+	ConfigMaps []corev1.ConfigMap
 }
 
 const maxTargetPorts = 24
 
+// ValidatePodSpec checks a pod spec and its target ports and returns the pod the server
+// creates. It reports every violation it finds as ValidationErrors; only a pod spec that
+// cannot be parsed stops it early. Without target ports it reads them from
+// targetPortsAnnotation. Along with violations it still returns the target ports and
+// annotations it read, so callers can check the rest of the request against them.
 func (v *Validator) ValidatePodSpec(raw string, targetPorts []PortSpec) (ValidationResult, error) {
+	var errs ValidationErrors
 	if strings.TrimSpace(raw) == "" {
-		return ValidationResult{}, fmt.Errorf("%w: pod_spec is required", ErrPodSpecInvalid)
-	}
-
-	if len(targetPorts) == 0 {
-		return ValidationResult{}, fmt.Errorf("%w: target_port is required", ErrInvalidInput)
-	}
-
-	if len(targetPorts) > maxTargetPorts {
-		return ValidationResult{}, fmt.Errorf("%w: target_port exceeds limit (max %d)", ErrInvalidInput, maxTargetPorts)
-	}
-
-	normalizedTargets := make([]PortSpec, 0, len(targetPorts))
-	targetSet := make(map[string]struct{}, len(targetPorts))
-	for _, tp := range targetPorts {
-		if tp.ContainerPort < 1 || tp.ContainerPort > 65535 {
-			return ValidationResult{}, fmt.Errorf("%w: target_port is out of range", ErrInvalidInput)
-		}
-
-		proto, err := normalizeProtocol(tp.Protocol)
-		if err != nil {
-			return ValidationResult{}, err
-		}
-
-		key := portKey(tp.ContainerPort, proto)
-		if _, exists := targetSet[key]; exists {
-			return ValidationResult{}, fmt.Errorf("%w: duplicate target_port entry", ErrInvalidInput)
+		normalizedTargets := validateTargetPorts(&errs, targetPorts)
+		errs.add("pod_spec", CodeRequired, "pod_spec is required")
+		return ValidationResult{TargetPorts: normalizedTargets}, errs.err()
+	}
+
+	pod, configMaps, parseErr := parseManifest(raw)
+	if parseErr == nil && len(targetPorts) == 0 {
+		targetPorts = annotatedTargetPorts(&errs, pod)
+	}
+
+	normalizedTargets := validateTargetPorts(&errs, targetPorts)
+	if parseErr != nil {
+		errs.append(parseErr)
+		return ValidationResult{TargetPorts: normalizedTargets}, errs.err()
+	}
+
+	configMapNames := v.validateConfigMaps(&errs, configMaps)
+
+	forbidden := []struct {
+		field string
+		set   bool
+	}{
+		{"spec.hostNetwork", pod.Spec.HostNetwork},
+		{"spec.hostPID", pod.Spec.HostPID},
+		{"spec.hostIPC", pod.Spec.HostIPC},
+		{"spec.securityContext", pod.Spec.SecurityContext != nil},
+		{"spec.serviceAccountName", pod.Spec.ServiceAccountName != ""},
+		{"spec.serviceAccount", pod.Spec.DeprecatedServiceAccount != ""},
+		{"spec.nodeName", pod.Spec.NodeName != ""},
+		{"spec.runtimeClassName", pod.Spec.RuntimeClassName != nil},
+		{"spec.priorityClassName", pod.Spec.PriorityClassName != ""},
+		{"spec.priority", pod.Spec.Priority != nil},
+		{"spec.preemptionPolicy", pod.Spec.PreemptionPolicy != nil},
+		{"spec.ephemeralContainers", len(pod.Spec.EphemeralContainers) > 0},
+	}
+	for _, f := range forbidden {
+		if f.set {
+			errs.add(f.field, CodeForbidden, fmt.Sprintf("%s is forbidden in input", f.field[len("spec."):]))
 		}
-
-		targetSet[key] = struct{}{}
-		normalizedTargets = append(normalizedTargets, PortSpec{ContainerPort: tp.ContainerPort, Protocol: proto})
-	}
-
-	var pod corev1.Pod
-	if err := sigsyaml.Unmarshal([]byte(raw), &pod); err != nil {
-		return ValidationResult{}, fmt.Errorf("%w: yaml parse failed", ErrPodSpecInvalid)
-	}
-
-	if !strings.EqualFold(pod.Kind, "Pod") {
-		return ValidationResult{}, fmt.Errorf("%w: kind must be Pod", ErrPodSpecInvalid)
-	}
-
-	if pod.Spec.HostNetwork || pod.Spec.HostPID || pod.Spec.HostIPC {
-		return ValidationResult{}, fmt.Errorf("%w: hostNetwork/hostPID/hostIPC are forbidden", ErrPodSpecInvalid)
 	}
 
-	if pod.Spec.SecurityContext != nil {
-		return ValidationResult{}, fmt.Errorf("%w: pod securityContext is forbidden in input", ErrPodSpecInvalid)
-	}
+	v.validateVolumes(&errs, pod.Spec.Volumes)
+	v.validateSecretRefs(&errs, &pod)
+	validateConfigMapRefs(&errs, &pod, configMapNames)
 
-	if pod.Spec.ServiceAccountName != "" || pod.Spec.DeprecatedServiceAccount != "" {
-		return ValidationResult{}, fmt.Errorf("%w: serviceAccount is forbidden in input", ErrPodSpecInvalid)
+	placement := strings.ToLower(strings.TrimSpace(pod.Annotations[placementAnnotation]))
+	if placement != "" && !config.IsPlacementStrategy(placement) {
+		errs.add("metadata.annotations["+placementAnnotation+"]", CodeUnknown, fmt.Sprintf("unknown placement %q", placement))
 	}
 
-	if pod.Spec.NodeName != "" || pod.Spec.RuntimeClassName != nil {
-		return ValidationResult{}, fmt.Errorf("%w: nodeName/runtimeClassName are forbidden in input", ErrPodSpecInvalid)
-	}
+	cluster := strings.ToLower(strings.TrimSpace(pod.Annotations[clusterAnnotation]))
+	nodePool := strings.ToLower(strings.TrimSpace(pod.Annotations[nodePoolAnnotation]))
 
-	if len(pod.Spec.EphemeralContainers) > 0 {
-		return ValidationResult{}, fmt.Errorf("%w: ephemeralContainers are forbidden", ErrPodSpecInvalid)
-	}
-
-	if err := validateVolumesNoHostAccess(pod.Spec.Volumes); err != nil {
-		return ValidationResult{}, err
+	if len(pod.Spec.Containers) == 0 {
+		errs.add("spec.containers", CodeRequired, "at least one container is required")
 	}
 
-	if len(pod.Spec.Containers) == 0 {
-		return ValidationResult{}, fmt.Errorf("%w: at least one container is required", ErrPodSpecInvalid)
+	if count := len(pod.Spec.Containers) + len(pod.Spec.InitContainers); v.cfg.MaxContainers > 0 && count > v.cfg.MaxContainers {
+		errs.add("spec.containers", CodeExceedsLimit, fmt.Sprintf("%d containers and init containers exceed the maximum of %d", count, v.cfg.MaxContainers))
 	}
 
 	var sumMilli int64
@@ -108,19 +120,16 @@ func (v *Validator) ValidatePodSpec(raw string, targetPorts []PortSpec) (Validat
 
 	for i := range pod.Spec.InitContainers {
 		c := &pod.Spec.InitContainers[i]
-		if err := validateContainerBasics(c); err != nil {
-			return ValidationResult{}, err
-		}
+		field := fmt.Sprintf("spec.initContainers[%d]", i)
+		validateContainerBasics(&errs, field, c)
+		errs.append(v.validateImage(field+".image", c.Image))
 
-		if len(c.Ports) > 0 {
-			return ValidationResult{}, fmt.Errorf("%w: initContainer ports are forbidden", ErrPodSpecInvalid)
+		for j := range c.Ports {
+			errs.add(fmt.Sprintf("%s.ports[%d]", field, j), CodeForbidden, "initContainer ports are forbidden")
 		}
 
-		cpuMilli, memBytes, err := normalizeAndValidateResources(c.Resources)
-		if err != nil {
-			return ValidationResult{}, err
-		}
-		c.Resources = buildEqualResources(cpuMilli, memBytes)
+		cpuMilli, memBytes := v.normalizeAndValidateResources(&errs, field+".resources", c.Resources)
+		c.Resources = withEphemeralStorage(buildEqualResources(cpuMilli, memBytes), containerEphemeralStorage(c.Resources))
 
 		if cpuMilli > initMaxMilli {
 			initMaxMilli = cpuMilli
@@ -133,30 +142,33 @@ func (v *Validator) ValidatePodSpec(raw string, targetPorts []PortSpec) (Validat
 
 	for i := range pod.Spec.Containers {
 		c := &pod.Spec.Containers[i]
-		if err := validateContainerBasics(c); err != nil {
-			return ValidationResult{}, err
-		}
-
-		cpuMilli, memBytes, err := normalizeAndValidateResources(c.Resources)
-		if err != nil {
-			return ValidationResult{}, err
-		}
+		field := fmt.Sprintf("spec.containers[%d]", i)
+		validateContainerBasics(&errs, field, c)
+		errs.append(v.validateImage(field+".image", c.Image))
 
-		c.Resources = buildEqualResources(cpuMilli, memBytes)
+		cpuMilli, memBytes := v.normalizeAndValidateResources(&errs, field+".resources", c.Resources)
+		c.Resources = withEphemeralStorage(buildEqualResources(cpuMilli, memBytes), containerEphemeralStorage(c.Resources))
 		sumMilli += cpuMilli
 		sumBytes += memBytes
 
-		for _, p := range c.Ports {
+		for j, p := range c.Ports {
+			portField := fmt.Sprintf("%s.ports[%d]", field, j)
 			if p.ContainerPort < 1 || p.ContainerPort > 65535 {
-				return ValidationResult{}, fmt.Errorf("%w: invalid container port", ErrPodSpecInvalid)
+				errs.add(portField+".containerPort", CodeInvalid, "container port must be between 1 and 65535")
+				continue
 			}
 
-			if p.HostPort != 0 || p.HostIP != "" {
-				return ValidationResult{}, fmt.Errorf("%w: hostPort/hostIP are forbidden", ErrPodSpecInvalid)
+			if p.HostPort != 0 {
+				errs.add(portField+".hostPort", CodeForbidden, "hostPort is forbidden")
+			}
+
+			if p.HostIP != "" {
+				errs.add(portField+".hostIP", CodeForbidden, "hostIP is forbidden")
 			}
 
 			if p.Protocol != "" && p.Protocol != corev1.ProtocolTCP && p.Protocol != corev1.ProtocolUDP {
-				return ValidationResult{}, fmt.Errorf("%w: protocol must be TCP or UDP", ErrPodSpecInvalid)
+				errs.add(portField+".protocol", CodeInvalid, "protocol must be TCP or UDP")
+				continue
 			}
 
 			portCount++
@@ -170,38 +182,31 @@ func (v *Validator) ValidatePodSpec(raw string, targetPorts []PortSpec) (Validat
 		}
 	}
 
-	if portCount == 0 {
-		return ValidationResult{}, fmt.Errorf("%w: at least one exposed container port is required", ErrPodSpecInvalid)
+	if len(pod.Spec.Containers) > 0 && portCount == 0 {
+		errs.add("spec.containers", CodeRequired, "at least one exposed container port is required")
 	}
 
-	for _, tp := range normalizedTargets {
-		key := portKey(tp.ContainerPort, tp.Protocol)
-		if _, ok := podPortSet[key]; !ok {
-			return ValidationResult{}, fmt.Errorf("%w: target_port must exist in container ports", ErrPodSpecInvalid)
+	if portCount > 0 {
+		for i, tp := range normalizedTargets {
+			key := portKey(tp.ContainerPort, tp.Protocol)
+			if _, ok := podPortSet[key]; !ok {
+				errs.addInput(fmt.Sprintf("target_port[%d]", i), CodeUnknown, fmt.Sprintf("%s is not a container port", key))
+			}
 		}
 	}
 
 	reqMilli := max64(sumMilli, initMaxMilli)
 	reqBytes := max64(sumBytes, initMaxBytes)
-
-	if reqMilli <= 0 || reqBytes <= 0 {
-		return ValidationResult{}, fmt.Errorf("%w: resources are required", ErrPodSpecInvalid)
-	}
+	v.validateStackResources(&errs, reqMilli, reqBytes)
 
 	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
 	pod.Spec.AutomountServiceAccountToken = boolPtr(false)
 	pod.Spec.EnableServiceLinks = boolPtr(false)
 
-	pod.Spec.SecurityContext = &corev1.PodSecurityContext{
-		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
-	}
-
-	for i := range pod.Spec.InitContainers {
-		pod.Spec.InitContainers[i].SecurityContext = hardenedContainerSecurityContext()
-	}
+	v.applyHardening(&errs, &pod)
 
-	for i := range pod.Spec.Containers {
-		pod.Spec.Containers[i].SecurityContext = hardenedContainerSecurityContext()
+	if len(errs) > 0 {
+		return ValidationResult{TargetPorts: normalizedTargets, Placement: placement, Cluster: cluster, NodePool: nodePool}, errs
 	}
 
 	sanitized, err := sigsyaml.Marshal(&pod)
@@ -210,13 +215,88 @@ func (v *Validator) ValidatePodSpec(raw string, targetPorts []PortSpec) (Validat
 	}
 
 	return ValidationResult{
-		SanitizedYAML:  string(sanitized),
-		RequestedMilli: reqMilli,
-		RequestedBytes: reqBytes,
-		TargetPorts:    normalizedTargets,
+		SanitizedYAML:           string(sanitized),
+		RequestedMilli:          reqMilli,
+		RequestedBytes:          reqBytes,
+		RequestedEphemeralBytes: ephemeralStorageBytes(&pod),
+		TargetPorts:             normalizedTargets,
+		Placement:               placement,
+		Cluster:                 cluster,
+		NodePool:                nodePool,
+		ConfigMaps:              configMaps,
 	}, nil
 }
 
+// validateTargetPorts normalizes the requested target ports; entries with a violation are
+// left out.
+func validateTargetPorts(errs *ValidationErrors, targetPorts []PortSpec) []PortSpec {
+	if len(targetPorts) == 0 {
+		errs.addInput("target_port", CodeRequired, "target_port is required")
+		return nil
+	}
+
+	if len(targetPorts) > maxTargetPorts {
+		errs.addInput("target_port", CodeExceedsLimit, fmt.Sprintf("target_port exceeds limit (max %d)", maxTargetPorts))
+		return nil
+	}
+
+	normalizedTargets := make([]PortSpec, 0, len(targetPorts))
+	targetSet := make(map[string]struct{}, len(targetPorts))
+	requestedNodePorts := make(map[int]struct{})
+	for i, tp := range targetPorts {
+		field := fmt.Sprintf("target_port[%d]", i)
+		if tp.ContainerPort < 1 || tp.ContainerPort > 65535 {
+			errs.addInput(field+".container_port", CodeInvalid, "target_port is out of range")
+			continue
+		}
+
+		proto, err := normalizeProtocol(tp.Protocol)
+		if err != nil {
+			errs.addInputErr(field+".protocol", CodeInvalid, err)
+			continue
+		}
+
+		key := portKey(tp.ContainerPort, proto)
+		if _, exists := targetSet[key]; exists {
+			errs.addInput(field, CodeDuplicate, "duplicate target_port entry")
+			continue
+		}
+
+		scheme, err := normalizeScheme(tp.Scheme, proto)
+		if err != nil {
+			errs.addInputErr(field+".scheme", CodeInvalid, err)
+			continue
+		}
+
+		name, err := normalizePortName(tp.Name)
+		if err != nil {
+			errs.addInputErr(field+".name", CodeInvalid, err)
+			continue
+		}
+
+		if tp.NodePort != 0 {
+			if _, exists := requestedNodePorts[tp.NodePort]; exists {
+				errs.addInput(field+".node_port", CodeDuplicate, "duplicate node_port entry")
+				continue
+			}
+
+			requestedNodePorts[tp.NodePort] = struct{}{}
+		}
+
+		targetSet[key] = struct{}{}
+		normalizedTargets = append(normalizedTargets, PortSpec{
+			ContainerPort: tp.ContainerPort,
+			Protocol:      proto,
+			Scheme:        scheme,
+			Name:          name,
+			NodePort:      tp.NodePort,
+			Sticky:        tp.Sticky,
+		})
+	}
+
+	return normalizedTargets
+}
+
 func normalizeProtocol(proto string) (string, error) {
 	upper := strings.ToUpper(strings.TrimSpace(proto))
 	if upper == "" {
@@ -235,61 +315,72 @@ func portKey(port int, proto string) string {
 	return fmt.Sprintf("%d/%s", port, strings.ToUpper(proto))
 }
 
-func validateContainerBasics(c *corev1.Container) error {
+func validateContainerBasics(errs *ValidationErrors, field string, c *corev1.Container) {
 	if strings.TrimSpace(c.Name) == "" {
-		return fmt.Errorf("%w: container name is required", ErrPodSpecInvalid)
+		errs.add(field+".name", CodeRequired, "container name is required")
 	}
 
 	if strings.TrimSpace(c.Image) == "" {
-		return fmt.Errorf("%w: container image is required", ErrPodSpecInvalid)
+		errs.add(field+".image", CodeRequired, "container image is required")
 	}
 
 	if c.SecurityContext != nil {
-		return fmt.Errorf("%w: container securityContext is forbidden in input", ErrPodSpecInvalid)
+		errs.add(field+".securityContext", CodeForbidden, "container securityContext is forbidden in input")
 	}
-
-	return nil
 }
 
-func validateVolumesNoHostAccess(vols []corev1.Volume) error {
-	for _, v := range vols {
-		if v.HostPath != nil {
-			return fmt.Errorf("%w: hostPath volume is forbidden", ErrPodSpecInvalid)
-		}
+// normalizeAndValidateResources returns the CPU and memory a container gets: the larger
+// of request and limit, or STACK_DEFAULT_CONTAINER_CPU/MEMORY when neither is set. Both
+// must fit the per-container maximums.
+func (v *Validator) normalizeAndValidateResources(errs *ValidationErrors, field string, r corev1.ResourceRequirements) (int64, int64) {
+	cpuReq := getMilli(r.Requests, corev1.ResourceCPU)
+	cpuLim := getMilli(r.Limits, corev1.ResourceCPU)
+	memReq := getBytes(r.Requests, corev1.ResourceMemory)
+	memLim := getBytes(r.Limits, corev1.ResourceMemory)
 
-		if v.Projected != nil {
-			for _, src := range v.Projected.Sources {
-				if src.ServiceAccountToken != nil {
-					return fmt.Errorf("%w: projected serviceAccountToken is forbidden", ErrPodSpecInvalid)
-				}
-			}
-		}
+	cpuMilli := max64(cpuReq, cpuLim)
+	memBytes := max64(memReq, memLim)
+	if cpuMilli <= 0 {
+		cpuMilli = v.cfg.DefaultContainerCPUMilli
 	}
 
-	return nil
-}
+	if memBytes <= 0 {
+		memBytes = v.cfg.DefaultContainerMemoryBytes
+	}
 
-func hardenedContainerSecurityContext() *corev1.SecurityContext {
-	return &corev1.SecurityContext{
-		Privileged:               boolPtr(false),
-		AllowPrivilegeEscalation: boolPtr(false),
-		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	if cpuMilli <= 0 {
+		errs.add(field+".limits.cpu", CodeRequired, "cpu request/limit must be set")
+	} else if v.cfg.MaxContainerCPUMilli > 0 && cpuMilli > v.cfg.MaxContainerCPUMilli {
+		errs.add(field, CodeExceedsLimit, fmt.Sprintf("cpu %s exceeds the per-container maximum of %s", formatMilli(cpuMilli), formatMilli(v.cfg.MaxContainerCPUMilli)))
 	}
+
+	if memBytes <= 0 {
+		errs.add(field+".limits.memory", CodeRequired, "memory request/limit must be set")
+	} else if v.cfg.MaxContainerMemoryBytes > 0 && memBytes > v.cfg.MaxContainerMemoryBytes {
+		errs.add(field, CodeExceedsLimit, fmt.Sprintf("memory %s exceeds the per-container maximum of %s", formatBytes(memBytes), formatBytes(v.cfg.MaxContainerMemoryBytes)))
+	}
+
+	return max64(cpuMilli, 0), max64(memBytes, 0)
 }
 
-func normalizeAndValidateResources(r corev1.ResourceRequirements) (int64, int64, error) {
-	cpuReq := getMilli(r.Requests, corev1.ResourceCPU)
-	cpuLim := getMilli(r.Limits, corev1.ResourceCPU)
-	memReq := getBytes(r.Requests, corev1.ResourceMemory)
-	memLim := getBytes(r.Limits, corev1.ResourceMemory)
+// validateStackResources checks what the whole pod reserves against the per-stack
+// maximums.
+func (v *Validator) validateStackResources(errs *ValidationErrors, cpuMilli, memBytes int64) {
+	if v.cfg.MaxStackCPUMilli > 0 && cpuMilli > v.cfg.MaxStackCPUMilli {
+		errs.add("spec.containers", CodeExceedsLimit, fmt.Sprintf("total cpu %s exceeds the per-stack maximum of %s", formatMilli(cpuMilli), formatMilli(v.cfg.MaxStackCPUMilli)))
+	}
 
-	cpuMilli := max64(cpuReq, cpuLim)
-	memBytes := max64(memReq, memLim)
-	if cpuMilli <= 0 || memBytes <= 0 {
-		return 0, 0, fmt.Errorf("%w: request/limit must be set", ErrPodSpecInvalid)
+	if v.cfg.MaxStackMemoryBytes > 0 && memBytes > v.cfg.MaxStackMemoryBytes {
+		errs.add("spec.containers", CodeExceedsLimit, fmt.Sprintf("total memory %s exceeds the per-stack maximum of %s", formatBytes(memBytes), formatBytes(v.cfg.MaxStackMemoryBytes)))
 	}
+}
+
+func formatMilli(milli int64) string {
+	return resource.NewMilliQuantity(milli, resource.DecimalSI).String()
+}
 
-	return cpuMilli, memBytes, nil
+func formatBytes(b int64) string {
+	return resource.NewQuantity(b, resource.BinarySI).String()
 }
 
 func buildEqualResources(cpuMilli, memBytes int64) corev1.ResourceRequirements {
@@ -1,6 +1,8 @@
 package stack
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -204,6 +206,55 @@ spec:
 	}
 }
 
+func TestValidatorCollectsAllViolations(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	_, err := v.ValidatePodSpec(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: bad-many
+spec:
+  hostNetwork: true
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          memory: "64Mi"
+    - name: sidecar
+      image: nginx:latest
+      ports:
+        - containerPort: 9090
+          hostPort: 9090
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`, []PortSpec{{ContainerPort: 8080, Protocol: "SCTP"}})
+	if !errors.Is(err, ErrPodSpecInvalid) {
+		t.Fatalf("expected pod spec error, got %v", err)
+	}
+
+	want := []Violation{
+		{Field: "target_port[0].protocol", Code: CodeInvalid},
+		{Field: "spec.hostNetwork", Code: CodeForbidden},
+		{Field: "spec.containers[0].resources.limits.cpu", Code: CodeRequired},
+		{Field: "spec.containers[1].ports[0].hostPort", Code: CodeForbidden},
+	}
+	got := Violations(err)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), got)
+	}
+
+	for i := range want {
+		if got[i].Field != want[i].Field || got[i].Code != want[i].Code || got[i].Message == "" {
+			t.Fatalf("violation %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
 func TestValidatorCountsInitContainerResources(t *testing.T) {
 	v := NewValidator(config.StackConfig{})
 	res, err := v.ValidatePodSpec(`
@@ -376,3 +427,279 @@ spec:
 		t.Fatalf("unexpected udp protocol error: %v", err)
 	}
 }
+
+func TestValidatorNormalizesTargetPortScheme(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	res, err := v.ValidatePodSpec(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: scheme
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 80
+        - containerPort: 1337
+      resources:
+        requests:
+          cpu: "100m"
+          memory: "64Mi"
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`, []PortSpec{{ContainerPort: 80, Protocol: "TCP", Scheme: "HTTP", Name: " web "}, {ContainerPort: 1337, Protocol: "TCP"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.TargetPorts[0].Scheme != SchemeHTTP || res.TargetPorts[0].Name != "web" {
+		t.Fatalf("unexpected normalized target: %+v", res.TargetPorts[0])
+	}
+
+	if res.TargetPorts[1].Scheme != SchemeTCP {
+		t.Fatalf("expected default tcp scheme, got %q", res.TargetPorts[1].Scheme)
+	}
+}
+
+func TestValidatorRejectsDuplicateNodePorts(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	_, err := v.ValidatePodSpec(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: node-port
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+        - containerPort: 9090
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`, []PortSpec{{ContainerPort: 8080, Protocol: "TCP", NodePort: 31000}, {ContainerPort: 9090, Protocol: "TCP", NodePort: 31000}})
+	if err == nil {
+		t.Fatalf("expected duplicate node_port error")
+	}
+}
+
+func TestValidatorReadsPlacementAnnotation(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	spec := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: placed
+  annotations:
+    smctf.io/placement: %s
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+	res, err := v.ValidatePodSpec(fmt.Sprintf(spec, "Binpack"), []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if res.Placement != config.PlacementBinpack {
+		t.Fatalf("expected binpack placement, got %q", res.Placement)
+	}
+
+	if _, err := v.ValidatePodSpec(fmt.Sprintf(spec, "random"), []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}); err == nil {
+		t.Fatalf("expected unknown placement to be rejected")
+	}
+}
+
+func TestValidatorRejectsPriorityClassName(t *testing.T) {
+	v := NewValidator(config.StackConfig{})
+	_, err := v.ValidatePodSpec(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: priority
+spec:
+  priorityClassName: system-cluster-critical
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`, []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}})
+	if err == nil {
+		t.Fatalf("expected priorityClassName to be rejected")
+	}
+}
+
+func TestValidatorImagePolicy(t *testing.T) {
+	const spec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: images
+spec:
+  initContainers:
+    - name: init
+      image: %s
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+  containers:
+    - name: app
+      image: %s
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+	const digest = "@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	v := NewValidator(config.StackConfig{
+		ImageAllowlist:    []string{"ghcr.io/acme", "docker.io/library/busybox"},
+		ImageDigestPolicy: config.ImageDigestPolicyRequire,
+	})
+	ports := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+
+	if _, err := v.ValidatePodSpec(fmt.Sprintf(spec, "busybox"+digest, "ghcr.io/acme/pwn:1.0"+digest), ports); err != nil {
+		t.Fatalf("expected allowed pinned images to pass: %v", err)
+	}
+
+	cases := []struct {
+		init, app, field string
+	}{
+		{"busybox" + digest, "ghcr.io/acme/pwn:1.0", "spec.containers[0].image"},
+		{"busybox" + digest, "ghcr.io/acme-evil/miner" + digest, "spec.containers[0].image"},
+		{"alpine" + digest, "ghcr.io/acme/pwn" + digest, "spec.initContainers[0].image"},
+		{"busybox@sha256:short", "ghcr.io/acme/pwn" + digest, "spec.initContainers[0].image"},
+	}
+
+	for _, tc := range cases {
+		_, err := v.ValidatePodSpec(fmt.Sprintf(spec, tc.init, tc.app), ports)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tc.field || !errors.Is(err, ErrPodSpecInvalid) {
+			t.Fatalf("expected %s violation for %s / %s, got %v", tc.field, tc.init, tc.app, err)
+		}
+	}
+}
+
+func TestNormalizeImageRepository(t *testing.T) {
+	cases := map[string]string{
+		"nginx:1.27":                     "docker.io/library/nginx",
+		"docker.io/nginx":                "docker.io/library/nginx",
+		"acme/pwn":                       "docker.io/acme/pwn",
+		"ghcr.io/acme/pwn:1.0":           "ghcr.io/acme/pwn",
+		"registry.local:5000/acme/pwn:2": "registry.local:5000/acme/pwn",
+		"localhost/pwn":                  "localhost/pwn",
+	}
+
+	for ref, want := range cases {
+		if got := normalizeImageRepository(ref); got != want {
+			t.Fatalf("normalizeImageRepository(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestValidatorImageAllowlistShortForms(t *testing.T) {
+	const spec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: %s
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+	v := NewValidator(config.StackConfig{ImageAllowlist: []string{"nginx", "docker.io/busybox", "index.docker.io", "registry.local:5000/"}})
+	ports := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+
+	for _, image := range []string{"nginx:1.27", "docker.io/library/nginx", "busybox", "acme/pwn", "registry.local:5000/pwn:1"} {
+		if _, err := v.ValidatePodSpec(fmt.Sprintf(spec, image), ports); err != nil {
+			t.Fatalf("expected %s to be allowed: %v", image, err)
+		}
+	}
+
+	v = NewValidator(config.StackConfig{ImageAllowlist: []string{"nginx"}})
+	for _, image := range []string{"nginx-evil", "ghcr.io/nginx", "acme/nginx"} {
+		if _, err := v.ValidatePodSpec(fmt.Sprintf(spec, image), ports); !errors.Is(err, ErrPodSpecInvalid) {
+			t.Fatalf("expected %s to be rejected, got %v", image, err)
+		}
+	}
+}
+
+func TestValidatorResourceCeilingsAndDefaults(t *testing.T) {
+	const spec = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: sized
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "%s"
+    - name: sidecar
+      image: busybox:latest
+`
+	v := NewValidator(config.StackConfig{
+		MaxContainerCPUMilli:        1000,
+		MaxStackCPUMilli:            1500,
+		MaxStackMemoryBytes:         1 << 30,
+		MaxContainers:               2,
+		DefaultContainerCPUMilli:    100,
+		DefaultContainerMemoryBytes: 64 << 20,
+	})
+	ports := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+
+	res, err := v.ValidatePodSpec(fmt.Sprintf(spec, "500m"), ports)
+	if err != nil {
+		t.Fatalf("expected defaults to fill missing resources: %v", err)
+	}
+
+	if res.RequestedMilli != 600 || res.RequestedBytes != 128<<20 || !strings.Contains(res.SanitizedYAML, "memory: 64Mi") {
+		t.Fatalf("unexpected resources %dm / %d:\n%s", res.RequestedMilli, res.RequestedBytes, res.SanitizedYAML)
+	}
+
+	_, err = v.ValidatePodSpec(fmt.Sprintf(spec, "2"), ports)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "spec.containers[0].resources" || !strings.Contains(err.Error(), "maximum of 1") {
+		t.Fatalf("expected per-container cpu ceiling, got %v", err)
+	}
+
+	v.cfg.MaxStackCPUMilli = 1000
+	_, err = v.ValidatePodSpec(fmt.Sprintf(spec, "1"), ports)
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "spec.containers" || !strings.Contains(err.Error(), "per-stack") {
+		t.Fatalf("expected per-stack cpu ceiling, got %v", err)
+	}
+
+	v.cfg.MaxContainers = 1
+	if _, err := v.ValidatePodSpec(fmt.Sprintf(spec, "500m"), ports); !errors.As(err, &fieldErr) || !strings.Contains(err.Error(), "maximum of 1") {
+		t.Fatalf("expected container count limit, got %v", err)
+	}
+}
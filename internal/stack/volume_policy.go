@@ -0,0 +1,187 @@
+package stack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// validateVolumes allows only the volume types in STACK_VOLUME_ALLOWLIST. Every emptyDir
+// must set a sizeLimit of at most STACK_MAX_EMPTYDIR_SIZE, and projected volumes may only
+// combine allowed sources.
+func (v *Validator) validateVolumes(errs *ValidationErrors, vols []corev1.Volume) {
+	for i, vol := range vols {
+		field := fmt.Sprintf("spec.volumes[%d]", i)
+		kinds := volumeSourceTypes(vol.VolumeSource)
+		switch {
+		case len(kinds) == 0:
+			errs.add(field, CodeRequired, "volume source is required")
+			continue
+		case len(kinds) > 1:
+			errs.add(field, CodeInvalid, fmt.Sprintf("volume sets more than one source: %s", strings.Join(kinds, ", ")))
+			continue
+		}
+
+		if kind := kinds[0]; kind == "hostPath" || !slices.Contains(v.cfg.VolumeAllowlist, kind) {
+			errs.add(field, CodeNotAllowed, fmt.Sprintf("%s volumes are not allowed", kind))
+			continue
+		}
+
+		if vol.EmptyDir != nil {
+			if vol.EmptyDir.SizeLimit == nil || vol.EmptyDir.SizeLimit.Sign() <= 0 {
+				errs.add(field+".emptyDir.sizeLimit", CodeRequired, "sizeLimit is required")
+			} else if size := vol.EmptyDir.SizeLimit.Value(); size > v.cfg.MaxEmptyDirBytes {
+				errs.add(field+".emptyDir.sizeLimit", CodeExceedsLimit, fmt.Sprintf("%s exceeds the maximum of %s", formatBytes(size), formatBytes(v.cfg.MaxEmptyDirBytes)))
+			}
+		}
+
+		if vol.Projected != nil {
+			for j, src := range vol.Projected.Sources {
+				srcField := fmt.Sprintf("%s.projected.sources[%d]", field, j)
+				switch kinds := projectionSourceTypes(src); {
+				case len(kinds) == 0:
+					errs.add(srcField, CodeRequired, "projection source is required")
+				case len(kinds) > 1:
+					errs.add(srcField, CodeInvalid, fmt.Sprintf("projection sets more than one source: %s", strings.Join(kinds, ", ")))
+				case src.ServiceAccountToken != nil || !slices.Contains(v.cfg.VolumeAllowlist, kinds[0]):
+					errs.add(srcField, CodeNotAllowed, fmt.Sprintf("%s sources are not allowed", kinds[0]))
+				}
+			}
+		}
+	}
+}
+
+// validateSecretRefs rejects environment variables read from Secrets unless secret is in
+// STACK_VOLUME_ALLOWLIST, the same rule that applies to secret volumes.
+func (v *Validator) validateSecretRefs(errs *ValidationErrors, pod *corev1.Pod) {
+	if slices.Contains(v.cfg.VolumeAllowlist, "secret") {
+		return
+	}
+
+	visit := func(prefix string, containers []corev1.Container) {
+		for i, c := range containers {
+			for j, from := range c.EnvFrom {
+				if from.SecretRef != nil {
+					errs.add(fmt.Sprintf("%s[%d].envFrom[%d].secretRef", prefix, i, j), CodeNotAllowed, "secret references are not allowed")
+				}
+			}
+
+			for j, env := range c.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					errs.add(fmt.Sprintf("%s[%d].env[%d].valueFrom.secretKeyRef", prefix, i, j), CodeNotAllowed, "secret references are not allowed")
+				}
+			}
+		}
+	}
+
+	visit("spec.initContainers", pod.Spec.InitContainers)
+	visit("spec.containers", pod.Spec.Containers)
+}
+
+// volumeSourceTypes returns the pod spec field names of the sources a volume sets, e.g.
+// emptyDir. A valid volume sets exactly one.
+func volumeSourceTypes(src corev1.VolumeSource) []string {
+	return setSources([]sourceField{
+		{"hostPath", src.HostPath != nil},
+		{"emptyDir", src.EmptyDir != nil},
+		{"gcePersistentDisk", src.GCEPersistentDisk != nil},
+		{"awsElasticBlockStore", src.AWSElasticBlockStore != nil},
+		{"gitRepo", src.GitRepo != nil},
+		{"secret", src.Secret != nil},
+		{"nfs", src.NFS != nil},
+		{"iscsi", src.ISCSI != nil},
+		{"glusterfs", src.Glusterfs != nil},
+		{"persistentVolumeClaim", src.PersistentVolumeClaim != nil},
+		{"rbd", src.RBD != nil},
+		{"flexVolume", src.FlexVolume != nil},
+		{"cinder", src.Cinder != nil},
+		{"cephfs", src.CephFS != nil},
+		{"flocker", src.Flocker != nil},
+		{"downwardAPI", src.DownwardAPI != nil},
+		{"fc", src.FC != nil},
+		{"azureFile", src.AzureFile != nil},
+		{"configMap", src.ConfigMap != nil},
+		{"vsphereVolume", src.VsphereVolume != nil},
+		{"quobyte", src.Quobyte != nil},
+		{"azureDisk", src.AzureDisk != nil},
+		{"photonPersistentDisk", src.PhotonPersistentDisk != nil},
+		{"projected", src.Projected != nil},
+		{"portworxVolume", src.PortworxVolume != nil},
+		{"scaleIO", src.ScaleIO != nil},
+		{"storageos", src.StorageOS != nil},
+		{"csi", src.CSI != nil},
+		{"ephemeral", src.Ephemeral != nil},
+		{"image", src.Image != nil},
+	})
+}
+
+// projectionSourceTypes is volumeSourceTypes for the sources of a projected volume.
+func projectionSourceTypes(src corev1.VolumeProjection) []string {
+	return setSources([]sourceField{
+		{"secret", src.Secret != nil},
+		{"downwardAPI", src.DownwardAPI != nil},
+		{"configMap", src.ConfigMap != nil},
+		{"serviceAccountToken", src.ServiceAccountToken != nil},
+		{"clusterTrustBundle", src.ClusterTrustBundle != nil},
+		{"podCertificate", src.PodCertificate != nil},
+	})
+}
+
+// sourceField is one of the mutually exclusive source fields of a volume or projection.
+type sourceField struct {
+	name string
+	set  bool
+}
+
+func setSources(sources []sourceField) []string {
+	var out []string
+	for _, src := range sources {
+		if src.set {
+			out = append(out, src.name)
+		}
+	}
+
+	return out
+}
+
+// ephemeralStorageBytes is the node disk a pod can fill: the larger of the container
+// total and the largest init container, plus its disk-backed emptyDir volumes.
+func ephemeralStorageBytes(pod *corev1.Pod) int64 {
+	var sum, initMax int64
+	for _, c := range pod.Spec.InitContainers {
+		initMax = max64(initMax, getBytes(c.Resources.Limits, corev1.ResourceEphemeralStorage))
+	}
+
+	for _, c := range pod.Spec.Containers {
+		sum += getBytes(c.Resources.Limits, corev1.ResourceEphemeralStorage)
+	}
+
+	total := max64(sum, initMax)
+	for _, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir != nil && vol.EmptyDir.Medium != corev1.StorageMediumMemory && vol.EmptyDir.SizeLimit != nil {
+			total += vol.EmptyDir.SizeLimit.Value()
+		}
+	}
+
+	return total
+}
+
+// containerEphemeralStorage returns the larger of a container's ephemeral-storage request
+// and limit, which it then gets as both.
+func containerEphemeralStorage(r corev1.ResourceRequirements) int64 {
+	return max64(getBytes(r.Requests, corev1.ResourceEphemeralStorage), getBytes(r.Limits, corev1.ResourceEphemeralStorage))
+}
+
+func withEphemeralStorage(r corev1.ResourceRequirements, b int64) corev1.ResourceRequirements {
+	if b <= 0 {
+		return r
+	}
+
+	q := *resource.NewQuantity(b, resource.BinarySI)
+	r.Requests[corev1.ResourceEphemeralStorage] = q
+	r.Limits[corev1.ResourceEphemeralStorage] = q
+	return r
+}
@@ -0,0 +1,193 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"smctf/internal/config"
+)
+
+func volumeTestPodSpec(volumes string) string {
+	return `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: volumes
+spec:
+  volumes:
+` + volumes + `
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+          ephemeral-storage: "100Mi"
+`
+}
+
+func volumeTestConfig() config.StackConfig {
+	return config.StackConfig{
+		VolumeAllowlist:       []string{"emptyDir", "configMap", "downwardAPI"},
+		MaxEmptyDirBytes:      1 << 30,
+		WritablePathSizeBytes: 64 << 20,
+	}
+}
+
+func TestValidatorVolumePolicy(t *testing.T) {
+	cfg := volumeTestConfig()
+	cfg.VolumeAllowlist = append(cfg.VolumeAllowlist, "projected")
+	v := NewValidator(cfg)
+	ports := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+
+	res, err := v.ValidatePodSpec(volumeTestPodSpec(`
+    - name: scratch
+      emptyDir:
+        sizeLimit: 256Mi
+    - name: shm
+      emptyDir:
+        medium: Memory
+        sizeLimit: 64Mi
+    - name: config
+      configMap:
+        name: challenge`)+"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: challenge\n", ports)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if want := int64(100<<20 + 256<<20); res.RequestedEphemeralBytes != want {
+		t.Fatalf("expected %d ephemeral bytes, got %d", want, res.RequestedEphemeralBytes)
+	}
+
+	cases := map[string]string{
+		"spec.volumes[0]": `
+    - name: data
+      persistentVolumeClaim:
+        claimName: data`,
+		"spec.volumes[1]": `
+    - name: scratch
+      emptyDir:
+        sizeLimit: 1Mi
+    - name: flag
+      secret:
+        secretName: flag`,
+		"spec.volumes[0].emptyDir.sizeLimit": `
+    - name: scratch
+      emptyDir: {}`,
+		"spec.volumes[0].projected.sources[1]": `
+    - name: mixed
+      projected:
+        sources:
+          - configMap:
+              name: challenge
+          - secret:
+              name: flag`,
+	}
+	for field, volumes := range cases {
+		var fieldErr *FieldError
+		if _, err := v.ValidatePodSpec(volumeTestPodSpec(volumes), ports); !errors.As(err, &fieldErr) || fieldErr.Field != field {
+			t.Fatalf("expected error on %s, got %v", field, err)
+		}
+	}
+
+	var fieldErr *FieldError
+	_, err = v.ValidatePodSpec(volumeTestPodSpec(`
+    - name: both
+      emptyDir:
+        sizeLimit: 1Mi
+      hostPath:
+        path: /`), ports)
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "spec.volumes[0]" || fieldErr.Code != CodeInvalid {
+		t.Fatalf("expected a volume with two sources to be rejected, got %v", err)
+	}
+
+	_, err = v.ValidatePodSpec(volumeTestPodSpec(`
+    - name: scratch
+      emptyDir:
+        sizeLimit: 2Gi`), ports)
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != "2Gi exceeds the maximum of 1Gi" {
+		t.Fatalf("expected emptyDir over the maximum to be rejected, got %v", err)
+	}
+}
+
+func TestStatsReservedEphemeralStorage(t *testing.T) {
+	svc, _, _ := newTestService(func(cfg *config.StackConfig) {
+		cfg.VolumeAllowlist = []string{"emptyDir"}
+		cfg.MaxEmptyDirBytes = 1 << 30
+		cfg.WritablePathSizeBytes = 64 << 20
+		cfg.CapacitySource = config.CapacitySourceStatic
+		cfg.CapacityCPUMilli = 4000
+		cfg.CapacityMemoryBytes = 4 << 30
+	})
+
+	in := testCreateInput(CreateInput{OwnerID: "team-a", Queue: true})
+	in.PodSpecYML = volumeTestPodSpec(`
+    - name: scratch
+      emptyDir:
+        sizeLimit: 28Mi`)
+	in.TargetPorts = []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+	st, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if st.RequestedEphemeralBytes != 128<<20 {
+		t.Fatalf("expected 128Mi ephemeral storage on the stack, got %d", st.RequestedEphemeralBytes)
+	}
+
+	stats, err := svc.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	if stats.ReservedEphemeralBytes != 128<<20 {
+		t.Fatalf("expected 128Mi reserved ephemeral storage, got %d", stats.ReservedEphemeralBytes)
+	}
+}
+
+func TestValidatorSecretEnvRefs(t *testing.T) {
+	spec := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: env
+spec:
+  containers:
+    - name: app
+      image: nginx:latest
+      ports:
+        - containerPort: 8080
+      env:
+        - name: FLAG
+          valueFrom:
+            secretKeyRef:
+              name: flag
+              key: flag
+      envFrom:
+        - secretRef:
+            name: creds
+      resources:
+        limits:
+          cpu: "100m"
+          memory: "64Mi"
+`
+	ports := []PortSpec{{ContainerPort: 8080, Protocol: "TCP"}}
+
+	_, err := NewValidator(volumeTestConfig()).ValidatePodSpec(spec, ports)
+	violations := Violations(err)
+	if len(violations) != 2 ||
+		violations[0].Field != "spec.containers[0].envFrom[0].secretRef" ||
+		violations[1].Field != "spec.containers[0].env[0].valueFrom.secretKeyRef" {
+		t.Fatalf("expected secret references to be rejected, got %+v", violations)
+	}
+
+	cfg := volumeTestConfig()
+	cfg.VolumeAllowlist = append(cfg.VolumeAllowlist, "secret")
+	if _, err := NewValidator(cfg).ValidatePodSpec(spec, ports); err != nil {
+		t.Fatalf("expected secret references to pass when secret is allowed: %v", err)
+	}
+}